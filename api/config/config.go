@@ -3,6 +3,8 @@ package config
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
+	"net/netip"
 	"os"
 	"strconv"
 	"strings"
@@ -14,6 +16,15 @@ type AppConfig struct {
 	// API Server configuration
 	ServerHost string
 	ServerPort string
+	LogLevel   slog.Level // least severe level logged
+
+	// HTTP server limits
+	ServerReadTimeout       time.Duration // whole request, body included
+	ServerReadHeaderTimeout time.Duration
+	ServerWriteTimeout      time.Duration // from the end of the request headers to the end of the response
+	ServerIdleTimeout       time.Duration // keep-alive connections waiting for their next request
+	ServerMaxHeaderBytes    int
+	ShutdownTimeout         time.Duration // time allowed to drain requests and background work on SIGTERM
 
 	// Database configuration
 	DBPath           string
@@ -24,22 +35,60 @@ type AppConfig struct {
 	Environment string
 
 	// Authentication configuration
-	SessionDuration time.Duration
-	BCryptCost      int
-	MaxPasswordLen  int
-	MinPasswordLen  int
-	MaxUsernameLen  int
-	MinUsernameLen  int
+	SessionPolicy      SessionPolicy
+	MaxSessionsPerUser int // oldest sessions are logged out beyond this
+	BCryptCost         int
+	MaxPasswordLen     int
+	MinPasswordLen     int
+	MaxUsernameLen     int
+	MinUsernameLen     int
+
+	// Two-factor authentication configuration
+	TOTPIssuer           string        // name shown in authenticator apps
+	TwoFactorLoginTTL    time.Duration // time allowed to enter a code after the password
+	MaxTwoFactorAttempts int           // wrong codes allowed per login, or for 2FA changes before they are locked
+
+	// Login brute-force protection
+	LoginFailureWindow      time.Duration // failed logins older than this are forgotten
+	LoginBackoffAfter       int           // failures before each attempt must wait
+	LoginBackoffBase        time.Duration // first wait, doubled after every further failure
+	LoginBackoffMax         time.Duration
+	LoginLockoutThreshold   int // failures that lock an account
+	LoginIPLockoutThreshold int // failures from one IP address that lock it out of all logins
+	LoginLockoutDuration    time.Duration
+	SecurityNotifier        string // "email" or "none"
+
+	// Personal access token configuration
+	MaxPersonalTokens    int // unexpired tokens a user can hold
+	MaxPersonalTokenDays int // longest lifetime a token can be created with
 
 	// Content configuration
+	MaxPostTitleLength   int
+	MinPostTitleLength   int
 	MaxPostContentLength int
 	MinPostContentLength int
+	PostExcerptLength    int // characters of content shown in post lists
 	MaxCommentLength     int
 	MinCommentLength     int
 
+	// Comment thread configuration
+	MaxCommentDepth        int // deepest reply nesting allowed (top-level comments are depth 0)
+	CommentTreeDepth       int // reply levels returned with a comment page
+	CommentRepliesPageSize int // replies returned per subtree
+
+	// Search configuration
+	MinSearchQueryLength int
+	MaxSearchQueryLength int
+
+	// Moderation configuration
+	MaxReportDetailsLength int // report details and moderator notes
+	MaxSanctionHours       int // longest suspension or mute
+
 	// Rate limiting configuration
-	RateLimitRequests int
-	RateLimitWindow   int // in minutes
+	RateLimitRequests int             // default bucket for requests no rule matches
+	RateLimitWindow   int             // in minutes
+	RateLimitRules    []RateLimitRule // first matching rule wins
+	RateLimitStore    string          // "memory" or "sqlite"
 
 	// Pagination configuration
 	DefaultPageSize int
@@ -50,6 +99,13 @@ type AppConfig struct {
 	MaxCategories int
 	MinCategories int
 
+	// Reverse proxies allowed to report the client address in X-Forwarded-For
+	TrustedProxies []netip.Prefix
+
+	// Metrics configuration
+	MetricsEnabled         bool
+	MetricsAllowedNetworks []netip.Prefix // clients allowed to scrape /metrics, empty for anyone
+
 	// CORS configuration (for frontend communication)
 	AllowedOrigins string // comma-separated origins
 	AllowedMethods string
@@ -59,6 +115,30 @@ type AppConfig struct {
 	GoogleOAuthClientID     string
 	GoogleOAuthClientSecret string
 	GoogleOAuthRedirectURL  string
+	GitHubOAuthClientID     string
+	GitHubOAuthClientSecret string
+	GitHubOAuthRedirectURL  string
+	OIDCProviderName        string // route name of the generic OpenID Connect provider, e.g. "keycloak"
+	OIDCDisplayName         string
+	OIDCIssuerURL           string // discovery document is read from <issuer>/.well-known/openid-configuration
+	OIDCClientID            string
+	OIDCClientSecret        string
+	OIDCRedirectURL         string
+	OIDCScopes              string        // space-separated
+	OAuthAutoLinkVerified   bool          // log provider users into an existing account with the same verified email
+	OAuthStateTTL           time.Duration // time allowed to complete a login at the provider
+
+	// Email configuration
+	Mailer                string // "smtp" or "log"
+	MailFrom              string
+	MailLogPath           string // where the log mailer writes, empty for the server log
+	SMTPHost              string
+	SMTPPort              string
+	SMTPUsername          string
+	SMTPPassword          string
+	FrontendURL           string // base of links sent by email
+	VerifyEmailTokenTTL   time.Duration
+	ResetPasswordTokenTTL time.Duration
 
 	// File/Image configuration (for future use)
 
@@ -77,6 +157,25 @@ func LoadConfig() error {
 	// API Server configuration
 	Config.ServerHost = getEnv("SERVER_HOST", "localhost")
 	Config.ServerPort = getEnv("SERVER_PORT", "8080")
+	logLevel := getEnv("LOG_LEVEL", "info")
+	if err := Config.LogLevel.UnmarshalText([]byte(logLevel)); err != nil {
+		return fmt.Errorf("unknown LOG_LEVEL %q, expected debug, info, warn or error", logLevel)
+	}
+
+	// HTTP server limits
+	Config.ServerReadTimeout = getEnvAsDuration("SERVER_READ_TIMEOUT", 15*time.Second)
+	Config.ServerReadHeaderTimeout = getEnvAsDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second)
+	Config.ServerWriteTimeout = getEnvAsDuration("SERVER_WRITE_TIMEOUT", 30*time.Second)
+	Config.ServerIdleTimeout = getEnvAsDuration("SERVER_IDLE_TIMEOUT", 2*time.Minute)
+	Config.ServerMaxHeaderBytes = getEnvAsInt("SERVER_MAX_HEADER_BYTES", 1<<20)
+	Config.ShutdownTimeout = getEnvAsDuration("SHUTDOWN_TIMEOUT", 10*time.Second)
+	if Config.ServerReadTimeout <= 0 || Config.ServerReadHeaderTimeout <= 0 ||
+		Config.ServerWriteTimeout <= 0 || Config.ServerIdleTimeout <= 0 {
+		return fmt.Errorf("SERVER_READ_TIMEOUT, SERVER_READ_HEADER_TIMEOUT, SERVER_WRITE_TIMEOUT and SERVER_IDLE_TIMEOUT must be positive")
+	}
+	if Config.ServerMaxHeaderBytes < 1 || Config.ShutdownTimeout <= 0 {
+		return fmt.Errorf("SERVER_MAX_HEADER_BYTES and SHUTDOWN_TIMEOUT must be positive")
+	}
 
 	// Database configuration
 	Config.DBPath = getEnv("DB_PATH", "./DBPath/forum.db")
@@ -88,24 +187,74 @@ func LoadConfig() error {
 	Config.Environment = getEnv("ENVIRONMENT", "development")
 
 	// Authentication configuration
-	Config.SessionDuration = getEnvAsDuration("SESSION_DURATION", 24*time.Hour)
+	Config.SessionPolicy, err = loadSessionPolicy()
+	if err != nil {
+		return err
+	}
+	Config.MaxSessionsPerUser = getEnvAsInt("MAX_SESSIONS_PER_USER", 10)
 	Config.BCryptCost = getEnvAsInt("BCRYPT_COST", 10) // 10 for dev, 12+ for production
 	Config.MaxUsernameLen = getEnvAsInt("MAX_USERNAME_LENGTH", 15)
 	Config.MinUsernameLen = getEnvAsInt("MIN_USERNAME_LENGTH", 5)
 	Config.MaxPasswordLen = getEnvAsInt("MAX_PASSWORD_LENGTH", 15)
 	Config.MinPasswordLen = getEnvAsInt("MIN_PASSWORD_LENGTH", 3)
 
+	// Two-factor authentication configuration
+	Config.TOTPIssuer = getEnv("TOTP_ISSUER", "Forum404NotFound")
+	Config.TwoFactorLoginTTL = getEnvAsDuration("TWO_FACTOR_LOGIN_TTL", 5*time.Minute)
+	Config.MaxTwoFactorAttempts = getEnvAsInt("MAX_TWO_FACTOR_ATTEMPTS", 5)
+
+	// Login brute-force protection
+	Config.LoginFailureWindow = getEnvAsDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute)
+	Config.LoginBackoffAfter = getEnvAsInt("LOGIN_BACKOFF_AFTER", 3)
+	Config.LoginBackoffBase = getEnvAsDuration("LOGIN_BACKOFF_BASE", time.Second)
+	Config.LoginBackoffMax = getEnvAsDuration("LOGIN_BACKOFF_MAX", time.Minute)
+	Config.LoginLockoutThreshold = getEnvAsInt("LOGIN_LOCKOUT_THRESHOLD", 10)
+	Config.LoginIPLockoutThreshold = getEnvAsInt("LOGIN_IP_LOCKOUT_THRESHOLD", 50)
+	Config.LoginLockoutDuration = getEnvAsDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+	Config.SecurityNotifier = getEnv("SECURITY_NOTIFIER", "email")
+
+	// Personal access token configuration
+	Config.MaxPersonalTokens = getEnvAsInt("MAX_PERSONAL_TOKENS_PER_USER", 20)
+	Config.MaxPersonalTokenDays = getEnvAsInt("MAX_PERSONAL_TOKEN_DAYS", 365)
+
 	// Content configuration - Posts
+	Config.MaxPostTitleLength = getEnvAsInt("MAX_POST_TITLE_LENGTH", 100)
+	Config.MinPostTitleLength = getEnvAsInt("MIN_POST_TITLE_LENGTH", 5)
 	Config.MaxPostContentLength = getEnvAsInt("MAX_POST_CONTENT_LENGTH", 500)
 	Config.MinPostContentLength = getEnvAsInt("MIN_POST_CONTENT_LENGTH", 10)
+	Config.PostExcerptLength = getEnvAsInt("POST_EXCERPT_LENGTH", 160)
 
 	// Content configuration - Comments
 	Config.MaxCommentLength = getEnvAsInt("MAX_COMMENT_LENGTH", 150)
 	Config.MinCommentLength = getEnvAsInt("MIN_COMMENT_LENGTH", 5)
 
+	// Comment thread configuration
+	Config.MaxCommentDepth = getEnvAsInt("MAX_COMMENT_DEPTH", 5)
+	Config.CommentTreeDepth = getEnvAsInt("COMMENT_TREE_DEPTH", 3)
+	Config.CommentRepliesPageSize = getEnvAsInt("COMMENT_REPLIES_PAGE_SIZE", 5)
+
+	// Search configuration
+	Config.MinSearchQueryLength = getEnvAsInt("MIN_SEARCH_QUERY_LENGTH", 2)
+	Config.MaxSearchQueryLength = getEnvAsInt("MAX_SEARCH_QUERY_LENGTH", 100)
+
+	// Moderation configuration
+	Config.MaxReportDetailsLength = getEnvAsInt("MAX_REPORT_DETAILS_LENGTH", 500)
+	Config.MaxSanctionHours = getEnvAsInt("MAX_SANCTION_HOURS", 8760) // one year
+
 	// Rate limiting configuration
-	Config.RateLimitRequests = getEnvAsInt("RATE_LIMIT_REQUESTS", 100000)
-	Config.RateLimitWindow = getEnvAsInt("RATE_LIMIT_WINDOW", 60) // minutes
+	Config.RateLimitRequests = getEnvAsInt("RATE_LIMIT_REQUESTS", 120)
+	Config.RateLimitWindow = getEnvAsInt("RATE_LIMIT_WINDOW", 1) // minutes
+	Config.RateLimitRules, err = parseRateLimitRules(getEnv("RATE_LIMIT_RULES", defaultRateLimitRules))
+	if err != nil {
+		return err
+	}
+	Config.RateLimitStore = getEnv("RATE_LIMIT_STORE", "memory")
+	if Config.RateLimitStore != "memory" && Config.RateLimitStore != "sqlite" {
+		return fmt.Errorf("unknown RATE_LIMIT_STORE %q, expected memory or sqlite", Config.RateLimitStore)
+	}
+	if Config.RateLimitRequests < 1 || Config.RateLimitWindow < 1 {
+		return fmt.Errorf("RATE_LIMIT_REQUESTS and RATE_LIMIT_WINDOW must be positive")
+	}
 
 	// Pagination configuration
 	Config.DefaultPageSize = getEnvAsInt("DEFAULT_PAGE_SIZE", 20)
@@ -116,6 +265,20 @@ func LoadConfig() error {
 	Config.MaxCategories = getEnvAsInt("MAX_CATEGORIES_PER_POST", 5)
 	Config.MinCategories = getEnvAsInt("MIN_CATEGORIES_PER_POST", 1)
 
+	// Trusted reverse proxies; the frontend on this machine by default. Set it empty to
+	// trust no one and use the connection's address as is.
+	Config.TrustedProxies, err = parseNetworks("TRUSTED_PROXIES", getEnv("TRUSTED_PROXIES", defaultTrustedProxies))
+	if err != nil {
+		return err
+	}
+
+	// Metrics configuration
+	Config.MetricsEnabled = getEnvAsBool("METRICS_ENABLED", true)
+	Config.MetricsAllowedNetworks, err = parseNetworks("METRICS_ALLOWED_NETWORKS", getEnv("METRICS_ALLOWED_NETWORKS", ""))
+	if err != nil {
+		return err
+	}
+
 	// CORS configuration (for frontend communication)
 	Config.AllowedOrigins = getEnv("ALLOWED_ORIGINS", "http://localhost:3000")
 	Config.AllowedMethods = getEnv("ALLOWED_METHODS", "GET,POST,PUT,DELETE,OPTIONS")
@@ -125,6 +288,30 @@ func LoadConfig() error {
 	Config.GoogleOAuthClientID = getEnv("GOOGLE_OAUTH_CLIENT_ID", "79779164831-4obtdfsgqnjakbd1n8lj1ma28lg1i7dl.apps.googleusercontent.com")
 	Config.GoogleOAuthClientSecret = getEnv("GOOGLE_OAUTH_CLIENT_SECRET", "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")
 	Config.GoogleOAuthRedirectURL = getEnv("GOOGLE_OAUTH_REDIRECT_URL", "http://localhost:8080/auth/google/callback")
+	Config.GitHubOAuthClientID = getEnv("GITHUB_OAUTH_CLIENT_ID", "")
+	Config.GitHubOAuthClientSecret = getEnv("GITHUB_OAUTH_CLIENT_SECRET", "")
+	Config.GitHubOAuthRedirectURL = getEnv("GITHUB_OAUTH_REDIRECT_URL", "http://localhost:8080/auth/github/callback")
+	Config.OIDCProviderName = getEnv("OIDC_PROVIDER_NAME", "oidc")
+	Config.OIDCDisplayName = getEnv("OIDC_DISPLAY_NAME", "Single Sign-On")
+	Config.OIDCIssuerURL = strings.TrimRight(getEnv("OIDC_ISSUER_URL", ""), "/")
+	Config.OIDCClientID = getEnv("OIDC_CLIENT_ID", "")
+	Config.OIDCClientSecret = getEnv("OIDC_CLIENT_SECRET", "")
+	Config.OIDCRedirectURL = getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/auth/oidc/callback")
+	Config.OIDCScopes = getEnv("OIDC_SCOPES", "openid email profile")
+	Config.OAuthAutoLinkVerified = getEnvAsBool("OAUTH_AUTO_LINK_VERIFIED_EMAIL", false)
+	Config.OAuthStateTTL = getEnvAsDuration("OAUTH_STATE_TTL", 10*time.Minute)
+
+	// Email configuration
+	Config.Mailer = getEnv("MAILER", "log")
+	Config.MailFrom = getEnv("MAIL_FROM", "Forum <no-reply@localhost>")
+	Config.MailLogPath = getEnv("MAIL_LOG_PATH", "")
+	Config.SMTPHost = getEnv("SMTP_HOST", "")
+	Config.SMTPPort = getEnv("SMTP_PORT", "587")
+	Config.SMTPUsername = getEnv("SMTP_USERNAME", "")
+	Config.SMTPPassword = getEnv("SMTP_PASSWORD", "")
+	Config.FrontendURL = strings.TrimRight(getEnv("FRONTEND_URL", "http://localhost:3000"), "/")
+	Config.VerifyEmailTokenTTL = getEnvAsDuration("VERIFY_EMAIL_TOKEN_TTL", 48*time.Hour)
+	Config.ResetPasswordTokenTTL = getEnvAsDuration("RESET_PASSWORD_TOKEN_TTL", time.Hour)
 
 	return nil
 
@@ -197,6 +384,15 @@ func getEnvAsInt(key string, fallback int) int {
 	return fallback
 }
 
+func getEnvAsBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
 func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
 	if value, exists := os.LookupEnv(key); exists {
 		if duration, err := time.ParseDuration(value); err == nil {
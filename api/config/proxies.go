@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// defaultTrustedProxies are the loopback addresses the frontend calls the API from when
+// both run on one machine, so browsers keep their own addresses without any setup
+const defaultTrustedProxies = "127.0.0.1,::1"
+
+// parseNetworks reads the comma-separated CIDRs or single addresses of a setting, e.g.
+// "10.0.0.0/8, 172.17.0.1"
+func parseNetworks(setting, value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s entry %q: %v", setting, entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %v", setting, entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
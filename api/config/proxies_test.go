@@ -0,0 +1,85 @@
+package config
+
+import (
+	"net/netip"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestParseNetworks(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "10.0.0.1", want: []string{"10.0.0.1/32"}},
+		{value: " 10.1.2.3/8 , 2001:db8::1", want: []string{"10.0.0.0/8", "2001:db8::1/128"}},
+		{value: "::ffff:10.0.0.1", want: []string{"10.0.0.1/32"}},
+		{value: "fd00::/8,", want: []string{"fd00::/8"}},
+		{value: "10.0.0.0/33", wantErr: true},
+		{value: "proxy.internal", wantErr: true},
+		{value: "10.0.0.1, nope", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseNetworks("TRUSTED_PROXIES", tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseNetworks(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		var gotStrings []string
+		for _, p := range got {
+			gotStrings = append(gotStrings, p.String())
+		}
+		if !slices.Equal(gotStrings, tt.want) {
+			t.Errorf("parseNetworks(%q) = %v, want %v", tt.value, gotStrings, tt.want)
+		}
+	}
+
+	// A single address must match itself
+	prefixes, _ := parseNetworks("TRUSTED_PROXIES", "10.0.0.1")
+	if !prefixes[0].Contains(netip.MustParseAddr("10.0.0.1")) || prefixes[0].Contains(netip.MustParseAddr("10.0.0.2")) {
+		t.Errorf("prefix %v does not match exactly 10.0.0.1", prefixes[0])
+	}
+}
+
+func TestLoadConfigTrustsLoopbackByDefault(t *testing.T) {
+	saved := Config
+	t.Cleanup(func() { Config = saved })
+
+	tests := []struct {
+		name  string
+		set   bool
+		value string
+		want  []string
+	}{
+		{"unset", false, "", []string{"127.0.0.1/32", "::1/128"}},
+		{"set empty", true, "", nil},
+		{"set", true, "172.28.0.3", []string{"172.28.0.3/32"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.set {
+				t.Setenv("TRUSTED_PROXIES", tt.value)
+			} else {
+				// t.Setenv restores the variable afterwards; unset it for this test only
+				t.Setenv("TRUSTED_PROXIES", "")
+				os.Unsetenv("TRUSTED_PROXIES")
+			}
+
+			if err := LoadConfig(); err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			var got []string
+			for _, p := range Config.TrustedProxies {
+				got = append(got, p.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("TrustedProxies = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
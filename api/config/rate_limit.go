@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRateLimitRules are strict on login, registration and content creation and
+// relaxed on reads. Anything they do not match falls back to RATE_LIMIT_REQUESTS per
+// RATE_LIMIT_WINDOW.
+const defaultRateLimitRules = "POST /api/auth/login 10/15m, " +
+	"POST /api/auth/login/2fa 10/15m, " +
+	"POST /api/auth/register 5/1h, " +
+	"POST /api/auth/forgot-password 5/1h, " +
+	"POST /api/auth/resend-verification 5/1h, " +
+	"POST /api/posts/create 10/10m, " +
+	"POST /api/comments/* 30/10m, " +
+	"POST /api/reports/create 10/1h, " +
+	"GET * 600/1m"
+
+// RateLimitRule gives the requests matching Method and Path their own token bucket:
+// Requests tokens that refill evenly over Window
+type RateLimitRule struct {
+	Method   string // "*" matches any method
+	Path     string // a trailing "*" matches any suffix
+	Requests int
+	Window   time.Duration
+}
+
+// Name identifies the rule in bucket keys and headers
+func (r RateLimitRule) Name() string {
+	return r.Method + " " + r.Path
+}
+
+// Matches reports whether the rule applies to a request
+func (r RateLimitRule) Matches(method, path string) bool {
+	if r.Method != "*" && r.Method != method {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.Path, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return r.Path == path
+}
+
+// parseRateLimitRules reads comma-separated "METHOD PATH REQUESTS/WINDOW" entries,
+// e.g. "POST /api/auth/login 10/15m, GET * 600/1m"
+func parseRateLimitRules(value string) ([]RateLimitRule, error) {
+	var rules []RateLimitRule
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.Fields(entry)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_RULES entry %q, expected \"METHOD PATH REQUESTS/WINDOW\"", entry)
+		}
+
+		requestsStr, windowStr, ok := strings.Cut(fields[2], "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_RULES limit %q, expected REQUESTS/WINDOW", fields[2])
+		}
+		requests, err := strconv.Atoi(requestsStr)
+		if err != nil || requests < 1 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_RULES request count %q", requestsStr)
+		}
+		window, err := time.ParseDuration(windowStr)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_RULES window %q", windowStr)
+		}
+
+		path := fields[1]
+		if path != "*" && !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_RULES path %q, expected /path or *", path)
+		}
+
+		rules = append(rules, RateLimitRule{
+			Method:   strings.ToUpper(fields[0]),
+			Path:     path,
+			Requests: requests,
+			Window:   window,
+		})
+	}
+	return rules, nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []RateLimitRule
+		wantErr bool
+	}{
+		{name: "empty", value: ""},
+		{
+			name:  "several rules",
+			value: "post /api/auth/login 10/15m, GET * 600/1m,",
+			want: []RateLimitRule{
+				{Method: "POST", Path: "/api/auth/login", Requests: 10, Window: 15 * time.Minute},
+				{Method: "GET", Path: "*", Requests: 600, Window: time.Minute},
+			},
+		},
+		{name: "missing limit", value: "POST /api/auth/login", wantErr: true},
+		{name: "limit without window", value: "POST /api/auth/login 10", wantErr: true},
+		{name: "zero requests", value: "POST /api/auth/login 0/1m", wantErr: true},
+		{name: "bad window", value: "POST /api/auth/login 10/soon", wantErr: true},
+		{name: "negative window", value: "POST /api/auth/login 10/-1m", wantErr: true},
+		{name: "relative path", value: "POST api/auth/login 10/1m", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRateLimitRules(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRateLimitRules(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseRateLimitRules(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("rule %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := parseRateLimitRules(defaultRateLimitRules); err != nil {
+		t.Errorf("default rules do not parse: %v", err)
+	}
+}
+
+func TestRateLimitRuleMatches(t *testing.T) {
+	tests := []struct {
+		rule   RateLimitRule
+		method string
+		path   string
+		want   bool
+	}{
+		{RateLimitRule{Method: "POST", Path: "/api/auth/login"}, "POST", "/api/auth/login", true},
+		{RateLimitRule{Method: "POST", Path: "/api/auth/login"}, "GET", "/api/auth/login", false},
+		{RateLimitRule{Method: "POST", Path: "/api/auth/login"}, "POST", "/api/auth/login/2fa", false},
+		{RateLimitRule{Method: "POST", Path: "/api/comments/*"}, "POST", "/api/comments/create/42", true},
+		{RateLimitRule{Method: "POST", Path: "/api/comments/*"}, "POST", "/api/posts/create", false},
+		{RateLimitRule{Method: "GET", Path: "*"}, "GET", "/api/posts", true},
+		{RateLimitRule{Method: "*", Path: "/api/health"}, "HEAD", "/api/health", true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rule.Matches(tt.method, tt.path); got != tt.want {
+			t.Errorf("%s Matches(%s %s) = %v, want %v", tt.rule.Name(), tt.method, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// Session IP policies
+const (
+	SessionIPOff    = "off"    // sessions survive any address change
+	SessionIPStrict = "strict" // any change from the login address logs the session out
+	SessionIPSubnet = "subnet" // the address may move within the login address's network
+)
+
+// SessionPolicy decides how long sessions last and which address changes they survive
+type SessionPolicy struct {
+	Duration    time.Duration // sliding: renewed while the session is in use
+	IdleTimeout time.Duration // unused this long logs the session out, 0 to disable
+	MaxLifetime time.Duration // since login, however active the session is; 0 for no limit
+	IPMode      string        // one of the SessionIP policies
+	IPv4Prefix  int           // network size compared in subnet mode
+	IPv6Prefix  int
+}
+
+// ExpiresAt returns when a session created at createdAt and used at now expires:
+// Duration from now, but never past its MaxLifetime
+func (p SessionPolicy) ExpiresAt(createdAt, now time.Time) time.Time {
+	expiresAt := now.Add(p.Duration)
+	if p.MaxLifetime > 0 {
+		if limit := createdAt.Add(p.MaxLifetime); expiresAt.After(limit) {
+			return limit
+		}
+	}
+	return expiresAt
+}
+
+// Renewal returns the new expiry of a session used at now. Sessions are renewed once less
+// than half of Duration is left, so an active session is written back at most twice per Duration.
+func (p SessionPolicy) Renewal(createdAt, expiresAt, now time.Time) (time.Time, bool) {
+	if expiresAt.Sub(now) >= p.Duration/2 {
+		return expiresAt, false
+	}
+	renewed := p.ExpiresAt(createdAt, now)
+	return renewed, renewed.After(expiresAt)
+}
+
+// Ended reports why a session that is not yet expired must still be logged out, or ""
+func (p SessionPolicy) Ended(createdAt, lastSeenAt, now time.Time) string {
+	if p.MaxLifetime > 0 && !now.Before(createdAt.Add(p.MaxLifetime)) {
+		return "maximum lifetime reached"
+	}
+	if p.IdleTimeout > 0 && now.Sub(lastSeenAt) > p.IdleTimeout {
+		return "idle timeout"
+	}
+	return ""
+}
+
+// AllowsIP reports whether a session started from loginIP may be used from currentIP
+func (p SessionPolicy) AllowsIP(loginIP, currentIP string) bool {
+	if p.IPMode == SessionIPOff || loginIP == currentIP {
+		return true
+	}
+	if p.IPMode == SessionIPStrict {
+		return false
+	}
+
+	from, err := netip.ParseAddr(loginIP)
+	if err != nil {
+		return false
+	}
+	to, err := netip.ParseAddr(currentIP)
+	if err != nil || from.Is4() != to.Is4() {
+		return false
+	}
+
+	bits := p.IPv6Prefix
+	if from.Is4() {
+		bits = p.IPv4Prefix
+	}
+	network, err := from.Prefix(bits)
+	return err == nil && network.Contains(to)
+}
+
+// loadSessionPolicy reads and validates the SESSION_* settings
+func loadSessionPolicy() (SessionPolicy, error) {
+	p := SessionPolicy{
+		Duration:    getEnvAsDuration("SESSION_DURATION", 24*time.Hour),
+		IdleTimeout: getEnvAsDuration("SESSION_IDLE_TIMEOUT", 0),
+		MaxLifetime: getEnvAsDuration("SESSION_MAX_LIFETIME", 30*24*time.Hour),
+		IPMode:      getEnv("SESSION_IP_POLICY", SessionIPSubnet),
+		IPv4Prefix:  getEnvAsInt("SESSION_IPV4_PREFIX", 16),
+		IPv6Prefix:  getEnvAsInt("SESSION_IPV6_PREFIX", 48),
+	}
+
+	switch {
+	case p.IPMode != SessionIPOff && p.IPMode != SessionIPStrict && p.IPMode != SessionIPSubnet:
+		return p, fmt.Errorf("unknown SESSION_IP_POLICY %q, expected off, strict or subnet", p.IPMode)
+	case p.IPv4Prefix < 0 || p.IPv4Prefix > 32:
+		return p, fmt.Errorf("SESSION_IPV4_PREFIX must be between 0 and 32")
+	case p.IPv6Prefix < 0 || p.IPv6Prefix > 128:
+		return p, fmt.Errorf("SESSION_IPV6_PREFIX must be between 0 and 128")
+	case p.Duration <= 0:
+		return p, fmt.Errorf("SESSION_DURATION must be positive")
+	case p.IdleTimeout < 0 || p.MaxLifetime < 0:
+		return p, fmt.Errorf("SESSION_IDLE_TIMEOUT and SESSION_MAX_LIFETIME cannot be negative")
+	case p.IdleTimeout > 0 && p.IdleTimeout < time.Minute:
+		// Last use is recorded about once a minute
+		return p, fmt.Errorf("SESSION_IDLE_TIMEOUT must be at least 1m")
+	}
+	return p, nil
+}
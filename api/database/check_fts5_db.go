@@ -0,0 +1,23 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// checkFTS5Support makes sure the linked SQLite library was compiled with FTS5.
+// mattn/go-sqlite3 only enables it with the sqlite_fts5 build tag.
+func checkFTS5Support(db *sql.DB) error {
+	var enabled int
+	err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled)
+	if err != nil {
+		return fmt.Errorf("failed to check FTS5 support: %v", err)
+	}
+
+	if enabled == 0 {
+		return errors.New("SQLite was built without FTS5, rebuild with: go build -tags sqlite_fts5")
+	}
+
+	return nil
+}
@@ -3,28 +3,57 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/database/migrations"
 )
 
-// InitDB initializes the database only if it does not exist
+// InitDB opens the database and brings its schema up to date
 func InitDB() (*sql.DB, error) {
+	db, err := OpenDB()
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply pending schema migrations (each runs in its own transaction)
+	applied, err := migrations.Up(db)
+	for _, m := range applied {
+		slog.Info("Applied migration", "version", m.Version, "name", m.Name)
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %v", err)
+	}
+
+	version, err := migrations.CurrentVersion(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	slog.Info("Database schema ready", "version", version)
+
+	// Seed categories on a fresh database (no-op once they exist)
+	if err := populateCategories(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to populate categories: %v", err)
+	}
+
+	return db, nil
+}
+
+// OpenDB connects to the database without touching its schema
+func OpenDB() (*sql.DB, error) {
 	dbDir := filepath.Dir(config.Config.DBPath)
 
 	if err := os.MkdirAll(dbDir, 0o755); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %v", err)
 	}
 
-	// Check if the database file exists
-	dbExists := true
-	if _, err := os.Stat(config.Config.DBPath); os.IsNotExist(err) {
-		dbExists = false
-	}
-
 	// Connect to SQLite database
 	db, err := sql.Open("sqlite3", config.Config.DBPath+"?_foreign_keys=on&_journal_mode=WAL&_synchronous=NORMAL&_cache_size=10000")
 	if err != nil {
@@ -42,29 +71,22 @@ func InitDB() (*sql.DB, error) {
 	db.SetMaxIdleConns(config.Config.DBMaxConnections / 2) // Half of max connections for idle
 	db.SetConnMaxLifetime(30 * time.Minute)                // Connections expire after 30 minutes
 	db.SetConnMaxIdleTime(5 * time.Minute)                 // Idle connections timeout after 5 minutes
-	// If the database didn't exist before, create schema and populate data
-	if !dbExists {
-		fmt.Println("Initializing new database...")
-
-		if err := createTables(db); err != nil {
-			db.Close()
-			return nil, fmt.Errorf("failed to create tables: %v", err)
-		}
 
-		if err := createIndexes(db); err != nil {
-			db.Close()
-			return nil, fmt.Errorf("failed to create indexes: %v", err)
-		}
-
-		if err := populateCategories(db); err != nil {
-			db.Close()
-			return nil, fmt.Errorf("failed to populate categories: %v", err)
-		}
-
-		fmt.Println("Database initialized successfully.")
-	} else {
-		fmt.Println("Database already exists. Skipping initialization.")
+	// Full-text search needs FTS5 compiled into the driver
+	if err := checkFTS5Support(db); err != nil {
+		db.Close()
+		return nil, err
 	}
 
 	return db, nil
 }
+
+// CloseDB folds the write-ahead log back into the database file and closes the
+// database, so the file is complete on its own once the server has stopped
+func CloseDB(db *sql.DB) error {
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to checkpoint database: %v", err)
+	}
+	return db.Close()
+}
@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Usage describes the migrate subcommand
+const Usage = `usage: migrate <command>
+
+commands:
+  status   list migrations and whether they are applied
+  up       apply all pending migrations
+  to N     apply pending migrations up to version N`
+
+// RunCommand runs "migrate status|up|to N" against db, writing a report to out
+func RunCommand(db *sql.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(Usage)
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := GetStatus(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%04d  %-24s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+
+	case "up":
+		applied, err := Up(db)
+		reportApplied(out, applied)
+		return err
+
+	case "to":
+		if len(args) != 2 {
+			return errors.New(Usage)
+		}
+		target, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		applied, err := UpTo(db, target)
+		reportApplied(out, applied)
+		return err
+
+	default:
+		return errors.New(Usage)
+	}
+}
+
+func reportApplied(out io.Writer, applied []Migration) {
+	if len(applied) == 0 {
+		fmt.Fprintln(out, "No pending migrations.")
+		return
+	}
+	for _, m := range applied {
+		fmt.Fprintf(out, "Applied %04d_%s\n", m.Version, m.Name)
+	}
+}
@@ -0,0 +1,103 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Databases created before schema_migrations existed were built from the schema in
+// code and patched by hand with the old *_migration.sql scripts. Each probe checks
+// whether the change made by that migration is already present, so those databases
+// are stamped with the versions they actually have instead of re-running them.
+var legacyProbes = []struct {
+	version int
+	query   string
+}{
+	{1, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'users'`},
+	{2, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'posts_fts'`},
+	{3, `SELECT COUNT(*) FROM pragma_table_info('comments') WHERE name = 'parent_comment_id'`},
+	{4, `SELECT COUNT(*) FROM pragma_table_info('posts') WHERE name = 'title'`},
+}
+
+// Columns added by the old oauth_migration.sql, which very old databases may lack
+var legacyOAuthStatements = []string{
+	`ALTER TABLE users ADD COLUMN provider VARCHAR(50) DEFAULT NULL;`,
+	`ALTER TABLE users ADD COLUMN provider_id VARCHAR(255) DEFAULT NULL;`,
+	`ALTER TABLE users ADD COLUMN provider_email VARCHAR(255) DEFAULT NULL;`,
+	`CREATE INDEX IF NOT EXISTS idx_users_provider_id ON users(provider, provider_id);`,
+	`CREATE INDEX IF NOT EXISTS idx_users_provider_email ON users(provider, provider_email);`,
+}
+
+// ensureMigrationsTable creates schema_migrations, stamping legacy databases on first run
+func ensureMigrationsTable(db *sql.DB) error {
+	var exists int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check schema_migrations table: %v", err)
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	migrations, err := Load()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(createMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %v", err)
+	}
+
+	// Stamp the leading run of migrations whose changes are already in place
+	now := time.Now()
+	for _, probe := range legacyProbes {
+		var found int
+		if err := tx.QueryRow(probe.query).Scan(&found); err != nil {
+			return fmt.Errorf("failed to inspect legacy schema: %v", err)
+		}
+		if found == 0 {
+			break
+		}
+
+		if probe.version == 1 {
+			if err := upgradeLegacyOAuthColumns(tx); err != nil {
+				return err
+			}
+		}
+
+		m := migrations[probe.version-1]
+		_, err = tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			m.Version, m.Name, now)
+		if err != nil {
+			return fmt.Errorf("failed to record legacy migration %04d: %v", m.Version, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// upgradeLegacyOAuthColumns adds the OAuth columns if the hand-applied script was never run
+func upgradeLegacyOAuthColumns(tx *sql.Tx) error {
+	var hasProvider int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('users') WHERE name = 'provider'`).Scan(&hasProvider)
+	if err != nil {
+		return fmt.Errorf("failed to inspect users table: %v", err)
+	}
+	if hasProvider > 0 {
+		return nil
+	}
+
+	for _, stmt := range legacyOAuthStatements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to execute statement: %s: %v", stmt, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+//go:build sqlite_fts5
+
+package migrations
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// applyUnrecorded builds a database the way it was done before schema_migrations
+// existed, running the first n migrations without recording them
+func applyUnrecorded(t *testing.T, db *sql.DB, n int) {
+	t.Helper()
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range migrations[:n] {
+		if _, err := db.Exec(m.SQL); err != nil {
+			t.Fatalf("migration %04d_%s: %v", m.Version, m.Name, err)
+		}
+	}
+}
+
+func TestLegacyDatabaseIsStamped(t *testing.T) {
+	latest, err := LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 0; n <= len(legacyProbes)+1; n++ {
+		db := openTestDB(t)
+		applyUnrecorded(t, db, n)
+
+		// Only the changes the probes can see are stamped; later ones run again
+		want := min(n, len(legacyProbes))
+		if v, err := CurrentVersion(db); err != nil || v != want {
+			t.Errorf("legacy database with %d migrations stamped at %d, %v, want %d", n, v, err, want)
+			continue
+		}
+		if n > len(legacyProbes) {
+			continue // 0005 adds columns, so running it again fails; no legacy database has it
+		}
+		if _, err := Up(db); err != nil {
+			t.Errorf("Up on a legacy database with %d migrations: %v", n, err)
+		}
+		if v, _ := CurrentVersion(db); v != latest {
+			t.Errorf("legacy database with %d migrations ended at version %d, want %d", n, v, latest)
+		}
+	}
+}
+
+func TestLegacyDatabaseGetsOAuthColumns(t *testing.T) {
+	db := openTestDB(t)
+	applyUnrecorded(t, db, 1)
+
+	// A database from before the hand-applied oauth_migration.sql
+	for _, stmt := range []string{
+		"DROP INDEX idx_users_provider_id",
+		"DROP INDEX idx_users_provider_email",
+		"ALTER TABLE users DROP COLUMN provider",
+		"ALTER TABLE users DROP COLUMN provider_id",
+		"ALTER TABLE users DROP COLUMN provider_email",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if v, err := CurrentVersion(db); err != nil || v != 1 {
+		t.Fatalf("CurrentVersion() = %d, %v, want 1", v, err)
+	}
+	var columns int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('users') WHERE name IN ('provider', 'provider_id', 'provider_email')`).Scan(&columns)
+	if err != nil || columns != 3 {
+		t.Errorf("users has %d of the OAuth columns, %v, want 3", columns, err)
+	}
+	if _, err := Up(db); err != nil {
+		t.Errorf("Up after adding the OAuth columns: %v", err)
+	}
+}
+
+func TestStampedDatabaseIsNotProbedAgain(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := UpTo(db, 1); err != nil {
+		t.Fatal(err)
+	}
+	// Tables made by hand after migrating must not be mistaken for applied migrations
+	if _, err := db.Exec(`CREATE VIRTUAL TABLE posts_fts USING fts5(content)`); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := CurrentVersion(db); err != nil || v != 1 {
+		t.Errorf("CurrentVersion() = %d, %v, want 1", v, err)
+	}
+}
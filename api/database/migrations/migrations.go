@@ -0,0 +1,250 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Numbered up-migrations, applied in order. Files are named NNNN_description.sql;
+// add a new file with the next number for every schema change and never edit an
+// applied one.
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+// Migration is a single numbered schema change
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Status pairs a migration with when it was applied (nil if pending)
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY NOT NULL,
+	name TEXT NOT NULL,
+	applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`
+
+// Load reads the embedded migrations sorted by version
+func Load() ([]Migration, error) {
+	entries, err := files.ReadDir("sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %v", err)
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		fileName := entry.Name()
+		base := strings.TrimSuffix(fileName, ".sql")
+
+		versionStr, name, found := strings.Cut(base, "_")
+		if !found {
+			return nil, fmt.Errorf("invalid migration file name: %s", fileName)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in file name: %s", fileName)
+		}
+
+		content, err := files.ReadFile(path.Join("sql", fileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %v", fileName, err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			SQL:     string(content),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	// Versions must be 1..N without gaps or duplicates
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %04d_%s is out of sequence, expected version %d", m.Version, m.Name, i+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+// CurrentVersion returns the highest applied migration version (0 for an empty database)
+func CurrentVersion(db *sql.DB) (int, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return 0, err
+	}
+
+	var version int
+	err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %v", err)
+	}
+	return version, nil
+}
+
+// AppliedVersion is CurrentVersion for checks that must not write: a database without
+// schema_migrations is at version 0 instead of getting the table
+func AppliedVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var exists int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&exists)
+	if err != nil || exists == 0 {
+		return 0, err
+	}
+
+	var version int
+	err = db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %v", err)
+	}
+	return version, nil
+}
+
+// GetStatus lists every known migration and whether it has been applied
+func GetStatus(db *sql.DB) ([]Status, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %v", err)
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		status := Status{Migration: m}
+		if appliedAt, ok := applied[m.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Up applies every pending migration
+func Up(db *sql.DB) ([]Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return applyUpTo(db, migrations, len(migrations))
+}
+
+// UpTo applies pending migrations up to and including the target version.
+// Only up-migrations exist, so a target below the current version is an error.
+func UpTo(db *sql.DB, target int) ([]Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if target < 1 || target > len(migrations) {
+		return nil, fmt.Errorf("unknown migration version %d (latest is %d)", target, len(migrations))
+	}
+
+	return applyUpTo(db, migrations, target)
+}
+
+// applyUpTo runs each pending migration in its own transaction, recording it in
+// schema_migrations in that same transaction so a failed migration leaves no trace
+func applyUpTo(db *sql.DB, migrations []Migration, target int) ([]Migration, error) {
+	current, err := CurrentVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if target < current {
+		return nil, fmt.Errorf("database is at version %d, cannot migrate down to %d", current, target)
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= current || m.Version > target {
+			continue
+		}
+
+		if err := applyMigration(db, m); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+func applyMigration(db *sql.DB, m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Another process may have applied it since we read the version
+	var exists int
+	err = tx.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = ?", m.Version).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check migration %04d: %v", m.Version, err)
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %v", m.Version, m.Name, err)
+	}
+
+	_, err = tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		m.Version, m.Name, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record migration %04d: %v", m.Version, err)
+	}
+
+	return tx.Commit()
+}
+
+// LatestVersion returns the version of the newest embedded migration, the version a
+// database built by this binary should be at
+func LatestVersion() (int, error) {
+	migrations, err := Load()
+	if err != nil {
+		return 0, err
+	}
+	return len(migrations), nil
+}
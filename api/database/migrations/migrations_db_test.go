@@ -0,0 +1,137 @@
+//go:build sqlite_fts5
+
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openTestDB returns an empty database in a temporary directory, closed when the test ends
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "forum.db")
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_journal_mode=WAL")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestUp(t *testing.T) {
+	db := openTestDB(t)
+	latest, err := LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A check that must not write sees an empty database as version 0 and leaves it alone
+	if v, err := AppliedVersion(context.Background(), db); err != nil || v != 0 {
+		t.Fatalf("AppliedVersion() on an empty database = %d, %v, want 0", v, err)
+	}
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables); err != nil || tables != 0 {
+		t.Fatalf("AppliedVersion created %d tables", tables)
+	}
+
+	applied, err := Up(db)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != latest {
+		t.Errorf("Up applied %d migrations, want %d", len(applied), latest)
+	}
+	if v, err := CurrentVersion(db); err != nil || v != latest {
+		t.Errorf("CurrentVersion() = %d, %v, want %d", v, err, latest)
+	}
+	if v, err := AppliedVersion(context.Background(), db); err != nil || v != latest {
+		t.Errorf("AppliedVersion() = %d, %v, want %d", v, err, latest)
+	}
+
+	// Running again is a no-op
+	if applied, err := Up(db); err != nil || len(applied) != 0 {
+		t.Errorf("second Up applied %d migrations, error %v, want none", len(applied), err)
+	}
+}
+
+func TestUpTo(t *testing.T) {
+	db := openTestDB(t)
+	latest, err := LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := UpTo(db, 3)
+	if err != nil {
+		t.Fatalf("UpTo(3): %v", err)
+	}
+	if len(applied) != 3 || applied[2].Version != 3 {
+		t.Fatalf("UpTo(3) applied %v, want versions 1 to 3", applied)
+	}
+
+	tests := []struct {
+		name    string
+		target  int
+		wantErr bool
+	}{
+		{"down", 2, true},
+		{"version zero", 0, true},
+		{"unknown version", latest + 1, true},
+		{"current version", 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied, err := UpTo(db, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpTo(%d) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+			if len(applied) != 0 {
+				t.Errorf("UpTo(%d) applied %v", tt.target, applied)
+			}
+			if v, _ := CurrentVersion(db); v != 3 {
+				t.Errorf("database moved to version %d", v)
+			}
+		})
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	db := openTestDB(t)
+	latest, err := LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := RunCommand(db, []string{"to", "2"}, &out); err != nil {
+		t.Fatalf("migrate to 2: %v", err)
+	}
+	if out.String() != "Applied 0001_initial_schema\nApplied 0002_search_index\n" {
+		t.Errorf("migrate to 2 printed %q", out.String())
+	}
+
+	out.Reset()
+	if err := RunCommand(db, []string{"status"}, &out); err != nil {
+		t.Fatalf("migrate status: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != latest {
+		t.Fatalf("migrate status printed %d lines, want %d", len(lines), latest)
+	}
+	if !strings.Contains(lines[1], "applied") || !strings.HasSuffix(lines[2], "pending") {
+		t.Errorf("migrate status printed %q", out.String())
+	}
+
+	for _, args := range [][]string{nil, {"down"}, {"to"}, {"to", "two"}} {
+		if err := RunCommand(db, args, &out); err == nil {
+			t.Errorf("migrate %v succeeded", args)
+		}
+	}
+}
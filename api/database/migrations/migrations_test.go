@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Load found no migrations")
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d, want %d", i, m.Version, i+1)
+		}
+		if m.Name == "" || strings.TrimSpace(m.SQL) == "" {
+			t.Errorf("migration %04d has name %q and %d bytes of SQL", m.Version, m.Name, len(m.SQL))
+		}
+	}
+	if migrations[0].Name != "initial_schema" {
+		t.Errorf("first migration is %q, want initial_schema", migrations[0].Name)
+	}
+
+	latest, err := LatestVersion()
+	if err != nil {
+		t.Fatalf("LatestVersion: %v", err)
+	}
+	if latest != len(migrations) {
+		t.Errorf("LatestVersion() = %d, want %d", latest, len(migrations))
+	}
+}
+
+func TestLegacyProbesCoverLeadingMigrations(t *testing.T) {
+	// Probes stamp a leading run of versions, so they must be 1, 2, 3, ... in order
+	for i, probe := range legacyProbes {
+		if probe.version != i+1 {
+			t.Errorf("legacy probe %d checks version %d, want %d", i, probe.version, i+1)
+		}
+	}
+}
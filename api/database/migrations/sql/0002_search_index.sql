@@ -0,0 +1,42 @@
+-- Full-text search: FTS5 indexes over posts and comments, kept in sync by triggers
+-- Needs FTS5 in the driver (go build -tags sqlite_fts5)
+
+-- Create the FTS5 virtual tables
+CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+    post_id UNINDEXED,
+    content,
+    tokenize = 'porter unicode61 remove_diacritics 2'
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS comments_fts USING fts5(
+    comment_id UNINDEXED,
+    content,
+    tokenize = 'porter unicode61 remove_diacritics 2'
+);
+
+-- Keep the index in sync with posts
+CREATE TRIGGER IF NOT EXISTS posts_fts_after_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_fts (post_id, content) VALUES (new.post_id, new.content);
+END;
+CREATE TRIGGER IF NOT EXISTS posts_fts_after_update AFTER UPDATE OF content ON posts BEGIN
+    UPDATE posts_fts SET content = new.content WHERE post_id = old.post_id;
+END;
+CREATE TRIGGER IF NOT EXISTS posts_fts_after_delete AFTER DELETE ON posts BEGIN
+    DELETE FROM posts_fts WHERE post_id = old.post_id;
+END;
+
+-- Keep the index in sync with comments
+CREATE TRIGGER IF NOT EXISTS comments_fts_after_insert AFTER INSERT ON comments BEGIN
+    INSERT INTO comments_fts (comment_id, content) VALUES (new.comment_id, new.content);
+END;
+CREATE TRIGGER IF NOT EXISTS comments_fts_after_update AFTER UPDATE OF content ON comments BEGIN
+    UPDATE comments_fts SET content = new.content WHERE comment_id = old.comment_id;
+END;
+CREATE TRIGGER IF NOT EXISTS comments_fts_after_delete AFTER DELETE ON comments BEGIN
+    DELETE FROM comments_fts WHERE comment_id = old.comment_id;
+END;
+
+-- Backfill existing content
+INSERT INTO posts_fts (post_id, content) SELECT post_id, content FROM posts;
+INSERT INTO comments_fts (comment_id, content) SELECT comment_id, content FROM comments;
+
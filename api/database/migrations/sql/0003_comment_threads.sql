@@ -0,0 +1,10 @@
+-- Threaded comments: replies point at their parent comment
+
+-- Add threading columns to comments table
+-- parent_comment_id is NULL for top-level comments; replies are removed with their parent
+ALTER TABLE comments ADD COLUMN parent_comment_id TEXT DEFAULT NULL REFERENCES comments(comment_id) ON DELETE CASCADE;
+-- depth is 0 for top-level comments and parent depth + 1 for replies
+ALTER TABLE comments ADD COLUMN depth INTEGER NOT NULL DEFAULT 0;
+
+-- Create index for loading the replies of a comment
+CREATE INDEX IF NOT EXISTS idx_comments_parent_created ON comments(parent_comment_id, created_at ASC);
@@ -0,0 +1,39 @@
+-- Post titles: required title on posts, also indexed for search
+
+-- Add title column to posts table
+ALTER TABLE posts ADD COLUMN title TEXT NOT NULL DEFAULT '';
+
+-- Backfill titles for existing posts from the start of their content (newlines flattened)
+UPDATE posts
+SET title = CASE
+    WHEN length(trim(replace(replace(content, char(13), ' '), char(10), ' '))) > 80
+        THEN rtrim(substr(trim(replace(replace(content, char(13), ' '), char(10), ' ')), 1, 77)) || '...'
+    ELSE trim(replace(replace(content, char(13), ' '), char(10), ' '))
+END
+WHERE title = '';
+
+-- Rebuild the posts search index with a title column
+DROP TRIGGER IF EXISTS posts_fts_after_insert;
+DROP TRIGGER IF EXISTS posts_fts_after_update;
+DROP TRIGGER IF EXISTS posts_fts_after_delete;
+DROP TABLE IF EXISTS posts_fts;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+    post_id UNINDEXED,
+    title,
+    content,
+    tokenize = 'porter unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_after_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_fts (post_id, title, content) VALUES (new.post_id, new.title, new.content);
+END;
+CREATE TRIGGER IF NOT EXISTS posts_fts_after_update AFTER UPDATE OF title, content ON posts BEGIN
+    UPDATE posts_fts SET title = new.title, content = new.content WHERE post_id = old.post_id;
+END;
+CREATE TRIGGER IF NOT EXISTS posts_fts_after_delete AFTER DELETE ON posts BEGIN
+    DELETE FROM posts_fts WHERE post_id = old.post_id;
+END;
+
+-- Backfill the index
+INSERT INTO posts_fts (post_id, title, content) SELECT post_id, title, content FROM posts;
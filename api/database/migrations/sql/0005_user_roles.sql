@@ -0,0 +1,13 @@
+-- Roles: members, moderators and administrators
+
+-- Every existing account starts as a member
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('member', 'moderator', 'admin'));
+
+-- Who last edited a post or comment and with which role (NULL until edited)
+ALTER TABLE posts ADD COLUMN updated_by TEXT DEFAULT NULL REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE posts ADD COLUMN updated_by_role TEXT DEFAULT NULL;
+ALTER TABLE comments ADD COLUMN updated_by TEXT DEFAULT NULL REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE comments ADD COLUMN updated_by_role TEXT DEFAULT NULL;
+
+-- Listing staff accounts
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role) WHERE role <> 'member';
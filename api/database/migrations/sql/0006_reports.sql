@@ -0,0 +1,27 @@
+-- Reports: users flag posts, comments or users for moderators to review
+
+CREATE TABLE IF NOT EXISTS reports (
+    report_id TEXT PRIMARY KEY NOT NULL,
+    reporter_id TEXT DEFAULT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+    -- target_id points at posts, comments or users depending on target_type,
+    -- so there is no foreign key; reports outlive deleted targets
+    target_type TEXT NOT NULL CHECK (target_type IN ('post', 'comment', 'user')),
+    target_id TEXT NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('spam', 'harassment', 'inappropriate', 'off_topic', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'claimed', 'resolved', 'dismissed')),
+    claimed_by TEXT DEFAULT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+    claimed_at TIMESTAMP DEFAULT NULL,
+    resolved_by TEXT DEFAULT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP DEFAULT NULL,
+    resolution_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Moderation queue, filtered by status and ordered by age
+CREATE INDEX IF NOT EXISTS idx_reports_status_created ON reports(status, created_at);
+-- Counting pending reports on the same target
+CREATE INDEX IF NOT EXISTS idx_reports_target ON reports(target_type, target_id);
+-- A user can have only one pending report on the same target
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_pending_per_reporter
+    ON reports(reporter_id, target_type, target_id) WHERE status IN ('open', 'claimed');
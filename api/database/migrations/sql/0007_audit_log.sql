@@ -0,0 +1,38 @@
+-- Audit log: an append-only record of privileged and destructive actions
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_id TEXT PRIMARY KEY NOT NULL,
+    -- No foreign keys: entries must outlive the users and content they describe.
+    -- actor_id is empty for changes made from the command line.
+    actor_id TEXT NOT NULL DEFAULT '',
+    actor_role TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    -- JSON snapshots of the target before and after the action (NULL when there is none)
+    before_json TEXT DEFAULT NULL,
+    after_json TEXT DEFAULT NULL,
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Newest first listing and filtering by date
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+-- Everything one moderator or admin did
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, created_at);
+-- The history of one post, comment or user
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action, created_at);
+
+-- Entries can be added but never changed or removed
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
@@ -0,0 +1,20 @@
+-- User sanctions: bans, timed suspensions and mutes issued by moderators
+
+CREATE TABLE IF NOT EXISTS user_sanctions (
+    sanction_id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    -- ban: no login, permanent; suspension: no login until expires_at;
+    -- mute: can log in and read but not post, comment or react
+    sanction_type TEXT NOT NULL CHECK (sanction_type IN ('ban', 'suspension', 'mute')),
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMP DEFAULT NULL, -- NULL for permanent bans
+    issued_by TEXT DEFAULT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Set when a moderator lifts the sanction before it expires
+    lifted_by TEXT DEFAULT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+    lifted_at TIMESTAMP DEFAULT NULL,
+    CHECK (sanction_type = 'ban' OR expires_at IS NOT NULL)
+);
+
+-- Looking up a user's sanctions on every authenticated request
+CREATE INDEX IF NOT EXISTS idx_user_sanctions_user ON user_sanctions(user_id, lifted_at);
@@ -0,0 +1,25 @@
+-- Sessions: allow several per user (one per device) instead of one keyed by user_id.
+-- SQLite cannot change a primary key in place, so the table is rebuilt.
+
+CREATE TABLE sessions_new (
+    session_id TEXT PRIMARY KEY NOT NULL, -- secret cookie value, never listed
+    -- Public identifier used to list and revoke a device's session
+    device_id TEXT NOT NULL UNIQUE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    ip_address TEXT,
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Existing sessions keep working; they just have no user agent yet
+INSERT INTO sessions_new (session_id, device_id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at)
+SELECT session_id, lower(hex(randomblob(16))), user_id, ip_address, '', created_at, created_at, expires_at
+FROM sessions;
+
+DROP TABLE sessions;
+ALTER TABLE sessions_new RENAME TO sessions;
+
+-- Listing a user's devices, most recently used first
+CREATE INDEX IF NOT EXISTS idx_sessions_user_last_seen ON sessions(user_id, last_seen_at);
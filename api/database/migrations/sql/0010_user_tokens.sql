@@ -0,0 +1,19 @@
+-- Email verification and password reset
+
+-- NULL until the user proves they own the address; Google accounts arrive verified
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP DEFAULT NULL;
+UPDATE users SET email_verified_at = created_at WHERE provider IS NOT NULL;
+
+-- Single-use tokens sent by email. Only a SHA-256 hash of the token is stored,
+-- so a leaked database cannot be used to take over accounts.
+CREATE TABLE IF NOT EXISTS user_tokens (
+    token_hash TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    purpose TEXT NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP DEFAULT NULL
+);
+
+-- Replacing a user's outstanding tokens when a new one is issued
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user ON user_tokens(user_id, purpose);
@@ -0,0 +1,34 @@
+-- TOTP two-factor authentication for local accounts
+
+-- One authenticator per user. The secret is stored as issued: unlike passwords it
+-- must be readable to check codes. confirmed_at stays NULL until the user proves
+-- their app works by entering a first code; until then login ignores it.
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id TEXT PRIMARY KEY NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    confirmed_at TIMESTAMP DEFAULT NULL,
+    last_used_step INTEGER NOT NULL DEFAULT 0 -- time step of the last accepted code, so codes cannot be replayed
+);
+
+-- One-time recovery codes for when the authenticator is lost, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    code_hash TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    used_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user ON user_recovery_codes(user_id);
+
+-- Logins that passed the password check and are waiting for a code. The token
+-- handed to the client is stored hashed; no session exists until it is redeemed.
+CREATE TABLE IF NOT EXISTS two_factor_challenges (
+    token_hash TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0, -- wrong codes entered so far
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_two_factor_challenges_user ON two_factor_challenges(user_id);
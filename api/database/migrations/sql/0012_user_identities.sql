@@ -0,0 +1,20 @@
+-- Login methods: a user can sign in with a password and any number of linked
+-- OAuth providers. users.provider/provider_id now only record how the account
+-- was created; logins through a provider are matched against this table.
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    provider TEXT NOT NULL,          -- registry name, e.g. google, github
+    provider_user_id TEXT NOT NULL,  -- the user's stable ID at the provider
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    email TEXT,                      -- address the provider reported when linked
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP DEFAULT NULL,
+    PRIMARY KEY (provider, provider_user_id),
+    UNIQUE (user_id, provider)       -- one account per provider
+);
+
+-- Accounts created through a provider keep logging in with it
+INSERT OR IGNORE INTO user_identities (provider, provider_user_id, user_id, email, created_at)
+SELECT provider, provider_id, user_id, provider_email, created_at
+FROM users
+WHERE provider IS NOT NULL AND provider_id IS NOT NULL;
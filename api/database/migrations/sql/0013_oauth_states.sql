@@ -0,0 +1,17 @@
+-- Pending OAuth logins, so a flow survives a restart and works across API instances
+
+-- One row per authorization request, deleted when the provider redirects back.
+-- The state and the browser binding are stored as SHA-256 hashes; the PKCE code
+-- verifier is stored as issued because it is sent to the token endpoint.
+CREATE TABLE IF NOT EXISTS oauth_states (
+    state_hash TEXT PRIMARY KEY NOT NULL,
+    provider TEXT NOT NULL,
+    binding_hash TEXT NOT NULL, -- hash of the cookie value the initiating browser must present
+    code_verifier TEXT NOT NULL,
+    link_user_id TEXT DEFAULT NULL REFERENCES users(user_id) ON DELETE CASCADE, -- set when linking instead of logging in
+    return_to TEXT NOT NULL DEFAULT '', -- local path to send the user to after logging in
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_states_expires ON oauth_states(expires_at);
@@ -0,0 +1,18 @@
+-- Personal access tokens: user-managed credentials for scripts and bots
+
+-- The token itself is shown once when created and stored only as a SHA-256 hash.
+-- token_prefix keeps the first characters so users can tell their tokens apart.
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    token_id TEXT PRIMARY KEY NOT NULL, -- public ID for listing and revoking
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    token_prefix TEXT NOT NULL,
+    scopes TEXT NOT NULL, -- space-separated, e.g. "read write:posts"
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP DEFAULT NULL,
+    last_used_ip TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user ON personal_access_tokens(user_id);
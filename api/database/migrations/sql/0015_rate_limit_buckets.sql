@@ -0,0 +1,13 @@
+-- Token buckets for the rate limiter when RATE_LIMIT_STORE=sqlite, so limits are
+-- shared by every API instance using the database and survive a restart
+
+-- One row per policy and client. A bucket that has refilled completely is the same
+-- as no bucket, so rows past full_at are deleted.
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    bucket_key TEXT PRIMARY KEY NOT NULL, -- "<policy>|user:<id>" or "<policy>|ip:<address>"
+    tokens REAL NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    full_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_full ON rate_limit_buckets(full_at);
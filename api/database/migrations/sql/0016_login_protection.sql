@@ -0,0 +1,39 @@
+-- Brute-force protection for password logins and a log of security events
+
+-- Failed password logins, kept for LOGIN_FAILURE_WINDOW. Failures are counted by the
+-- email as typed (lowercased), so unknown addresses are throttled exactly like real
+-- accounts and responses do not reveal which emails are registered.
+CREATE TABLE IF NOT EXISTS login_failures (
+    failure_id TEXT PRIMARY KEY NOT NULL,
+    email TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_failures_email ON login_failures(email, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_failures_ip ON login_failures(ip_address, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_failures_created ON login_failures(created_at);
+
+-- Temporary lockouts of an email ("email:<address>") or an IP address ("ip:<address>")
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    subject TEXT PRIMARY KEY NOT NULL,
+    locked_until TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Security-relevant events: failed logins, lockouts and the like.
+-- user_id is NULL when the event concerns an email with no account.
+CREATE TABLE IF NOT EXISTS security_events (
+    event_id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT DEFAULT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A user's own history, newest first
+CREATE INDEX IF NOT EXISTS idx_security_events_user ON security_events(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_security_events_type ON security_events(event_type, created_at);
@@ -0,0 +1,12 @@
+-- Session policy: ip_address and user_agent now keep the values from login, so address
+-- changes are judged against where the session started. The latest ones are kept apart.
+ALTER TABLE sessions ADD COLUMN last_ip_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN last_user_agent TEXT NOT NULL DEFAULT '';
+
+-- Role the session was issued for; the session ID is replaced when the user's role changes
+ALTER TABLE sessions ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+
+UPDATE sessions SET
+    last_ip_address = COALESCE(ip_address, ''),
+    last_user_agent = user_agent,
+    role = COALESCE((SELECT role FROM users WHERE users.user_id = sessions.user_id), 'member');
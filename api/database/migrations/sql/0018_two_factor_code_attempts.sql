@@ -0,0 +1,4 @@
+-- Wrong codes entered to change two-factor settings, and until when such changes are
+-- refused after too many of them
+ALTER TABLE user_totp ADD COLUMN failed_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_totp ADD COLUMN locked_until DATETIME;
@@ -0,0 +1,11 @@
+-- Deleted comments that still have replies are kept as "[deleted]" tombstones, so the
+-- replies stay in their thread
+
+-- When the comment was deleted (NULL while it is shown)
+ALTER TABLE comments ADD COLUMN deleted_at DATETIME DEFAULT NULL;
+
+-- Tombstones are not searchable
+CREATE TRIGGER IF NOT EXISTS comments_fts_after_tombstone AFTER UPDATE OF deleted_at ON comments
+WHEN new.deleted_at IS NOT NULL BEGIN
+    DELETE FROM comments_fts WHERE comment_id = old.comment_id;
+END;
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/mailer"
+	"github.com/PaulKerasidis/forum/internal/middleware"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// ForgotPasswordHandler emails a password reset link. It answers the same way whether
+// or not the address belongs to an account, so it cannot be used to discover users.
+func ForgotPasswordHandler(ur *repository.UserRepository, tr *repository.TokenRepository, m mailer.Mailer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req models.ForgotPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		req.Email = strings.TrimSpace(req.Email)
+		if err := utils.ValidateEmail(req.Email); err != nil {
+			utils.RespondWithDomainError(w, r, models.InvalidField("email", err.Error()))
+			return
+		}
+
+		const sent = "If an account exists for that email, a password reset link has been sent"
+
+		user, err := ur.GetUserByEmail(req.Email)
+		if err != nil {
+			if !errors.Is(err, repository.ErrUserNotFound) {
+				slog.ErrorContext(r.Context(), "Error looking up user for password reset", "err", err)
+			}
+			utils.RespondWithSuccess(w, http.StatusOK, sent)
+			return
+		}
+
+		ttl := config.Config.ResetPasswordTokenTTL
+		token, err := tr.CreateToken(user.ID, models.TokenPurposeResetPassword, ttl)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create reset link")
+			return
+		}
+
+		link := frontendLink("/reset-password", token)
+		sendMail(r.Context(), m, mailer.ResetPasswordMessage(user.Email, user.Username, link, describeDuration(ttl)))
+
+		utils.RespondWithSuccess(w, http.StatusOK, sent)
+	}
+}
+
+// ResetPasswordHandler sets a new password from a reset link and logs the user out everywhere
+func ResetPasswordHandler(ur *repository.UserRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req models.ResetPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		if req.Token == "" || req.Password == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Token and password are required")
+			return
+		}
+		if req.Password != req.ConfirmPassword {
+			utils.RespondWithDomainError(w, r, models.InvalidField("confirm_password", "Passwords do not match"))
+			return
+		}
+		if err := utils.ValidatePassword(req.Password); err != nil {
+			utils.RespondWithDomainError(w, r, models.InvalidField("password", err.Error()))
+			return
+		}
+
+		if err := ur.ResetPassword(req.Token, req.Password); err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, "Password has been reset, please log in")
+	}
+}
+
+// VerifyEmailHandler confirms an email address from a verification link
+func VerifyEmailHandler(ur *repository.UserRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req models.VerifyEmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		if req.Token == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Token is required")
+			return
+		}
+
+		if err := ur.VerifyEmail(req.Token); err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, "Email address verified")
+	}
+}
+
+// ResendVerificationHandler emails the logged-in user a new verification link
+func ResendVerificationHandler(tr *repository.TokenRepository, m mailer.Mailer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		if user.EmailVerified {
+			utils.RespondWithError(w, http.StatusConflict, "Email address is already verified")
+			return
+		}
+
+		if err := sendVerificationEmail(r.Context(), tr, m, user); err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create verification link")
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, "Verification email sent")
+	}
+}
+
+// sendVerificationEmail issues a verification token and emails the link to the user
+func sendVerificationEmail(ctx context.Context, tr *repository.TokenRepository, m mailer.Mailer, user *models.User) error {
+	ttl := config.Config.VerifyEmailTokenTTL
+	token, err := tr.CreateToken(user.ID, models.TokenPurposeVerifyEmail, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := frontendLink("/verify-email", token)
+	sendMail(ctx, m, mailer.VerifyEmailMessage(user.Email, user.Username, link, describeDuration(ttl)))
+	return nil
+}
+
+// sendMail delivers a message in the background so slow mail servers do not hold up
+// the request, and so response times do not reveal whether an account exists
+func sendMail(ctx context.Context, m mailer.Mailer, msg mailer.Message) {
+	utils.RunInBackground(ctx, fmt.Sprintf("sending %q email", msg.Subject), func() error {
+		return m.Send(msg)
+	})
+}
+
+// frontendLink builds a link to a frontend page carrying a token
+func frontendLink(path, token string) string {
+	return config.Config.FrontendURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// describeDuration formats a token lifetime for an email, e.g. "1 hour" or "30 minutes"
+func describeDuration(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		if hours := int(d / time.Hour); hours != 1 {
+			return fmt.Sprintf("%d hours", hours)
+		}
+		return "1 hour"
+	}
+	if minutes := int(d / time.Minute); minutes != 1 {
+		return fmt.Sprintf("%d minutes", minutes)
+	}
+	return "1 minute"
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/PaulKerasidis/forum/internal/middleware"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// UpdateUserRoleHandler lets an admin promote or demote a user
+func UpdateUserRoleHandler(ur *repository.UserRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// Get authenticated user (RequireRole already checked it is an admin)
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		// Extract target user ID from URL path
+		userID := r.PathValue("id")
+		if userID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "User ID is required")
+			return
+		}
+
+		// Admins cannot demote themselves and leave the forum without an admin
+		if userID == user.ID {
+			utils.RespondWithError(w, http.StatusBadRequest, "You cannot change your own role")
+			return
+		}
+
+		var req models.UpdateUserRoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		role := strings.ToLower(strings.TrimSpace(req.Role))
+		err := ur.SetUserRole(userID, role, middleware.GetActor(r))
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		updated, err := ur.GetCurrentUser(userID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to load user")
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, updated)
+	}
+}
+
+// GetAuditLogHandler lets an admin browse the audit log
+// GET /api/admin/audit-log?actor=&action=&target_type=&target_id=&since=&until=&limit=&offset=
+func GetAuditLogHandler(ar *repository.AuditRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		filters, err := utils.ParseAuditFilters(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Parse pagination parameters
+		limit, offset := utils.ParsePaginationParams(r)
+
+		totalCount, err := ar.GetCountAuditEntries(filters)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve audit log count")
+			return
+		}
+
+		entries, err := ar.GetAuditEntries(filters, limit, offset)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve audit log")
+			return
+		}
+
+		utils.RespondWithPaginatedAuditLog(w, entries, totalCount, limit, offset)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/PaulKerasidis/forum/internal/metrics"
 	"github.com/PaulKerasidis/forum/internal/middleware"
 	"github.com/PaulKerasidis/forum/internal/models"
 	"github.com/PaulKerasidis/forum/internal/repository"
@@ -42,15 +43,14 @@ func ToggleCommentReactionHandler(crr *repository.CommentReactionRepository) htt
 		// Call repository method to toggle comment reaction
 		result, err := crr.ToggleCommentReaction(user.ID, req.CommentID, req.ReactionType)
 		if err != nil {
-			// Handle specific errors from repository
-			if err.Error() == "comment not found" {
-				utils.RespondWithError(w, http.StatusNotFound, "Comment not found")
-				return
-			}
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to toggle reaction")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 
+		if result.Created() {
+			metrics.ReactionsCreated.Inc("comment", models.ReactionTypeName(req.ReactionType))
+		}
+
 		// Return the detailed reaction result
 		utils.RespondWithSuccess(w, http.StatusOK, result)
 	}
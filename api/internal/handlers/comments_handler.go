@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/metrics"
 	"github.com/PaulKerasidis/forum/internal/middleware"
 	"github.com/PaulKerasidis/forum/internal/models"
 	"github.com/PaulKerasidis/forum/internal/repository"
@@ -42,26 +44,71 @@ func CreateCommentHandler(cor *repository.CommentRepository) http.HandlerFunc {
 
 		// Validate comment content
 		if err := utils.ValidateCommentContent(req.Content); err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			utils.RespondWithDomainError(w, r, models.InvalidField("content", err.Error()))
 			return
 		}
 
 		// Create comment - now returns lightweight response
 		createResponse, err := cor.CreateComment(postID, user.ID, req.Content)
 		if err != nil {
-			if err.Error() == "post not found" {
-				utils.RespondWithError(w, http.StatusNotFound, "Post not found")
-				return
-			}
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create comment")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
+		metrics.CommentsCreated.Inc("comment")
 
 		// Return lightweight response
 		utils.RespondWithSuccess(w, http.StatusCreated, createResponse)
 	}
 }
 
+// Reply to comment handler - same as CreateCommentHandler but keyed by the parent comment.
+func CreateReplyHandler(cor *repository.CommentRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// Get authenticated user
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		// Get parent comment ID from URL path
+		parentCommentID := r.PathValue("id")
+		if parentCommentID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Comment ID is required")
+			return
+		}
+
+		// Parse request body
+		var req models.ReplyCommentRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		// Validate reply content
+		if err := utils.ValidateCommentContent(req.Content); err != nil {
+			utils.RespondWithDomainError(w, r, models.InvalidField("content", err.Error()))
+			return
+		}
+
+		// Create reply
+		createResponse, err := cor.CreateReply(parentCommentID, user.ID, req.Content, config.Config.MaxCommentDepth)
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+		metrics.CommentsCreated.Inc("reply")
+
+		utils.RespondWithSuccess(w, http.StatusCreated, createResponse)
+	}
+}
+
 // update comment handler.
 func UpdateCommentHandler(cor *repository.CommentRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -94,21 +141,13 @@ func UpdateCommentHandler(cor *repository.CommentRepository) http.HandlerFunc {
 
 		// Validate comment content
 		if err := utils.ValidateCommentContent(req.Content); err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			utils.RespondWithDomainError(w, r, models.InvalidField("content", err.Error()))
 			return
 		}
 		// Update the comment
-		err = cor.UpdateComment(commentID, user.ID, req.Content)
+		err = cor.UpdateComment(commentID, middleware.GetActor(r), req.Content)
 		if err != nil {
-			if err.Error() == "comment not found" {
-				utils.RespondWithError(w, http.StatusNotFound, "Comment not found")
-				return
-			}
-			if err.Error() == "unauthorized: you can only update your own comments" {
-				utils.RespondWithError(w, http.StatusForbidden, "You can only update your own comments")
-				return
-			}
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update comment")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 
@@ -139,18 +178,10 @@ func DeleteCommentHandler(cor *repository.CommentRepository) http.HandlerFunc {
 			return
 		}
 
-		// Delete comment base on userID
-		err := cor.DeleteComment(commentID, user.ID)
+		// Delete comment as the current user (owner or moderator)
+		err := cor.DeleteComment(commentID, middleware.GetActor(r))
 		if err != nil {
-			if err.Error() == "comment not found" {
-				utils.RespondWithError(w, http.StatusNotFound, "Comment not found")
-				return
-			}
-			if err.Error() == "unauthorized: you can only delete your own comments" {
-				utils.RespondWithError(w, http.StatusForbidden, "You can only delete your own comments")
-				return
-			}
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete comment")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 
@@ -182,28 +213,91 @@ func GetCommentsByPostIDHandler(cor *repository.CommentRepository) http.HandlerF
 
 		// Parse pagination and sort parameters using unified system
 		limit, offset := utils.ParsePaginationParams(r)
-		// Parse sort options using unified system
+		// Parse sort options using unified system - applied within every sibling level
 		sortOptions := utils.ParseCommentSortOptions(r)
+		// Parse how much of each reply subtree to include
+		depth, repliesLimit := utils.ParseCommentTreeParams(r)
 
-		// Get total count
-		totalCount, err := cor.GetCommentCountByPost(postID)
+		// Pagination counts top-level comments only
+		totalCount, err := cor.GetTopLevelCommentCountByPost(postID)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve comment count")
 			return
 		}
 
-		// Get comments with sorting
+		// Get top-level comments with sorting
 		comments, err := cor.GetCommentsByPostID(postID, limit, offset, userID, sortOptions)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve comments")
 			return
 		}
 
-		// Respond with paginated comments
+		// Attach the reply subtrees
+		if err := cor.LoadReplies(comments, depth, repliesLimit, userID, sortOptions); err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve replies")
+			return
+		}
+
+		// Respond with paginated comment tree
 		utils.RespondWithPaginatedComments(w, comments, totalCount, limit, offset)
 	}
 }
 
+// GetCommentRepliesHandler pages through the replies of a single comment, with their own subtrees
+func GetCommentRepliesHandler(cor *repository.CommentRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// Get user context
+		currentUser := middleware.GetCurrentUser(r)
+		var userID *string = nil
+		if currentUser != nil {
+			userID = &currentUser.ID
+		}
+
+		// Get parent comment ID from URL
+		commentID := r.PathValue("id")
+		if commentID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Comment ID is required")
+			return
+		}
+
+		// Parse pagination, sort and subtree parameters
+		limit, offset := utils.ParsePaginationParams(r)
+		sortOptions := utils.ParseCommentSortOptions(r)
+		depth, repliesLimit := utils.ParseCommentTreeParams(r)
+
+		// Make sure the parent exists
+		if _, err := cor.GetCommentByID(commentID, userID); err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		totalCount, err := cor.GetReplyCountByComment(commentID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve reply count")
+			return
+		}
+
+		replies, err := cor.GetReplies(commentID, limit, offset, userID, sortOptions)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve replies")
+			return
+		}
+
+		// depth counts the levels below these replies
+		if err := cor.LoadReplies(replies, depth-1, repliesLimit, userID, sortOptions); err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve replies")
+			return
+		}
+
+		utils.RespondWithPaginatedComments(w, replies, totalCount, limit, offset)
+	}
+}
+
 // GetSingleCommentHandler retrieves a single comment by ID
 func GetSingleCommentHandler(cor *repository.CommentRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -229,11 +323,7 @@ func GetSingleCommentHandler(cor *repository.CommentRepository) http.HandlerFunc
 		// Get the comment - you'll need to add this method to your repository
 		comment, err := cor.GetCommentByID(commentID, userID)
 		if err != nil {
-			if err.Error() == "comment not found" {
-				utils.RespondWithError(w, http.StatusNotFound, "Comment not found")
-				return
-			}
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve comment")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// readinessTimeout bounds the readiness checks, so a stuck database fails the probe
+// instead of hanging it
+const readinessTimeout = 2 * time.Second
+
+// HealthHandler is the liveness probe: it answers as long as the server is running
+func HealthHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		utils.RespondWithSuccess(w, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+// ReadyHandler is the readiness probe: the database answers, is in WAL mode with
+// checkpoints keeping up, and its schema is current. It answers 503 when any check fails.
+func ReadyHandler(hr *repository.HealthRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		readiness := models.Readiness{Ready: true, Checks: make(map[string]models.ReadinessCheck)}
+		check := func(name string, run func() (string, error)) {
+			message, err := run()
+			if err != nil {
+				readiness.Ready = false
+				readiness.Checks[name] = models.ReadinessCheck{OK: false, Message: err.Error()}
+				return
+			}
+			readiness.Checks[name] = models.ReadinessCheck{OK: true, Message: message}
+		}
+
+		check("database", func() (string, error) {
+			start := time.Now()
+			if err := hr.CheckDatabase(ctx); err != nil {
+				return "", err
+			}
+			return "answered in " + time.Since(start).Round(time.Microsecond).String(), nil
+		})
+		// The other checks need the database; skip them rather than wait for it again
+		if readiness.Ready {
+			check("wal", func() (string, error) { return hr.CheckWAL(ctx) })
+			check("migrations", func() (string, error) { return hr.CheckMigrations(ctx) })
+		}
+
+		utils.RespondWithReadiness(w, readiness)
+	}
+}
+
+// VersionHandler describes the running build
+func VersionHandler() http.HandlerFunc {
+	build := utils.ReadBuildInfo()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		utils.RespondWithSuccess(w, http.StatusOK, build)
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/PaulKerasidis/forum/internal/middleware"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/oauth"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// GetLoginMethodsHandler lists the current user's password and linked providers,
+// and the enabled providers they could still link
+func GetLoginMethodsHandler(ur *repository.UserRepository, ir *repository.IdentityRepository, registry *oauth.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		hasPassword, err := ur.HasPassword(user.ID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve login methods")
+			return
+		}
+
+		identities, err := ir.ListIdentities(user.ID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve login methods")
+			return
+		}
+
+		linked := make(map[string]bool, len(identities))
+		for i := range identities {
+			linked[identities[i].Provider] = true
+			// Providers that were switched off since keep their route name
+			identities[i].DisplayName = identities[i].Provider
+			if p, ok := registry.Get(identities[i].Provider); ok {
+				identities[i].DisplayName = p.DisplayName()
+			}
+		}
+
+		available := []models.OAuthProviderInfo{}
+		for _, p := range registry.Providers() {
+			if !linked[p.Name] {
+				available = append(available, p)
+			}
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, models.LoginMethodsResponse{
+			HasPassword: hasPassword,
+			Identities:  identities,
+			Available:   available,
+		})
+	}
+}
+
+// UnlinkProviderHandler removes a linked provider from the current user's login methods
+func UnlinkProviderHandler(ir *repository.IdentityRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		provider := r.PathValue("provider")
+		if provider == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Provider is required")
+			return
+		}
+
+		if err := ir.UnlinkIdentity(user.ID, provider); err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, "Provider unlinked")
+	}
+}
+
+// SetPasswordHandler adds a password to an account that only logs in through providers.
+// Changing an existing password goes through the reset flow instead.
+func SetPasswordHandler(ur *repository.UserRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		var req models.SetPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		if req.Password == "" {
+			utils.RespondWithDomainError(w, r, models.InvalidField("password", "Password is required"))
+			return
+		}
+		if req.Password != req.ConfirmPassword {
+			utils.RespondWithDomainError(w, r, models.InvalidField("confirm_password", "Passwords do not match"))
+			return
+		}
+		if err := utils.ValidatePassword(req.Password); err != nil {
+			utils.RespondWithDomainError(w, r, models.InvalidField("password", err.Error()))
+			return
+		}
+
+		if err := ur.SetPassword(user.ID, req.Password); err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, "Password set, you can now log in with your email")
+	}
+}
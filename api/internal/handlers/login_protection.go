@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/notifier"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// loginProtection returns the configured brute-force limits for password logins
+func loginProtection() models.LoginProtection {
+	return models.LoginProtection{
+		FailureWindow:      config.Config.LoginFailureWindow,
+		BackoffAfter:       config.Config.LoginBackoffAfter,
+		BackoffBase:        config.Config.LoginBackoffBase,
+		BackoffMax:         config.Config.LoginBackoffMax,
+		LockoutThreshold:   config.Config.LoginLockoutThreshold,
+		IPLockoutThreshold: config.Config.LoginIPLockoutThreshold,
+		LockoutDuration:    config.Config.LoginLockoutDuration,
+	}
+}
+
+// recordLoginFailure counts a wrong email or password and responds. The owner is told
+// when the failure locks their account. owner is nil when no account has the email.
+func recordLoginFailure(w http.ResponseWriter, r *http.Request, lar *repository.LoginAttemptRepository, n notifier.Notifier, attempt models.LoginAttempt, owner *models.User) {
+	lock, err := lar.RecordFailure(attempt, loginProtection())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error recording failed login", "err", err)
+	}
+
+	if lock != nil {
+		if lock.Reason == models.LoginThrottleAccount && owner != nil {
+			// In the background, like other mail, so timing does not reveal the account exists
+			utils.RunInBackground(r.Context(), "notifying user "+owner.ID+" of account lockout", func() error {
+				return n.AccountLocked(owner, attempt.IP, lock.RetryAfter)
+			})
+		}
+		respondLoginThrottled(w, lock)
+		return
+	}
+
+	utils.RespondWithError(w, http.StatusUnauthorized, "invalid credentials")
+}
+
+// releaseLoginAttempt drops the failure counted for an attempt that turned out not to be one
+func releaseLoginAttempt(r *http.Request, lar *repository.LoginAttemptRepository, failureID string) {
+	if err := lar.ReleaseAttempt(failureID); err != nil {
+		slog.WarnContext(r.Context(), "Failed to release login attempt", "err", err)
+	}
+}
+
+// respondLoginThrottled refuses a login attempt that came too soon or while locked
+func respondLoginThrottled(w http.ResponseWriter, throttle *models.LoginThrottle) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttle.RetryAfter.Seconds()))))
+	utils.RespondWithError(w, http.StatusTooManyRequests, throttle.Message())
+}
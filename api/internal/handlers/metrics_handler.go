@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/netip"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/metrics"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// MetricsHandler serves the metrics in the Prometheus text format, to clients in
+// METRICS_ALLOWED_NETWORKS, or on loopback and private networks when it is not set
+func MetricsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		if !metricsAllowed(utils.ClientIP(r)) {
+			utils.RespondWithError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := metrics.Default.Write(w); err != nil {
+			slog.ErrorContext(r.Context(), "Error writing metrics", "err", err)
+		}
+	}
+}
+
+// metricsAllowed reports whether a client may scrape the metrics
+func metricsAllowed(clientIP string) bool {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	// Scrapers run next to the API, not on the internet
+	if len(config.Config.MetricsAllowedNetworks) == 0 {
+		return addr.IsLoopback() || addr.IsPrivate()
+	}
+	for _, network := range config.Config.MetricsAllowedNetworks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
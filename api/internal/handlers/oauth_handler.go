@@ -1,189 +1,286 @@
 package handlers
 
 import (
-	"fmt"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
-	"sync"
-	"time"
+	"net/url"
 
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/middleware"
 	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/oauth"
 	"github.com/PaulKerasidis/forum/internal/repository"
 	"github.com/PaulKerasidis/forum/internal/utils"
 )
 
-// In-memory store for OAuth states (in production, use Redis or database)
-var (
-	oauthStates = make(map[string]*models.OAuthState)
-	statesMutex = sync.RWMutex{}
-)
+// OAuthLoginHandler initiates login with the provider named in the path.
+// An optional return_to query parameter names the local page to continue to afterwards.
+func OAuthLoginHandler(registry *oauth.Registry, osr *repository.OAuthStateRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
 
-// CleanupExpiredStates removes expired OAuth states
-func CleanupExpiredStates() {
-	statesMutex.Lock()
-	defer statesMutex.Unlock()
-	
-	now := time.Now()
-	for state, stateData := range oauthStates {
-		if now.After(stateData.ExpiresAt) {
-			delete(oauthStates, state)
+		provider, ok := registry.Get(r.PathValue("provider"))
+		if !ok {
+			utils.RespondWithError(w, http.StatusNotFound, "Unknown login provider")
+			return
+		}
+
+		returnTo := r.URL.Query().Get("return_to")
+		if returnTo != "" {
+			if err := utils.ValidateReturnTo(returnTo); err != nil {
+				utils.RespondWithError(w, http.StatusBadRequest, "Invalid return_to: "+err.Error())
+				return
+			}
 		}
+
+		beginOAuth(w, r, osr, provider, "", returnTo)
 	}
 }
 
-// GoogleLoginHandler initiates Google OAuth login
-func GoogleLoginHandler() http.HandlerFunc {
+// LinkProviderHandler starts adding the provider named in the path to the current user's
+// login methods. The provider redirects to the same callback as a login.
+func LinkProviderHandler(registry *oauth.Registry, osr *repository.OAuthStateRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
 			return
 		}
 
-		// Clean up expired states
-		go CleanupExpiredStates()
-
-		// Generate secure state
-		state, err := utils.GenerateOAuthState()
-		if err != nil {
-			log.Printf("Failed to generate OAuth state: %v", err)
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to initiate OAuth")
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
 			return
 		}
 
-		// Store state with expiration (10 minutes)
-		statesMutex.Lock()
-		oauthStates[state] = &models.OAuthState{
-			State:     state,
-			CreatedAt: time.Now(),
-			ExpiresAt: time.Now().Add(10 * time.Minute),
+		provider, ok := registry.Get(r.PathValue("provider"))
+		if !ok {
+			utils.RespondWithError(w, http.StatusNotFound, "Unknown login provider")
+			return
 		}
-		statesMutex.Unlock()
 
-		// Generate Google auth URL
-		authURL := utils.GenerateGoogleAuthURL(state)
+		beginOAuth(w, r, osr, provider, user.ID, "")
+	}
+}
 
-		// Return JSON response with the auth URL
-		response := map[string]string{
-			"auth_url": authURL,
-			"state":    state,
-		}
+// beginOAuth stores a new flow and responds with the provider's authorization URL.
+// linkUserID is empty for a login, or the user who is linking the provider.
+// The response sets the browser binding cookie and also returns its value, so a
+// frontend calling this server-side can set the same cookie in the user's browser.
+func beginOAuth(w http.ResponseWriter, r *http.Request, osr *repository.OAuthStateRepository, provider oauth.Provider, linkUserID, returnTo string) {
+	stored, err := osr.CreateState(provider.Name(), linkUserID, returnTo, config.Config.OAuthStateTTL)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to create OAuth state", "err", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to initiate OAuth")
+		return
+	}
 
-		utils.RespondWithSuccess(w, http.StatusOK, response)
+	authURL, err := provider.AuthURL(stored.State, utils.PKCEChallenge(stored.CodeVerifier))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to build OAuth URL", "provider", provider.Name(), "err", err)
+		utils.RespondWithError(w, http.StatusBadGateway, "Login provider is unavailable")
+		return
+	}
+
+	utils.SetOAuthBindingCookie(stored.Binding, w, stored.ExpiresAt)
+
+	// Return JSON response with the auth URL
+	response := map[string]interface{}{
+		"auth_url":   authURL,
+		"state":      stored.State,
+		"binding":    stored.Binding,
+		"expires_at": stored.ExpiresAt,
 	}
+
+	utils.RespondWithSuccess(w, http.StatusOK, response)
 }
 
-// GoogleCallbackHandler handles Google OAuth callback
-func GoogleCallbackHandler(ur *repository.UserRepository, sr *repository.SessionRepository) http.HandlerFunc {
+// OAuthCallbackHandler handles the redirect back from the provider named in the path
+func OAuthCallbackHandler(registry *oauth.Registry, osr *repository.OAuthStateRepository, ur *repository.UserRepository, ir *repository.IdentityRepository, sr *repository.SessionRepository, sanctionRepo *repository.SanctionRepository, tfr *repository.TwoFactorRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
 			return
 		}
 
+		provider, ok := registry.Get(r.PathValue("provider"))
+		if !ok {
+			redirectToLogin(w, r, "oauth_invalid")
+			return
+		}
+
 		// Get query parameters
 		code := r.URL.Query().Get("code")
 		state := r.URL.Query().Get("state")
 		errorParam := r.URL.Query().Get("error")
 
+		// The binding cookie has done its job whatever the outcome
+		var binding string
+		if cookie, err := r.Cookie(utils.OAuthBindingCookie); err == nil {
+			binding = cookie.Value
+		}
+		utils.ClearOAuthBindingCookie(w)
+
 		// Check for OAuth errors
 		if errorParam != "" {
-			log.Printf("OAuth error: %s", errorParam)
-			http.Redirect(w, r, "http://localhost:3000/login?error=oauth_cancelled", http.StatusTemporaryRedirect)
+			slog.WarnContext(r.Context(), "OAuth error from provider", "provider", provider.Name(), "error", errorParam)
+			if stored, err := osr.ConsumeState(state, binding); err == nil && stored.LinkUser != "" {
+				http.Redirect(w, r, config.Config.FrontendURL+"/profile?identity=cancelled#sign-in-methods", http.StatusTemporaryRedirect)
+				return
+			}
+			redirectToLogin(w, r, "oauth_cancelled")
 			return
 		}
 
 		// Validate required parameters
 		if code == "" {
-			log.Printf("OAuth error: missing authorization code")
-			http.Redirect(w, r, "http://localhost:3000/login?error=oauth_invalid", http.StatusTemporaryRedirect)
+			slog.WarnContext(r.Context(), "OAuth callback without authorization code", "provider", provider.Name())
+			redirectToLogin(w, r, "oauth_invalid")
 			return
 		}
 
 		if state == "" {
-			log.Printf("OAuth error: missing state parameter")
-			http.Redirect(w, r, "http://localhost:3000/login?error=oauth_invalid", http.StatusTemporaryRedirect)
+			slog.WarnContext(r.Context(), "OAuth callback without state", "provider", provider.Name())
+			redirectToLogin(w, r, "oauth_invalid")
 			return
 		}
 
-		// Validate state
-		statesMutex.RLock()
-		storedState, exists := oauthStates[state]
-		statesMutex.RUnlock()
+		// Validate state and consume it; it must have been started by this browser
+		storedState, err := osr.ConsumeState(state, binding)
+		if err != nil || storedState.Provider != provider.Name() {
+			slog.WarnContext(r.Context(), "OAuth callback with invalid or expired state", "provider", provider.Name())
+			redirectToLogin(w, r, "oauth_expired")
+			return
+		}
 
-		if !exists || time.Now().After(storedState.ExpiresAt) {
-			log.Printf("OAuth error: invalid or expired state")
-			http.Redirect(w, r, "http://localhost:3000/login?error=oauth_expired", http.StatusTemporaryRedirect)
+		// Exchange code for the user's profile
+		profile, err := provider.Exchange(code, storedState.CodeVerifier)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to complete OAuth login", "provider", provider.Name(), "err", err)
+			redirectToLogin(w, r, "oauth_token_failed")
 			return
 		}
 
-		// Clean up used state
-		statesMutex.Lock()
-		delete(oauthStates, state)
-		statesMutex.Unlock()
+		// Linking adds the provider to the user who started it and goes back to their profile
+		if storedState.LinkUser != "" {
+			outcome := "linked"
+			if err := ir.LinkIdentity(storedState.LinkUser, profile); err != nil {
+				slog.ErrorContext(r.Context(), "Failed to link OAuth provider", "provider", provider.Name(), "user_id", storedState.LinkUser, "err", err)
+				switch {
+				case errors.Is(err, repository.ErrIdentityTaken):
+					outcome = "taken"
+				case errors.Is(err, repository.ErrProviderLinked):
+					outcome = "already_linked"
+				default:
+					outcome = "link_failed"
+				}
+			}
+			http.Redirect(w, r, config.Config.FrontendURL+"/profile?identity="+outcome+"#sign-in-methods", http.StatusTemporaryRedirect)
+			return
+		}
 
-		// Exchange code for token
-		tokenResp, err := utils.ExchangeGoogleCode(code)
+		// Check if user exists or create new user
+		user, isNewUser, err := ur.CreateOrGetOAuthUser(profile, config.Config.OAuthAutoLinkVerified)
 		if err != nil {
-			log.Printf("Failed to exchange code for token: %v", err)
-			http.Redirect(w, r, "http://localhost:3000/login?error=oauth_token_failed", http.StatusTemporaryRedirect)
+			slog.ErrorContext(r.Context(), "Failed to create or find OAuth user", "provider", provider.Name(), "err", err)
+			errorCode := "oauth_user_failed"
+			if errors.Is(err, repository.ErrOAuthEmailTaken) {
+				errorCode = "oauth_email_taken"
+			}
+			redirectToLogin(w, r, errorCode)
 			return
 		}
 
-		// Get user info from Google
-		userInfo, err := utils.GetGoogleUserInfo(tokenResp.AccessToken)
+		// Banned and suspended users cannot log in through a provider either
+		sanctions, err := sanctionRepo.GetActiveSanctions(user.ID)
 		if err != nil {
-			log.Printf("Failed to get user info: %v", err)
-			http.Redirect(w, r, "http://localhost:3000/login?error=oauth_userinfo_failed", http.StatusTemporaryRedirect)
+			slog.ErrorContext(r.Context(), "Failed to load sanctions", "user_id", user.ID, "err", err)
+			redirectToLogin(w, r, "oauth_user_failed")
+			return
+		}
+		if lock := models.AccountLock(sanctions); lock != nil {
+			slog.InfoContext(r.Context(), "OAuth login refused for sanctioned user", "user_id", user.ID)
+			redirectToLogin(w, r, "account_"+lock.Type)
 			return
 		}
 
-		// Check if user exists or create new user
-		user, isNewUser, err := ur.CreateOrGetOAuthUser(userInfo)
+		// The provider replaces the password, not the second factor
+		twoFactor, err := tfr.IsEnabled(user.ID)
 		if err != nil {
-			log.Printf("Failed to create/get OAuth user: %v", err)
-			http.Redirect(w, r, "http://localhost:3000/login?error=oauth_user_failed", http.StatusTemporaryRedirect)
+			slog.ErrorContext(r.Context(), "Failed to check two-factor status", "user_id", user.ID, "err", err)
+			redirectToLogin(w, r, "oauth_user_failed")
+			return
+		}
+		if twoFactor {
+			token, _, err := tfr.CreateChallenge(user.ID, config.Config.TwoFactorLoginTTL)
+			if errors.Is(err, repository.ErrTwoFactorCodesLocked) {
+				redirectToLogin(w, r, "two_factor_locked")
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Failed to create two-factor challenge", "user_id", user.ID, "err", err)
+				redirectToLogin(w, r, "oauth_session_failed")
+				return
+			}
+			params := url.Values{}
+			params.Set("token", token)
+			if storedState.ReturnTo != "" {
+				params.Set("return_to", storedState.ReturnTo)
+			}
+			http.Redirect(w, r, config.Config.FrontendURL+"/login/2fa?"+params.Encode(), http.StatusTemporaryRedirect)
 			return
 		}
 
 		// Create session
-		session, err := sr.CreateSession(user.ID, r.RemoteAddr)
+		session, err := sr.CreateSession(user.ID, utils.ClientIP(r), r.UserAgent())
 		if err != nil {
-			log.Printf("Failed to create session: %v", err)
-			http.Redirect(w, r, "http://localhost:3000/login?error=oauth_session_failed", http.StatusTemporaryRedirect)
+			slog.ErrorContext(r.Context(), "Failed to create session", "user_id", user.ID, "err", err)
+			redirectToLogin(w, r, "oauth_session_failed")
 			return
 		}
 
-		log.Printf("OAuth session created successfully: UserID=%s, SessionID=%s", user.ID, session.SessionID)
+		slog.InfoContext(r.Context(), "OAuth login succeeded", "provider", provider.Name(), "user_id", user.ID)
 
 		// Don't set session cookie here, let frontend handle it
 		// utils.SetSessionCookie(session.SessionID, w, r, session.ExpiresAt)
 
 		// Redirect to frontend with success and session ID
-		var redirectURL string
+		params := url.Values{}
+		params.Set("oauth", "success")
 		if isNewUser {
-			redirectURL = fmt.Sprintf("http://localhost:3000/?oauth=success&new_user=true&session_id=%s", session.SessionID)
-		} else {
-			redirectURL = fmt.Sprintf("http://localhost:3000/?oauth=success&session_id=%s", session.SessionID)
+			params.Set("new_user", "true")
+		}
+		params.Set("session_id", session.SessionID)
+		if storedState.ReturnTo != "" {
+			params.Set("return_to", storedState.ReturnTo)
 		}
 
-		log.Printf("OAuth success, redirecting to: %s", redirectURL)
-		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		http.Redirect(w, r, config.Config.FrontendURL+"/?"+params.Encode(), http.StatusTemporaryRedirect)
 	}
 }
 
-// GoogleLoginStatusHandler returns OAuth login status for frontend
-func GoogleLoginStatusHandler() http.HandlerFunc {
+// OAuthStatusHandler lists the enabled login providers for the frontend
+func OAuthStatusHandler(registry *oauth.Registry) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
 			return
 		}
 
+		providers := registry.Providers()
 		response := map[string]interface{}{
-			"oauth_enabled": true,
-			"providers":     []string{"google"},
+			"oauth_enabled": len(providers) > 0,
+			"providers":     providers,
 		}
 
 		utils.RespondWithSuccess(w, http.StatusOK, response)
 	}
-}
\ No newline at end of file
+}
+
+// redirectToLogin sends the browser back to the frontend login page with an error code
+func redirectToLogin(w http.ResponseWriter, r *http.Request, errorCode string) {
+	http.Redirect(w, r, config.Config.FrontendURL+"/login?error="+url.QueryEscape(errorCode), http.StatusTemporaryRedirect)
+}
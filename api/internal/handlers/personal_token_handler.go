@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/middleware"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// Lifetime of a token created without expires_in_days
+const defaultPersonalTokenDays = 30
+
+// Longest name a token can be given
+const maxPersonalTokenNameLength = 50
+
+// GetPersonalTokensHandler lists the current user's personal access tokens
+func GetPersonalTokensHandler(ptr *repository.PersonalTokenRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		tokens, err := ptr.ListTokens(user.ID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve tokens")
+			return
+		}
+		if tokens == nil {
+			tokens = []*models.PersonalAccessToken{}
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, tokens)
+	}
+}
+
+// CreatePersonalTokenHandler creates a personal access token. The token is only in this response.
+func CreatePersonalTokenHandler(ptr *repository.PersonalTokenRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		var req models.CreatePersonalTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		var verr models.ValidationError
+		name := strings.TrimSpace(req.Name)
+		if name == "" || len(name) > maxPersonalTokenNameLength {
+			verr.Add("name", fmt.Sprintf("Token name must be between 1 and %d characters", maxPersonalTokenNameLength))
+		}
+
+		scopes, err := normalizeScopes(req.Scopes, user)
+		verr.Check("scopes", err)
+
+		days := req.ExpiresInDays
+		if days == 0 {
+			days = min(defaultPersonalTokenDays, config.Config.MaxPersonalTokenDays)
+		}
+		if days < 1 || days > config.Config.MaxPersonalTokenDays {
+			verr.Add("expires_in_days", fmt.Sprintf("Tokens can last between 1 and %d days", config.Config.MaxPersonalTokenDays))
+		}
+		if err := verr.Err(); err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		token, stored, err := ptr.CreateToken(user.ID, name, scopes, time.Duration(days)*24*time.Hour, config.Config.MaxPersonalTokens)
+		if err != nil {
+			if errors.Is(err, repository.ErrTokenLimitReached) {
+				utils.RespondWithError(w, http.StatusConflict, fmt.Sprintf("You can have at most %d tokens, revoke one first", config.Config.MaxPersonalTokens))
+				return
+			}
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusCreated, models.CreatedPersonalToken{
+			Token:               token,
+			PersonalAccessToken: *stored,
+		})
+	}
+}
+
+// RevokePersonalTokenHandler deletes one of the current user's tokens
+func RevokePersonalTokenHandler(ptr *repository.PersonalTokenRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		tokenID := r.PathValue("id")
+		if tokenID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Token ID is required")
+			return
+		}
+
+		if err := ptr.RevokeToken(user.ID, tokenID); err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, "Token revoked successfully")
+	}
+}
+
+// normalizeScopes checks requested scopes and returns them without duplicates in
+// their canonical order. Only moderators can grant the moderate scope.
+func normalizeScopes(requested []string, user *models.User) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("At least one scope is required")
+	}
+
+	wanted := make(map[string]bool, len(requested))
+	for _, scope := range requested {
+		if !models.IsValidScope(scope) {
+			return nil, fmt.Errorf("Unknown scope: %s", scope)
+		}
+		wanted[scope] = true
+	}
+
+	if wanted[models.ScopeModerate] && !user.HasRole(models.RoleModerator) {
+		return nil, fmt.Errorf("Only moderators can grant the %s scope", models.ScopeModerate)
+	}
+
+	scopes := make([]string, 0, len(wanted))
+	for _, scope := range models.TokenScopes {
+		if wanted[scope] {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+func TestNormalizeScopes(t *testing.T) {
+	member := &models.User{Role: models.RoleMember}
+	moderator := &models.User{Role: models.RoleModerator}
+	admin := &models.User{Role: models.RoleAdmin}
+
+	tests := []struct {
+		name      string
+		requested []string
+		user      *models.User
+		want      []string
+		wantErr   bool
+	}{
+		{"single scope", []string{"read"}, member, []string{"read"}, false},
+		{"canonical order", []string{"write:comments", "read", "write:posts"}, member, []string{"read", "write:posts", "write:comments"}, false},
+		{"duplicates dropped", []string{"read", "read", "write:posts"}, member, []string{"read", "write:posts"}, false},
+		{"moderator grants moderate", []string{"moderate", "read"}, moderator, []string{"read", "moderate"}, false},
+		{"admin grants moderate", []string{"moderate"}, admin, []string{"moderate"}, false},
+		{"member cannot grant moderate", []string{"read", "moderate"}, member, nil, true},
+		{"no scopes", nil, member, nil, true},
+		{"unknown scope", []string{"read", "write:users"}, admin, nil, true},
+		{"scopes are case sensitive", []string{"Read"}, member, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeScopes(tt.requested, tt.user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeScopes(%v) error = %v, wantErr %v", tt.requested, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("normalizeScopes(%v) = %v, want %v", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
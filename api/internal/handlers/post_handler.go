@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/metrics"
 	"github.com/PaulKerasidis/forum/internal/middleware"
 	"github.com/PaulKerasidis/forum/internal/models"
 	"github.com/PaulKerasidis/forum/internal/repository"
@@ -20,7 +23,7 @@ import (
 func CreatePostHandler(pr *repository.PostsRepository, cr *repository.CategoryRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
 			return
 		}
 
@@ -38,38 +41,20 @@ func CreatePostHandler(pr *repository.PostsRepository, cr *repository.CategoryRe
 			return
 		}
 
-		if utils.ValidatePostContent(req.Content) != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid post content")
-			return
-		}
-
-		// Validate and get category IDs
-		var categoryIDs []string
-		for _, categoryName := range req.CategoryNames {
-			categoryID, err := cr.GetCategoryID(categoryName)
-			if err != nil {
-				utils.RespondWithError(w, http.StatusBadRequest, "Invalid category: "+categoryName)
-				return
-			}
-			categoryIDs = append(categoryIDs, categoryID)
-		}
-		if len(categoryIDs) < config.Config.MinCategories {
-			utils.RespondWithError(w, http.StatusBadRequest,
-				fmt.Sprintf("Minimum %d category required", config.Config.MinCategories))
+		req.Title = strings.TrimSpace(req.Title)
+		categoryIDs, err := validatePost(cr, req.Title, req.Content, req.CategoryNames)
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 
-		if len(categoryIDs) > config.Config.MaxCategories {
-			utils.RespondWithError(w, http.StatusBadRequest,
-				fmt.Sprintf("Maximum %d categories allowed", config.Config.MaxCategories))
-			return
-		}
 		// Create post - now returns lightweight response
-		createResponse, err := pr.CreatePost(user.ID, req.Content, categoryIDs)
+		createResponse, err := pr.CreatePost(user.ID, req.Title, req.Content, categoryIDs)
 		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create post")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
+		metrics.PostsCreated.Inc()
 
 		// Return lightweight response
 		utils.RespondWithSuccess(w, http.StatusCreated, createResponse)
@@ -99,11 +84,11 @@ func UpdatePostHandler(pr *repository.PostsRepository, cr *repository.CategoryRe
 		// Pass userID to GetPostByID for ownership check
 		post, err := pr.GetPostByID(postID, &user.ID)
 		if err != nil {
-			utils.RespondWithError(w, http.StatusNotFound, "Post not found")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
-		if post.UserID != user.ID {
-			utils.RespondWithError(w, http.StatusForbidden, "You can only update your own posts")
+		if post.UserID != user.ID && !user.HasRole(models.RoleModerator) {
+			utils.RespondWithDomainError(w, r, repository.ErrNotPostAuthor)
 			return
 		}
 		// Parse request body
@@ -113,49 +98,54 @@ func UpdatePostHandler(pr *repository.PostsRepository, cr *repository.CategoryRe
 			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
 			return
 		}
-		// Validate and get category IDs
-		var categoryIDs []string
-		for _, categoryName := range req.CategoryNames {
-			categoryID, err := cr.GetCategoryID(categoryName)
-			if err != nil {
-				utils.RespondWithError(w, http.StatusBadRequest, "Invalid category: "+categoryName)
-				return
-			}
-			categoryIDs = append(categoryIDs, categoryID)
-		}
-		if len(categoryIDs) < config.Config.MinCategories {
-			utils.RespondWithError(w, http.StatusBadRequest,
-				fmt.Sprintf("Minimum %d category required", config.Config.MinCategories))
-			return
-		}
-
-		if len(categoryIDs) > config.Config.MaxCategories {
-			utils.RespondWithError(w, http.StatusBadRequest,
-				fmt.Sprintf("Maximum %d categories allowed", config.Config.MaxCategories))
-			return
-		}
-		if utils.ValidatePostContent(req.Content) != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid post content")
+		req.Title = strings.TrimSpace(req.Title)
+		categoryIDs, err := validatePost(cr, req.Title, req.Content, req.CategoryNames)
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 		// Update post
-		err = pr.UpdatePost(postID, user.ID, req.Content, categoryIDs)
+		err = pr.UpdatePost(postID, middleware.GetActor(r), req.Title, req.Content, categoryIDs)
 		if err != nil {
-			if err.Error() == "post not found" {
-				utils.RespondWithError(w, http.StatusNotFound, "Post not found")
-				return
-			}
-			if err.Error() == "unauthorized: you can only update your own posts" {
-				utils.RespondWithError(w, http.StatusForbidden, "You can only update your own posts")
-				return
-			}
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update post")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 		utils.RespondWithSuccess(w, http.StatusOK, nil)
 	}
 }
 
+// validatePost checks a new or edited post and returns the IDs of its categories.
+// Every problem is reported against its field in a models.ValidationError.
+func validatePost(cr *repository.CategoryRepository, title, content string, categoryNames []string) ([]string, error) {
+	var verr models.ValidationError
+	verr.Check("title", utils.ValidatePostTitle(title))
+	verr.Check("content", utils.ValidatePostContent(content))
+
+	var categoryIDs []string
+	unknownCategory := false
+	for _, categoryName := range categoryNames {
+		categoryID, err := cr.GetCategoryID(categoryName)
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			verr.Add("category_names", "Invalid category: "+categoryName)
+			unknownCategory = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		categoryIDs = append(categoryIDs, categoryID)
+	}
+	switch {
+	case unknownCategory:
+	case len(categoryIDs) < config.Config.MinCategories:
+		verr.Add("category_names", fmt.Sprintf("Minimum %d category required", config.Config.MinCategories))
+	case len(categoryIDs) > config.Config.MaxCategories:
+		verr.Add("category_names", fmt.Sprintf("Maximum %d categories allowed", config.Config.MaxCategories))
+	}
+
+	return categoryIDs, verr.Err()
+}
+
 // DeletePostHandler deletes an existing post
 func DeletePostHandler(pr *repository.PostsRepository, cr *repository.CategoryRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -179,26 +169,18 @@ func DeletePostHandler(pr *repository.PostsRepository, cr *repository.CategoryRe
 		// Pass userID to GetPostByID for ownership check
 		post, err := pr.GetPostByID(postID, &user.ID)
 		if err != nil {
-			utils.RespondWithError(w, http.StatusNotFound, "Post not found")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
-		// Check if the post belongs to the user
-		if post.UserID != user.ID {
-			utils.RespondWithError(w, http.StatusForbidden, "You can only delete your own posts")
+		// Check if the post belongs to the user (moderators may remove any post)
+		if post.UserID != user.ID && !user.HasRole(models.RoleModerator) {
+			utils.RespondWithDomainError(w, r, repository.ErrNotPostAuthor)
 			return
 		}
 		// Delete the post
-		err = pr.DeletePost(postID, user.ID)
+		err = pr.DeletePost(postID, middleware.GetActor(r))
 		if err != nil {
-			if err.Error() == "post not found" {
-				utils.RespondWithError(w, http.StatusNotFound, "Post not found")
-				return
-			}
-			if err.Error() == "unauthorized: you can only delete your own posts" {
-				utils.RespondWithError(w, http.StatusForbidden, "You can only delete your own posts")
-				return
-			}
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete post")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 		utils.RespondWithSuccess(w, http.StatusOK, nil)
@@ -312,11 +294,7 @@ func GetSinglePostHandler(pr *repository.PostsRepository) http.HandlerFunc {
 		//Pass userID to repository
 		post, err := pr.GetPostByID(postID, userID)
 		if err != nil {
-			if err.Error() == "post not found" {
-				utils.RespondWithError(w, http.StatusNotFound, "Post not found")
-				return
-			}
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve post")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 		utils.RespondWithSuccess(w, http.StatusOK, post)
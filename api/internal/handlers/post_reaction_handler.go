@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/PaulKerasidis/forum/internal/metrics"
 	"github.com/PaulKerasidis/forum/internal/middleware"
 	"github.com/PaulKerasidis/forum/internal/models"
 	"github.com/PaulKerasidis/forum/internal/repository"
@@ -42,15 +43,14 @@ func TogglePostReactionHandler(prr *repository.PostReactionRepository) http.Hand
 		// Call repository method to toggle post reaction
 		result, err := prr.TogglePostReaction(user.ID, req.PostID, req.ReactionType)
 		if err != nil {
-			// Handle specific errors from repository
-			if err.Error() == "post not found" {
-				utils.RespondWithError(w, http.StatusNotFound, "Post not found")
-				return
-			}
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to toggle reaction")
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 
+		if result.Created() {
+			metrics.ReactionsCreated.Inc("post", models.ReactionTypeName(req.ReactionType))
+		}
+
 		// Return the detailed reaction result
 		utils.RespondWithSuccess(w, http.StatusOK, result)
 	}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/PaulKerasidis/forum/internal/middleware"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// CreateReportHandler lets a user report a post, comment or user
+func CreateReportHandler(rr *repository.ReportRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// Get authenticated user
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		var req models.CreateReportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		req.TargetType = strings.ToLower(strings.TrimSpace(req.TargetType))
+		req.TargetID = strings.TrimSpace(req.TargetID)
+		req.Reason = strings.ToLower(strings.TrimSpace(req.Reason))
+		req.Details = strings.TrimSpace(req.Details)
+
+		if !models.IsValidReportTarget(req.TargetType) {
+			utils.RespondWithError(w, http.StatusBadRequest, "Target type must be post, comment or user")
+			return
+		}
+		if req.TargetID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Target ID is required")
+			return
+		}
+		if !models.IsValidReportReason(req.Reason) {
+			utils.RespondWithError(w, http.StatusBadRequest, "Reason must be spam, harassment, inappropriate, off_topic or other")
+			return
+		}
+		if err := utils.ValidateReportText(req.Details); err != nil {
+			utils.RespondWithDomainError(w, r, models.InvalidField("details", err.Error()))
+			return
+		}
+
+		reportID, err := rr.CreateReport(user.ID, req)
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusCreated, map[string]string{"report_id": reportID})
+	}
+}
+
+// GetReportQueueHandler lists reports for moderators
+// GET /api/reports/queue?status=pending|open|claimed|resolved|dismissed|all&type=post|comment|user&limit=&offset=
+func GetReportQueueHandler(rr *repository.ReportRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		filters, err := utils.ParseReportFilters(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Parse pagination parameters
+		limit, offset := utils.ParsePaginationParams(r)
+
+		totalCount, err := rr.GetCountReports(filters)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve reports count")
+			return
+		}
+
+		reports, err := rr.GetReports(filters, limit, offset)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve reports")
+			return
+		}
+
+		utils.RespondWithPaginatedReports(w, filters.Status, reports, totalCount, limit, offset)
+	}
+}
+
+// GetReportHandler returns a single report for moderators
+func GetReportHandler(rr *repository.ReportRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		reportID := r.PathValue("id")
+		if reportID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Report ID is required")
+			return
+		}
+
+		report, err := rr.GetReportByID(reportID)
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, report)
+	}
+}
+
+// ClaimReportHandler assigns a pending report to the current moderator
+func ClaimReportHandler(rr *repository.ReportRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// Get authenticated user
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		reportID := r.PathValue("id")
+		if reportID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Report ID is required")
+			return
+		}
+
+		err := rr.ClaimReport(reportID, middleware.GetActor(r))
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		respondWithReport(w, rr, reportID)
+	}
+}
+
+// ResolveReportHandler closes a report after action was taken
+func ResolveReportHandler(rr *repository.ReportRepository) http.HandlerFunc {
+	return closeReportHandler(rr, models.ReportStatusResolved)
+}
+
+// DismissReportHandler closes a report that needs no action
+func DismissReportHandler(rr *repository.ReportRepository) http.HandlerFunc {
+	return closeReportHandler(rr, models.ReportStatusDismissed)
+}
+
+func closeReportHandler(rr *repository.ReportRepository, status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// Get authenticated user
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		reportID := r.PathValue("id")
+		if reportID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Report ID is required")
+			return
+		}
+
+		// The note is optional, so an empty body is fine
+		var req models.CloseReportRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+				return
+			}
+		}
+		req.Note = strings.TrimSpace(req.Note)
+		if err := utils.ValidateReportText(req.Note); err != nil {
+			utils.RespondWithDomainError(w, r, models.InvalidField("note", err.Error()))
+			return
+		}
+
+		err := rr.CloseReport(reportID, middleware.GetActor(r), status, req.Note)
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		respondWithReport(w, rr, reportID)
+	}
+}
+
+// respondWithReport sends the report's current state after a change
+func respondWithReport(w http.ResponseWriter, rr *repository.ReportRepository, reportID string) {
+	report, err := rr.GetReportByID(reportID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve report")
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, report)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/PaulKerasidis/forum/internal/middleware"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// CreateSanctionHandler bans, suspends or mutes a user.
+// Bans and suspensions also log the user out everywhere.
+func CreateSanctionHandler(sr *repository.SanctionRepository, sessionRepo *repository.SessionRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// Get authenticated user (RequireRole already checked it is a moderator)
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		// Extract target user ID from URL path
+		userID := r.PathValue("id")
+		if userID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "User ID is required")
+			return
+		}
+
+		var req models.CreateSanctionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+
+		req.Type = strings.ToLower(strings.TrimSpace(req.Type))
+		req.Reason = strings.TrimSpace(req.Reason)
+		if err := utils.ValidateSanctionRequest(req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		sanction, err := sr.CreateSanction(userID, middleware.GetActor(r), req)
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		if sanction.LocksAccount() {
+			if err := sessionRepo.DeleteUserSessions(userID); err != nil {
+				// The middleware still refuses the sessions, so the sanction holds
+				slog.ErrorContext(r.Context(), "Error revoking sessions of sanctioned user", "user_id", userID, "err", err)
+			}
+		}
+
+		utils.RespondWithSuccess(w, http.StatusCreated, sanction)
+	}
+}
+
+// LiftSanctionHandler ends a sanction before it expires
+func LiftSanctionHandler(sr *repository.SanctionRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// Get authenticated user
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		sanctionID := r.PathValue("id")
+		if sanctionID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Sanction ID is required")
+			return
+		}
+
+		err := sr.LiftSanction(sanctionID, middleware.GetActor(r))
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, "Sanction lifted successfully")
+	}
+}
+
+// GetUserSanctionsHandler lists a user's sanctions, active and past
+func GetUserSanctionsHandler(sr *repository.SanctionRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		userID := r.PathValue("id")
+		if userID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "User ID is required")
+			return
+		}
+
+		sanctions, err := sr.GetSanctionsByUser(userID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve sanctions")
+			return
+		}
+		if sanctions == nil {
+			sanctions = []*models.Sanction{}
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, sanctions)
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// SearchHandler runs a ranked full-text search over posts or comments
+// GET /api/search?q=...&type=posts|comments&category=<id>&author=<username>&limit=&offset=
+func SearchHandler(sr *repository.SearchRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// Validate the search text
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if err := utils.ValidateSearchQuery(query); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		matchQuery, err := utils.BuildFTSMatchQuery(query)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		searchType, err := utils.ParseSearchType(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Parse filters and pagination parameters
+		filters := utils.ParseSearchFilters(r)
+		limit, offset := utils.ParsePaginationParams(r)
+
+		var results []*models.SearchResult
+		var totalCount int
+		if searchType == models.SearchTypeComments {
+			totalCount, err = sr.GetCountSearchComments(matchQuery, filters)
+			if err == nil {
+				results, err = sr.SearchComments(matchQuery, filters, limit, offset)
+			}
+		} else {
+			totalCount, err = sr.GetCountSearchPosts(matchQuery, filters)
+			if err == nil {
+				results, err = sr.SearchPosts(matchQuery, filters, limit, offset)
+			}
+		}
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to search")
+			return
+		}
+
+		utils.RespondWithPaginatedSearch(w, query, searchType, results, totalCount, limit, offset)
+	}
+}
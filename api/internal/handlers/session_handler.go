@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/PaulKerasidis/forum/internal/middleware"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// GetSessionsHandler lists the devices the current user is logged in on
+func GetSessionsHandler(sr *repository.SessionRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// Get authenticated user and the session they are using
+		user := middleware.GetCurrentUser(r)
+		current := middleware.GetCurrentSession(r)
+		if user == nil || current == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		sessions, err := sr.GetUserSessions(user.ID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve sessions")
+			return
+		}
+
+		devices := make([]models.DeviceSession, 0, len(sessions))
+		for _, session := range sessions {
+			devices = append(devices, models.DeviceSession{
+				ID:         session.DeviceID,
+				Device:     utils.DescribeUserAgent(session.UserAgent),
+				UserAgent:  session.UserAgent,
+				IPAddress:  session.LastIPAddress,
+				CreatedAt:  session.CreatedAt,
+				LastSeenAt: session.LastSeenAt,
+				ExpiresAt:  session.ExpiresAt,
+				Current:    session.SessionID == current.SessionID,
+			})
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, devices)
+	}
+}
+
+// RevokeSessionHandler logs one of the current user's devices out.
+// Revoking the current session is the same as logging out.
+func RevokeSessionHandler(sr *repository.SessionRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		current := middleware.GetCurrentSession(r)
+		if user == nil || current == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		deviceID := r.PathValue("id")
+		if deviceID == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Session ID is required")
+			return
+		}
+
+		// Only the user's own sessions can be found this way
+		err := sr.DeleteUserSession(user.ID, deviceID)
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		if deviceID == current.DeviceID {
+			utils.ClearSessionCookie(w)
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, "Session revoked successfully")
+	}
+}
+
+// RevokeOtherSessionsHandler logs the current user out on every other device
+func RevokeOtherSessionsHandler(sr *repository.SessionRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		current := middleware.GetCurrentSession(r)
+		if user == nil || current == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		revoked, err := sr.DeleteOtherSessions(user.ID, current.SessionID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to revoke sessions")
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, map[string]int64{"revoked": revoked})
+	}
+}
+
+// GetSecurityEventsHandler lists the current user's security events, newest first: failed
+// logins, lockouts and sessions used from new addresses or devices
+func GetSecurityEventsHandler(ser *repository.SecurityEventRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		limit, offset := utils.ParsePaginationParams(r)
+		events, err := ser.GetUserEvents(user.ID, limit, offset)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve security events")
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, events)
+	}
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/middleware"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/repository"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// LoginTwoFactorHandler completes a login that is waiting for a two-factor code
+func LoginTwoFactorHandler(ur *repository.UserRepository, sr *repository.SessionRepository, sanctionRepo *repository.SanctionRepository, tfr *repository.TwoFactorRepository, lar *repository.LoginAttemptRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req models.TwoFactorLoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		if req.Token == "" || req.Code == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Two-factor token and code are required")
+			return
+		}
+
+		attempt := twoFactorAttempt(r, "", "login")
+		userID, err := tfr.CompleteChallenge(req.Token, req.Code, attempt, config.Config.MaxTwoFactorAttempts, config.Config.LoginLockoutDuration)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrInvalidCode):
+				// A wrong code fails the login, like a wrong password
+				utils.RespondWithDomainError(w, r, models.Typed(models.Unauthorized("Invalid code"), models.ProblemInvalidCode))
+			case errors.Is(err, repository.ErrTwoFactorNotEnabled):
+				utils.RespondWithDomainError(w, r, repository.ErrTwoFactorLoginExpired)
+			default:
+				respondTwoFactorCodeError(w, r, err)
+			}
+			return
+		}
+
+		user, err := ur.GetCurrentUser(userID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "authentication failed")
+			return
+		}
+
+		// Both factors passed, so the failed password count starts over
+		if err := lar.ClearFailures(strings.ToLower(user.Email)); err != nil {
+			slog.WarnContext(r.Context(), "Failed to clear failed logins", "err", err)
+		}
+
+		// The user may have been banned or suspended since entering their password
+		sanctions, err := sanctionRepo.GetActiveSanctions(user.ID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "authentication failed")
+			return
+		}
+		if lock := models.AccountLock(sanctions); lock != nil {
+			utils.RespondWithError(w, http.StatusForbidden, lock.Message())
+			return
+		}
+		user.Mute = models.ActiveMute(sanctions)
+
+		startSession(w, r, sr, user)
+	}
+}
+
+// GetTwoFactorStatusHandler reports whether the current user has two-factor login on
+func GetTwoFactorStatusHandler(tfr *repository.TwoFactorRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		status, err := tfr.GetStatus(user.ID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to load two-factor status")
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, status)
+	}
+}
+
+// BeginTwoFactorSetupHandler issues an authenticator secret. Two-factor login
+// stays off until the secret is confirmed with a first code.
+func BeginTwoFactorSetupHandler(ur *repository.UserRepository, tfr *repository.TwoFactorRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		// The second factor protects password logins; Google accounts use Google's
+		hasPassword, err := ur.HasPassword(user.ID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to start two-factor setup")
+			return
+		}
+		if !hasPassword {
+			utils.RespondWithDomainError(w, r, models.Forbidden("Two-factor authentication is only available for accounts with a password"))
+			return
+		}
+
+		secret, err := tfr.BeginSetup(user.ID)
+		if err != nil {
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, models.TwoFactorSetupResponse{
+			Secret:     secret,
+			OTPAuthURI: utils.TOTPURI(config.Config.TOTPIssuer, user.Email, secret),
+		})
+	}
+}
+
+// ConfirmTwoFactorSetupHandler turns two-factor login on and returns the recovery codes
+func ConfirmTwoFactorSetupHandler(tfr *repository.TwoFactorRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		req, ok := decodeTwoFactorCode(w, r)
+		if !ok {
+			return
+		}
+
+		codes, err := tfr.ConfirmSetup(user.ID, req.Code)
+		if err != nil {
+			if errors.Is(err, repository.ErrInvalidCode) {
+				utils.RespondWithDomainError(w, r, models.InvalidField("code", "Invalid code, check the time on your device and try again"))
+				return
+			}
+			utils.RespondWithDomainError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
+
+// DisableTwoFactorHandler turns two-factor login off; it needs the password and a current code
+func DisableTwoFactorHandler(ur *repository.UserRepository, tfr *repository.TwoFactorRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		var req models.TwoFactorDisableRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		if req.Password == "" || req.Code == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Password and code are required")
+			return
+		}
+
+		auth, err := ur.GetAuthByUserID(user.ID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to disable two-factor authentication")
+			return
+		}
+		if !utils.CheckPasswordHash(req.Password, auth.PasswordHash) {
+			utils.RespondWithDomainError(w, r, models.InvalidField("password", "Incorrect password"))
+			return
+		}
+
+		attempt := twoFactorAttempt(r, user.ID, "disable")
+		if err := tfr.Disable(attempt, req.Code, config.Config.MaxTwoFactorAttempts, config.Config.LoginLockoutDuration); err != nil {
+			respondTwoFactorCodeError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, "Two-factor authentication disabled")
+	}
+}
+
+// RegenerateRecoveryCodesHandler replaces the user's recovery codes after checking a current code
+func RegenerateRecoveryCodesHandler(tfr *repository.TwoFactorRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user := middleware.GetCurrentUser(r)
+		if user == nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		req, ok := decodeTwoFactorCode(w, r)
+		if !ok {
+			return
+		}
+
+		attempt := twoFactorAttempt(r, user.ID, "regenerate recovery codes")
+		codes, err := tfr.RegenerateRecoveryCodes(attempt, req.Code, config.Config.MaxTwoFactorAttempts, config.Config.LoginLockoutDuration)
+		if err != nil {
+			respondTwoFactorCodeError(w, r, err)
+			return
+		}
+
+		utils.RespondWithSuccess(w, http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
+
+// decodeTwoFactorCode reads a {"code": ...} body, responding with an error if it is missing
+func decodeTwoFactorCode(w http.ResponseWriter, r *http.Request) (models.TwoFactorCodeRequest, bool) {
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return req, false
+	}
+	if req.Code == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Code is required")
+		return req, false
+	}
+	return req, true
+}
+
+// twoFactorAttempt describes a settings change the user confirms with a code
+func twoFactorAttempt(r *http.Request, userID, action string) models.TwoFactorAttempt {
+	return models.TwoFactorAttempt{
+		UserID:    userID,
+		Action:    action,
+		IP:        utils.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// respondTwoFactorCodeError answers a refused two-factor login or settings change; too
+// many wrong codes are reported like other throttled requests
+func respondTwoFactorCodeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, repository.ErrTwoFactorCodesLocked) {
+		utils.RespondWithError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	utils.RespondWithDomainError(w, r, err)
+}
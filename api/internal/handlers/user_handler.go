@@ -3,17 +3,21 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/mailer"
 	"github.com/PaulKerasidis/forum/internal/middleware"
 	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/notifier"
 	"github.com/PaulKerasidis/forum/internal/repository"
 	"github.com/PaulKerasidis/forum/internal/utils"
 )
 
 // Handle user registration logic here
-func RegisterHandler(ur *repository.UserRepository) http.HandlerFunc {
+func RegisterHandler(ur *repository.UserRepository, tr *repository.TokenRepository, m mailer.Mailer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -27,48 +31,47 @@ func RegisterHandler(ur *repository.UserRepository) http.HandlerFunc {
 			return
 		}
 
-		// Validate request - basic required fields check
-		if reg.Email == "" || reg.Username == "" || reg.Password == "" {
-			utils.RespondWithError(w, http.StatusBadRequest, "Email, username and password are required")
-			return
-		}
-
-		// NEW: Check if ConfirmPassword field exists and validate
+		// Every field is checked, so the form can show all of its problems at once
+		var verr models.ValidationError
+		checkRequired(&verr, "username", reg.Username, "Username is required", utils.ValidateUsername)
+		checkRequired(&verr, "email", reg.Email, "Email is required", utils.ValidateEmail)
+		checkRequired(&verr, "password", reg.Password, "Password is required", utils.ValidatePassword)
 		if reg.ConfirmPassword == "" {
-			utils.RespondWithError(w, http.StatusBadRequest, "Password confirmation is required")
-			return
+			verr.Add("confirm_password", "Password confirmation is required")
+		} else if reg.Password != reg.ConfirmPassword {
+			verr.Add("confirm_password", "Passwords do not match")
 		}
-
-		// NEW: Validate that passwords match
-		if reg.Password != reg.ConfirmPassword {
-			utils.RespondWithError(w, http.StatusBadRequest, "Passwords do not match")
+		if err := verr.Err(); err != nil {
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 
-		err = utils.ValidateUserInput(reg.Username, reg.Email, reg.Password)
+		user, err := ur.CreateUser(reg)
 		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 
-		user, err := ur.CreateUser(reg)
-		if err != nil {
-			if err.Error() == "email already taken" {
-				utils.RespondWithError(w, http.StatusConflict, err.Error())
-			} else if err.Error() == "username already taken" {
-				utils.RespondWithError(w, http.StatusConflict, err.Error())
-			} else {
-				utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
-			}
-			return
+		// The account works straight away; the user can ask for a new link if this one fails
+		if err := sendVerificationEmail(r.Context(), tr, m, user); err != nil {
+			slog.ErrorContext(r.Context(), "Error creating verification link", "user_id", user.ID, "err", err)
 		}
 
 		utils.RespondWithSuccess(w, http.StatusCreated, user)
 	}
 }
 
+// checkRequired records a problem with a field that is empty or fails validate
+func checkRequired(verr *models.ValidationError, field, value, missing string, validate func(string) error) {
+	if value == "" {
+		verr.Add(field, missing)
+		return
+	}
+	verr.Check(field, validate(value))
+}
+
 // LoginHandler handles user login
-func LoginHandler(ur *repository.UserRepository, sr *repository.SessionRepository) http.HandlerFunc {
+func LoginHandler(ur *repository.UserRepository, sr *repository.SessionRepository, sanctionRepo *repository.SanctionRepository, tfr *repository.TwoFactorRepository, lar *repository.LoginAttemptRepository, n notifier.Notifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Only allow POST requests
 		if r.Method != http.MethodPost {
@@ -100,34 +103,111 @@ func LoginHandler(ur *repository.UserRepository, sr *repository.SessionRepositor
 			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+
+		// Guesses against a locked email or address are refused without checking the password
+		ip, isClient := utils.ResolveClientIP(r)
+		attempt := models.LoginAttempt{
+			Email:     strings.ToLower(strings.TrimSpace(login.Email)),
+			IP:        ip,
+			SharedIP:  !isClient,
+			UserAgent: r.UserAgent(),
+		}
+		failureID, throttle, err := lar.CheckAllowed(attempt, loginProtection())
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, errors.New("authentication failed").Error())
+			return
+		}
+		if throttle != nil {
+			respondLoginThrottled(w, throttle)
+			return
+		}
+
 		// Authenticate user
 		user, err := ur.Authenticate(login)
 		if err != nil {
-			switch err {
-			case errors.New("invalid credentials"), errors.New("email not found"):
-				utils.RespondWithError(w, http.StatusUnauthorized, errors.New("invalid credentials").Error())
+			switch {
+			case errors.Is(err, repository.ErrInvalidCredentials):
+				owner, _ := ur.GetUserByEmail(login.Email)
+				if owner != nil {
+					attempt.UserID = owner.ID
+				}
+				recordLoginFailure(w, r, lar, n, attempt, owner)
+			case errors.Is(err, repository.ErrEmailNotFound):
+				recordLoginFailure(w, r, lar, n, attempt, nil)
 			default:
+				releaseLoginAttempt(r, lar, failureID)
 				utils.RespondWithError(w, http.StatusInternalServerError, errors.New("authentication failed").Error())
 			}
 			return
 		}
 
-		// Create a new session
-		session, err := sr.CreateSession(user.ID, r.RemoteAddr)
+		// The password was right, so this attempt is not a failure. Earlier failures are
+		// only cleared once the login completes, after the second factor if there is one.
+		releaseLoginAttempt(r, lar, failureID)
+
+		// Banned and suspended users are told why and until when
+		sanctions, err := sanctionRepo.GetActiveSanctions(user.ID)
 		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, errors.New("failed to create session").Error())
+			utils.RespondWithError(w, http.StatusInternalServerError, errors.New("authentication failed").Error())
 			return
 		}
+		if lock := models.AccountLock(sanctions); lock != nil {
+			utils.RespondWithError(w, http.StatusForbidden, lock.Message())
+			return
+		}
+		user.Mute = models.ActiveMute(sanctions)
 
-		utils.SetSessionCookie(session.SessionID, w, r, session.ExpiresAt) // CHANGED: Use simplified call
+		// With two-factor login on, the password only earns a short-lived token;
+		// the session is created once a code is entered at /api/auth/login/2fa
+		twoFactor, err := tfr.IsEnabled(user.ID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, errors.New("authentication failed").Error())
+			return
+		}
+		if twoFactor {
+			// The failed password count is only cleared once the code is right too
+			token, expiresAt, err := tfr.CreateChallenge(user.ID, config.Config.TwoFactorLoginTTL)
+			if err != nil {
+				if errors.Is(err, repository.ErrTwoFactorCodesLocked) {
+					respondTwoFactorCodeError(w, r, err)
+					return
+				}
+				utils.RespondWithError(w, http.StatusInternalServerError, errors.New("authentication failed").Error())
+				return
+			}
+			utils.RespondWithSuccess(w, http.StatusAccepted, models.TwoFactorChallengeResponse{
+				TwoFactorRequired: true,
+				TwoFactorToken:    token,
+				ExpiresAt:         expiresAt,
+			})
+			return
+		}
 
-		// Return JSON response
-		response := models.LoginResponse{
-			User:      *user,
-			SessionID: session.SessionID,
+		// The right password starts the count over
+		if err := lar.ClearFailures(attempt.Email); err != nil {
+			slog.WarnContext(r.Context(), "Failed to clear failed logins", "err", err)
 		}
-		utils.RespondWithSuccess(w, http.StatusOK, response)
+
+		startSession(w, r, sr, user)
+	}
+}
+
+// startSession logs the user in on this device and responds with the session
+func startSession(w http.ResponseWriter, r *http.Request, sr *repository.SessionRepository, user *models.User) {
+	session, err := sr.CreateSession(user.ID, utils.ClientIP(r), r.UserAgent())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, errors.New("failed to create session").Error())
+		return
 	}
+
+	utils.SetSessionCookie(session.SessionID, w, r, session.ExpiresAt) // CHANGED: Use simplified call
+
+	// Return JSON response
+	response := models.LoginResponse{
+		User:      *user,
+		SessionID: session.SessionID,
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, response)
 }
 
 // LogoutHandler handles user logout
@@ -139,9 +219,9 @@ func LogoutHandler(ur *repository.UserRepository, sr *repository.SessionReposito
 			return
 		}
 
-		// Get the session cookie using config session name
-		cookie, err := r.Cookie(config.Config.SessionName) // CHANGED: Use config session name
-		if err != nil {
+		// The session the request was authenticated with, which may have just been rotated
+		session := middleware.GetCurrentSession(r)
+		if session == nil {
 			// this will never happen because of requireAuth function in middleware
 			// sending error response in case authentication fails
 			utils.RespondWithError(w, http.StatusUnauthorized, errors.New("unauthorized access").Error())
@@ -149,7 +229,7 @@ func LogoutHandler(ur *repository.UserRepository, sr *repository.SessionReposito
 		}
 
 		// Delete the session
-		err = sr.DeleteSession(cookie.Value)
+		err := sr.DeleteSession(session.SessionID)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, errors.New("failed to logout").Error())
 			return
@@ -207,11 +287,7 @@ func GetUserProfileHandler(ur *repository.UserRepository) http.HandlerFunc {
 		// Get user profile with statistics
 		profile, err := ur.GetUserProfile(userID)
 		if err != nil {
-			if err.Error() == "user not found" {
-				utils.RespondWithError(w, http.StatusNotFound, "User not found")
-			} else {
-				utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve user profile")
-			}
+			utils.RespondWithDomainError(w, r, err)
 			return
 		}
 
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// RequestIDHeader carries the ID that ties the log lines of one user action together,
+// from the frontend through the API
+const RequestIDHeader = "X-Request-ID"
+
+type contextKey string
+
+const requestIDContextKey contextKey = "request-id"
+
+// redacted replaces the value of attributes that may hold secrets
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are never logged. Keys ending in
+// _password, _secret or _token are treated the same way.
+var sensitiveKeys = map[string]bool{
+	"authorization":  true,
+	"code":           true,
+	"cookie":         true,
+	"password":       true,
+	"recovery_codes": true,
+	"secret":         true,
+	"session":        true,
+	"session_id":     true,
+	"token":          true,
+}
+
+// Setup makes JSON logs at level or above the default for slog and the log package
+func Setup(w io.Writer, level slog.Level) {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})
+	slog.SetDefault(slog.New(requestIDHandler{handler}))
+}
+
+// redact hides the values of sensitive attributes
+func redact(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	if sensitiveKeys[key] || strings.HasSuffix(key, "_password") ||
+		strings.HasSuffix(key, "_secret") || strings.HasSuffix(key, "_token") {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+// requestIDHandler adds the request ID of the context to every record logged with one
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func (h requestIDHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{h.Handler.WithGroup(name)}
+}
+
+// WithRequestID returns a context carrying a request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, id)
+}
+
+// RequestID returns the request ID of a context, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// NewRequestID returns a random request ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID reports whether a request ID from another service is safe to log:
+// at most 128 letters, digits, dashes, underscores, dots or colons
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		isAlnum := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+		if !isAlnum && c != '-' && c != '_' && c != '.' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
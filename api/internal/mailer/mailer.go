@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PaulKerasidis/forum/config"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New returns the mailer selected by the MAILER setting: "smtp" for real delivery,
+// or "log" (the default) to write messages to MAIL_LOG_PATH or the server log
+func New() (Mailer, error) {
+	switch config.Config.Mailer {
+	case "smtp":
+		if config.Config.SMTPHost == "" {
+			return nil, fmt.Errorf("MAILER=smtp requires SMTP_HOST")
+		}
+		return NewSMTPMailer(
+			config.Config.SMTPHost,
+			config.Config.SMTPPort,
+			config.Config.SMTPUsername,
+			config.Config.SMTPPassword,
+			config.Config.MailFrom,
+		), nil
+	case "log", "":
+		return NewLogMailer(config.Config.MailLogPath), nil
+	default:
+		return nil, fmt.Errorf("unknown MAILER %q, expected smtp or log", config.Config.Mailer)
+	}
+}
+
+// headerSafe strips line breaks so user-supplied values cannot add headers
+func headerSafe(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
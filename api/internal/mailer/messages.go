@@ -0,0 +1,51 @@
+package mailer
+
+import "fmt"
+
+// VerifyEmailMessage is sent after registration with a link that confirms the address
+func VerifyEmailMessage(to, username, link, validFor string) Message {
+	return Message{
+		To:      to,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf(`Hi %s,
+
+Please confirm your email address by opening this link:
+
+%s
+
+The link expires in %s. If you did not create an account, you can ignore this email.
+`, username, link, validFor),
+	}
+}
+
+// ResetPasswordMessage is sent when a user asks to reset a forgotten password
+func ResetPasswordMessage(to, username, link, validFor string) Message {
+	return Message{
+		To:      to,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(`Hi %s,
+
+Someone asked to reset the password for your account. To choose a new password, open this link:
+
+%s
+
+The link expires in %s and can only be used once. If you did not ask for this, you can ignore this email; your password has not changed.
+`, username, link, validFor),
+	}
+}
+
+// AccountLockedMessage is sent when repeated failed logins lock an account
+func AccountLockedMessage(to, username, ip, lockedFor, resetLink string) Message {
+	return Message{
+		To:      to,
+		Subject: "Your account was temporarily locked",
+		Body: fmt.Sprintf(`Hi %s,
+
+There were too many failed attempts to log in to your account, most recently from %s, so logging in with a password is blocked for %s.
+
+If this was you, wait and try again. If it was not, someone may be trying to guess your password. You can choose a new one here:
+
+%s
+`, username, ip, lockedFor, resetLink),
+	}
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"database/sql"
+)
+
+// Default is the registry served at /metrics
+var Default = NewRegistry()
+
+// Requests, recorded by the instrumentation middleware
+var (
+	HTTPRequests = Default.NewCounter("forum_http_requests_total",
+		"HTTP requests served, by route pattern, method and status code.",
+		"route", "method", "status")
+	HTTPRequestDuration = Default.NewHistogram("forum_http_request_duration_seconds",
+		"Time taken to serve HTTP requests, by route pattern, method and status code.",
+		DefaultBuckets, "route", "method", "status")
+)
+
+// RateLimitRejections counts requests turned away by the rate limiter, by policy
+var RateLimitRejections = Default.NewCounter("forum_rate_limit_rejections_total",
+	"Requests rejected by the rate limiter, by policy.",
+	"policy")
+
+// Content created through the API
+var (
+	PostsCreated = Default.NewCounter("forum_posts_created_total",
+		"Posts created.")
+	CommentsCreated = Default.NewCounter("forum_comments_created_total",
+		"Comments created, by kind: comment on a post or reply to a comment.",
+		"kind")
+	ReactionsCreated = Default.NewCounter("forum_reactions_created_total",
+		"Reactions added, by target (post or comment) and type (like or dislike). Changing or removing a reaction is not counted.",
+		"target", "type")
+)
+
+// RegisterDB exports the connection pool statistics of db
+func RegisterDB(db *sql.DB) {
+	gauge := func(name, help string, value func(sql.DBStats) float64) {
+		Default.NewGaugeFunc(name, help, func() (float64, error) {
+			return value(db.Stats()), nil
+		})
+	}
+	counter := func(name, help string, value func(sql.DBStats) float64) {
+		Default.NewCounterFunc(name, help, func() float64 {
+			return value(db.Stats())
+		})
+	}
+
+	gauge("forum_db_max_open_connections", "Maximum number of open connections to the database.",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	gauge("forum_db_open_connections", "Established connections, in use and idle.",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	gauge("forum_db_in_use_connections", "Connections currently in use.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	gauge("forum_db_idle_connections", "Idle connections.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	counter("forum_db_wait_count_total", "Connections waited for because the pool was exhausted.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	counter("forum_db_wait_duration_seconds_total", "Time spent waiting for a connection.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	counter("forum_db_max_idle_closed_total", "Connections closed because of the idle connection limit.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	counter("forum_db_max_idle_time_closed_total", "Connections closed because they were idle too long.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) })
+	counter("forum_db_max_lifetime_closed_total", "Connections closed because they reached their maximum lifetime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
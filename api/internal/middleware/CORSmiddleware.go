@@ -32,6 +32,7 @@ func CORS(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Methods", config.Config.AllowedMethods)
 		w.Header().Set("Access-Control-Allow-Headers", config.Config.AllowedHeaders)
 		w.Header().Set("Access-Control-Max-Age", "86400")
+		w.Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
 
 		// Handle preflight requests
 		if r.Method == "OPTIONS" {
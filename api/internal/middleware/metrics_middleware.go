@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/metrics"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// unmatchedRoute labels requests no route matched, so stray paths cannot add series
+const unmatchedRoute = "other"
+
+// quietRoutes are polled by orchestrators and Prometheus; they are logged at debug level
+var quietRoutes = map[string]bool{
+	"/api/health":  true,
+	"/api/ready":   true,
+	"/api/version": true,
+	"/metrics":     true,
+}
+
+// Instrument records the count and latency of every request by route pattern, method
+// and status, and logs it. routeOf returns the pattern a request matches, or "" when none does.
+func Instrument(routeOf func(*http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Routed before the handlers run, since they may change the request
+		route := routeOf(r)
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		method := metricMethod(r.Method)
+		status := strconv.Itoa(recorder.status)
+		metrics.HTTPRequests.Inc(route, method, status)
+		metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(), route, method, status)
+
+		// The path only: query strings can carry OAuth codes and tokens
+		level := slog.LevelInfo
+		if quietRoutes[route] {
+			level = slog.LevelDebug
+		}
+		slog.Log(r.Context(), level, "Request served",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", route,
+			"status", recorder.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"ip", utils.ClientIP(r))
+	})
+}
+
+// metricMethod keeps the method label to the standard methods
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "OTHER"
+}
+
+// statusRecorder remembers the status code a handler responded with
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
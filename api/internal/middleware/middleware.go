@@ -3,10 +3,11 @@ package middleware
 import (
 	"context"
 	"errors"
-	"log"
-	"net"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/PaulKerasidis/forum/config"
 	"github.com/PaulKerasidis/forum/internal/models"
@@ -15,14 +16,20 @@ import (
 )
 
 type AuthMiddleware struct {
-	userRepo    *repository.UserRepository
-	sessionRepo *repository.SessionRepository
+	userRepo     *repository.UserRepository
+	sessionRepo  *repository.SessionRepository
+	sanctionRepo *repository.SanctionRepository
+	tokenRepo    *repository.PersonalTokenRepository
+	securityRepo *repository.SecurityEventRepository
 }
 
-func NewMiddleware(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository) *AuthMiddleware {
+func NewMiddleware(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, sanctionRepo *repository.SanctionRepository, tokenRepo *repository.PersonalTokenRepository, securityRepo *repository.SecurityEventRepository) *AuthMiddleware {
 	return &AuthMiddleware{
-		userRepo:    userRepo,
-		sessionRepo: sessionRepo,
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		sanctionRepo: sanctionRepo,
+		tokenRepo:    tokenRepo,
+		securityRepo: securityRepo,
 	}
 }
 
@@ -30,12 +37,27 @@ type contextKey string
 
 // Define constants using this type
 const (
-	userContextKey contextKey = "user"
+	userContextKey     contextKey = "user"
+	sessionContextKey  contextKey = "session"
+	sanctionContextKey contextKey = "sanction" // ban or suspension that blocked authentication
+	tokenContextKey    contextKey = "token"    // personal access token the request was authenticated with
+	scopeContextKey    contextKey = "scope"    // scope a route granted the token through RequireScope
+
+	tokenRejectedContextKey contextKey = "token_rejected" // bearer token that Authenticate could not accept
 )
 
-// Authenticate middleware verifies authentication and sets user in context
+// How often a session's last-seen time is written back, so not every request is a write
+const sessionTouchInterval = time.Minute
+
+// Authenticate middleware verifies authentication and sets user in context.
+// Requests carry either the session cookie or an "Authorization: Bearer" personal access token.
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := bearerToken(r); ok {
+			m.authenticateToken(w, r, next, token)
+			return
+		}
+
 		// Get the session cookie using config session name
 		cookie, err := r.Cookie(config.Config.SessionName)
 		if err != nil {
@@ -53,44 +75,44 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		// Get current client IP and clean it (remove port if present)
-		currentIP := getClientIP(r)
-
-		// Clean both IPs to ensure consistent comparison
-		storedIP := cleanIP(session.IPAddress)
-		cleanCurrentIP := cleanIP(currentIP)
-
-		// Smart IP validation using cleaned IPs
-		if shouldLogoutDueToIPChange(storedIP, cleanCurrentIP) {
-			log.Printf("🛡️ SECURITY: Suspicious IP change detected - forcing logout")
-			log.Printf("   User: %s", session.UserID)
-			log.Printf("   Original IP: %s", storedIP)
-			log.Printf("   New IP: %s", cleanCurrentIP)
-			log.Printf("   Reason: Major network/location change detected")
+		policy := config.Config.SessionPolicy
+		now := time.Now()
+		currentIP := utils.ClientIP(r)
+		userAgent := r.UserAgent()
 
-			// Delete the session for security
-			err = m.sessionRepo.DeleteSession(cookie.Value)
-			if err != nil {
-				log.Printf("Error deleting session: %v", err)
-			}
-
-			// Clear the cookie
-			utils.ClearSessionCookie(w)
+		// Idle and too old sessions end even though they have not expired yet
+		if reason := policy.Ended(session.CreatedAt, session.LastSeenAt, now); reason != "" {
+			slog.InfoContext(r.Context(), "Session ended", "user_id", session.UserID, "reason", reason)
+			m.endSession(w, r, session)
+			next.ServeHTTP(w, r)
+			return
+		}
 
-			// Continue without authentication
+		// The address is judged against the login address, so a session cannot drift away step by step
+		if !policy.AllowsIP(session.IPAddress, currentIP) {
+			m.recordSessionEvent(session, r, models.SecurityEventSessionIPRejected,
+				fmt.Sprintf("logged out: logged in from %s", session.IPAddress))
+			m.endSession(w, r, session)
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		// If IP changed but it's safe, update the session
-		if storedIP != cleanCurrentIP {
-			log.Printf("🔄 INFO: Updating session IP for user %s: %s -> %s",
-				session.UserID, storedIP, cleanCurrentIP)
+		// Changes the policy allows are still recorded for the user to review
+		ipChanged := currentIP != session.LastIPAddress
+		if ipChanged {
+			m.recordSessionEvent(session, r, models.SecurityEventSessionIPChanged,
+				fmt.Sprintf("previously used from %s", session.LastIPAddress))
+		}
 
-			// Update session with new CLEAN IP (no port)
-			if updateErr := m.sessionRepo.UpdateSessionIP(session.SessionID, cleanCurrentIP); updateErr != nil {
-				log.Printf("Warning: Failed to update session IP: %v", updateErr)
+		// Only browsers and tools that can be told apart are compared; the frontend's own
+		// requests would otherwise look like a different device every time
+		lastUserAgent := session.LastUserAgent
+		if device, ok := knownDevice(userAgent); ok && userAgent != lastUserAgent {
+			if previous, ok := knownDevice(lastUserAgent); ok && previous != device {
+				m.recordSessionEvent(session, r, models.SecurityEventSessionAgentChanged,
+					fmt.Sprintf("previously used with %s", previous))
 			}
+			lastUserAgent = userAgent
 		}
 
 		// Get the user
@@ -100,24 +122,188 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		// Set user in context
+		// Banned and suspended users are logged out; muted users stay logged in read-only
+		sanctions, err := m.sanctionRepo.GetActiveSanctions(user.ID)
+		if err != nil {
+			// Carrying on anonymously would hide the failure behind confusing login prompts
+			slog.ErrorContext(r.Context(), "Error loading sanctions", "user_id", user.ID, "err", err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		if lock := models.AccountLock(sanctions); lock != nil {
+			if err := m.sessionRepo.DeleteSession(cookie.Value); err != nil {
+				slog.ErrorContext(r.Context(), "Error deleting session", "err", err)
+			}
+			utils.ClearSessionCookie(w)
+
+			// Let RequireAuth tell the user why
+			ctx := context.WithValue(r.Context(), sanctionContextKey, lock)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		user.Mute = models.ActiveMute(sanctions)
+
+		expiresAt, renew := policy.Renewal(session.CreatedAt, session.ExpiresAt, now)
+		if session.Role != user.Role {
+			// A new role gets a new session ID, so an ID planted or leaked earlier does not carry it
+			rotated, err := m.sessionRepo.RotateSession(session, user.Role, currentIP, lastUserAgent,
+				policy.ExpiresAt(session.CreatedAt, now))
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Error rotating session", "user_id", user.ID, "err", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			m.recordSessionEvent(rotated, r, models.SecurityEventSessionRotated,
+				fmt.Sprintf("role changed from %s to %s", session.Role, user.Role))
+			session = rotated
+			utils.SetSessionCookie(session.SessionID, w, r, session.ExpiresAt)
+		} else if renew || ipChanged || lastUserAgent != session.LastUserAgent || now.Sub(session.LastSeenAt) > sessionTouchInterval {
+			// Record use, but not on every request, and slide the expiry forward
+			if err := m.sessionRepo.TouchSession(session.SessionID, currentIP, lastUserAgent, expiresAt); err != nil {
+				slog.WarnContext(r.Context(), "Failed to update session", "err", err)
+			} else if renew {
+				session.ExpiresAt = expiresAt
+				utils.SetSessionCookie(session.SessionID, w, r, session.ExpiresAt)
+			}
+		}
+
+		// Set user and session in context
 		ctx := context.WithValue(r.Context(), userContextKey, user)
+		ctx = context.WithValue(ctx, sessionContextKey, session)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// RequireAuth middleware ensures the user is authenticated
+// authenticateToken authenticates a request by personal access token. Unlike a stale
+// cookie, a bad token is refused so scripts notice instead of running anonymously, but
+// only by RejectInvalidToken, once rate limiting and CORS have seen the request.
+func (m *AuthMiddleware) authenticateToken(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	pat, err := m.tokenRepo.GetByToken(token)
+	if err != nil {
+		if !errors.Is(err, repository.ErrInvalidAPIToken) {
+			slog.ErrorContext(r.Context(), "Error looking up personal access token", "err", err)
+		}
+		rejectToken(w, r, next)
+		return
+	}
+
+	user, err := m.userRepo.GetUserBySessionID(pat.UserID)
+	if err != nil {
+		rejectToken(w, r, next)
+		return
+	}
+
+	// Banned and suspended users cannot use their tokens either
+	sanctions, err := m.sanctionRepo.GetActiveSanctions(user.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error loading sanctions", "user_id", user.ID, "err", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	if lock := models.AccountLock(sanctions); lock != nil {
+		ctx := context.WithValue(r.Context(), sanctionContextKey, lock)
+		next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	}
+	user.Mute = models.ActiveMute(sanctions)
+
+	// Record use, but not on every request
+	currentIP := utils.ClientIP(r)
+	if pat.LastUsedAt == nil || pat.LastUsedIP != currentIP || time.Since(*pat.LastUsedAt) > sessionTouchInterval {
+		if err := m.tokenRepo.TouchToken(pat.ID, currentIP); err != nil {
+			slog.WarnContext(r.Context(), "Failed to update token last use", "err", err)
+		}
+	}
+
+	ctx := context.WithValue(r.Context(), userContextKey, user)
+	ctx = context.WithValue(ctx, tokenContextKey, pat)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// rejectToken passes a request with a bad token on for RejectInvalidToken to refuse
+func rejectToken(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	ctx := context.WithValue(r.Context(), tokenRejectedContextKey, true)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RejectInvalidToken middleware refuses requests whose bearer token Authenticate could not
+// accept. It goes inside rate limiting, CORS and the security headers, so bad tokens count
+// against the client's allowance and the 401 carries the same headers as any response.
+func (m *AuthMiddleware) RejectInvalidToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rejected, _ := r.Context().Value(tokenRejectedContextKey).(bool); rejected {
+			utils.RespondWithError(w, http.StatusUnauthorized, "invalid or expired token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAuth middleware ensures the user is authenticated.
+// Personal access tokens are only accepted where a RequireScope in front of it granted them.
 func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := r.Context().Value(userContextKey)
 		if user == nil {
+			if lock, ok := r.Context().Value(sanctionContextKey).(*models.Sanction); ok {
+				utils.RespondWithError(w, http.StatusForbidden, lock.Message())
+				return
+			}
 			utils.RespondWithError(w, http.StatusUnauthorized, errors.New("unauthorized access").Error())
 			return
 		}
+		if GetCurrentToken(r) != nil && r.Context().Value(scopeContextKey) == nil {
+			utils.RespondWithError(w, http.StatusForbidden, "personal access tokens cannot be used here")
+			return
+		}
 		next.ServeHTTP(w, r)
 	})
 }
 
+// RequireScope middleware lets personal access tokens holding scope through to the
+// authentication checks after it. Session logins hold every scope and pass unchanged.
+func (m *AuthMiddleware) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := GetCurrentToken(r); token != nil {
+				if !token.HasScope(scope) {
+					utils.RespondWithError(w, http.StatusForbidden, "token is missing the "+scope+" scope")
+					return
+				}
+				r = r.WithContext(context.WithValue(r.Context(), scopeContextKey, scope))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireUnmuted middleware ensures the user is authenticated and not muted
+func (m *AuthMiddleware) RequireUnmuted(next http.Handler) http.Handler {
+	return m.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := GetCurrentUser(r)
+		if user != nil && user.IsMuted() {
+			utils.RespondWithError(w, http.StatusForbidden, user.Mute.Message())
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// RequireRole middleware ensures the user is authenticated and holds at least the given role
+func (m *AuthMiddleware) RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return m.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := GetCurrentUser(r)
+			if user == nil || !user.HasRole(role) {
+				utils.RespondWithError(w, http.StatusForbidden, errors.New("insufficient permissions").Error())
+				return
+			}
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
+
 // GetCurrentUser returns the authenticated user from the context
 func GetCurrentUser(r *http.Request) *models.User {
 	userValue := r.Context().Value(userContextKey)
@@ -134,129 +320,64 @@ func GetCurrentUser(r *http.Request) *models.User {
 	return user
 }
 
-// Clean IP address - remove port if present and normalize localhost
-func cleanIP(ipWithPossiblePort string) string {
-	// Handle the case where IP might include port (like "127.0.0.1:55394")
-	host, _, err := net.SplitHostPort(ipWithPossiblePort)
-	if err != nil {
-		// If SplitHostPort fails, it's probably just an IP without port
-		host = ipWithPossiblePort
-	}
-	
-	// Normalize localhost - treat IPv4 and IPv6 localhost as the same
-	if host == "127.0.0.1" || host == "::1" || host == "localhost" {
-		return "localhost"
-	}
-	
-	return host
+// GetCurrentSession returns the session the request was authenticated with
+func GetCurrentSession(r *http.Request) *models.Session {
+	session, _ := r.Context().Value(sessionContextKey).(*models.Session)
+	return session
 }
 
-// Smart IP validation - returns true if user should be logged out
-func shouldLogoutDueToIPChange(originalIP, currentIP string) bool {
-	if originalIP == currentIP {
-		return false // No change - all good
-	}
-
-	// Parse both IP addresses
-	origParsed := net.ParseIP(originalIP)
-	currParsed := net.ParseIP(currentIP)
-
-	if origParsed == nil || currParsed == nil {
-		return true // Invalid IP format - logout for safety
-	}
-
-	// Both are private IPs (home networks) - check if same subnet
-	if isPrivateIP(origParsed) && isPrivateIP(currParsed) {
-		// Allow changes within same home network (DHCP renewals)
-		return !isSameSubnet(originalIP, currentIP, "/24")
-	}
+// GetCurrentToken returns the personal access token the request was authenticated with,
+// or nil for session logins and anonymous requests
+func GetCurrentToken(r *http.Request) *models.PersonalAccessToken {
+	token, _ := r.Context().Value(tokenContextKey).(*models.PersonalAccessToken)
+	return token
+}
 
-	// Both are public IPs - check if same ISP range
-	if !isPrivateIP(origParsed) && !isPrivateIP(currParsed) {
-		// Allow changes within same ISP (dynamic IP assignments)
-		return !isSameISPRange(originalIP, currentIP)
+// bearerToken returns the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
 	}
-
-	// One private, one public = major change (home to mobile)
-	return true
+	token = strings.TrimSpace(token)
+	return token, token != ""
 }
 
-// Check if IP is in private network range (RFC 1918)
-func isPrivateIP(ip net.IP) bool {
-	// Handle localhost normalization first
-	ipStr := ip.String()
-	if ipStr == "127.0.0.1" || ipStr == "::1" || ipStr == "localhost" {
-		return true
-	}
-	
-	// Private IPv4 ranges: 10.0.0.0/8, 172.16.0.0/12, 192.168.0.0/16
-	privateRanges := []string{
-		"10.0.0.0/8",     // Class A private
-		"172.16.0.0/12",  // Class B private
-		"192.168.0.0/16", // Class C private
-		"127.0.0.0/8",    // IPv4 Localhost
-		"::1/128",        // IPv6 Localhost
-		"fc00::/7",       // IPv6 private range
-		"fe80::/10",      // IPv6 link-local
+// GetActor returns the authenticated user as an Actor, with the client IP for the audit log
+func GetActor(r *http.Request) models.Actor {
+	user := GetCurrentUser(r)
+	if user == nil {
+		return models.Actor{IP: utils.ClientIP(r)}
 	}
+	actor := models.ActorFromUser(user)
+	actor.IP = utils.ClientIP(r)
+	return actor
+}
 
-	for _, cidr := range privateRanges {
-		_, subnet, _ := net.ParseCIDR(cidr)
-		if subnet.Contains(ip) {
-			return true
-		}
+// endSession logs a session out that may no longer be used
+func (m *AuthMiddleware) endSession(w http.ResponseWriter, r *http.Request, session *models.Session) {
+	if err := m.sessionRepo.DeleteSession(session.SessionID); err != nil {
+		slog.ErrorContext(r.Context(), "Error deleting session", "err", err)
 	}
-	return false
+	utils.ClearSessionCookie(w)
 }
 
-// Check if two IPs are in the same subnet
-func isSameSubnet(ip1, ip2, mask string) bool {
-	// Create subnet from first IP
-	_, subnet, err := net.ParseCIDR(ip1 + mask)
+// recordSessionEvent adds an event about a session to its user's security log
+func (m *AuthMiddleware) recordSessionEvent(session *models.Session, r *http.Request, eventType, details string) {
+	err := m.securityRepo.RecordEvent(models.SecurityEvent{
+		UserID:    session.UserID,
+		Type:      eventType,
+		IP:        utils.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details:   details,
+	})
 	if err != nil {
-		return false
-	}
-
-	// Check if second IP is in that subnet
-	ip2Parsed := net.ParseIP(ip2)
-	if ip2Parsed == nil {
-		return false
+		slog.ErrorContext(r.Context(), "Error recording security event", "user_id", session.UserID, "err", err)
 	}
-
-	return subnet.Contains(ip2Parsed)
-}
-
-// Check if two public IPs are from same ISP (broad range check)
-func isSameISPRange(ip1, ip2 string) bool {
-	// For public IPs, check /16 range (usually same ISP/region)
-	return isSameSubnet(ip1, ip2, "/16")
 }
 
-// Get real client IP address (handles proxies and load balancers)
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header (most common proxy header)
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// Can contain multiple IPs, take the first (original client)
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-
-	// Check X-Real-IP header (nginx reverse proxy)
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return strings.TrimSpace(xri)
-	}
-
-	// Check CF-Connecting-IP header (Cloudflare)
-	if cfip := r.Header.Get("CF-Connecting-IP"); cfip != "" {
-		return strings.TrimSpace(cfip)
-	}
-
-	// Fall back to direct connection IP
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr // Return as-is if can't parse
-	}
-	return host
+// knownDevice describes a user agent, reporting false when it is not one that can be recognised
+func knownDevice(userAgent string) (string, bool) {
+	device := utils.DescribeUserAgent(userAgent)
+	return device, !strings.HasPrefix(device, "Unknown")
 }
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+func TestRequireScope(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleMember}
+	readToken := &models.PersonalAccessToken{Scopes: []string{models.ScopeRead}}
+	postsToken := &models.PersonalAccessToken{Scopes: []string{models.ScopeRead, models.ScopeWritePosts}}
+
+	m := &AuthMiddleware{}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := []struct {
+		name    string
+		token   *models.PersonalAccessToken // nil for a session login
+		handler http.Handler
+		want    int
+	}{
+		{"session passes a scoped route", nil, m.RequireScope(models.ScopeWritePosts)(m.RequireAuth(ok)), http.StatusNoContent},
+		{"session passes an unscoped route", nil, m.RequireAuth(ok), http.StatusNoContent},
+		{"token with the scope", postsToken, m.RequireScope(models.ScopeWritePosts)(m.RequireAuth(ok)), http.StatusNoContent},
+		{"token without the scope", readToken, m.RequireScope(models.ScopeWritePosts)(m.RequireAuth(ok)), http.StatusForbidden},
+		{"token on a route with no scope", postsToken, m.RequireAuth(ok), http.StatusForbidden},
+		{"token on a role route with no scope", postsToken, m.RequireRole(models.RoleMember)(ok), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), userContextKey, user)
+			if tt.token != nil {
+				ctx = context.WithValue(ctx, tokenContextKey, tt.token)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/posts", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			tt.handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRejectedTokenIsRateLimited(t *testing.T) {
+	m := &AuthMiddleware{}
+	rl := NewRateLimiter(NewMemoryRateLimitStore(), nil, models.RateLimit{Requests: 2, Window: time.Minute})
+	var reached bool
+	handler := m.RejectInvalidToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { reached = true }))
+	handler = CORS(SecurityHeaders(rl.Limit(handler)))
+
+	// Authenticate passes a bad token on the way rejectToken does, in the order routes wrap the API
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { rejectToken(w, r, handler) })
+
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/api/posts", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("request %d status = %d, want %d", i+1, rec.Code, want)
+		}
+		for _, header := range []string{"X-Content-Type-Options", "Access-Control-Max-Age", "RateLimit-Limit"} {
+			if rec.Header().Get(header) == "" {
+				t.Errorf("request %d response is missing %s", i+1, header)
+			}
+		}
+	}
+	if reached {
+		t.Error("a request with a rejected token reached the handler")
+	}
+}
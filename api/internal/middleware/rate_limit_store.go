@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+// RateLimitStore keeps the rate limiter's token buckets. Take must refill the bucket
+// for key and take a token from it atomically.
+type RateLimitStore interface {
+	Take(key string, limit models.RateLimit) (models.RateLimitResult, error)
+}
+
+const (
+	// Buckets are spread over shards so requests for different clients rarely wait on the same lock
+	memoryStoreShards = 32
+	// How often each shard drops the buckets that have refilled completely
+	memoryStoreSweepInterval = time.Minute
+)
+
+// MemoryRateLimitStore keeps buckets in this process. Limits are per API instance and
+// reset on restart.
+type MemoryRateLimitStore struct {
+	shards [memoryStoreShards]memoryShard
+}
+
+type memoryShard struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+	fullAt  time.Time // a full bucket is the same as no bucket, so it can be dropped from then on
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	s := &MemoryRateLimitStore{}
+	now := time.Now()
+	for i := range s.shards {
+		s.shards[i].buckets = make(map[string]*memoryBucket)
+		s.shards[i].lastSweep = now
+	}
+	return s
+}
+
+// Take implements RateLimitStore
+func (s *MemoryRateLimitStore) Take(key string, limit models.RateLimit) (models.RateLimitResult, error) {
+	shard := &s.shards[shardIndex(key)]
+	now := time.Now()
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	// Idle clients' buckets are refilled by now, so forget them as the shard is used
+	if now.Sub(shard.lastSweep) > memoryStoreSweepInterval {
+		for k, b := range shard.buckets {
+			if !now.Before(b.fullAt) {
+				delete(shard.buckets, k)
+			}
+		}
+		shard.lastSweep = now
+	}
+
+	b, ok := shard.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(limit.Requests), updated: now}
+		shard.buckets[key] = b
+	}
+
+	tokens, result := limit.Take(b.tokens, now.Sub(b.updated))
+	b.tokens = tokens
+	b.updated = now
+	b.fullAt = now.Add(result.Reset)
+	return result, nil
+}
+
+// shardIndex picks the shard holding key
+func shardIndex(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32() % memoryStoreShards
+}
@@ -1,85 +1,173 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"net/http"
-	"sync"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/metrics"
+	"github.com/PaulKerasidis/forum/internal/models"
 	"github.com/PaulKerasidis/forum/internal/utils"
 )
 
-// RateLimiter tracks request rates per IP and limits excessive requests
+// defaultRateLimitPolicy names the bucket of requests that no rule matches
+const defaultRateLimitPolicy = "default"
+
+// RateLimiter limits requests with token buckets, one per policy and client.
+// A logged-in client is its user ID, so it keeps its allowance across networks;
+// anyone else is their IP address, and a login also the email it is for.
 type RateLimiter struct {
-	requests    map[string][]time.Time
-	windowSize  time.Duration
-	maxRequests int
-	mutex       sync.Mutex
+	store        RateLimitStore
+	rules        []config.RateLimitRule
+	defaultLimit models.RateLimit
 }
 
-// NewRateLimiter creates a new rate limiter with the specified window size and max requests
-func NewRateLimiter(windowSize time.Duration, maxRequests int) *RateLimiter {
+// NewRateLimiter creates a rate limiter. Requests use the first rule that matches them,
+// or defaultLimit when none does.
+func NewRateLimiter(store RateLimitStore, rules []config.RateLimitRule, defaultLimit models.RateLimit) *RateLimiter {
 	return &RateLimiter{
-		requests:    make(map[string][]time.Time),
-		windowSize:  windowSize,
-		maxRequests: maxRequests,
-		mutex:       sync.Mutex{},
+		store:        store,
+		rules:        rules,
+		defaultLimit: defaultLimit,
 	}
 }
 
-// cleanupOldRequests removes requests outside the current time window
-func (rl *RateLimiter) cleanupOldRequests(ip string) {
-	now := time.Now()
-	keepFrom := now.Add(-rl.windowSize)
-
-	var validRequests []time.Time
-	for _, reqTime := range rl.requests[ip] {
-		if reqTime.After(keepFrom) {
-			validRequests = append(validRequests, reqTime)
+// policyFor returns the name and limit that apply to a request
+func (rl *RateLimiter) policyFor(r *http.Request) (string, models.RateLimit) {
+	for _, rule := range rl.rules {
+		if rule.Matches(r.Method, r.URL.Path) {
+			return rule.Name(), models.RateLimit{Requests: rule.Requests, Window: rule.Window}
 		}
 	}
+	return defaultRateLimitPolicy, rl.defaultLimit
+}
+
+// loginPath is also limited per submitted email, so failures against other accounts
+// from a shared address do not use up a user's own logins
+const loginPath = "/api/auth/login"
 
-	rl.requests[ip] = validRequests
+// sharedLoginAllowance multiplies the login limit for an address as a whole. Each account
+// keeps the rule's own limit, while a network of users behind one address can still log in.
+const sharedLoginAllowance = 5
+
+// maxEmailPeekBytes bounds how much of a body is read to find its email
+const maxEmailPeekBytes = 64 << 10
+
+// rateLimitBucket is one bucket a request takes a token from
+type rateLimitBucket struct {
+	key   string
+	limit models.RateLimit
 }
 
-// checkRateLimit checks if an IP has exceeded the rate limit
-func (rl *RateLimiter) checkRateLimit(ip string) bool {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
+// bucketsFor returns the buckets a request is charged to. Anyone not logged in always
+// pays from their address's bucket; a login also pays from the bucket for the address
+// and submitted email.
+func bucketsFor(r *http.Request, policy string, limit models.RateLimit) []rateLimitBucket {
+	if user := GetCurrentUser(r); user != nil {
+		return []rateLimitBucket{{policy + "|user:" + user.ID, limit}}
+	}
 
-	// First cleanup old requests
-	rl.cleanupOldRequests(ip)
+	ipKey := policy + "|ip:" + utils.ClientIP(r)
+	if r.Method != http.MethodPost || r.URL.Path != loginPath {
+		return []rateLimitBucket{{ipKey, limit}}
+	}
 
-	// Check current count against limit
-	return len(rl.requests[ip]) >= rl.maxRequests
+	shared := models.RateLimit{Requests: limit.Requests * sharedLoginAllowance, Window: limit.Window}
+	return []rateLimitBucket{
+		{ipKey, shared},
+		{ipKey + "|email:" + submittedEmail(r), limit},
+	}
 }
 
-// addRequest records a new request for an IP
-func (rl *RateLimiter) addRequest(ip string) {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
+// submittedEmail returns the lowercased email of a JSON body, leaving the body for the handler
+func submittedEmail(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+	peeked, _ := io.ReadAll(io.LimitReader(r.Body, maxEmailPeekBytes))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(peeked), r.Body), r.Body}
 
-	// First cleanup old requests
-	rl.cleanupOldRequests(ip)
+	var payload struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(peeked, &payload) != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(payload.Email))
+}
 
-	// Add current request
-	rl.requests[ip] = append(rl.requests[ip], time.Now())
+// readCloser reads the peeked start of a body, then the rest, and closes the original
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
 // Limit is the middleware handler for rate limiting
 func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := getClientIP(r)
+		policy, limit := rl.policyFor(r)
 
-		// Check if rate limited
-		if rl.checkRateLimit(ip) {
-			utils.RespondWithError(w, http.StatusTooManyRequests, "Rate limit exceeded. Please try again later.")
+		limit, result, err := rl.take(bucketsFor(r, policy, limit))
+		if err != nil {
+			// A broken store should not take the whole API down with it
+			slog.ErrorContext(r.Context(), "Error checking rate limit", "err", err)
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Record this request
-		rl.addRequest(ip)
+		setRateLimitHeaders(w, limit, result)
+		if !result.Allowed {
+			metrics.RateLimitRejections.Inc(policy)
+			retryAfter := ceilSeconds(result.RetryAfter)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			utils.RespondWithError(w, http.StatusTooManyRequests,
+				fmt.Sprintf("Too many requests. Please try again in %d seconds.", retryAfter))
+			return
+		}
 
 		// Continue with the next handler
 		next.ServeHTTP(w, r)
 	})
 }
+
+// take takes a token from each bucket in turn, stopping at the first that refuses. It
+// returns the bucket that refused, or else the one with the fewest tokens left, for the
+// response headers.
+func (rl *RateLimiter) take(buckets []rateLimitBucket) (models.RateLimit, models.RateLimitResult, error) {
+	var tightest models.RateLimitResult
+	var tightestLimit models.RateLimit
+	for i, b := range buckets {
+		result, err := rl.store.Take(b.key, b.limit)
+		if err != nil {
+			return b.limit, result, err
+		}
+		if !result.Allowed {
+			return b.limit, result, nil
+		}
+		if i == 0 || result.Remaining < tightest.Remaining {
+			tightest, tightestLimit = result, b.limit
+		}
+	}
+	return tightestLimit, tightest, nil
+}
+
+// setRateLimitHeaders describes the client's bucket with the IETF RateLimit header fields
+func setRateLimitHeaders(w http.ResponseWriter, limit models.RateLimit, result models.RateLimitResult) {
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, ceilSeconds(limit.Window)))
+}
+
+// ceilSeconds rounds a duration up to whole seconds, as the headers need
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
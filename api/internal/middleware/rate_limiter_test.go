@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+func TestRateLimiterLimit(t *testing.T) {
+	rules := []config.RateLimitRule{{Method: "POST", Path: "/api/auth/login", Requests: 3, Window: time.Minute}}
+	rl := NewRateLimiter(NewMemoryRateLimitStore(), rules, models.RateLimit{Requests: 100, Window: time.Minute})
+
+	var bodies []string
+	h := rl.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The email was peeked at, but the handler still gets the whole body
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+	}))
+
+	login := func(ip, email string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"email":"`+email+`","password":"x"}`))
+		req.RemoteAddr = ip + ":5000"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		if rec := login("10.0.0.1", "alice@example.com"); rec.Code != http.StatusOK {
+			t.Fatalf("login %d status = %d, want 200", i+1, rec.Code)
+		}
+	}
+	if bodies[0] != `{"email":"alice@example.com","password":"x"}` {
+		t.Errorf("handler read body %q", bodies[0])
+	}
+
+	rec := login("10.0.0.1", "Alice@Example.com ")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("fourth login status = %d, want 429", rec.Code)
+	}
+	wantHeaders := map[string]string{
+		"Retry-After":         "20",
+		"RateLimit-Limit":     "3",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+		"RateLimit-Policy":    "3;w=60",
+	}
+	for name, want := range wantHeaders {
+		if got := rec.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	// Other accounts, other clients and other routes have their own buckets
+	if rec := login("10.0.0.1", "bob@example.com"); rec.Code != http.StatusOK {
+		t.Errorf("login for another email status = %d, want 200", rec.Code)
+	}
+	if rec := login("10.0.0.2", "alice@example.com"); rec.Code != http.StatusOK {
+		t.Errorf("login from another address status = %d, want 200", rec.Code)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/posts", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "99" {
+		t.Errorf("default policy status = %d, remaining %q, want 200 and 99", rec.Code, rec.Header().Get("RateLimit-Remaining"))
+	}
+}
+
+func TestRateLimiterChargesEachAddress(t *testing.T) {
+	rules := []config.RateLimitRule{
+		{Method: "POST", Path: "/api/auth/login", Requests: 2, Window: time.Hour},
+		{Method: "POST", Path: "/api/auth/register", Requests: 5, Window: time.Hour},
+	}
+	rl := NewRateLimiter(NewMemoryRateLimitStore(), rules, models.RateLimit{Requests: 100, Window: time.Minute})
+	h := rl.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	post := func(path, email string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"email":"`+email+`"}`))
+		req.RemoteAddr = "10.0.0.1:5000"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// A new email for every registration does not get a new allowance
+	for i := 1; i <= 6; i++ {
+		want := http.StatusOK
+		if i == 6 {
+			want = http.StatusTooManyRequests
+		}
+		if code := post("/api/auth/register", fmt.Sprintf("user%d@example.com", i)); code != want {
+			t.Errorf("registration %d status = %d, want %d", i, code, want)
+		}
+	}
+
+	// Logins spread over many accounts stop at the address's shared allowance
+	spray := 2 * sharedLoginAllowance
+	for i := 1; i <= spray+1; i++ {
+		want := http.StatusOK
+		if i == spray+1 {
+			want = http.StatusTooManyRequests
+		}
+		if code := post("/api/auth/login", fmt.Sprintf("user%d@example.com", i)); code != want {
+			t.Errorf("login %d status = %d, want %d", i, code, want)
+		}
+	}
+}
+
+func TestRateLimiterKeysUsersByID(t *testing.T) {
+	rl := NewRateLimiter(NewMemoryRateLimitStore(), nil, models.RateLimit{Requests: 2, Window: time.Minute})
+	h := rl.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	user := &models.User{ID: "user-1"}
+
+	// A logged-in user keeps one allowance as their address changes
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/posts", nil)
+		req.RemoteAddr = ip + ":5000"
+		req = req.WithContext(context.WithValue(req.Context(), userContextKey, user))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		want := http.StatusOK
+		if i == 2 {
+			want = http.StatusTooManyRequests
+		}
+		if rec.Code != want {
+			t.Errorf("request %d from %s status = %d, want %d", i+1, ip, rec.Code, want)
+		}
+	}
+}
+
+func TestMemoryRateLimitStoreConcurrentTakes(t *testing.T) {
+	s := NewMemoryRateLimitStore()
+	limit := models.RateLimit{Requests: 10, Window: time.Hour}
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := s.Take("client", limit)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if result.Allowed {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != int32(limit.Requests) {
+		t.Errorf("%d concurrent requests were allowed, want %d", got, limit.Requests)
+	}
+	if result, _ := s.Take("other-client", limit); !result.Allowed {
+		t.Error("another client's first request was refused")
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited actions, recorded as "<target>.<verb>"
+const (
+	AuditPostEdit       = "post.edit" // includes category changes
+	AuditPostDelete     = "post.delete"
+	AuditCommentEdit    = "comment.edit"
+	AuditCommentDelete  = "comment.delete"
+	AuditUserRoleChange = "user.role_change"
+	AuditUserBan        = "user.ban"
+	AuditUserSuspend    = "user.suspend"
+	AuditUserMute       = "user.mute"
+	AuditUserLift       = "user.sanction_lift"
+	AuditReportResolve  = "report.resolve"
+	AuditReportDismiss  = "report.dismiss"
+)
+
+// Kinds of audit targets
+const (
+	AuditTargetPost    = "post"
+	AuditTargetComment = "comment"
+	AuditTargetUser    = "user"
+	AuditTargetReport  = "report"
+)
+
+// AuditEntry is one row of the append-only audit log
+type AuditEntry struct {
+	ID            string          `json:"audit_id"`
+	ActorID       string          `json:"actor_id,omitempty"` // empty for command line changes
+	ActorUsername string          `json:"actor_username,omitempty"`
+	ActorRole     string          `json:"actor_role"`
+	Action        string          `json:"action"`
+	TargetType    string          `json:"target_type"`
+	TargetID      string          `json:"target_id"`
+	Before        json.RawMessage `json:"before,omitempty"` // snapshot of the target before the action
+	After         json.RawMessage `json:"after,omitempty"`  // snapshot after the action, absent for deletions
+	IPAddress     string          `json:"ip_address,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// AuditFilters holds the audit log query filters, all optional
+type AuditFilters struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Since      *time.Time
+	Until      *time.Time
+}
+
+// PaginatedAuditLogResponse is the response for a page of the audit log
+type PaginatedAuditLogResponse struct {
+	Entries    []*AuditEntry  `json:"entries"`
+	Pagination PaginationInfo `json:"pagination"`
+}
+
+// NewPaginatedAuditLogResponse creates a paginated audit log response
+func NewPaginatedAuditLogResponse(entries []*AuditEntry, totalCount, limit, offset int) *PaginatedAuditLogResponse {
+	return &PaginatedAuditLogResponse{
+		Entries:    entries,
+		Pagination: NewPaginationInfo(totalCount, limit, offset),
+	}
+}
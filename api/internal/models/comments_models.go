@@ -11,6 +11,16 @@ type Comment struct {
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 
+	// Role of the staff member who last edited the comment (empty unless edited by someone other than the author)
+	UpdatedByRole string `json:"updated_by_role,omitempty"`
+
+	// Thread position
+	ParentCommentID *string `json:"parent_comment_id,omitempty"` // nil for top-level comments
+	Depth           int     `json:"depth"`                       // 0 for top-level comments
+
+	// Deleted comments with replies stay in the thread without their author and content
+	Deleted bool `json:"deleted,omitempty"`
+
 	// Aggregated metrics
 	LikeCount    int `json:"like_count"`
 	DislikeCount int `json:"dislike_count"`
@@ -18,14 +28,27 @@ type Comment struct {
 	// User context
 	UserReaction *int `json:"user_reaction,omitempty"` // nil, 1=like, 2=dislike
 	IsOwner      bool `json:"is_owner,omitempty"`      // can current user edit/delete
+
+	// Replies subtree - only the first page of replies is loaded, page further via /api/comments/replies/{id}
+	ReplyCount     int        `json:"reply_count"`
+	Replies        []*Comment `json:"replies,omitempty"`
+	HasMoreReplies bool       `json:"has_more_replies,omitempty"`
 }
 
+// DeletedCommentContent replaces the content of a deleted comment that is kept for its replies
+const DeletedCommentContent = "[deleted]"
+
 // Request models are good
 type CreateCommentRequest struct {
 	PostID  string `json:"post_id" binding:"required"`
 	Content string `json:"content" binding:"required,min=10,max=500"`
 }
 
+// ReplyCommentRequest is used for replying to an existing comment
+type ReplyCommentRequest struct {
+	Content string `json:"content" binding:"required,min=10,max=500"`
+}
+
 // UpdateCommentRequest is used for updating an existing comment
 type UpdateCommentRequest struct {
 	Content string `json:"content" binding:"required,min=10,max=500"`
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// Error kinds. Domain errors wrap one of these, so handlers can choose a status
+// code with errors.Is instead of comparing messages.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Problem types of errors that clients tell apart from others with the same status code.
+// Errors without one are answered with the type "about:blank".
+const (
+	ProblemInvalidCode        = "urn:forum:problem:invalid-code"
+	ProblemLastLoginMethod    = "urn:forum:problem:last-login-method"
+	ProblemPasswordAlreadySet = "urn:forum:problem:password-already-set"
+	ProblemMaxReplyDepth      = "urn:forum:problem:max-reply-depth"
+	ProblemReportOwnContent   = "urn:forum:problem:report-own-content"
+	ProblemReportClaimed      = "urn:forum:problem:report-claimed"
+	ProblemReportClosed       = "urn:forum:problem:report-closed"
+)
+
+// DomainError is an error of a known kind, with a message that is safe to show to users
+// and an optional problem type
+type DomainError struct {
+	Kind    error
+	Message string
+	Type    string
+}
+
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Kind
+}
+
+// NotFound returns an error for something that does not exist
+func NotFound(message string) error {
+	return &DomainError{Kind: ErrNotFound, Message: message}
+}
+
+// Forbidden returns an error for something the user may not do
+func Forbidden(message string) error {
+	return &DomainError{Kind: ErrForbidden, Message: message}
+}
+
+// Conflict returns an error for something that clashes with the current state
+func Conflict(message string) error {
+	return &DomainError{Kind: ErrConflict, Message: message}
+}
+
+// Unauthorized returns an error for credentials or tokens that are not accepted
+func Unauthorized(message string) error {
+	return &DomainError{Kind: ErrUnauthorized, Message: message}
+}
+
+// Invalid returns a validation error that is not about one field
+func Invalid(message string) error {
+	return &DomainError{Kind: ErrValidation, Message: message}
+}
+
+// Typed returns a copy of the domain error err with a problem type, for errors clients
+// need to tell apart from others of the same kind
+func Typed(err error, problemType string) error {
+	var de *DomainError
+	if !errors.As(err, &de) {
+		return err
+	}
+	typed := *de
+	typed.Type = problemType
+	return &typed
+}
+
+// FieldError is a problem with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects the problems with the fields of a request
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// InvalidField returns a validation error for a single field
+func InvalidField(field, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// Add records a problem with field
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Check records err, if any, as a problem with field
+func (e *ValidationError) Check(field string, err error) {
+	if err != nil {
+		e.Add(field, err.Error())
+	}
+}
+
+// Err returns e when it holds any problems and nil otherwise
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// Problem is an RFC 7807 problem details body. Success and Error repeat the detail in
+// the shape of APIResponse, for clients that read that.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	Success  bool         `json:"success"`
+	Error    string       `json:"error,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Readiness is the outcome of the readiness probe
+type Readiness struct {
+	Ready  bool                      `json:"ready"`
+	Checks map[string]ReadinessCheck `json:"checks"` // keyed by what was checked, e.g. "database"
+}
+
+// ReadinessCheck is one dependency the API needs to serve requests
+type ReadinessCheck struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// BuildInfo describes the running binary, as embedded by the Go toolchain
+type BuildInfo struct {
+	Module       string `json:"module"`
+	Version      string `json:"version"` // module version, "(devel)" for local builds
+	GoVersion    string `json:"go_version"`
+	Revision     string `json:"revision,omitempty"` // VCS commit the binary was built from
+	RevisionTime string `json:"revision_time,omitempty"`
+	Modified     bool   `json:"modified"` // built with uncommitted changes
+	Tags         string `json:"tags,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// UserIdentity is an OAuth provider account linked to a user as a way to log in
+type UserIdentity struct {
+	Provider    string     `json:"provider"`
+	DisplayName string     `json:"display_name"`
+	Email       string     `json:"email,omitempty"` // address the provider reported when linked
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+// LoginMethodsResponse lists how the current user can log in
+type LoginMethodsResponse struct {
+	HasPassword bool                `json:"has_password"`
+	Identities  []UserIdentity      `json:"identities"`
+	Available   []OAuthProviderInfo `json:"available"` // enabled providers that are not linked yet
+}
+
+// SetPasswordRequest adds a password to an account that only logs in through providers
+type SetPasswordRequest struct {
+	Password        string `json:"password"`
+	ConfirmPassword string `json:"confirm_password"`
+}
@@ -2,12 +2,16 @@ package models
 
 import "time"
 
-// OAuthState represents the state parameter for OAuth flow
+// OAuthState is a pending authorization request, stored until the provider redirects back
 type OAuthState struct {
-	State     string    `json:"state"`
-	Nonce     string    `json:"nonce"`
-	CreatedAt time.Time `json:"created_at"`
-	ExpiresAt time.Time `json:"expires_at"`
+	State        string    `json:"state"`
+	Provider     string    `json:"provider"`
+	Binding      string    `json:"-"`                   // cookie value tying the flow to the browser that started it; only its hash is stored
+	CodeVerifier string    `json:"-"`                   // PKCE verifier sent with the token exchange
+	LinkUser     string    `json:"link_user,omitempty"` // set when a logged-in user is linking the provider instead of logging in
+	ReturnTo     string    `json:"return_to,omitempty"` // local path to continue to after logging in
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
 }
 
 // GoogleUserInfo represents the user info returned by Google OAuth
@@ -22,8 +26,55 @@ type GoogleUserInfo struct {
 	Locale        string `json:"locale"`
 }
 
-// GoogleTokenResponse represents the token response from Google
-type GoogleTokenResponse struct {
+// GitHubUserInfo represents the user returned by GitHub's /user endpoint
+type GitHubUserInfo struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// GitHubEmail is one entry of GitHub's /user/emails endpoint
+type GitHubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// OIDCUserInfo represents the standard claims returned by an OpenID Connect userinfo endpoint
+type OIDCUserInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// OIDCDiscovery holds the endpoints from an OpenID Connect discovery document
+type OIDCDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// OAuthProfile is the provider-independent identity an OAuth login resolves to
+type OAuthProfile struct {
+	Provider      string // registry name, e.g. "google" or "github"
+	ProviderID    string // the user's stable ID at the provider
+	Email         string
+	EmailVerified bool   // whether the provider vouches for the address
+	Username      string // suggested username, may be empty
+}
+
+// OAuthProviderInfo describes an enabled login provider for clients
+type OAuthProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OAuthTokenResponse represents the token response of an OAuth 2.0 token endpoint
+type OAuthTokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	TokenType    string `json:"token_type"`
 	ExpiresIn    int    `json:"expires_in"`
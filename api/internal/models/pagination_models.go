@@ -12,7 +12,7 @@ type PaginationInfo struct {
 
 // PaginatedPostsResponse is the response for paginated posts
 type PaginatedPostsResponse struct {
-	Posts      []*Post        `json:"posts"`
+	Posts      []*PostSummary `json:"posts"`
 	Pagination PaginationInfo `json:"pagination"`
 }
 
@@ -45,7 +45,7 @@ func NewPaginationInfo(totalCount, limit, offset int) PaginationInfo {
 }
 
 // NewPaginatedPostsResponse creates a paginated posts response
-func NewPaginatedPostsResponse(posts []*Post, totalCount, limit, offset int) *PaginatedPostsResponse {
+func NewPaginatedPostsResponse(posts []*PostSummary, totalCount, limit, offset int) *PaginatedPostsResponse {
 	return &PaginatedPostsResponse{
 		Posts:      posts,
 		Pagination: NewPaginationInfo(totalCount, limit, offset),
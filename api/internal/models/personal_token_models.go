@@ -0,0 +1,56 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// PersonalTokenPrefix starts every personal access token, so leaked tokens are easy to spot
+const PersonalTokenPrefix = "fpat_"
+
+// Scopes a personal access token can be granted. Session logins hold all of them.
+const (
+	ScopeRead          = "read"           // read the user's own data, such as their profile
+	ScopeWritePosts    = "write:posts"    // create, edit and delete posts and react to them
+	ScopeWriteComments = "write:comments" // create, edit and delete comments and react to them
+	ScopeModerate      = "moderate"       // use the moderation queue and sanctions, for moderators
+)
+
+// TokenScopes lists the valid scopes in the order they are shown
+var TokenScopes = []string{ScopeRead, ScopeWritePosts, ScopeWriteComments, ScopeModerate}
+
+// IsValidScope reports whether scope is one of the known scopes
+func IsValidScope(scope string) bool {
+	return slices.Contains(TokenScopes, scope)
+}
+
+// PersonalAccessToken is a token as stored and listed. It never includes the token itself.
+type PersonalAccessToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // first characters of the token, e.g. "fpat_AbC1"
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+}
+
+// HasScope reports whether the token was granted scope
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// CreatePersonalTokenRequest is the body for creating a personal access token
+type CreatePersonalTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// CreatedPersonalToken is returned once, when a token is created
+type CreatedPersonalToken struct {
+	Token string `json:"token"` // shown only now; it cannot be retrieved later
+	PersonalAccessToken
+}
@@ -7,10 +7,14 @@ type Post struct {
 	UserID     string         `json:"user_id"`
 	Username   string         `json:"username"`
 	Categories []PostCategory `json:"categories"`
+	Title      string         `json:"post_title"`
 	Content    string         `json:"post_content"`
 	CreatedAt  time.Time      `json:"created_at"`
 	UpdatedAt  *time.Time     `json:"updated_at,omitempty"`
 
+	// Role of the staff member who last edited the post (empty unless edited by someone other than the author)
+	UpdatedByRole string `json:"updated_by_role,omitempty"`
+
 	// Aggregated metrics
 	LikeCount    int `json:"like_count"`
 	DislikeCount int `json:"dislike_count"`
@@ -21,14 +25,40 @@ type Post struct {
 	IsOwner      bool `json:"is_owner,omitempty"`      // can current user edit/delete
 }
 
+// PostSummary - Lightweight post for list views (no full content)
+type PostSummary struct {
+	ID         string         `json:"post_id"`
+	UserID     string         `json:"user_id"`
+	Username   string         `json:"username"`
+	Categories []PostCategory `json:"categories"`
+	Title      string         `json:"post_title"`
+	Excerpt    string         `json:"excerpt"` // start of the content, "…" appended when cut
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  *time.Time     `json:"updated_at,omitempty"`
+
+	// Latest of created_at, updated_at and the newest comment
+	LastActivityAt time.Time `json:"last_activity_at"`
+
+	// Aggregated metrics
+	LikeCount    int `json:"like_count"`
+	DislikeCount int `json:"dislike_count"`
+	CommentCount int `json:"comment_count"`
+
+	// User context
+	UserReaction *int `json:"user_reaction,omitempty"` // nil, 1=like, 2=dislike
+	IsOwner      bool `json:"is_owner,omitempty"`      // can current user edit/delete
+}
+
 // CreatePostRequest - Post creation payload
 type CreatePostRequest struct {
 	CategoryNames []string `json:"category_names" binding:"required,min=1,max=5"`
+	Title         string   `json:"title" binding:"required,min=5,max=100"`
 	Content       string   `json:"content" binding:"required,min=10,max=5000"`
 }
 
 // UpdatePostRequest - Post update payload
 type UpdatePostRequest struct {
 	CategoryNames []string `json:"category_names" binding:"required,min=1,max=5"`
+	Title         string   `json:"title" binding:"required,min=5,max=100"`
 	Content       string   `json:"content" binding:"required,min=10,max=5000"`
 }
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit is a token bucket holding up to Requests tokens that refill evenly over Window.
+// Each request takes one token, so bursts up to Requests are allowed and the sustained
+// rate is Requests per Window.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// RateLimitResult is the state of a bucket after a request tried to take a token
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // whole tokens left
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token, when the request was refused
+}
+
+// perToken is the time it takes to refill one token
+func (l RateLimit) perToken() time.Duration {
+	return l.Window / time.Duration(l.Requests)
+}
+
+// Take refills a bucket that held tokens when it was last updated elapsed ago and takes
+// one token from it if it can. A new bucket starts full. It returns the tokens left
+// and the outcome.
+func (l RateLimit) Take(tokens float64, elapsed time.Duration) (float64, RateLimitResult) {
+	capacity := float64(l.Requests)
+	perToken := float64(l.perToken())
+
+	if elapsed > 0 {
+		tokens = math.Min(capacity, tokens+float64(elapsed)/perToken)
+	}
+
+	result := RateLimitResult{Limit: l.Requests}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - tokens) * perToken)
+	}
+
+	result.Remaining = int(tokens)
+	result.Reset = time.Duration((capacity - tokens) * perToken)
+	return tokens, result
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitTake(t *testing.T) {
+	// One token refills every second
+	limit := RateLimit{Requests: 10, Window: 10 * time.Second}
+
+	tests := []struct {
+		name       string
+		tokens     float64
+		elapsed    time.Duration
+		wantTokens float64
+		want       RateLimitResult
+	}{
+		{
+			name: "new bucket", tokens: 10,
+			wantTokens: 9,
+			want:       RateLimitResult{Allowed: true, Limit: 10, Remaining: 9, Reset: time.Second},
+		},
+		{
+			name: "empty bucket", tokens: 0,
+			wantTokens: 0,
+			want:       RateLimitResult{Limit: 10, Reset: 10 * time.Second, RetryAfter: time.Second},
+		},
+		{
+			name: "part of a token refilled", tokens: 0, elapsed: 500 * time.Millisecond,
+			wantTokens: 0.5,
+			want:       RateLimitResult{Limit: 10, Reset: 9500 * time.Millisecond, RetryAfter: 500 * time.Millisecond},
+		},
+		{
+			name: "a token refilled", tokens: 0, elapsed: time.Second,
+			wantTokens: 0,
+			want:       RateLimitResult{Allowed: true, Limit: 10, Reset: 10 * time.Second},
+		},
+		{
+			name: "refill stops at capacity", tokens: 5, elapsed: time.Hour,
+			wantTokens: 9,
+			want:       RateLimitResult{Allowed: true, Limit: 10, Remaining: 9, Reset: time.Second},
+		},
+		{
+			name: "clock went backwards", tokens: 3, elapsed: -5 * time.Second,
+			wantTokens: 2,
+			want:       RateLimitResult{Allowed: true, Limit: 10, Remaining: 2, Reset: 8 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, got := limit.Take(tt.tokens, tt.elapsed)
+			if tokens != tt.wantTokens {
+				t.Errorf("tokens left = %v, want %v", tokens, tt.wantTokens)
+			}
+			if got != tt.want {
+				t.Errorf("result = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitBurstThenSustainedRate(t *testing.T) {
+	limit := RateLimit{Requests: 5, Window: time.Minute}
+
+	// A full bucket allows a burst of Requests, then refuses
+	tokens := float64(limit.Requests)
+	for i := 0; i < limit.Requests; i++ {
+		var result RateLimitResult
+		tokens, result = limit.Take(tokens, 0)
+		if !result.Allowed {
+			t.Fatalf("request %d of the burst was refused", i+1)
+		}
+	}
+	tokens, result := limit.Take(tokens, 0)
+	if result.Allowed {
+		t.Fatal("request after the burst was allowed")
+	}
+	if result.RetryAfter != 12*time.Second {
+		t.Errorf("RetryAfter = %v, want 12s", result.RetryAfter)
+	}
+
+	// Afterwards one request per Window/Requests gets through
+	if _, result = limit.Take(tokens, result.RetryAfter); !result.Allowed {
+		t.Error("request after RetryAfter was refused")
+	}
+}
@@ -52,6 +52,23 @@ func IsValidReactionType(reactionType int) bool {
 	return reactionType == ReactionTypeLike || reactionType == ReactionTypeDislike
 }
 
+// ReactionTypeName returns "like" or "dislike"
+func ReactionTypeName(reactionType int) string {
+	if reactionType == ReactionTypeDislike {
+		return "dislike"
+	}
+	return "like"
+}
+
+// Created reports whether the toggle added a reaction where there was none
+func (rr *ReactionResult) Created() bool {
+	switch rr.Action {
+	case ActionPostLikeCreated, ActionPostDislikeCreated, ActionCommentLikeCreated, ActionCommentDislikeCreated:
+		return true
+	}
+	return false
+}
+
 // Helper function to create ReactionResult with appropriate message
 func NewReactionResult(action string) *ReactionResult {
 	messages := map[string]string{
@@ -0,0 +1,117 @@
+package models
+
+import "time"
+
+// What can be reported
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+)
+
+// Why it was reported
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonInappropriate = "inappropriate"
+	ReportReasonOffTopic      = "off_topic"
+	ReportReasonOther         = "other"
+)
+
+// Where a report is in the moderation queue. Open and claimed reports are pending,
+// resolved (action taken) and dismissed (no action needed) reports are closed.
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusClaimed   = "claimed"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+
+	// Queue filters covering several statuses
+	ReportStatusPending = "pending"
+	ReportStatusAll     = "all"
+)
+
+// IsValidReportTarget reports whether targetType can be reported
+func IsValidReportTarget(targetType string) bool {
+	switch targetType {
+	case ReportTargetPost, ReportTargetComment, ReportTargetUser:
+		return true
+	}
+	return false
+}
+
+// IsValidReportReason reports whether reason is one of the known reasons
+func IsValidReportReason(reason string) bool {
+	switch reason {
+	case ReportReasonSpam, ReportReasonHarassment, ReportReasonInappropriate, ReportReasonOffTopic, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+// Report is a user's flag on a post, comment or user, as seen in the moderation queue
+type Report struct {
+	ID               string `json:"report_id"`
+	ReporterID       string `json:"reporter_id,omitempty"`
+	ReporterUsername string `json:"reporter_username,omitempty"`
+
+	// What was reported
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details,omitempty"`
+
+	// Context for moderators - empty once the target has been deleted
+	PostID              string `json:"post_id,omitempty"`        // the post, or the post a comment is on
+	TargetPreview       string `json:"target_preview,omitempty"` // post title, comment content or username
+	TargetAuthorID      string `json:"target_author_id,omitempty"`
+	TargetAuthorName    string `json:"target_author_username,omitempty"`
+	TargetExists        bool   `json:"target_exists"`
+	OpenReportsOnTarget int    `json:"open_reports_on_target"` // pending reports on the same target, this one included
+
+	// Moderation state
+	Status         string     `json:"status"`
+	ClaimedBy      string     `json:"claimed_by,omitempty"`
+	ClaimedByName  string     `json:"claimed_by_username,omitempty"`
+	ClaimedAt      *time.Time `json:"claimed_at,omitempty"`
+	ResolvedBy     string     `json:"resolved_by,omitempty"`
+	ResolvedByName string     `json:"resolved_by_username,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CreateReportRequest - Report creation payload
+type CreateReportRequest struct {
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details"`
+}
+
+// CloseReportRequest - Resolve or dismiss payload
+type CloseReportRequest struct {
+	Note string `json:"note"`
+}
+
+// ReportFilters holds the moderation queue filters
+type ReportFilters struct {
+	Status     string // a status, "pending" (open and claimed) or "all"
+	TargetType string // only reports on this kind of target (optional)
+}
+
+// PaginatedReportsResponse is the response for a page of the moderation queue
+type PaginatedReportsResponse struct {
+	Status     string         `json:"status"`
+	Reports    []*Report      `json:"reports"`
+	Pagination PaginationInfo `json:"pagination"`
+}
+
+// NewPaginatedReportsResponse creates a paginated reports response
+func NewPaginatedReportsResponse(status string, reports []*Report, totalCount, limit, offset int) *PaginatedReportsResponse {
+	return &PaginatedReportsResponse{
+		Status:     status,
+		Reports:    reports,
+		Pagination: NewPaginationInfo(totalCount, limit, offset),
+	}
+}
@@ -23,6 +23,9 @@ type CreatePostResponse struct {
 
 // CreateCommentResponse - Lightweight response for comment creation
 type CreateCommentResponse struct {
-	CommentID string    `json:"comment_id"`
-	CreatedAt time.Time `json:"created_at"`
+	CommentID       string    `json:"comment_id"`
+	PostID          string    `json:"post_id,omitempty"`
+	ParentCommentID string    `json:"parent_comment_id,omitempty"` // set for replies
+	Depth           int       `json:"depth"`
+	CreatedAt       time.Time `json:"created_at"`
 }
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kinds of sanctions. Bans and suspensions lock the account out;
+// a mute leaves it read-only.
+const (
+	SanctionBan        = "ban"
+	SanctionSuspension = "suspension"
+	SanctionMute       = "mute"
+)
+
+// IsValidSanctionType reports whether sanctionType is one of the known sanctions
+func IsValidSanctionType(sanctionType string) bool {
+	switch sanctionType {
+	case SanctionBan, SanctionSuspension, SanctionMute:
+		return true
+	}
+	return false
+}
+
+// Sanction is a ban, suspension or mute on a user account
+type Sanction struct {
+	ID           string     `json:"sanction_id"`
+	UserID       string     `json:"user_id"`
+	Type         string     `json:"type"`
+	Reason       string     `json:"reason"`
+	ExpiresAt    *time.Time `json:"expires_at"` // nil for permanent bans
+	IssuedBy     string     `json:"issued_by,omitempty"`
+	IssuedByName string     `json:"issued_by_username,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LiftedBy     string     `json:"lifted_by,omitempty"`
+	LiftedAt     *time.Time `json:"lifted_at,omitempty"`
+	Active       bool       `json:"active"`
+}
+
+// IsActive reports whether the sanction is in force at the given time
+func (s *Sanction) IsActive(now time.Time) bool {
+	if s.LiftedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
+
+// LocksAccount reports whether the sanction keeps the user from logging in
+func (s *Sanction) LocksAccount() bool {
+	return s.Type == SanctionBan || s.Type == SanctionSuspension
+}
+
+// Message is what the sanctioned user is told, including the reason and when it ends
+func (s *Sanction) Message() string {
+	switch {
+	case s.Type == SanctionBan:
+		return fmt.Sprintf("Your account has been permanently banned. Reason: %s", s.Reason)
+	case s.Type == SanctionSuspension:
+		return fmt.Sprintf("Your account is suspended until %s. Reason: %s", s.ExpiresAt.UTC().Format("Jan 2, 2006 15:04 UTC"), s.Reason)
+	default:
+		return fmt.Sprintf("Your account is muted until %s. Reason: %s", s.ExpiresAt.UTC().Format("Jan 2, 2006 15:04 UTC"), s.Reason)
+	}
+}
+
+// AccountLock returns the sanction keeping the user from logging in, if any:
+// a ban, or else the suspension that ends last
+func AccountLock(sanctions []*Sanction) *Sanction {
+	var lock *Sanction
+	for _, s := range sanctions {
+		if s.LocksAccount() && (lock == nil || endsLater(s, lock)) {
+			lock = s
+		}
+	}
+	return lock
+}
+
+// ActiveMute returns the mute that ends last, if any
+func ActiveMute(sanctions []*Sanction) *Sanction {
+	var mute *Sanction
+	for _, s := range sanctions {
+		if s.Type == SanctionMute && (mute == nil || endsLater(s, mute)) {
+			mute = s
+		}
+	}
+	return mute
+}
+
+// endsLater reports whether a ends after b; permanent sanctions never end
+func endsLater(a, b *Sanction) bool {
+	if a.ExpiresAt == nil {
+		return true
+	}
+	if b.ExpiresAt == nil {
+		return false
+	}
+	return a.ExpiresAt.After(*b.ExpiresAt)
+}
+
+// CreateSanctionRequest - Sanction payload. DurationHours is required for
+// suspensions and mutes and must be left out for bans, which are permanent.
+type CreateSanctionRequest struct {
+	Type          string `json:"type"`
+	Reason        string `json:"reason"`
+	DurationHours int    `json:"duration_hours"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Search result types
+const (
+	SearchTypePosts    = "posts"
+	SearchTypeComments = "comments"
+)
+
+// SearchFilters holds the optional filters applied to a search
+type SearchFilters struct {
+	CategoryID string // only posts in this category (or comments on them)
+	Author     string // only content written by this username
+}
+
+// SearchResult is a single ranked full-text match
+type SearchResult struct {
+	Type       string         `json:"type"` // "post" or "comment"
+	PostID     string         `json:"post_id"`
+	CommentID  string         `json:"comment_id,omitempty"`
+	UserID     string         `json:"user_id"`
+	Username   string         `json:"username"`
+	PostTitle  string         `json:"post_title"` // title of the post (or of the post the comment is on)
+	Snippet    string         `json:"snippet"`    // matched terms wrapped in <mark></mark>
+	Categories []PostCategory `json:"categories"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Rank       float64        `json:"rank"` // bm25 score, lower is more relevant
+}
+
+// PaginatedSearchResponse is the response for paginated search results
+type PaginatedSearchResponse struct {
+	Query      string          `json:"query"`
+	Type       string          `json:"type"`
+	Results    []*SearchResult `json:"results"`
+	Pagination PaginationInfo  `json:"pagination"`
+}
+
+// NewPaginatedSearchResponse creates a paginated search response
+func NewPaginatedSearchResponse(query, searchType string, results []*SearchResult, totalCount, limit, offset int) *PaginatedSearchResponse {
+	return &PaginatedSearchResponse{
+		Query:      query,
+		Type:       searchType,
+		Results:    results,
+		Pagination: NewPaginationInfo(totalCount, limit, offset),
+	}
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Security event types
+const (
+	SecurityEventLoginFailed   = "login_failed"
+	SecurityEventAccountLocked = "account_locked"
+	SecurityEventIPLocked      = "ip_locked"
+
+	// Sessions used from somewhere unexpected, and sessions the API replaced
+	SecurityEventSessionIPRejected   = "session_ip_rejected"
+	SecurityEventSessionIPChanged    = "session_ip_changed"
+	SecurityEventSessionAgentChanged = "session_user_agent_changed"
+	SecurityEventSessionRotated      = "session_rotated"
+
+	// Wrong codes entered to turn two-factor off or replace the recovery codes
+	SecurityEventTwoFactorFailed = "two_factor_failed"
+	SecurityEventTwoFactorLocked = "two_factor_locked"
+)
+
+// SecurityEvent is one entry of the security event log
+type SecurityEvent struct {
+	ID        string    `json:"event_id"`
+	UserID    string    `json:"user_id,omitempty"` // empty when the email has no account
+	Type      string    `json:"event_type"`
+	Email     string    `json:"email,omitempty"`
+	IP        string    `json:"ip_address,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginAttempt describes a password login for brute-force tracking
+type LoginAttempt struct {
+	Email     string // lowercased
+	UserID    string // empty when no account has the email
+	IP        string
+	SharedIP  bool // IP is a proxy's, shared by everyone behind it, so it is never locked out
+	UserAgent string
+}
+
+// Event describes the attempt as a security event of eventType
+func (a LoginAttempt) Event(eventType, details string) SecurityEvent {
+	return SecurityEvent{
+		UserID:    a.UserID,
+		Type:      eventType,
+		Email:     a.Email,
+		IP:        a.IP,
+		UserAgent: a.UserAgent,
+		Details:   details,
+	}
+}
+
+// TwoFactorAttempt describes a two-factor settings change checked with a code
+type TwoFactorAttempt struct {
+	UserID    string
+	Action    string // what the code was entered for, e.g. "disable"
+	IP        string
+	UserAgent string
+}
+
+// Event describes the attempt as a security event of eventType
+func (a TwoFactorAttempt) Event(eventType, details string) SecurityEvent {
+	return SecurityEvent{
+		UserID:    a.UserID,
+		Type:      eventType,
+		IP:        a.IP,
+		UserAgent: a.UserAgent,
+		Details:   details,
+	}
+}
+
+// LoginProtection holds the brute-force limits for password logins
+type LoginProtection struct {
+	FailureWindow      time.Duration // failures older than this are forgotten
+	BackoffAfter       int           // failures allowed before attempts must wait
+	BackoffBase        time.Duration // wait after the first failure past BackoffAfter, doubling with each one
+	BackoffMax         time.Duration
+	LockoutThreshold   int // failures for one email that lock it
+	IPLockoutThreshold int // failures from one IP address that lock it out of all logins
+	LockoutDuration    time.Duration
+}
+
+// Backoff returns how long to wait after the last of failures
+func (p LoginProtection) Backoff(failures int) time.Duration {
+	if failures < p.BackoffAfter {
+		return 0
+	}
+	wait := float64(p.BackoffBase) * math.Pow(2, float64(failures-p.BackoffAfter))
+	if wait > float64(p.BackoffMax) {
+		return p.BackoffMax
+	}
+	return time.Duration(wait)
+}
+
+// Reasons a login attempt is refused before the password is checked
+const (
+	LoginThrottleBackoff = "backoff"
+	LoginThrottleAccount = "account_locked"
+	LoginThrottleIP      = "ip_locked"
+)
+
+// LoginThrottle tells why and for how long login attempts are refused
+type LoginThrottle struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// Message is what the client is told
+func (t *LoginThrottle) Message() string {
+	switch t.Reason {
+	case LoginThrottleAccount:
+		return fmt.Sprintf("Too many failed login attempts. This account is locked for %s.", describeWait(t.RetryAfter))
+	case LoginThrottleIP:
+		return fmt.Sprintf("Too many failed login attempts from your network. Try again in %s.", describeWait(t.RetryAfter))
+	default:
+		return fmt.Sprintf("Too many failed login attempts. Please wait %s before trying again.", describeWait(t.RetryAfter))
+	}
+}
+
+// describeWait formats a wait for a message, rounded up to seconds or minutes
+func describeWait(d time.Duration) string {
+	if d <= time.Minute {
+		seconds := int(math.Ceil(d.Seconds()))
+		if seconds == 1 {
+			return "1 second"
+		}
+		return fmt.Sprintf("%d seconds", seconds)
+	}
+	return fmt.Sprintf("%d minutes", int(math.Ceil(d.Minutes())))
+}
@@ -4,12 +4,27 @@ import "time"
 
 // // Session represents a user session
 type Session struct {
-	UserID    string    `json:"user_id"`
-	SessionID string    `json:"session_id"`
-	IPAddress string    `json:"ip_address"`
-	CreatedAt time.Time `json:"created_at"`
-	ExpiresAt time.Time `json:"expires_at"`
+	UserID        string    `json:"user_id"`
+	SessionID     string    `json:"session_id"`
+	DeviceID      string    `json:"device_id"`  // public ID for listing and revoking, unlike the secret SessionID
+	IPAddress     string    `json:"ip_address"` // address and user agent at login
+	UserAgent     string    `json:"user_agent"`
+	LastIPAddress string    `json:"last_ip_address"` // address and user agent it was last used with
+	LastUserAgent string    `json:"last_user_agent"`
+	Role          string    `json:"role"` // user's role when the session was issued
+	CreatedAt     time.Time `json:"created_at"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
 }
 
-
-
+// DeviceSession is a session as shown in the user's device list. It never includes the session ID.
+type DeviceSession struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"` // short description such as "Firefox on Linux"
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // the session making the request
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// RecoveryCodeCount is how many one-time recovery codes are issued at a time
+const RecoveryCodeCount = 10
+
+// TwoFactorStatus tells the user whether two-factor login is on
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetupResponse carries a new authenticator secret, shown once during enrollment
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"` // encode as a QR code for authenticator apps
+}
+
+// RecoveryCodesResponse carries newly issued recovery codes, shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorCodeRequest carries a code from the authenticator app (or a recovery code where allowed)
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorDisableRequest turns two-factor login off; both factors are required
+type TwoFactorDisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// TwoFactorChallengeResponse is returned by login instead of a session when a code is still needed
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	TwoFactorToken    string    `json:"two_factor_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// TwoFactorLoginRequest completes a login with the token from the first step and a code
+type TwoFactorLoginRequest struct {
+	Token string `json:"two_factor_token"`
+	Code  string `json:"code"`
+}
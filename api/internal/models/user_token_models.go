@@ -0,0 +1,24 @@
+package models
+
+// Purposes of the single-use tokens sent by email
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+)
+
+// ForgotPasswordRequest asks for a password reset link to be emailed
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest sets a new password using the token from a reset link
+type ResetPasswordRequest struct {
+	Token           string `json:"token"`
+	Password        string `json:"password"`
+	ConfirmPassword string `json:"confirm_password"`
+}
+
+// VerifyEmailRequest confirms an email address using the token from a verification link
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
@@ -7,12 +7,79 @@ type User struct {
 	ID            string    `json:"id"`
 	Username      string    `json:"username"`
 	Email         string    `json:"email"`
-	Provider      string    `json:"provider,omitempty"`      // oauth provider (google, github, etc.)
-	ProviderID    string    `json:"provider_id,omitempty"`   // user id from oauth provider
+	Provider      string    `json:"provider,omitempty"`       // oauth provider (google, github, etc.)
+	ProviderID    string    `json:"provider_id,omitempty"`    // user id from oauth provider
 	ProviderEmail string    `json:"provider_email,omitempty"` // email from oauth provider
+	Role          string    `json:"role"`                     // member, moderator or admin
+	EmailVerified bool      `json:"email_verified"`           // set once the user follows the verification link
+	Mute          *Sanction `json:"mute,omitempty"`           // active mute, set by the auth middleware
 	CreatedAt     time.Time `json:"created_at"`
 }
 
+// IsMuted reports whether the user is currently read-only
+func (u *User) IsMuted() bool {
+	return u.Mute != nil
+}
+
+// Roles, from least to most privileged
+const (
+	RoleMember    = "member"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+var roleRank = map[string]int{
+	RoleMember:    0,
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// HasRole reports whether the user's role is at least the given role
+func (u *User) HasRole(role string) bool {
+	return HasRole(u.Role, role)
+}
+
+// HasRole reports whether have is at least as privileged as want
+func HasRole(have, want string) bool {
+	haveRank, ok := roleRank[have]
+	if !ok {
+		return false
+	}
+	return haveRank >= roleRank[want]
+}
+
+// Actor is the user performing a write, passed to repositories for permission checks
+// and recorded in the audit log
+type Actor struct {
+	UserID string
+	Role   string
+	IP     string // client IP of the request, empty outside HTTP requests
+}
+
+// SystemActor is used for changes made from the command line rather than by a logged-in user
+var SystemActor = Actor{Role: "system"}
+
+// ActorFromUser builds an Actor from the authenticated user
+func ActorFromUser(u *User) Actor {
+	return Actor{UserID: u.ID, Role: u.Role}
+}
+
+// CanModerate reports whether the actor may edit or remove other users' content
+func (a Actor) CanModerate() bool {
+	return HasRole(a.Role, RoleModerator)
+}
+
+// UpdateUserRoleRequest is the body for changing a user's role
+type UpdateUserRoleRequest struct {
+	Role string `json:"role"`
+}
+
 // UserAuth represents internal user data including authentication
 type UserPassword struct {
 	UserID       string `json:"user_id"`
@@ -0,0 +1,33 @@
+package notifier
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/mailer"
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+// EmailNotifier emails the account owner
+type EmailNotifier struct {
+	m mailer.Mailer
+}
+
+// NewEmailNotifier creates a notifier that sends through m
+func NewEmailNotifier(m mailer.Mailer) *EmailNotifier {
+	return &EmailNotifier{m: m}
+}
+
+// AccountLocked emails the user with a link to reset their password
+func (n *EmailNotifier) AccountLocked(user *models.User, ip string, lockedFor time.Duration) error {
+	minutes := int(math.Ceil(lockedFor.Minutes()))
+	lockedForText := fmt.Sprintf("%d minutes", minutes)
+	if minutes == 1 {
+		lockedForText = "1 minute"
+	}
+
+	return n.m.Send(mailer.AccountLockedMessage(user.Email, user.Username, ip, lockedForText,
+		config.Config.FrontendURL+"/forgot-password"))
+}
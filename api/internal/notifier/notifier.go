@@ -0,0 +1,36 @@
+package notifier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/mailer"
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+// Notifier tells account owners about security events on their account.
+// Implementations must be safe for concurrent use.
+type Notifier interface {
+	// AccountLocked is called once when failed logins lock the user's account
+	AccountLocked(user *models.User, ip string, lockedFor time.Duration) error
+}
+
+// New returns the notifier selected by the SECURITY_NOTIFIER setting: "email" (the
+// default) to mail the user through m, or "none" to only record the event
+func New(m mailer.Mailer) (Notifier, error) {
+	switch config.Config.SecurityNotifier {
+	case "email", "":
+		return NewEmailNotifier(m), nil
+	case "none":
+		return NopNotifier{}, nil
+	default:
+		return nil, fmt.Errorf("unknown SECURITY_NOTIFIER %q, expected email or none", config.Config.SecurityNotifier)
+	}
+}
+
+// NopNotifier tells nobody
+type NopNotifier struct{}
+
+// AccountLocked does nothing
+func (NopNotifier) AccountLocked(*models.User, string, time.Duration) error { return nil }
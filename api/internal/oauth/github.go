@@ -0,0 +1,84 @@
+package oauth
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+const (
+	githubAuthURL  = "https://github.com/login/oauth/authorize"
+	githubTokenURL = "https://github.com/login/oauth/access_token"
+	githubAPIURL   = "https://api.github.com"
+)
+
+// GitHubProvider logs users in with their GitHub account
+type GitHubProvider struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+}
+
+// NewGitHubProvider creates a GitHub provider
+func NewGitHubProvider(clientID, clientSecret, redirectURL string) *GitHubProvider {
+	return &GitHubProvider{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+	}
+}
+
+func (p *GitHubProvider) Name() string        { return "github" }
+func (p *GitHubProvider) DisplayName() string { return "GitHub" }
+
+// AuthURL generates the GitHub authorization URL
+func (p *GitHubProvider) AuthURL(state, codeChallenge string) (string, error) {
+	params := url.Values{}
+	params.Set("client_id", p.clientID)
+	params.Set("redirect_uri", p.redirectURL)
+	params.Set("scope", "read:user user:email")
+	params.Set("state", state)
+	setPKCE(params, codeChallenge)
+	params.Set("allow_signup", "true")
+
+	return githubAuthURL + "?" + params.Encode(), nil
+}
+
+// Exchange exchanges the authorization code for a token and fetches the user's GitHub profile.
+// The public profile email is unverified, so the primary verified address is used when there is one.
+func (p *GitHubProvider) Exchange(code, codeVerifier string) (*models.OAuthProfile, error) {
+	tokenResp, err := exchangeCode(githubTokenURL, p.clientID, p.clientSecret, p.redirectURL, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.GitHubUserInfo
+	if err := getJSON(githubAPIURL+"/user", tokenResp.AccessToken, &user); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %v", err)
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("github user info has no id")
+	}
+
+	profile := &models.OAuthProfile{
+		Provider:   p.Name(),
+		ProviderID: strconv.FormatInt(user.ID, 10),
+		Email:      user.Email,
+		Username:   user.Login,
+	}
+
+	var emails []models.GitHubEmail
+	if err := getJSON(githubAPIURL+"/user/emails", tokenResp.AccessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to get user emails: %v", err)
+	}
+	for _, e := range emails {
+		if e.Verified && (e.Primary || !profile.EmailVerified) {
+			profile.Email = e.Email
+			profile.EmailVerified = true
+		}
+	}
+
+	return profile, nil
+}
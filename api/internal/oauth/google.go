@@ -0,0 +1,72 @@
+package oauth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+const (
+	googleAuthURL  = "https://accounts.google.com/o/oauth2/auth"
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+	googleUserURL  = "https://www.googleapis.com/oauth2/v2/userinfo"
+)
+
+// GoogleProvider logs users in with their Google account
+type GoogleProvider struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+}
+
+// NewGoogleProvider creates a Google provider
+func NewGoogleProvider(clientID, clientSecret, redirectURL string) *GoogleProvider {
+	return &GoogleProvider{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+	}
+}
+
+func (p *GoogleProvider) Name() string        { return "google" }
+func (p *GoogleProvider) DisplayName() string { return "Google" }
+
+// AuthURL generates the Google OAuth authorization URL
+func (p *GoogleProvider) AuthURL(state, codeChallenge string) (string, error) {
+	params := url.Values{}
+	params.Set("client_id", p.clientID)
+	params.Set("redirect_uri", p.redirectURL)
+	params.Set("scope", "openid email profile")
+	params.Set("response_type", "code")
+	params.Set("state", state)
+	setPKCE(params, codeChallenge)
+	params.Set("access_type", "offline")
+	params.Set("prompt", "consent")
+
+	return googleAuthURL + "?" + params.Encode(), nil
+}
+
+// Exchange exchanges the authorization code for a token and fetches the user's Google profile
+func (p *GoogleProvider) Exchange(code, codeVerifier string) (*models.OAuthProfile, error) {
+	tokenResp, err := exchangeCode(googleTokenURL, p.clientID, p.clientSecret, p.redirectURL, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var userInfo models.GoogleUserInfo
+	if err := getJSON(googleUserURL, tokenResp.AccessToken, &userInfo); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %v", err)
+	}
+	if userInfo.ID == "" {
+		return nil, fmt.Errorf("google user info has no id")
+	}
+
+	return &models.OAuthProfile{
+		Provider:      p.Name(),
+		ProviderID:    userInfo.ID,
+		Email:         strings.TrimSpace(userInfo.Email),
+		EmailVerified: userInfo.VerifiedEmail,
+	}, nil
+}
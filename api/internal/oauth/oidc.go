@@ -0,0 +1,118 @@
+package oauth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+// OIDCProvider logs users in with any OpenID Connect provider. Its endpoints come from
+// the issuer's discovery document, fetched on first use so the API can start while
+// the provider is unreachable.
+type OIDCProvider struct {
+	name         string
+	displayName  string
+	issuerURL    string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       string
+
+	mu        sync.Mutex
+	discovery *models.OIDCDiscovery
+}
+
+// NewOIDCProvider creates a provider for the OpenID Connect issuer at issuerURL
+func NewOIDCProvider(name, displayName, issuerURL, clientID, clientSecret, redirectURL, scopes string) *OIDCProvider {
+	return &OIDCProvider{
+		name:         name,
+		displayName:  displayName,
+		issuerURL:    strings.TrimRight(issuerURL, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+	}
+}
+
+func (p *OIDCProvider) Name() string        { return p.name }
+func (p *OIDCProvider) DisplayName() string { return p.displayName }
+
+// AuthURL generates the authorization URL from the discovered endpoint
+func (p *OIDCProvider) AuthURL(state, codeChallenge string) (string, error) {
+	d, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("client_id", p.clientID)
+	params.Set("redirect_uri", p.redirectURL)
+	params.Set("scope", p.scopes)
+	params.Set("response_type", "code")
+	params.Set("state", state)
+	setPKCE(params, codeChallenge)
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// Exchange exchanges the authorization code for a token and reads the userinfo claims
+func (p *OIDCProvider) Exchange(code, codeVerifier string) (*models.OAuthProfile, error) {
+	d, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	tokenResp, err := exchangeCode(d.TokenEndpoint, p.clientID, p.clientSecret, p.redirectURL, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims models.OIDCUserInfo
+	if err := getJSON(d.UserinfoEndpoint, tokenResp.AccessToken, &claims); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %v", err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("userinfo response has no sub claim")
+	}
+
+	return &models.OAuthProfile{
+		Provider:      p.name,
+		ProviderID:    claims.Subject,
+		Email:         strings.TrimSpace(claims.Email),
+		EmailVerified: claims.EmailVerified,
+		Username:      claims.PreferredUsername,
+	}, nil
+}
+
+// discover loads and caches the discovery document. Failures are not cached,
+// so a provider that was down at first use is retried on the next login.
+func (p *OIDCProvider) discover() (*models.OIDCDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var d models.OIDCDiscovery
+	if err := getJSON(p.issuerURL+"/.well-known/openid-configuration", "", &d); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %v", err)
+	}
+	if strings.TrimRight(d.Issuer, "/") != p.issuerURL {
+		return nil, fmt.Errorf("OIDC discovery failed: issuer %q does not match %q", d.Issuer, p.issuerURL)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.UserinfoEndpoint == "" {
+		return nil, fmt.Errorf("OIDC discovery failed: document is missing an endpoint")
+	}
+
+	p.discovery = &d
+	return p.discovery, nil
+}
@@ -0,0 +1,354 @@
+package oauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// stubProvider is an authorization server that grants one access token for the stub
+// code and verifier, and answers profile requests made with it
+type stubProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	mux    *http.ServeMux
+}
+
+const (
+	stubCode        = "auth-code"
+	stubVerifier    = "code-verifier"
+	stubAccessToken = "access-token"
+)
+
+func newStubProvider(t *testing.T, tokenPath string) *stubProvider {
+	t.Helper()
+	s := &stubProvider{t: t, mux: http.NewServeMux()}
+	s.mux.HandleFunc(tokenPath, s.token)
+	s.server = httptest.NewServer(s.mux)
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	want := map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     "client-id",
+		"client_secret": "client-secret",
+		"redirect_uri":  "https://forum.example/callback",
+	}
+	for key, value := range want {
+		if got := r.PostForm.Get(key); got != value {
+			s.t.Errorf("token request %s = %q, want %q", key, got, value)
+		}
+	}
+	if r.PostForm.Get("code") != stubCode || r.PostForm.Get("code_verifier") != stubVerifier {
+		// GitHub answers a bad code with 200 and an error instead of a token
+		writeJSON(w, map[string]string{"error": "bad_verification_code"})
+		return
+	}
+	writeJSON(w, map[string]string{"access_token": stubAccessToken, "token_type": "bearer"})
+}
+
+// handleProfile serves v at path to requests carrying the stub access token
+func (s *stubProvider) handleProfile(path string, v interface{}) {
+	s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+stubAccessToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, v)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// redirectTransport sends every request to the stub server, keeping its path, so the
+// providers with fixed endpoints can be tested
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// routeTo points the shared HTTP client at the stub server for the rest of the test
+func routeTo(t *testing.T, s *stubProvider) {
+	t.Helper()
+	target, err := url.Parse(s.server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := httpClient.Transport
+	httpClient.Transport = redirectTransport{target: target}
+	t.Cleanup(func() { httpClient.Transport = previous })
+}
+
+// checkAuthURL parses an authorization URL and checks the parameters every flow needs
+func checkAuthURL(t *testing.T, authURL, wantPrefix string) url.Values {
+	t.Helper()
+	if !strings.HasPrefix(authURL, wantPrefix) {
+		t.Fatalf("AuthURL = %q, want prefix %q", authURL, wantPrefix)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("AuthURL is not a URL: %v", err)
+	}
+	params := parsed.Query()
+	want := map[string]string{
+		"client_id":             "client-id",
+		"redirect_uri":          "https://forum.example/callback",
+		"state":                 "the-state",
+		"code_challenge":        "the-challenge",
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if got := params.Get(key); got != value {
+			t.Errorf("AuthURL %s = %q, want %q", key, got, value)
+		}
+	}
+	return params
+}
+
+func TestGoogleAuthURL(t *testing.T) {
+	p := NewGoogleProvider("client-id", "client-secret", "https://forum.example/callback")
+	authURL, err := p.AuthURL("the-state", "the-challenge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := checkAuthURL(t, authURL, googleAuthURL+"?")
+	if params.Get("response_type") != "code" {
+		t.Errorf("response_type = %q, want code", params.Get("response_type"))
+	}
+	if params.Get("scope") != "openid email profile" {
+		t.Errorf("scope = %q", params.Get("scope"))
+	}
+}
+
+func TestGoogleExchange(t *testing.T) {
+	s := newStubProvider(t, "/token")
+	s.handleProfile("/oauth2/v2/userinfo", map[string]interface{}{
+		"id":             "1234",
+		"email":          " ada@example.com ",
+		"verified_email": true,
+	})
+	routeTo(t, s)
+
+	p := NewGoogleProvider("client-id", "client-secret", "https://forum.example/callback")
+	profile, err := p.Exchange(stubCode, stubVerifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if profile.Provider != "google" || profile.ProviderID != "1234" {
+		t.Errorf("profile = %+v, want google 1234", profile)
+	}
+	if profile.Email != "ada@example.com" || !profile.EmailVerified {
+		t.Errorf("email = %q verified %v, want trimmed verified address", profile.Email, profile.EmailVerified)
+	}
+}
+
+func TestGoogleExchangeRequiresID(t *testing.T) {
+	s := newStubProvider(t, "/token")
+	s.handleProfile("/oauth2/v2/userinfo", map[string]interface{}{"email": "ada@example.com"})
+	routeTo(t, s)
+
+	p := NewGoogleProvider("client-id", "client-secret", "https://forum.example/callback")
+	if _, err := p.Exchange(stubCode, stubVerifier); err == nil {
+		t.Fatal("Exchange accepted a profile without an id")
+	}
+}
+
+func TestGitHubAuthURL(t *testing.T) {
+	p := NewGitHubProvider("client-id", "client-secret", "https://forum.example/callback")
+	authURL, err := p.AuthURL("the-state", "the-challenge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := checkAuthURL(t, authURL, githubAuthURL+"?")
+	if params.Get("scope") != "read:user user:email" {
+		t.Errorf("scope = %q", params.Get("scope"))
+	}
+}
+
+func TestGitHubExchange(t *testing.T) {
+	tests := []struct {
+		name         string
+		publicEmail  string
+		emails       []map[string]interface{}
+		wantEmail    string
+		wantVerified bool
+	}{
+		{
+			name:        "primary verified address wins",
+			publicEmail: "public@example.com",
+			emails: []map[string]interface{}{
+				{"email": "other@example.com", "primary": false, "verified": true},
+				{"email": "primary@example.com", "primary": true, "verified": true},
+			},
+			wantEmail:    "primary@example.com",
+			wantVerified: true,
+		},
+		{
+			name:        "any verified address over the public one",
+			publicEmail: "public@example.com",
+			emails: []map[string]interface{}{
+				{"email": "primary@example.com", "primary": true, "verified": false},
+				{"email": "other@example.com", "primary": false, "verified": true},
+			},
+			wantEmail:    "other@example.com",
+			wantVerified: true,
+		},
+		{
+			name:         "unverified public address",
+			publicEmail:  "public@example.com",
+			emails:       []map[string]interface{}{},
+			wantEmail:    "public@example.com",
+			wantVerified: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStubProvider(t, "/login/oauth/access_token")
+			s.handleProfile("/user", map[string]interface{}{"id": 42, "login": "ada", "email": tt.publicEmail})
+			s.handleProfile("/user/emails", tt.emails)
+			routeTo(t, s)
+
+			p := NewGitHubProvider("client-id", "client-secret", "https://forum.example/callback")
+			profile, err := p.Exchange(stubCode, stubVerifier)
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if profile.ProviderID != "42" || profile.Username != "ada" {
+				t.Errorf("profile = %+v, want id 42 login ada", profile)
+			}
+			if profile.Email != tt.wantEmail || profile.EmailVerified != tt.wantVerified {
+				t.Errorf("email = %q verified %v, want %q verified %v", profile.Email, profile.EmailVerified, tt.wantEmail, tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestGitHubExchangeRejectsWrongVerifier(t *testing.T) {
+	s := newStubProvider(t, "/login/oauth/access_token")
+	routeTo(t, s)
+
+	p := NewGitHubProvider("client-id", "client-secret", "https://forum.example/callback")
+	if _, err := p.Exchange(stubCode, "another-verifier"); err == nil {
+		t.Fatal("Exchange succeeded without an access token")
+	}
+}
+
+// newStubOIDC serves a discovery document for the stub server as issuer
+func newStubOIDC(t *testing.T) *stubProvider {
+	t.Helper()
+	s := newStubProvider(t, "/token")
+	s.mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 s.server.URL + "/",
+			"authorization_endpoint": s.server.URL + "/authorize?tenant=forum",
+			"token_endpoint":         s.server.URL + "/token",
+			"userinfo_endpoint":      s.server.URL + "/userinfo",
+		})
+	})
+	s.handleProfile("/userinfo", map[string]interface{}{
+		"sub":                "subject-1",
+		"email":              "ada@example.com",
+		"email_verified":     true,
+		"preferred_username": "ada",
+	})
+	return s
+}
+
+func TestOIDCAuthURL(t *testing.T) {
+	s := newStubOIDC(t)
+	p := NewOIDCProvider("corp", "Corp", s.server.URL, "client-id", "client-secret", "https://forum.example/callback", "openid email")
+
+	authURL, err := p.AuthURL("the-state", "the-challenge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := checkAuthURL(t, authURL, s.server.URL+"/authorize?")
+	if params.Get("tenant") != "forum" {
+		t.Errorf("AuthURL dropped the endpoint's own query: %q", authURL)
+	}
+	if params.Get("scope") != "openid email" {
+		t.Errorf("scope = %q", params.Get("scope"))
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	s := newStubOIDC(t)
+	p := NewOIDCProvider("corp", "Corp", s.server.URL+"/", "client-id", "client-secret", "https://forum.example/callback", "openid email")
+
+	profile, err := p.Exchange(stubCode, stubVerifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if profile.Provider != "corp" || profile.ProviderID != "subject-1" || profile.Username != "ada" {
+		t.Errorf("profile = %+v, want corp subject-1 ada", profile)
+	}
+	if profile.Email != "ada@example.com" || !profile.EmailVerified {
+		t.Errorf("email = %q verified %v, want verified ada@example.com", profile.Email, profile.EmailVerified)
+	}
+}
+
+func TestOIDCDiscoveryRejectsOtherIssuer(t *testing.T) {
+	s := newStubProvider(t, "/token")
+	s.mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 "https://attacker.example",
+			"authorization_endpoint": "https://attacker.example/authorize",
+			"token_endpoint":         "https://attacker.example/token",
+			"userinfo_endpoint":      "https://attacker.example/userinfo",
+		})
+	})
+	p := NewOIDCProvider("corp", "Corp", s.server.URL, "client-id", "client-secret", "https://forum.example/callback", "openid")
+
+	if _, err := p.AuthURL("the-state", "the-challenge"); err == nil {
+		t.Fatal("AuthURL trusted a discovery document for another issuer")
+	}
+}
+
+func TestOIDCDiscoveryRetriesAfterFailure(t *testing.T) {
+	s := newStubOIDC(t)
+	down := true
+	s.mux.HandleFunc("/down/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, map[string]string{
+			"issuer":                 s.server.URL + "/down",
+			"authorization_endpoint": s.server.URL + "/authorize",
+			"token_endpoint":         s.server.URL + "/token",
+			"userinfo_endpoint":      s.server.URL + "/userinfo",
+		})
+	})
+	p := NewOIDCProvider("corp", "Corp", s.server.URL+"/down", "client-id", "client-secret", "https://forum.example/callback", "openid")
+
+	if _, err := p.AuthURL("the-state", "the-challenge"); err == nil {
+		t.Fatal("AuthURL succeeded while discovery was failing")
+	}
+	down = false
+	if _, err := p.AuthURL("the-state", "the-challenge"); err != nil {
+		t.Fatalf("AuthURL after the issuer recovered: %v", err)
+	}
+}
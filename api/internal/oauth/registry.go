@@ -0,0 +1,87 @@
+package oauth
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+// providerNamePattern keeps provider names safe to use in URL paths
+var providerNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+// Registry holds the enabled providers by name, in the order they are offered
+type Registry struct {
+	providers map[string]Provider
+	order     []string
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// Register adds a provider. Names must be unique and URL-safe.
+func (r *Registry) Register(p Provider) error {
+	name := p.Name()
+	if !providerNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid OAuth provider name %q", name)
+	}
+	if _, exists := r.providers[name]; exists {
+		return fmt.Errorf("OAuth provider %q is registered twice", name)
+	}
+
+	r.providers[name] = p
+	r.order = append(r.order, name)
+	return nil
+}
+
+// Get returns the provider with the given name
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Providers lists the enabled providers for display
+func (r *Registry) Providers() []models.OAuthProviderInfo {
+	providers := make([]models.OAuthProviderInfo, 0, len(r.order))
+	for _, name := range r.order {
+		providers = append(providers, models.OAuthProviderInfo{
+			Name:        name,
+			DisplayName: r.providers[name].DisplayName(),
+		})
+	}
+	return providers
+}
+
+// NewRegistryFromConfig registers every provider that has a client ID configured
+func NewRegistryFromConfig() (*Registry, error) {
+	r := NewRegistry()
+	cfg := config.Config
+
+	if cfg.GoogleOAuthClientID != "" {
+		if err := r.Register(NewGoogleProvider(cfg.GoogleOAuthClientID, cfg.GoogleOAuthClientSecret, cfg.GoogleOAuthRedirectURL)); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.GitHubOAuthClientID != "" {
+		if err := r.Register(NewGitHubProvider(cfg.GitHubOAuthClientID, cfg.GitHubOAuthClientSecret, cfg.GitHubOAuthRedirectURL)); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.OIDCClientID != "" {
+		if cfg.OIDCIssuerURL == "" {
+			return nil, fmt.Errorf("OIDC_CLIENT_ID is set but OIDC_ISSUER_URL is empty")
+		}
+		p := NewOIDCProvider(cfg.OIDCProviderName, cfg.OIDCDisplayName, cfg.OIDCIssuerURL,
+			cfg.OIDCClientID, cfg.OIDCClientSecret, cfg.OIDCRedirectURL, cfg.OIDCScopes)
+		if err := r.Register(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
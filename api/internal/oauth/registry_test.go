@@ -0,0 +1,150 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/PaulKerasidis/forum/config"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"github", false},
+		{"corp-sso", false},
+		{"my_idp2", false},
+		{"", true},
+		{"x", true},
+		{"Corp", true},
+		{"2fa", true},
+		{"corp/../admin", true},
+		{"a-name-that-is-far-too-long-to-use", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			err := r.Register(NewOIDCProvider(tt.name, "Display", "https://idp.example", "id", "secret", "https://forum.example/callback", "openid"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Register(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if _, ok := r.Get(tt.name); ok == tt.wantErr {
+				t.Errorf("Get(%q) found = %v after Register error %v", tt.name, ok, err)
+			}
+		})
+	}
+}
+
+func TestRegistryRejectsDuplicates(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(NewGitHubProvider("id", "secret", "https://forum.example/callback")); err != nil {
+		t.Fatal(err)
+	}
+	// An OIDC provider may not take over a built-in provider's routes
+	err := r.Register(NewOIDCProvider("github", "Fake GitHub", "https://idp.example", "id", "secret", "https://forum.example/callback", "openid"))
+	if err == nil {
+		t.Fatal("Register accepted a second provider named github")
+	}
+	if p, _ := r.Get("github"); p.DisplayName() != "GitHub" {
+		t.Errorf("duplicate registration replaced the provider with %q", p.DisplayName())
+	}
+}
+
+func TestRegistryProvidersKeepsOrder(t *testing.T) {
+	r := NewRegistry()
+	for _, p := range []Provider{
+		NewGoogleProvider("id", "secret", "https://forum.example/callback"),
+		NewOIDCProvider("corp", "Corp SSO", "https://idp.example", "id", "secret", "https://forum.example/callback", "openid"),
+		NewGitHubProvider("id", "secret", "https://forum.example/callback"),
+	} {
+		if err := r.Register(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := r.Providers()
+	want := []struct{ name, display string }{{"google", "Google"}, {"corp", "Corp SSO"}, {"github", "GitHub"}}
+	if len(got) != len(want) {
+		t.Fatalf("Providers() = %v, want %d providers", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Name != w.name || got[i].DisplayName != w.display {
+			t.Errorf("Providers()[%d] = %+v, want %s %s", i, got[i], w.name, w.display)
+		}
+	}
+	if _, ok := r.Get("twitter"); ok {
+		t.Error("Get found a provider that was never registered")
+	}
+}
+
+func TestNewRegistryFromConfig(t *testing.T) {
+	saved := config.Config
+	t.Cleanup(func() { config.Config = saved })
+
+	tests := []struct {
+		name      string
+		configure func(*config.AppConfig)
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "nothing configured",
+			configure: func(c *config.AppConfig) {},
+		},
+		{
+			name: "every provider",
+			configure: func(c *config.AppConfig) {
+				c.GoogleOAuthClientID = "google-id"
+				c.GitHubOAuthClientID = "github-id"
+				c.OIDCClientID = "oidc-id"
+				c.OIDCIssuerURL = "https://idp.example"
+				c.OIDCProviderName = "corp"
+				c.OIDCDisplayName = "Corp"
+			},
+			want: []string{"google", "github", "corp"},
+		},
+		{
+			name: "OIDC without an issuer",
+			configure: func(c *config.AppConfig) {
+				c.OIDCClientID = "oidc-id"
+				c.OIDCProviderName = "corp"
+			},
+			wantErr: true,
+		},
+		{
+			name: "OIDC named like a built-in provider",
+			configure: func(c *config.AppConfig) {
+				c.GitHubOAuthClientID = "github-id"
+				c.OIDCClientID = "oidc-id"
+				c.OIDCIssuerURL = "https://idp.example"
+				c.OIDCProviderName = "github"
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Config = config.AppConfig{}
+			tt.configure(&config.Config)
+
+			r, err := NewRegistryFromConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRegistryFromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := r.Providers()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Providers() = %v, want %v", got, tt.want)
+			}
+			for i, name := range tt.want {
+				if got[i].Name != name {
+					t.Errorf("Providers()[%d] = %q, want %q", i, got[i].Name, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// writeAudit appends an audit log entry inside the transaction making the change,
+// so the action and its record are committed or rolled back together.
+// before and after are stored as JSON; pass nil when there is no snapshot.
+func writeAudit(tx *sql.Tx, actor models.Actor, action, targetType, targetID string, before, after interface{}) error {
+	beforeJSON, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO audit_log (audit_id, actor_id, actor_role, action, target_type, target_id, before_json, after_json, ip_address, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		utils.GenerateUUIDToken(), actor.UserID, actor.Role, action, targetType, targetID, beforeJSON, afterJSON, actor.IP, time.Now())
+	return err
+}
+
+func auditSnapshot(snapshot interface{}) (sql.NullString, error) {
+	if snapshot == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// GetAuditEntries returns a page of the audit log, newest first
+func (ar *AuditRepository) GetAuditEntries(filters models.AuditFilters, limit, offset int) ([]*models.AuditEntry, error) {
+	whereClause, args := buildAuditFilters(filters)
+
+	args = append(args, limit, offset)
+	rows, err := ar.db.Query(`
+		SELECT
+			a.audit_id,
+			a.actor_id,
+			COALESCE(u.username, ''),
+			a.actor_role,
+			a.action,
+			a.target_type,
+			a.target_id,
+			a.before_json,
+			a.after_json,
+			a.ip_address,
+			a.created_at
+		FROM audit_log a
+		LEFT JOIN users u ON u.user_id = a.actor_id`+whereClause+`
+		ORDER BY a.created_at DESC, a.rowid DESC
+		LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after sql.NullString
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.ActorUsername,
+			&entry.ActorRole,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&before,
+			&after,
+			&entry.IPAddress,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if before.Valid {
+			entry.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			entry.After = json.RawMessage(after.String)
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetCountAuditEntries counts the audit log entries matching the filters
+func (ar *AuditRepository) GetCountAuditEntries(filters models.AuditFilters) (int, error) {
+	whereClause, args := buildAuditFilters(filters)
+
+	var count int
+	err := ar.db.QueryRow("SELECT COUNT(*) FROM audit_log a"+whereClause, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func buildAuditFilters(filters models.AuditFilters) (string, []interface{}) {
+	whereClause := " WHERE 1=1"
+	var args []interface{}
+
+	if filters.ActorID != "" {
+		whereClause += " AND a.actor_id = ?"
+		args = append(args, filters.ActorID)
+	}
+	if filters.Action != "" {
+		whereClause += " AND a.action = ?"
+		args = append(args, filters.Action)
+	}
+	if filters.TargetType != "" {
+		whereClause += " AND a.target_type = ?"
+		args = append(args, filters.TargetType)
+	}
+	if filters.TargetID != "" {
+		whereClause += " AND a.target_id = ?"
+		args = append(args, filters.TargetID)
+	}
+	if filters.Since != nil {
+		whereClause += " AND a.created_at >= ?"
+		args = append(args, *filters.Since)
+	}
+	if filters.Until != nil {
+		whereClause += " AND a.created_at < ?"
+		args = append(args, *filters.Until)
+	}
+
+	return whereClause, args
+}
+
+// Snapshots stored in before_json/after_json
+
+type postSnapshot struct {
+	UserID       string   `json:"user_id"`
+	Title        string   `json:"title"`
+	Content      string   `json:"content"`
+	CategoryIDs  []string `json:"category_ids"`
+	CommentCount int      `json:"comment_count"`
+}
+
+type commentSnapshot struct {
+	UserID          string `json:"user_id"`
+	PostID          string `json:"post_id"`
+	ParentCommentID string `json:"parent_comment_id,omitempty"`
+	Content         string `json:"content"`
+	ReplyCount      int    `json:"reply_count"`
+}
+
+type userRoleSnapshot struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// readPostSnapshot loads a post for the audit log; returns sql.ErrNoRows if it does not exist
+func readPostSnapshot(tx *sql.Tx, postID string) (*postSnapshot, error) {
+	var snapshot postSnapshot
+	err := tx.QueryRow(`SELECT user_id, title, content,
+			(SELECT COUNT(*) FROM comments WHERE post_id = posts.post_id AND deleted_at IS NULL)
+		FROM posts WHERE post_id = ?`, postID).
+		Scan(&snapshot.UserID, &snapshot.Title, &snapshot.Content, &snapshot.CommentCount)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query("SELECT category_id FROM post_categories WHERE post_id = ? ORDER BY category_id", postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshot.CategoryIDs = []string{}
+	for rows.Next() {
+		var categoryID string
+		if err := rows.Scan(&categoryID); err != nil {
+			return nil, err
+		}
+		snapshot.CategoryIDs = append(snapshot.CategoryIDs, categoryID)
+	}
+
+	return &snapshot, rows.Err()
+}
+
+// readCommentSnapshot loads a comment for the audit log; returns sql.ErrNoRows if it does not exist.
+// Comments already deleted are not found. ReplyCount counts every reply below the comment.
+func readCommentSnapshot(tx *sql.Tx, commentID string) (*commentSnapshot, error) {
+	var snapshot commentSnapshot
+	err := tx.QueryRow(`
+		WITH RECURSIVE thread(comment_id) AS (
+			SELECT comment_id FROM comments WHERE parent_comment_id = ?
+			UNION ALL
+			SELECT c.comment_id FROM comments c JOIN thread t ON c.parent_comment_id = t.comment_id
+		)
+		SELECT user_id, post_id, COALESCE(parent_comment_id, ''), content,
+			(SELECT COUNT(*) FROM thread)
+		FROM comments WHERE comment_id = ? AND deleted_at IS NULL`, commentID, commentID).
+		Scan(&snapshot.UserID, &snapshot.PostID, &snapshot.ParentCommentID, &snapshot.Content, &snapshot.ReplyCount)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"database/sql"
-	"errors"
 
 	"github.com/PaulKerasidis/forum/internal/models"
 )
@@ -22,7 +21,7 @@ func (cr *CategoryRepository) GetCategoryID(name string) (string, error) {
 	err := cr.DB.QueryRow("SELECT category_id FROM categories WHERE category_name = ?", name).Scan(&id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return "", errors.New("category not found")
+			return "", ErrCategoryNotFound
 		}
 		return "", err
 	}
@@ -61,7 +60,7 @@ func (cr *CategoryRepository) GetAllCategories() ([]models.Category, error) {
 // 	err := cr.DB.QueryRow("SELECT category_name FROM categories WHERE category_id = ?", id).Scan(&name)
 // 	if err != nil {
 // 		if err == sql.ErrNoRows {
-// 			return "", errors.New("category not found")
+// 			return "", ErrCategoryNotFound
 // 		}
 // 		return "", err
 // 	}
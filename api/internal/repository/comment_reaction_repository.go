@@ -2,7 +2,6 @@ package repository
 
 import (
 	"database/sql"
-	"errors"
 	"time"
 
 	"github.com/PaulKerasidis/forum/internal/models"
@@ -115,12 +114,12 @@ func (crr *CommentReactionRepository) changeCommentReaction(tx *sql.Tx, userID,
 // Helper method to validate that a comment exists
 func (crr *CommentReactionRepository) validateCommentExists(tx *sql.Tx, commentID string) error {
 	var exists int
-	err := tx.QueryRow("SELECT COUNT(*) FROM comments WHERE comment_id = ?", commentID).Scan(&exists)
+	err := tx.QueryRow("SELECT COUNT(*) FROM comments WHERE comment_id = ? AND deleted_at IS NULL", commentID).Scan(&exists)
 	if err != nil {
 		return err
 	}
 	if exists == 0 {
-		return errors.New("comment not found")
+		return ErrCommentNotFound
 	}
 	return nil
 }
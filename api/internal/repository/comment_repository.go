@@ -3,12 +3,55 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/PaulKerasidis/forum/internal/models"
 	"github.com/PaulKerasidis/forum/internal/utils"
 )
 
+// commentSelectQuery is the shared SELECT for comments - callers append WHERE/ORDER BY/LIMIT
+// The first placeholder is always the current user ID for user_reaction
+const commentSelectQuery = `
+		SELECT 
+			c.comment_id,
+			c.post_id,
+			c.user_id,
+			u.username,
+			c.content,
+			c.created_at,
+			c.updated_at,
+			CASE WHEN c.updated_by <> c.user_id THEN c.updated_by_role ELSE '' END as updated_by_role,
+			c.parent_comment_id,
+			c.depth,
+			c.deleted_at IS NOT NULL as deleted,
+			COALESCE(like_counts.count, 0) as like_count,
+			COALESCE(dislike_counts.count, 0) as dislike_count,
+			COALESCE(reply_counts.count, 0) as reply_count,
+			ur.reaction_type as user_reaction
+		FROM comments c
+		JOIN users u ON c.user_id = u.user_id
+		LEFT JOIN (
+			SELECT comment_id, COUNT(*) as count 
+			FROM comment_reactions 
+			WHERE reaction_type = 1
+			GROUP BY comment_id
+		) like_counts ON c.comment_id = like_counts.comment_id
+		LEFT JOIN (
+			SELECT comment_id, COUNT(*) as count 
+			FROM comment_reactions 
+			WHERE reaction_type = 2
+			GROUP BY comment_id
+		) dislike_counts ON c.comment_id = dislike_counts.comment_id
+		LEFT JOIN (
+			SELECT parent_comment_id, COUNT(*) as count
+			FROM comments
+			WHERE parent_comment_id IS NOT NULL
+			GROUP BY parent_comment_id
+		) reply_counts ON c.comment_id = reply_counts.parent_comment_id
+		LEFT JOIN comment_reactions ur ON c.comment_id = ur.comment_id AND ur.user_id = ?`
+
 type CommentRepository struct {
 	db *sql.DB
 }
@@ -29,7 +72,7 @@ func (cor *CommentRepository) CreateComment(postID, userID, content string) (*mo
 		// exists == 0 means post does exist
 		// exists == 1 means post does NOT exist
 		if exists == 0 {
-			return nil, errors.New("post not found")
+			return nil, ErrPostNotFound
 		}
 
 		// Generate UUID for comment
@@ -48,65 +91,125 @@ func (cor *CommentRepository) CreateComment(postID, userID, content string) (*mo
 		// Return lightweight response - just ID and timestamp
 		return &models.CreateCommentResponse{
 			CommentID: commentID,
+			PostID:    postID,
 			CreatedAt: createdAt,
 		}, nil
 	})
 }
 
-func (cor *CommentRepository) UpdateComment(commentID, userID, content string) error {
+// CreateReply adds a reply under an existing comment, on the same post, one level deeper
+func (cor *CommentRepository) CreateReply(parentCommentID, userID, content string, maxDepth int) (*models.CreateCommentResponse, error) {
+	return utils.ExecuteInTransactionWithResult(cor.db, func(tx *sql.Tx) (*models.CreateCommentResponse, error) {
+		// Check if parent comment exists and get its thread position; tombstones take no new replies
+		var postID string
+		var parentDepth int
+		err := tx.QueryRow("SELECT post_id, depth FROM comments WHERE comment_id = ? AND deleted_at IS NULL", parentCommentID).Scan(&postID, &parentDepth)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrCommentNotFound
+			}
+			return nil, err
+		}
+
+		depth := parentDepth + 1
+		if depth > maxDepth {
+			return nil, ErrMaxReplyDepth
+		}
+
+		// Generate UUID for reply
+		commentID := utils.GenerateUUIDToken()
+		createdAt := time.Now()
+
+		// Insert reply
+		_, err = tx.Exec(
+			"INSERT INTO comments (comment_id, post_id, user_id, parent_comment_id, depth, content, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			commentID, postID, userID, parentCommentID, depth, content, createdAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.CreateCommentResponse{
+			CommentID:       commentID,
+			PostID:          postID,
+			ParentCommentID: parentCommentID,
+			Depth:           depth,
+			CreatedAt:       createdAt,
+		}, nil
+	})
+}
+
+func (cor *CommentRepository) UpdateComment(commentID string, actor models.Actor, content string) error {
 	return utils.ExecuteInTransaction(cor.db, func(tx *sql.Tx) error {
-		// Check if comment exists and user owns it
-		var ownerID string
-		err := tx.QueryRow("SELECT user_id FROM comments WHERE comment_id = ?", commentID).Scan(&ownerID)
+		// Check if comment exists and user owns it, keeping the old version for the audit log
+		before, err := readCommentSnapshot(tx, commentID)
 		if err != nil {
 			if err == sql.ErrNoRows {
-				return errors.New("comment not found")
+				return ErrCommentNotFound
 			}
 			return err
 		}
 
-		// Check ownership
-		if ownerID != userID {
-			return errors.New("unauthorized: you can only update your own comments")
+		// Check ownership (moderators may edit any comment)
+		if before.UserID != actor.UserID && !actor.CanModerate() {
+			return ErrNotCommentAuthor
 		}
 		now := time.Now()
-		// Update comment content and set updated_at
+		// Update comment content and set updated_at, recording who edited it
 		_, err = tx.Exec(
-			"UPDATE comments SET content = ?, updated_at = ? WHERE comment_id = ?",
-			content, now, commentID,
+			"UPDATE comments SET content = ?, updated_at = ?, updated_by = ?, updated_by_role = ? WHERE comment_id = ?",
+			content, now, actor.UserID, actor.Role, commentID,
 		)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		after := *before
+		after.Content = content
+		return writeAudit(tx, actor, models.AuditCommentEdit, models.AuditTargetComment, commentID, before, after)
 	})
 }
 
-func (cor *CommentRepository) DeleteComment(commentID, userID string) error {
+func (cor *CommentRepository) DeleteComment(commentID string, actor models.Actor) error {
 	return utils.ExecuteInTransaction(cor.db, func(tx *sql.Tx) error {
 		// Check if comment exists and user owns it
-		var ownerID string
-		err := tx.QueryRow("SELECT user_id FROM comments WHERE comment_id = ?", commentID).Scan(&ownerID)
+		before, err := readCommentSnapshot(tx, commentID)
 		if err != nil {
 			if err == sql.ErrNoRows {
-				return errors.New("comment not found")
+				return ErrCommentNotFound
 			}
 			return err
 		}
 
-		// Check ownership
-		if ownerID != userID {
-			return errors.New("unauthorized: you can only delete your own comments")
+		// Check ownership (moderators may remove any comment)
+		if before.UserID != actor.UserID && !actor.CanModerate() {
+			return ErrNotCommentAuthor
 		}
 
-		// Delete comment
-		_, err = tx.Exec("DELETE FROM comments WHERE comment_id = ?", commentID)
-		if err != nil {
-			return err
+		if before.UserID != actor.UserID {
+			slog.Info("Comment removed by moderator", "comment_id", commentID, "author_id", before.UserID, "moderator_role", actor.Role, "moderator_id", actor.UserID)
 		}
 
-		return nil
+		// A comment with replies is kept as a tombstone, so the replies stay in their thread
+		if before.ReplyCount > 0 {
+			_, err = tx.Exec(
+				"UPDATE comments SET content = ?, deleted_at = ? WHERE comment_id = ?",
+				models.DeletedCommentContent, time.Now(), commentID,
+			)
+			if err != nil {
+				return err
+			}
+		} else {
+			_, err = tx.Exec("DELETE FROM comments WHERE comment_id = ?", commentID)
+			if err != nil {
+				return err
+			}
+			if err := pruneTombstones(tx, before.ParentCommentID); err != nil {
+				return err
+			}
+		}
+
+		return writeAudit(tx, actor, models.AuditCommentDelete, models.AuditTargetComment, commentID, before, nil)
 	})
 }
 
@@ -114,9 +217,107 @@ func (cor *CommentRepository) DeleteComment(commentID, userID string) error {
 // GET comment or comments
 // ...
 
-// Get []*comments for PostID
+// GetCommentsByPostID returns a page of the top-level comments of a post
 func (cor *CommentRepository) GetCommentsByPostID(postID string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.Comment, error) {
-	// Prepare user ID argument - UNCHANGED
+	whereClause := `
+		WHERE c.post_id = ? AND c.parent_comment_id IS NULL`
+	return cor.queryComments(whereClause, postID, limit, offset, userID, options)
+}
+
+// GetReplies returns a page of the direct replies to a comment, sorted within that sibling level
+func (cor *CommentRepository) GetReplies(parentCommentID string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.Comment, error) {
+	whereClause := `
+		WHERE c.parent_comment_id = ?`
+	return cor.queryComments(whereClause, parentCommentID, limit, offset, userID, options)
+}
+
+// LoadReplies fills in the replies subtree of each comment, levels deep, with at most
+// repliesLimit replies per comment. Every sibling level uses the same sort options.
+// Each level of the tree is read with one query.
+func (cor *CommentRepository) LoadReplies(comments []*models.Comment, levels, repliesLimit int, userID *string, options utils.SortOptions) error {
+	parents := comments
+	for level := 0; level < levels; level++ {
+		byID := make(map[string]*models.Comment, len(parents))
+		var parentIDs []string
+		for _, comment := range parents {
+			if comment.ReplyCount > 0 {
+				byID[comment.ID] = comment
+				parentIDs = append(parentIDs, comment.ID)
+			}
+		}
+		if len(parentIDs) == 0 {
+			return nil
+		}
+
+		replies, err := cor.getRepliesOf(parentIDs, repliesLimit, userID, options)
+		if err != nil {
+			return err
+		}
+
+		for _, reply := range replies {
+			parent := byID[*reply.ParentCommentID]
+			parent.Replies = append(parent.Replies, reply)
+		}
+		for _, parent := range byID {
+			parent.HasMoreReplies = parent.ReplyCount > len(parent.Replies)
+		}
+
+		parents = replies
+	}
+
+	return nil
+}
+
+// getRepliesOf returns the first repliesLimit replies to each of the given comments, every
+// sibling level sorted by options. The shared comment SELECT is wrapped as "c", so the
+// ORDER BY clause can rank the replies of each parent.
+func (cor *CommentRepository) getRepliesOf(parentIDs []string, repliesLimit int, userID *string, options utils.SortOptions) ([]*models.Comment, error) {
+	var userIDArg interface{} = ""
+	if userID != nil {
+		userIDArg = *userID
+	}
+
+	orderClause := utils.BuildOrderClause(options.SortBy, utils.ContentTypeComments)
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(parentIDs)), ", ")
+
+	query := `
+		SELECT comment_id, post_id, user_id, username, content, created_at, updated_at, updated_by_role,
+			parent_comment_id, depth, deleted, like_count, dislike_count, reply_count, user_reaction
+		FROM (
+			SELECT c.*, ROW_NUMBER() OVER (PARTITION BY c.parent_comment_id ` + orderClause + `) AS sibling_rank
+			FROM (` + commentSelectQuery + `
+		WHERE c.parent_comment_id IN (` + placeholders + `)) c
+		) c
+		WHERE sibling_rank <= ?
+		` + orderClause
+
+	args := make([]interface{}, 0, len(parentIDs)+2)
+	args = append(args, userIDArg)
+	for _, id := range parentIDs {
+		args = append(args, id)
+	}
+	args = append(args, repliesLimit)
+
+	rows, err := cor.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var replies []*models.Comment
+	for rows.Next() {
+		reply, err := cor.scanCommentRow(rows, userID)
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, reply)
+	}
+	return replies, rows.Err()
+}
+
+// queryComments runs the shared comment SELECT with the given filter, sorting and pagination
+func (cor *CommentRepository) queryComments(whereClause, filterArg string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.Comment, error) {
+	// Prepare user ID argument
 	var userIDArg interface{}
 	if userID != nil {
 		userIDArg = *userID
@@ -124,41 +325,14 @@ func (cor *CommentRepository) GetCommentsByPostID(postID string, limit, offset i
 		userIDArg = "" // Won't match any user_id
 	}
 
-	// Build dynamic query with sorting using unified system - UNCHANGED
+	// Build dynamic query with sorting using unified system
 	orderClause := utils.BuildOrderClause(options.SortBy, utils.ContentTypeComments)
 
-	query := `
-		SELECT 
-			c.comment_id,
-			c.post_id,
-			c.user_id,
-			u.username,
-			c.content,
-			c.created_at,
-			c.updated_at,
-			COALESCE(like_counts.count, 0) as like_count,
-			COALESCE(dislike_counts.count, 0) as dislike_count,
-			ur.reaction_type as user_reaction
-		FROM comments c
-		JOIN users u ON c.user_id = u.user_id
-		LEFT JOIN (
-			SELECT comment_id, COUNT(*) as count 
-			FROM comment_reactions 
-			WHERE reaction_type = 1
-			GROUP BY comment_id
-		) like_counts ON c.comment_id = like_counts.comment_id
-		LEFT JOIN (
-			SELECT comment_id, COUNT(*) as count 
-			FROM comment_reactions 
-			WHERE reaction_type = 2
-			GROUP BY comment_id
-		) dislike_counts ON c.comment_id = dislike_counts.comment_id
-		LEFT JOIN comment_reactions ur ON c.comment_id = ur.comment_id AND ur.user_id = ?
-		WHERE c.post_id = ?
+	query := commentSelectQuery + whereClause + `
 		` + orderClause + `
 		LIMIT ? OFFSET ?`
 
-	rows, err := cor.db.Query(query, userIDArg, postID, limit, offset)
+	rows, err := cor.db.Query(query, userIDArg, filterArg, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -184,9 +358,31 @@ func (cor *CommentRepository) GetCommentsByPostID(postID string, limit, offset i
 // ..
 // COUNT comments methods
 // ..
+// GetCommentCountByPost counts a post's comments, leaving out deleted ones kept as tombstones
 func (cor *CommentRepository) GetCommentCountByPost(postID string) (int, error) {
 	var count int
-	err := cor.db.QueryRow("SELECT COUNT(*) FROM comments WHERE post_id = ?", postID).Scan(&count)
+	err := cor.db.QueryRow("SELECT COUNT(*) FROM comments WHERE post_id = ? AND deleted_at IS NULL", postID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetTopLevelCommentCountByPost counts only the comments that are not replies. Tombstones
+// are counted, as they are listed to keep their replies in place.
+func (cor *CommentRepository) GetTopLevelCommentCountByPost(postID string) (int, error) {
+	var count int
+	err := cor.db.QueryRow("SELECT COUNT(*) FROM comments WHERE post_id = ? AND parent_comment_id IS NULL", postID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetReplyCountByComment counts the direct replies to a comment, tombstones included
+func (cor *CommentRepository) GetReplyCountByComment(commentID string) (int, error) {
+	var count int
+	err := cor.db.QueryRow("SELECT COUNT(*) FROM comments WHERE parent_comment_id = ?", commentID).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -201,6 +397,7 @@ func (cor *CommentRepository) scanCommentRow(scanner interface{}, userID *string
 	var comment models.Comment
 	var userReaction sql.NullInt64
 	var updatedAt sql.NullTime
+	var parentCommentID sql.NullString
 
 	var err error
 	switch s := scanner.(type) {
@@ -213,8 +410,13 @@ func (cor *CommentRepository) scanCommentRow(scanner interface{}, userID *string
 			&comment.Content,
 			&comment.CreatedAt,
 			&updatedAt,
+			&comment.UpdatedByRole,
+			&parentCommentID,
+			&comment.Depth,
+			&comment.Deleted,
 			&comment.LikeCount,
 			&comment.DislikeCount,
+			&comment.ReplyCount,
 			&userReaction,
 		)
 	case *sql.Rows:
@@ -226,8 +428,13 @@ func (cor *CommentRepository) scanCommentRow(scanner interface{}, userID *string
 			&comment.Content,
 			&comment.CreatedAt,
 			&updatedAt,
+			&comment.UpdatedByRole,
+			&parentCommentID,
+			&comment.Depth,
+			&comment.Deleted,
 			&comment.LikeCount,
 			&comment.DislikeCount,
+			&comment.ReplyCount,
 			&userReaction,
 		)
 	default:
@@ -245,6 +452,11 @@ func (cor *CommentRepository) scanCommentRow(scanner interface{}, userID *string
 		comment.UpdatedAt = nil
 	}
 
+	// Handle ParentCommentID
+	if parentCommentID.Valid {
+		comment.ParentCommentID = &parentCommentID.String
+	}
+
 	// Handle UserReaction
 	if userReaction.Valid {
 		reactionType := int(userReaction.Int64)
@@ -253,6 +465,12 @@ func (cor *CommentRepository) scanCommentRow(scanner interface{}, userID *string
 		comment.UserReaction = nil
 	}
 
+	// Tombstones keep their place in the thread but not their author
+	if comment.Deleted {
+		comment.UserID = ""
+		comment.Username = ""
+	}
+
 	// Handle IsOwner
 	comment.IsOwner = (userID != nil && comment.UserID == *userID)
 
@@ -269,43 +487,42 @@ func (cor *CommentRepository) GetCommentByID(commentID string, userID *string) (
 		userIDArg = ""
 	}
 
-	query := `
-		SELECT 
-			c.comment_id,
-			c.post_id,
-			c.user_id,
-			u.username,
-			c.content,
-			c.created_at,
-			c.updated_at,
-			COALESCE(like_counts.count, 0) as like_count,
-			COALESCE(dislike_counts.count, 0) as dislike_count,
-			ur.reaction_type as user_reaction
-		FROM comments c
-		JOIN users u ON c.user_id = u.user_id
-		LEFT JOIN (
-			SELECT comment_id, COUNT(*) as count 
-			FROM comment_reactions 
-			WHERE reaction_type = 1
-			GROUP BY comment_id
-		) like_counts ON c.comment_id = like_counts.comment_id
-		LEFT JOIN (
-			SELECT comment_id, COUNT(*) as count 
-			FROM comment_reactions 
-			WHERE reaction_type = 2
-			GROUP BY comment_id
-		) dislike_counts ON c.comment_id = dislike_counts.comment_id
-		LEFT JOIN comment_reactions ur ON c.comment_id = ur.comment_id AND ur.user_id = ?
+	query := commentSelectQuery + `
 		WHERE c.comment_id = ?`
 
 	row := cor.db.QueryRow(query, userIDArg, commentID)
 	comment, err := cor.scanCommentRow(row, userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("comment not found")
+			return nil, ErrCommentNotFound
 		}
 		return nil, err
 	}
 
 	return comment, nil
 }
+
+// pruneTombstones deletes the tombstones above a deleted comment that no longer have any
+// replies, starting with its parent
+func pruneTombstones(tx *sql.Tx, commentID string) error {
+	for commentID != "" {
+		var parentID sql.NullString
+		err := tx.QueryRow(`
+			SELECT parent_comment_id FROM comments
+			WHERE comment_id = ? AND deleted_at IS NOT NULL
+				AND NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_comment_id = comments.comment_id)`,
+			commentID).Scan(&parentID)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM comments WHERE comment_id = ?", commentID); err != nil {
+			return err
+		}
+		commentID = parentID.String
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+//go:build sqlite_fts5
+
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+func TestDeletedCommentWithReplies(t *testing.T) {
+	db := openTestDB(t)
+	user, err := NewUserRepository(db).CreateUser(models.UserRegistration{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: "Passw0rd!",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO categories (category_id, category_name) VALUES ('general', 'General')"); err != nil {
+		t.Fatal(err)
+	}
+	pr := NewPostsRepository(db)
+	post, err := pr.CreatePost(user.ID, "A title", "Some content", []string{"general"})
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+
+	cor := NewCommentRepository(db)
+	parent, err := cor.CreateComment(post.PostID, user.ID, "parent")
+	if err != nil {
+		t.Fatalf("CreateComment: %v", err)
+	}
+	if _, err := cor.CreateReply(parent.CommentID, user.ID, "reply", 5); err != nil {
+		t.Fatalf("CreateReply: %v", err)
+	}
+
+	// The parent has a reply, so deleting it leaves a tombstone
+	actor := models.ActorFromUser(user)
+	if err := cor.DeleteComment(parent.CommentID, actor); err != nil {
+		t.Fatalf("DeleteComment: %v", err)
+	}
+	tombstone, err := cor.GetCommentByID(parent.CommentID, nil)
+	if err != nil {
+		t.Fatalf("GetCommentByID on the tombstone: %v", err)
+	}
+	if tombstone.Content != models.DeletedCommentContent {
+		t.Errorf("tombstone content = %q, want %q", tombstone.Content, models.DeletedCommentContent)
+	}
+
+	if _, err := cor.CreateReply(parent.CommentID, user.ID, "late reply", 5); !errors.Is(err, ErrCommentNotFound) {
+		t.Errorf("CreateReply under a tombstone: error = %v, want ErrCommentNotFound", err)
+	}
+	if err := cor.UpdateComment(parent.CommentID, actor, "edited"); !errors.Is(err, ErrCommentNotFound) {
+		t.Errorf("UpdateComment on a tombstone: error = %v, want ErrCommentNotFound", err)
+	}
+
+	// Totals count the reply but not the tombstone
+	if n, err := cor.GetCommentCountByPost(post.PostID); err != nil || n != 1 {
+		t.Errorf("GetCommentCountByPost() = %d, %v, want 1", n, err)
+	}
+	summaries, err := pr.GetAllPosts(10, 0, nil, utils.SortOptions{SortBy: "newest"})
+	if err != nil {
+		t.Fatalf("GetAllPosts: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].CommentCount != 1 {
+		t.Errorf("post summaries = %+v, want one post with 1 comment", summaries)
+	}
+}
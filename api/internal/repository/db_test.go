@@ -0,0 +1,29 @@
+//go:build sqlite_fts5
+
+package repository
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/PaulKerasidis/forum/database/migrations"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openTestDB returns a migrated database in a temporary directory, closed when the test ends
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "forum.db")
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_journal_mode=WAL")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,66 @@
+package repository
+
+import "github.com/PaulKerasidis/forum/internal/models"
+
+// Errors returned by the repositories. Each wraps a models error kind, so handlers can
+// answer them with utils.RespondWithDomainError, and its message can be shown to users.
+var (
+	// Users and accounts
+	ErrUserNotFound       = models.NotFound("User not found")
+	ErrEmailNotFound      = models.NotFound("Email not found")
+	ErrEmailTaken         = models.Conflict("Email already taken")
+	ErrUsernameTaken      = models.Conflict("Username already taken")
+	ErrInvalidCredentials = models.Unauthorized("Invalid credentials")
+	ErrInvalidToken       = models.Invalid("This link is invalid or has expired")
+	ErrPasswordAlreadySet = models.Typed(models.Conflict("Your account already has a password"), models.ProblemPasswordAlreadySet)
+	ErrInvalidRole        = models.Invalid("Role must be member, moderator or admin")
+
+	// Sessions and personal access tokens
+	ErrSessionNotFound   = models.NotFound("Session not found")
+	ErrSessionExpired    = models.Unauthorized("Session expired")
+	ErrTokenNotFound     = models.NotFound("Token not found")
+	ErrTokenLimitReached = models.Conflict("Token limit reached")
+	ErrInvalidAPIToken   = models.Unauthorized("Invalid or expired token")
+
+	// Login providers
+	ErrInvalidOAuthState = models.Unauthorized("Invalid or expired state")
+	ErrOAuthEmailMissing = models.Invalid("Provider did not share an email address")
+	ErrOAuthEmailTaken   = models.Conflict("Email already registered with another account, log in and link the provider from your profile")
+	ErrIdentityNotFound  = models.NotFound("Provider is not linked")
+	ErrIdentityTaken     = models.Conflict("Provider account is linked to another user")
+	ErrProviderLinked    = models.Conflict("Provider is already linked")
+	ErrLastLoginMethod   = models.Typed(models.Conflict("This is your only way to log in, set a password or link another provider first"), models.ProblemLastLoginMethod)
+
+	// Two-factor authentication
+	ErrInvalidCode           = models.Typed(models.Invalid("Invalid code"), models.ProblemInvalidCode)
+	ErrTwoFactorLoginExpired = models.Unauthorized("Login expired, please sign in again")
+	ErrTwoFactorEnabled      = models.Conflict("Two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled   = models.Invalid("Two-factor authentication is not enabled")
+	ErrTwoFactorNotStarted   = models.Invalid("Two-factor setup has not been started")
+	ErrTwoFactorCodesLocked  = models.Forbidden("Too many wrong codes, try again later")
+
+	// Posts, comments and categories
+	ErrPostNotFound     = models.NotFound("Post not found")
+	ErrCommentNotFound  = models.NotFound("Comment not found")
+	ErrCategoryNotFound = models.NotFound("Category not found")
+	ErrNotPostAuthor    = models.Forbidden("You can only change your own posts")
+	ErrNotCommentAuthor = models.Forbidden("You can only change your own comments")
+	ErrMaxReplyDepth    = models.Typed(models.Invalid("Maximum reply depth reached"), models.ProblemMaxReplyDepth)
+
+	// Reports
+	ErrReportNotFound       = models.NotFound("Report not found")
+	ErrReportTargetNotFound = models.NotFound("Reported content not found")
+	ErrReportOwnContent     = models.Typed(models.Invalid("You cannot report your own content"), models.ProblemReportOwnContent)
+	ErrAlreadyReported      = models.Conflict("You have already reported this")
+	ErrReportClosed         = models.Typed(models.Conflict("Report is already closed"), models.ProblemReportClosed)
+	ErrReportClaimed        = models.Typed(models.Conflict("Report is claimed by another moderator"), models.ProblemReportClaimed)
+	ErrInvalidReportTarget  = models.Invalid("Invalid target type")
+	ErrInvalidReportStatus  = models.Invalid("Invalid report status")
+
+	// Sanctions
+	ErrSanctionNotFound  = models.NotFound("Sanction not found")
+	ErrSanctionNotActive = models.Conflict("Sanction has already ended")
+	ErrBanRequiresAdmin  = models.Forbidden("Only admins can ban users")
+	ErrLiftRequiresAdmin = models.Forbidden("Only admins can lift bans")
+	ErrSanctionOutranked = models.Forbidden("You cannot sanction a user with an equal or higher role")
+)
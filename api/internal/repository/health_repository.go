@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/database/migrations"
+)
+
+// walBacklogLimit is how many WAL frames may wait for a checkpoint before the database
+// counts as falling behind (about 40MB with 4KB pages)
+const walBacklogLimit = 10000
+
+// HealthRepository checks the database for the readiness probe. The checks only read,
+// so probes cannot add to the load of a database that is struggling.
+type HealthRepository struct {
+	db            *sql.DB
+	latestVersion int   // version of the newest embedded migration
+	latestErr     error // why the embedded migrations could not be read
+}
+
+func NewHealthRepository(db *sql.DB) *HealthRepository {
+	// The embedded migrations cannot change while the server runs
+	latest, err := migrations.LatestVersion()
+	return &HealthRepository{db: db, latestVersion: latest, latestErr: err}
+}
+
+// CheckDatabase runs a trivial query, so a connection is really taken from the pool
+func (hr *HealthRepository) CheckDatabase(ctx context.Context) error {
+	var one int
+	return hr.db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
+// CheckWAL makes sure the database is in WAL mode and checkpoints keep up with writes
+func (hr *HealthRepository) CheckWAL(ctx context.Context) (string, error) {
+	var mode string
+	if err := hr.db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&mode); err != nil {
+		return "", err
+	}
+	if mode != "wal" {
+		return "", fmt.Errorf("journal mode is %s, expected wal", mode)
+	}
+
+	frames, checkpointed, err := readWALIndex(config.Config.DBPath + "-shm")
+	if err != nil {
+		return "", err
+	}
+	if backlog := frames - checkpointed; backlog > walBacklogLimit {
+		return "", fmt.Errorf("%d WAL frames are waiting for a checkpoint", backlog)
+	}
+	return fmt.Sprintf("%d of %d WAL frames checkpointed", checkpointed, frames), nil
+}
+
+// readWALIndex reads how many frames the WAL holds and how many of them are already
+// checkpointed from the wal-index header in the shared-memory file, without taking
+// part in checkpointing (https://www.sqlite.org/walformat.html). The header is in
+// native byte order; mxFrame is at offset 16 and nBackfill at offset 96.
+func readWALIndex(shmPath string) (frames, checkpointed int, err error) {
+	f, err := os.Open(shmPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open wal-index: %v", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, 100)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return 0, 0, fmt.Errorf("failed to read wal-index: %v", err)
+	}
+	frames = int(binary.NativeEndian.Uint32(header[16:20]))
+	checkpointed = int(binary.NativeEndian.Uint32(header[96:100]))
+	return frames, checkpointed, nil
+}
+
+// CheckMigrations makes sure the schema is at the version this binary expects
+func (hr *HealthRepository) CheckMigrations(ctx context.Context) (string, error) {
+	if hr.latestErr != nil {
+		return "", hr.latestErr
+	}
+	current, err := migrations.AppliedVersion(ctx, hr.db)
+	if err != nil {
+		return "", err
+	}
+	if current != hr.latestVersion {
+		return "", fmt.Errorf("schema is at version %d, expected %d", current, hr.latestVersion)
+	}
+	return fmt.Sprintf("schema at version %d", current), nil
+}
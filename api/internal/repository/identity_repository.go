@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// IdentityRepository handles the OAuth provider accounts linked to users
+type IdentityRepository struct {
+	db *sql.DB
+}
+
+// NewIdentityRepository creates a new identity repository
+func NewIdentityRepository(db *sql.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// ListIdentities returns the providers linked to a user, oldest first
+func (ir *IdentityRepository) ListIdentities(userID string) ([]models.UserIdentity, error) {
+	rows, err := ir.db.Query(`
+		SELECT provider, COALESCE(email, ''), created_at, last_used_at
+		FROM user_identities
+		WHERE user_id = ?
+		ORDER BY created_at ASC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []models.UserIdentity{}
+	for rows.Next() {
+		var identity models.UserIdentity
+		var lastUsed sql.NullTime
+		if err := rows.Scan(&identity.Provider, &identity.Email, &identity.CreatedAt, &lastUsed); err != nil {
+			return nil, err
+		}
+		if lastUsed.Valid {
+			identity.LastUsedAt = &lastUsed.Time
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// LinkIdentity adds a provider account to a logged-in user. Linking the same
+// account again is a no-op.
+func (ir *IdentityRepository) LinkIdentity(userID string, profile *models.OAuthProfile) error {
+	return utils.ExecuteInTransaction(ir.db, func(tx *sql.Tx) error {
+		return linkIdentity(tx, userID, profile)
+	})
+}
+
+// UnlinkIdentity removes a provider from a user, refusing to remove the only way they can log in
+func (ir *IdentityRepository) UnlinkIdentity(userID, provider string) error {
+	return utils.ExecuteInTransaction(ir.db, func(tx *sql.Tx) error {
+		var hasPassword bool
+		var identities int
+		err := tx.QueryRow(`
+			SELECT password_hash IS NOT NULL,
+				(SELECT COUNT(*) FROM user_identities WHERE user_id = users.user_id)
+			FROM users WHERE user_id = ?`, userID).Scan(&hasPassword, &identities)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec("DELETE FROM user_identities WHERE user_id = ? AND provider = ?", userID, provider)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrIdentityNotFound
+		}
+
+		if !hasPassword && identities <= 1 {
+			return ErrLastLoginMethod
+		}
+
+		return nil
+	})
+}
+
+// linkIdentity records a provider account for a user inside a transaction
+func linkIdentity(tx *sql.Tx, userID string, profile *models.OAuthProfile) error {
+	var ownerID string
+	err := tx.QueryRow(
+		"SELECT user_id FROM user_identities WHERE provider = ? AND provider_user_id = ?",
+		profile.Provider, profile.ProviderID,
+	).Scan(&ownerID)
+	if err == nil {
+		if ownerID != userID {
+			return ErrIdentityTaken
+		}
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	var existing int
+	err = tx.QueryRow(
+		"SELECT COUNT(*) FROM user_identities WHERE user_id = ? AND provider = ?",
+		userID, profile.Provider,
+	).Scan(&existing)
+	if err != nil {
+		return err
+	}
+	if existing > 0 {
+		return ErrProviderLinked
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO user_identities (provider, provider_user_id, user_id, email, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		profile.Provider, profile.ProviderID, userID, profile.Email, time.Now(),
+	)
+	return err
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// LoginAttemptRepository tracks failed password logins per email and per IP address
+// to slow down and then lock out password guessing
+type LoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+// CheckAllowed returns why a login attempt must be refused right now, or nil when the
+// password may be checked. An attempt that may go ahead is counted as a failure in the
+// same transaction, so parallel guesses cannot all pass the check before any of them is
+// recorded. The returned ID is that pending failure: RecordFailure confirms it, and
+// ReleaseAttempt drops it once the password turns out to be right.
+func (lar *LoginAttemptRepository) CheckAllowed(attempt models.LoginAttempt, p models.LoginProtection) (string, *models.LoginThrottle, error) {
+	var failureID string
+	throttle, err := utils.ExecuteInTransactionWithResult(lar.db, func(tx *sql.Tx) (*models.LoginThrottle, error) {
+		now := time.Now()
+		email := attempt.Email
+
+		// Forget failures and lockouts that no longer count. Writing first makes the
+		// transaction take the write lock before it reads, so concurrent attempts wait
+		// their turn instead of reading the same counts.
+		if _, err := tx.Exec("DELETE FROM login_failures WHERE created_at <= ?", now.Add(-p.FailureWindow)); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec("DELETE FROM login_lockouts WHERE locked_until <= ?", now); err != nil {
+			return nil, err
+		}
+
+		// A locked email takes precedence over a locked address, so its owner is told about it
+		for _, lock := range []struct{ subject, reason string }{
+			{"email:" + email, models.LoginThrottleAccount},
+			{"ip:" + attempt.IP, models.LoginThrottleIP},
+		} {
+			if lock.reason == models.LoginThrottleIP && attempt.SharedIP {
+				continue
+			}
+			var lockedUntil time.Time
+			err := tx.QueryRow("SELECT locked_until FROM login_lockouts WHERE subject = ? AND locked_until > ?",
+				lock.subject, now).Scan(&lockedUntil)
+			if err == nil {
+				return &models.LoginThrottle{Reason: lock.reason, RetryAfter: lockedUntil.Sub(now)}, nil
+			}
+			if err != sql.ErrNoRows {
+				return nil, err
+			}
+		}
+
+		// Past the free attempts, each failure doubles the wait before the next try
+		var failures int
+		err := tx.QueryRow("SELECT COUNT(*) FROM login_failures WHERE email = ? AND created_at > ?",
+			email, now.Add(-p.FailureWindow)).Scan(&failures)
+		if err != nil {
+			return nil, err
+		}
+		if wait := p.Backoff(failures); wait > 0 {
+			var lastFailure time.Time
+			err = tx.QueryRow("SELECT created_at FROM login_failures WHERE email = ? ORDER BY created_at DESC LIMIT 1",
+				email).Scan(&lastFailure)
+			if err != nil {
+				return nil, err
+			}
+			if retryAt := lastFailure.Add(wait); now.Before(retryAt) {
+				return &models.LoginThrottle{Reason: models.LoginThrottleBackoff, RetryAfter: retryAt.Sub(now)}, nil
+			}
+		}
+
+		failureID = utils.GenerateUUIDToken()
+		_, err = tx.Exec("INSERT INTO login_failures (failure_id, email, ip_address, created_at) VALUES (?, ?, ?, ?)",
+			failureID, email, attempt.IP, now)
+		return nil, err
+	})
+	if err != nil || throttle != nil {
+		return "", throttle, err
+	}
+	return failureID, nil, nil
+}
+
+// ReleaseAttempt drops the failure CheckAllowed counted for an attempt whose password was right
+func (lar *LoginAttemptRepository) ReleaseAttempt(failureID string) error {
+	_, err := lar.db.Exec("DELETE FROM login_failures WHERE failure_id = ?", failureID)
+	return err
+}
+
+// RecordFailure confirms the failure CheckAllowed counted for a wrong email or password
+// and writes its security event. When the failure reaches a lockout threshold, the email
+// or IP address is locked and the lock is returned.
+func (lar *LoginAttemptRepository) RecordFailure(attempt models.LoginAttempt, p models.LoginProtection) (*models.LoginThrottle, error) {
+	return utils.ExecuteInTransactionWithResult(lar.db, func(tx *sql.Tx) (*models.LoginThrottle, error) {
+		now := time.Now()
+
+		if err := writeSecurityEvent(tx, attempt.Event(models.SecurityEventLoginFailed, "")); err != nil {
+			return nil, err
+		}
+
+		lockedUntil := now.Add(p.LockoutDuration)
+		var lock *models.LoginThrottle
+
+		// Too many failures for one email
+		accountLocked, err := lockIfOverThreshold(tx, "email", attempt.Email, "email:"+attempt.Email, p.LockoutThreshold, lockedUntil)
+		if err != nil {
+			return nil, err
+		}
+		if accountLocked {
+			details := fmt.Sprintf("locked until %s", lockedUntil.UTC().Format(time.RFC3339))
+			if err := writeSecurityEvent(tx, attempt.Event(models.SecurityEventAccountLocked, details)); err != nil {
+				return nil, err
+			}
+			lock = &models.LoginThrottle{Reason: models.LoginThrottleAccount, RetryAfter: p.LockoutDuration}
+		}
+
+		// Too many failures from one address across any emails (password spraying). A proxy's
+		// address is everyone's behind it, and locking it would lock them all out.
+		if attempt.SharedIP {
+			return lock, nil
+		}
+		ipLocked, err := lockIfOverThreshold(tx, "ip_address", attempt.IP, "ip:"+attempt.IP, p.IPLockoutThreshold, lockedUntil)
+		if err != nil {
+			return nil, err
+		}
+		if ipLocked {
+			details := fmt.Sprintf("locked until %s", lockedUntil.UTC().Format(time.RFC3339))
+			if err := writeSecurityEvent(tx, attempt.Event(models.SecurityEventIPLocked, details)); err != nil {
+				return nil, err
+			}
+			if lock == nil {
+				lock = &models.LoginThrottle{Reason: models.LoginThrottleIP, RetryAfter: p.LockoutDuration}
+			}
+		}
+
+		return lock, nil
+	})
+}
+
+// lockIfOverThreshold locks subject once the failures where column = value reach
+// threshold. Those failures are cleared, so counting starts over when the lock ends.
+func lockIfOverThreshold(tx *sql.Tx, column, value, subject string, threshold int, lockedUntil time.Time) (bool, error) {
+	var failures int
+	err := tx.QueryRow("SELECT COUNT(*) FROM login_failures WHERE "+column+" = ?", value).Scan(&failures)
+	if err != nil {
+		return false, err
+	}
+	if failures < threshold {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`INSERT INTO login_lockouts (subject, locked_until) VALUES (?, ?)
+		ON CONFLICT(subject) DO UPDATE SET locked_until = excluded.locked_until`,
+		subject, lockedUntil)
+	if err != nil {
+		return false, err
+	}
+	_, err = tx.Exec("DELETE FROM login_failures WHERE "+column+" = ?", value)
+	return err == nil, err
+}
+
+// ClearFailures forgets an email's failed logins after a successful one
+func (lar *LoginAttemptRepository) ClearFailures(email string) error {
+	_, err := lar.db.Exec("DELETE FROM login_failures WHERE email = ?", email)
+	return err
+}
@@ -0,0 +1,76 @@
+//go:build sqlite_fts5
+
+package repository
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+func TestConcurrentLoginAttemptsKeepTheBackoff(t *testing.T) {
+	db := openTestDB(t)
+	lar := NewLoginAttemptRepository(db)
+	p := models.LoginProtection{
+		FailureWindow:      time.Hour,
+		BackoffAfter:       2,
+		BackoffBase:        time.Hour,
+		BackoffMax:         time.Hour,
+		LockoutThreshold:   100,
+		IPLockoutThreshold: 100,
+		LockoutDuration:    time.Hour,
+	}
+	attempt := models.LoginAttempt{Email: "alice@example.com", IP: "203.0.113.7"}
+
+	// Guesses sent all at once get no more tries than guesses sent one by one
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, throttle, err := lar.CheckAllowed(attempt, p)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if throttle == nil {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != int32(p.BackoffAfter) {
+		t.Errorf("%d concurrent attempts were allowed, want %d", got, p.BackoffAfter)
+	}
+}
+
+func TestReleasedLoginAttemptIsNotAFailure(t *testing.T) {
+	db := openTestDB(t)
+	lar := NewLoginAttemptRepository(db)
+	p := models.LoginProtection{FailureWindow: time.Hour, BackoffAfter: 1, BackoffBase: time.Hour, BackoffMax: time.Hour}
+	attempt := models.LoginAttempt{Email: "alice@example.com", IP: "203.0.113.7"}
+
+	// A right password gives the attempt back, so the next one is not made to wait
+	for i := 0; i < 3; i++ {
+		failureID, throttle, err := lar.CheckAllowed(attempt, p)
+		if err != nil || throttle != nil {
+			t.Fatalf("attempt %d: throttle = %v, err = %v, want allowed", i+1, throttle, err)
+		}
+		if err := lar.ReleaseAttempt(failureID); err != nil {
+			t.Fatalf("ReleaseAttempt: %v", err)
+		}
+	}
+
+	if _, _, err := lar.CheckAllowed(attempt, p); err != nil {
+		t.Fatal(err)
+	}
+	_, throttle, err := lar.CheckAllowed(attempt, p)
+	if err != nil || throttle == nil || throttle.Reason != models.LoginThrottleBackoff {
+		t.Errorf("attempt after a failure: throttle = %v, err = %v, want a backoff", throttle, err)
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// OAuthStateRepository keeps OAuth authorization requests between the redirect to the
+// provider and its callback
+type OAuthStateRepository struct {
+	db *sql.DB
+}
+
+func NewOAuthStateRepository(db *sql.DB) *OAuthStateRepository {
+	return &OAuthStateRepository{db: db}
+}
+
+// CreateState issues a state, browser binding and PKCE verifier for a new flow.
+// linkUserID is empty for a login; returnTo must already be validated.
+func (osr *OAuthStateRepository) CreateState(provider, linkUserID, returnTo string, ttl time.Duration) (*models.OAuthState, error) {
+	state, err := utils.GenerateUserToken()
+	if err != nil {
+		return nil, err
+	}
+	binding, err := utils.GenerateUserToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := utils.GeneratePKCEVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stored := &models.OAuthState{
+		State:        state,
+		Provider:     provider,
+		Binding:      binding,
+		CodeVerifier: verifier,
+		LinkUser:     linkUserID,
+		ReturnTo:     returnTo,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(ttl),
+	}
+
+	var linkUser sql.NullString
+	if linkUserID != "" {
+		linkUser = sql.NullString{String: linkUserID, Valid: true}
+	}
+
+	err = utils.ExecuteInTransaction(osr.db, func(tx *sql.Tx) error {
+		// Abandoned flows are never called back, so clear them out as new ones start
+		_, err := tx.Exec("DELETE FROM oauth_states WHERE expires_at <= ?", now)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO oauth_states (state_hash, provider, binding_hash, code_verifier, link_user_id, return_to, created_at, expires_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			utils.HashUserToken(state), provider, utils.HashUserToken(binding), verifier, linkUser, returnTo, now, stored.ExpiresAt,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// ConsumeState removes a state and returns it if it is unexpired and was started by the
+// browser presenting binding. A state is deleted on first use whether or not the checks
+// pass, and every failure gives the same error so callers cannot tell them apart.
+func (osr *OAuthStateRepository) ConsumeState(state, binding string) (*models.OAuthState, error) {
+	stateHash := utils.HashUserToken(state)
+
+	var bindingHash string
+	stored, err := utils.ExecuteInTransactionWithResult(osr.db, func(tx *sql.Tx) (*models.OAuthState, error) {
+		stored := &models.OAuthState{State: state}
+		var linkUser sql.NullString
+		err := tx.QueryRow(
+			`SELECT provider, binding_hash, code_verifier, link_user_id, return_to, created_at, expires_at
+			FROM oauth_states WHERE state_hash = ?`,
+			stateHash,
+		).Scan(&stored.Provider, &bindingHash, &stored.CodeVerifier, &linkUser, &stored.ReturnTo, &stored.CreatedAt, &stored.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		stored.LinkUser = linkUser.String
+
+		_, err = tx.Exec("DELETE FROM oauth_states WHERE state_hash = ?", stateHash)
+		return stored, err
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidOAuthState
+		}
+		return nil, err
+	}
+
+	// Checked after the delete is committed, so a failed attempt still burns the state
+	if !time.Now().Before(stored.ExpiresAt) ||
+		subtle.ConstantTimeCompare([]byte(utils.HashUserToken(binding)), []byte(bindingHash)) != 1 {
+		return nil, ErrInvalidOAuthState
+	}
+
+	stored.Binding = binding
+	return stored, nil
+}
@@ -0,0 +1,100 @@
+//go:build sqlite_fts5
+
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConsumeState(t *testing.T) {
+	osr := NewOAuthStateRepository(openTestDB(t))
+
+	created, err := osr.CreateState("github", "", "/post/1", time.Minute)
+	if err != nil {
+		t.Fatalf("CreateState: %v", err)
+	}
+	if created.State == "" || created.Binding == "" || created.State == created.Binding {
+		t.Fatalf("CreateState returned state %q and binding %q, want two different tokens", created.State, created.Binding)
+	}
+	if created.CodeVerifier == "" {
+		t.Fatal("CreateState returned no PKCE verifier")
+	}
+
+	consumed, err := osr.ConsumeState(created.State, created.Binding)
+	if err != nil {
+		t.Fatalf("ConsumeState: %v", err)
+	}
+	if consumed.Provider != "github" || consumed.ReturnTo != "/post/1" || consumed.LinkUser != "" {
+		t.Errorf("ConsumeState = %+v, want the github login returning to /post/1", consumed)
+	}
+	// The verifier must be the one the challenge in the authorization URL was made from
+	if consumed.CodeVerifier != created.CodeVerifier {
+		t.Errorf("ConsumeState verifier = %q, want %q", consumed.CodeVerifier, created.CodeVerifier)
+	}
+
+	if _, err := osr.ConsumeState(created.State, created.Binding); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("second ConsumeState error = %v, want ErrInvalidOAuthState", err)
+	}
+}
+
+func TestConsumeStateRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		consume func(state, binding string) (string, string)
+	}{
+		{
+			name:    "binding from another browser",
+			ttl:     time.Minute,
+			consume: func(state, binding string) (string, string) { return state, "another-browser" },
+		},
+		{
+			name:    "missing binding",
+			ttl:     time.Minute,
+			consume: func(state, binding string) (string, string) { return state, "" },
+		},
+		{
+			name:    "unknown state",
+			ttl:     time.Minute,
+			consume: func(state, binding string) (string, string) { return "unknown", binding },
+		},
+		{
+			name:    "expired state",
+			ttl:     -time.Second,
+			consume: func(state, binding string) (string, string) { return state, binding },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			osr := NewOAuthStateRepository(openTestDB(t))
+			created, err := osr.CreateState("google", "", "", tt.ttl)
+			if err != nil {
+				t.Fatalf("CreateState: %v", err)
+			}
+
+			state, binding := tt.consume(created.State, created.Binding)
+			if _, err := osr.ConsumeState(state, binding); !errors.Is(err, ErrInvalidOAuthState) {
+				t.Fatalf("ConsumeState error = %v, want ErrInvalidOAuthState", err)
+			}
+		})
+	}
+}
+
+func TestConsumeStateBurnsStateOnWrongBinding(t *testing.T) {
+	osr := NewOAuthStateRepository(openTestDB(t))
+	created, err := osr.CreateState("google", "", "", time.Minute)
+	if err != nil {
+		t.Fatalf("CreateState: %v", err)
+	}
+
+	if _, err := osr.ConsumeState(created.State, "another-browser"); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Fatalf("ConsumeState with a wrong binding error = %v, want ErrInvalidOAuthState", err)
+	}
+	// A state guessed or stolen without its cookie cannot be retried with the right one
+	if _, err := osr.ConsumeState(created.State, created.Binding); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("ConsumeState after a failed attempt error = %v, want ErrInvalidOAuthState", err)
+	}
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// Characters of a token kept in the clear so users can recognise it, prefix included
+const personalTokenVisibleLength = len(models.PersonalTokenPrefix) + 4
+
+// PersonalTokenRepository stores the personal access tokens users create for scripts and bots
+type PersonalTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPersonalTokenRepository(db *sql.DB) *PersonalTokenRepository {
+	return &PersonalTokenRepository{db: db}
+}
+
+// CreateToken issues a token for the user and returns it with its stored details.
+// Scopes must already be validated. Expired tokens of the user are removed first, and
+// the user may hold at most maxTokens unexpired tokens.
+func (ptr *PersonalTokenRepository) CreateToken(userID, name string, scopes []string, ttl time.Duration, maxTokens int) (string, *models.PersonalAccessToken, error) {
+	secret, err := utils.GenerateUserToken()
+	if err != nil {
+		return "", nil, err
+	}
+	token := models.PersonalTokenPrefix + secret
+
+	now := time.Now()
+	stored := &models.PersonalAccessToken{
+		ID:        utils.GenerateUUIDToken(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    token[:personalTokenVisibleLength],
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	err = utils.ExecuteInTransaction(ptr.db, func(tx *sql.Tx) error {
+		_, err := tx.Exec("DELETE FROM personal_access_tokens WHERE user_id = ? AND expires_at <= ?", userID, now)
+		if err != nil {
+			return err
+		}
+
+		var count int
+		err = tx.QueryRow("SELECT COUNT(*) FROM personal_access_tokens WHERE user_id = ?", userID).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count >= maxTokens {
+			return ErrTokenLimitReached
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO personal_access_tokens (token_id, user_id, name, token_hash, token_prefix, scopes, created_at, expires_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			stored.ID, userID, name, utils.HashUserToken(token), stored.Prefix, strings.Join(scopes, " "), now, stored.ExpiresAt,
+		)
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, stored, nil
+}
+
+// GetByToken returns the unexpired token matching the presented value
+func (ptr *PersonalTokenRepository) GetByToken(token string) (*models.PersonalAccessToken, error) {
+	if !strings.HasPrefix(token, models.PersonalTokenPrefix) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	row := ptr.db.QueryRow(
+		`SELECT token_id, user_id, name, token_prefix, scopes, created_at, expires_at, last_used_at, last_used_ip
+		FROM personal_access_tokens WHERE token_hash = ? AND expires_at > ?`,
+		utils.HashUserToken(token), time.Now(),
+	)
+	stored, err := scanPersonalToken(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// ListTokens returns the user's unexpired tokens, newest first
+func (ptr *PersonalTokenRepository) ListTokens(userID string) ([]*models.PersonalAccessToken, error) {
+	rows, err := ptr.db.Query(
+		`SELECT token_id, user_id, name, token_prefix, scopes, created_at, expires_at, last_used_at, last_used_ip
+		FROM personal_access_tokens WHERE user_id = ? AND expires_at > ?
+		ORDER BY created_at DESC`,
+		userID, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*models.PersonalAccessToken
+	for rows.Next() {
+		stored, err := scanPersonalToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, stored)
+	}
+
+	return tokens, rows.Err()
+}
+
+// RevokeToken deletes one of the user's tokens by its public ID
+func (ptr *PersonalTokenRepository) RevokeToken(userID, tokenID string) error {
+	result, err := ptr.db.Exec("DELETE FROM personal_access_tokens WHERE token_id = ? AND user_id = ?", tokenID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// TouchToken records when and from where a token was last used
+func (ptr *PersonalTokenRepository) TouchToken(tokenID, ipAddress string) error {
+	_, err := ptr.db.Exec(
+		"UPDATE personal_access_tokens SET last_used_at = ?, last_used_ip = ? WHERE token_id = ?",
+		time.Now(), ipAddress, tokenID,
+	)
+	return err
+}
+
+// scanPersonalToken reads a token row selected in the column order used above
+func scanPersonalToken(row interface{ Scan(...any) error }) (*models.PersonalAccessToken, error) {
+	var stored models.PersonalAccessToken
+	var scopes string
+	var lastUsedAt sql.NullTime
+	err := row.Scan(&stored.ID, &stored.UserID, &stored.Name, &stored.Prefix, &scopes,
+		&stored.CreatedAt, &stored.ExpiresAt, &lastUsedAt, &stored.LastUsedIP)
+	if err != nil {
+		return nil, err
+	}
+
+	stored.Scopes = strings.Fields(scopes)
+	if lastUsedAt.Valid {
+		stored.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &stored, nil
+}
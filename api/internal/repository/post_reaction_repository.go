@@ -2,7 +2,6 @@ package repository
 
 import (
 	"database/sql"
-	"errors"
 	"time"
 
 	"github.com/PaulKerasidis/forum/internal/models"
@@ -120,7 +119,7 @@ func (prr *PostReactionRepository) validatePostExists(tx *sql.Tx, postID string)
 		return err
 	}
 	if exists == 0 {
-		return errors.New("post not found")
+		return ErrPostNotFound
 	}
 	return nil
 }
@@ -3,9 +3,11 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/PaulKerasidis/forum/config"
 	"github.com/PaulKerasidis/forum/internal/models"
 	"github.com/PaulKerasidis/forum/internal/utils"
 	"github.com/PaulKerasidis/forum/queries"
@@ -21,7 +23,7 @@ func NewPostsRepository(db *sql.DB) *PostsRepository {
 }
 
 // CRUD methods
-func (pr *PostsRepository) CreatePost(userID string, content string, categoryIDs []string) (*models.CreatePostResponse, error) {
+func (pr *PostsRepository) CreatePost(userID, title, content string, categoryIDs []string) (*models.CreatePostResponse, error) {
 	return utils.ExecuteInTransactionWithResult(pr.db, func(tx *sql.Tx) (*models.CreatePostResponse, error) {
 		// Generate UUID for the post
 		postID := utils.GenerateUUIDToken()
@@ -29,8 +31,8 @@ func (pr *PostsRepository) CreatePost(userID string, content string, categoryIDs
 
 		// Insert post
 		_, err := tx.Exec(
-			"INSERT INTO posts (post_id, user_id, content, created_at) VALUES (?, ?, ?, ?)",
-			postID, userID, content, createdAt,
+			"INSERT INTO posts (post_id, user_id, title, content, created_at) VALUES (?, ?, ?, ?, ?)",
+			postID, userID, title, content, createdAt,
 		)
 		if err != nil {
 			return nil, err
@@ -55,26 +57,26 @@ func (pr *PostsRepository) CreatePost(userID string, content string, categoryIDs
 	})
 }
 
-func (pr *PostsRepository) UpdatePost(postID, userID, content string, categoryIDs []string) error {
+func (pr *PostsRepository) UpdatePost(postID string, actor models.Actor, title, content string, categoryIDs []string) error {
 	return utils.ExecuteInTransaction(pr.db, func(tx *sql.Tx) error {
-		// Check if user owns the post
-		var ownerID string
-		err := tx.QueryRow("SELECT user_id FROM posts WHERE post_id = ?", postID).Scan(&ownerID)
+		// Check if user owns the post or may moderate it, keeping the old version for the audit log
+		before, err := readPostSnapshot(tx, postID)
 		if err != nil {
 			if err == sql.ErrNoRows {
-				return errors.New("post not found")
+				return ErrPostNotFound
 			}
 			return err
 		}
 
-		if ownerID != userID {
-			return errors.New("unauthorized: you can only update your own posts")
+		if before.UserID != actor.UserID && !actor.CanModerate() {
+			return ErrNotPostAuthor
 		}
 
 		now := time.Now()
 
-		// 1. Update post content AND set updated_at
-		_, err = tx.Exec("UPDATE posts SET content = ?, updated_at = ? WHERE post_id = ?", content, now, postID)
+		// 1. Update post title, content AND set updated_at, recording who edited it
+		_, err = tx.Exec("UPDATE posts SET title = ?, content = ?, updated_at = ?, updated_by = ?, updated_by_role = ? WHERE post_id = ?",
+			title, content, now, actor.UserID, actor.Role, postID)
 		if err != nil {
 			return err
 		}
@@ -96,23 +98,31 @@ func (pr *PostsRepository) UpdatePost(postID, userID, content string, categoryID
 			}
 		}
 
-		return nil
+		// 4. AUDIT - record the edit, including any category change
+		after, err := readPostSnapshot(tx, postID)
+		if err != nil {
+			return err
+		}
+		return writeAudit(tx, actor, models.AuditPostEdit, models.AuditTargetPost, postID, before, after)
 	})
 }
 
-func (pr *PostsRepository) DeletePost(postID, userID string) error {
+func (pr *PostsRepository) DeletePost(postID string, actor models.Actor) error {
 	return utils.ExecuteInTransaction(pr.db, func(tx *sql.Tx) error {
-		var ownerID string
-		err := tx.QueryRow("SELECT user_id FROM posts WHERE post_id = ?", postID).Scan(&ownerID)
+		before, err := readPostSnapshot(tx, postID)
 		if err != nil {
 			if err == sql.ErrNoRows {
-				return errors.New("post not found")
+				return ErrPostNotFound
 			}
 			return err
 		}
 
-		if ownerID != userID {
-			return errors.New("unauthorized: you can only delete your own posts")
+		if before.UserID != actor.UserID && !actor.CanModerate() {
+			return ErrNotPostAuthor
+		}
+
+		if before.UserID != actor.UserID {
+			slog.Info("Post removed by moderator", "post_id", postID, "author_id", before.UserID, "moderator_role", actor.Role, "moderator_id", actor.UserID)
 		}
 
 		// Delete the post (CASCADE will handle related records)
@@ -121,7 +131,8 @@ func (pr *PostsRepository) DeletePost(postID, userID string) error {
 			return err
 		}
 
-		return nil
+		// The snapshot is all that is left of the post and its comment count once the cascade runs
+		return writeAudit(tx, actor, models.AuditPostDelete, models.AuditTargetPost, postID, before, nil)
 	})
 }
 
@@ -140,7 +151,7 @@ func (pr *PostsRepository) GetPostByID(postID string, userID *string) (*models.P
 	post, err := pr.scanAndParsePost(row, userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("post not found")
+			return nil, ErrPostNotFound
 		}
 		return nil, err
 	}
@@ -149,7 +160,7 @@ func (pr *PostsRepository) GetPostByID(postID string, userID *string) (*models.P
 }
 
 // GetAllPosts retrieves all posts (sorted by newest first by default, or custom sorting)
-func (pr *PostsRepository) GetAllPosts(limit, offset int, userID *string, options utils.SortOptions) ([]*models.Post, error) {
+func (pr *PostsRepository) GetAllPosts(limit, offset int, userID *string, options utils.SortOptions) ([]*models.PostSummary, error) {
 	// Prepare arguments
 	var userIDArg interface{}
 	if userID != nil {
@@ -168,9 +179,9 @@ func (pr *PostsRepository) GetAllPosts(limit, offset int, userID *string, option
 	}
 	defer rows.Close()
 
-	var posts []*models.Post
+	var posts []*models.PostSummary
 	for rows.Next() {
-		post, err := pr.scanAndParsePost(rows, userID)
+		post, err := pr.scanPostSummary(rows, userID)
 		if err != nil {
 			return nil, err
 		}
@@ -181,7 +192,7 @@ func (pr *PostsRepository) GetAllPosts(limit, offset int, userID *string, option
 }
 
 // GetPostsByCategory retrieves posts by category (sorted by newest first by default, or custom sorting)
-func (pr *PostsRepository) GetPostsByCategory(categoryID string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.Post, error) {
+func (pr *PostsRepository) GetPostsByCategory(categoryID string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.PostSummary, error) {
 	// Prepare arguments
 	var userIDArg interface{}
 	if userID != nil {
@@ -200,9 +211,9 @@ func (pr *PostsRepository) GetPostsByCategory(categoryID string, limit, offset i
 	}
 	defer rows.Close()
 
-	var posts []*models.Post
+	var posts []*models.PostSummary
 	for rows.Next() {
-		post, err := pr.scanAndParsePost(rows, userID)
+		post, err := pr.scanPostSummary(rows, userID)
 		if err != nil {
 			return nil, err
 		}
@@ -216,7 +227,7 @@ func (pr *PostsRepository) GetPostsByCategory(categoryID string, limit, offset i
 // Profiling methods for user-specific posts
 // ...
 // GetPostsByUser retrieves posts by user (sorted by newest first by default, or custom sorting)
-func (pr *PostsRepository) GetPostsByUser(targetUserID string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.Post, error) {
+func (pr *PostsRepository) GetPostsByUser(targetUserID string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.PostSummary, error) {
 	// Prepare arguments
 	var userIDArg interface{}
 	if userID != nil {
@@ -235,9 +246,9 @@ func (pr *PostsRepository) GetPostsByUser(targetUserID string, limit, offset int
 	}
 	defer rows.Close()
 
-	var posts []*models.Post
+	var posts []*models.PostSummary
 	for rows.Next() {
-		post, err := pr.scanAndParsePost(rows, userID)
+		post, err := pr.scanPostSummary(rows, userID)
 		if err != nil {
 			return nil, err
 		}
@@ -248,7 +259,7 @@ func (pr *PostsRepository) GetPostsByUser(targetUserID string, limit, offset int
 }
 
 // GetPostsLikedByUser retrieves posts liked by user (sorted by newest first by default, or custom sorting)
-func (pr *PostsRepository) GetPostsLikedByUser(targetUserID string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.Post, error) {
+func (pr *PostsRepository) GetPostsLikedByUser(targetUserID string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.PostSummary, error) {
 	// Prepare arguments
 	var userIDArg interface{}
 	if userID != nil {
@@ -267,9 +278,9 @@ func (pr *PostsRepository) GetPostsLikedByUser(targetUserID string, limit, offse
 	}
 	defer rows.Close()
 
-	var posts []*models.Post
+	var posts []*models.PostSummary
 	for rows.Next() {
-		post, err := pr.scanAndParsePost(rows, userID)
+		post, err := pr.scanPostSummary(rows, userID)
 		if err != nil {
 			return nil, err
 		}
@@ -280,7 +291,7 @@ func (pr *PostsRepository) GetPostsLikedByUser(targetUserID string, limit, offse
 }
 
 // GetPostsCommentedByUser retrieves posts commented by user (sorted by newest first by default, or custom sorting)
-func (pr *PostsRepository) GetPostsCommentedByUser(targetUserID string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.Post, error) {
+func (pr *PostsRepository) GetPostsCommentedByUser(targetUserID string, limit, offset int, userID *string, options utils.SortOptions) ([]*models.PostSummary, error) {
 	// Prepare arguments
 	var userIDArg interface{}
 	if userID != nil {
@@ -299,9 +310,9 @@ func (pr *PostsRepository) GetPostsCommentedByUser(targetUserID string, limit, o
 	}
 	defer rows.Close()
 
-	var posts []*models.Post
+	var posts []*models.PostSummary
 	for rows.Next() {
-		post, err := pr.scanAndParsePost(rows, userID)
+		post, err := pr.scanPostSummary(rows, userID)
 		if err != nil {
 			return nil, err
 		}
@@ -347,7 +358,7 @@ func (pr *PostsRepository) GetCountCommentedPostByUser(userID string) (int, erro
 	err := pr.db.QueryRow(`
 		SELECT COUNT(DISTINCT p.post_id) 
 		FROM posts p
-		JOIN comments c ON p.post_id = c.post_id
+		JOIN comments c ON p.post_id = c.post_id AND c.deleted_at IS NULL
 		WHERE c.user_id = ?
 	`, userID).Scan(&count)
 	if err != nil {
@@ -391,9 +402,11 @@ func (pr *PostsRepository) scanAndParsePost(rows interface{}, userID *string) (*
 			&post.ID,
 			&post.UserID,
 			&post.Username,
+			&post.Title,
 			&post.Content,
 			&post.CreatedAt,
 			&updatedAt,
+			&post.UpdatedByRole,
 			&post.LikeCount,
 			&post.DislikeCount,
 			&post.CommentCount,
@@ -405,9 +418,11 @@ func (pr *PostsRepository) scanAndParsePost(rows interface{}, userID *string) (*
 			&post.ID,
 			&post.UserID,
 			&post.Username,
+			&post.Title,
 			&post.Content,
 			&post.CreatedAt,
 			&updatedAt,
+			&post.UpdatedByRole,
 			&post.LikeCount,
 			&post.DislikeCount,
 			&post.CommentCount,
@@ -456,3 +471,78 @@ func (pr *PostsRepository) scanAndParsePost(rows interface{}, userID *string) (*
 
 	return &post, nil
 }
+
+// Helper method to scan and parse a single post summary row
+func (pr *PostsRepository) scanPostSummary(rows *sql.Rows, userID *string) (*models.PostSummary, error) {
+	var post models.PostSummary
+	var categoriesStr sql.NullString
+	var userReaction sql.NullInt64
+	var updatedAt sql.NullTime
+	var lastCommentAt sql.NullString
+	var contentLength int
+
+	err := rows.Scan(
+		&post.ID,
+		&post.UserID,
+		&post.Username,
+		&post.Title,
+		&post.Excerpt,
+		&contentLength,
+		&post.CreatedAt,
+		&updatedAt,
+		&lastCommentAt,
+		&post.LikeCount,
+		&post.DislikeCount,
+		&post.CommentCount,
+		&categoriesStr,
+		&userReaction,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cut the excerpt to the configured length
+	post.Excerpt = utils.BuildExcerpt(post.Excerpt, contentLength, config.Config.PostExcerptLength)
+
+	// Parse categories directly
+	if categoriesStr.Valid && categoriesStr.String != "" {
+		categoryPairs := strings.Split(categoriesStr.String, ",")
+		for _, pair := range categoryPairs {
+			parts := strings.Split(strings.TrimSpace(pair), ":")
+			if len(parts) == 2 {
+				post.Categories = append(post.Categories, models.PostCategory{
+					ID:   parts[0],
+					Name: parts[1],
+				})
+			}
+		}
+	}
+
+	// Handle UpdatedAt directly
+	if updatedAt.Valid {
+		post.UpdatedAt = &updatedAt.Time
+	}
+
+	// Last activity is the newest of creation, edit and latest comment
+	post.LastActivityAt = post.CreatedAt
+	if post.UpdatedAt != nil && post.UpdatedAt.After(post.LastActivityAt) {
+		post.LastActivityAt = *post.UpdatedAt
+	}
+	if lastCommentAt.Valid {
+		// MAX() over a subquery loses the column type, so the driver hands back text
+		if commentedAt, err := utils.ParseSQLiteTimestamp(lastCommentAt.String); err == nil && commentedAt.After(post.LastActivityAt) {
+			post.LastActivityAt = commentedAt
+		}
+	}
+
+	// Handle UserReaction
+	if userReaction.Valid {
+		reactionType := int(userReaction.Int64)
+		post.UserReaction = &reactionType
+	}
+
+	// Handle IsOwner
+	post.IsOwner = (userID != nil && post.UserID == *userID)
+
+	return &post, nil
+}
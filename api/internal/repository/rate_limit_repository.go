@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// rateLimitPurgeInterval is how often refilled buckets are deleted
+const rateLimitPurgeInterval = time.Minute
+
+// RateLimitRepository keeps the rate limiter's token buckets in the database, so every
+// API instance sharing it enforces the same limits
+type RateLimitRepository struct {
+	db *sql.DB
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+func NewRateLimitRepository(db *sql.DB) *RateLimitRepository {
+	return &RateLimitRepository{db: db, lastPurge: time.Now()}
+}
+
+// Take refills the bucket for key and takes a token from it
+func (rlr *RateLimitRepository) Take(key string, limit models.RateLimit) (models.RateLimitResult, error) {
+	rlr.purgeRefilled()
+
+	return utils.ExecuteInTransactionWithResult(rlr.db, func(tx *sql.Tx) (models.RateLimitResult, error) {
+		now := time.Now()
+
+		// Write first so the transaction holds the write lock before it reads the bucket;
+		// concurrent requests for the same client then wait their turn instead of both
+		// spending the same token. A new bucket starts full.
+		_, err := tx.Exec(`
+			INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at, full_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(bucket_key) DO NOTHING`,
+			key, float64(limit.Requests), now, now)
+		if err != nil {
+			return models.RateLimitResult{}, err
+		}
+
+		var tokens float64
+		var updatedAt time.Time
+		err = tx.QueryRow("SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = ?", key).Scan(&tokens, &updatedAt)
+		if err != nil {
+			return models.RateLimitResult{}, err
+		}
+
+		tokens, result := limit.Take(tokens, now.Sub(updatedAt))
+
+		_, err = tx.Exec("UPDATE rate_limit_buckets SET tokens = ?, updated_at = ?, full_at = ? WHERE bucket_key = ?",
+			tokens, now, now.Add(result.Reset), key)
+		if err != nil {
+			return models.RateLimitResult{}, err
+		}
+
+		return result, nil
+	})
+}
+
+// purgeRefilled deletes the buckets of clients that have been idle long enough to refill
+func (rlr *RateLimitRepository) purgeRefilled() {
+	rlr.mu.Lock()
+	now := time.Now()
+	if now.Sub(rlr.lastPurge) < rateLimitPurgeInterval {
+		rlr.mu.Unlock()
+		return
+	}
+	rlr.lastPurge = now
+	rlr.mu.Unlock()
+
+	if _, err := rlr.db.Exec("DELETE FROM rate_limit_buckets WHERE full_at <= ?", now); err != nil {
+		slog.Warn("Failed to purge rate limit buckets", "err", err)
+	}
+}
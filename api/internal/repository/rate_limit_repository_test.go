@@ -0,0 +1,42 @@
+//go:build sqlite_fts5
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+func TestRateLimitRepositoryTake(t *testing.T) {
+	db := openTestDB(t)
+	limit := models.RateLimit{Requests: 3, Window: time.Hour}
+
+	// Two API instances sharing the database share each client's bucket
+	instances := []*RateLimitRepository{NewRateLimitRepository(db), NewRateLimitRepository(db)}
+	for i := 0; i < limit.Requests; i++ {
+		result, err := instances[i%2].Take("login|ip:10.0.0.1", limit)
+		if err != nil {
+			t.Fatalf("Take: %v", err)
+		}
+		if !result.Allowed || result.Remaining != limit.Requests-1-i {
+			t.Errorf("request %d = %+v, want allowed with %d remaining", i+1, result, limit.Requests-1-i)
+		}
+	}
+
+	result, err := instances[1].Take("login|ip:10.0.0.1", limit)
+	if err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	if result.Allowed {
+		t.Error("request over the limit was allowed on the other instance")
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > limit.Window/time.Duration(limit.Requests) {
+		t.Errorf("RetryAfter = %v, want up to one token's refill time", result.RetryAfter)
+	}
+
+	if result, _ := instances[0].Take("login|ip:10.0.0.2", limit); !result.Allowed {
+		t.Error("another client's first request was refused")
+	}
+}
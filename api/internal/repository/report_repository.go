@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// reportSelectQuery is the shared SELECT for the moderation queue - callers append WHERE/ORDER BY/LIMIT.
+// The target is joined by type so moderators see what was reported and who wrote it.
+const reportSelectQuery = `
+		SELECT
+			r.report_id,
+			COALESCE(r.reporter_id, ''),
+			COALESCE(reporter.username, ''),
+			r.target_type,
+			r.target_id,
+			r.reason,
+			r.details,
+			COALESCE(p.post_id, c.post_id, ''),
+			COALESCE(p.title, c.content, author.username, ''),
+			COALESCE(author.user_id, ''),
+			COALESCE(author.username, ''),
+			(SELECT COUNT(*) FROM reports same
+				WHERE same.target_type = r.target_type AND same.target_id = r.target_id
+				AND same.status IN ('open', 'claimed')) as open_reports_on_target,
+			r.status,
+			COALESCE(r.claimed_by, ''),
+			COALESCE(claimer.username, ''),
+			r.claimed_at,
+			COALESCE(r.resolved_by, ''),
+			COALESCE(resolver.username, ''),
+			r.resolved_at,
+			r.resolution_note,
+			r.created_at
+		FROM reports r
+		LEFT JOIN users reporter ON r.reporter_id = reporter.user_id
+		LEFT JOIN users claimer ON r.claimed_by = claimer.user_id
+		LEFT JOIN users resolver ON r.resolved_by = resolver.user_id
+		LEFT JOIN posts p ON r.target_type = 'post' AND p.post_id = r.target_id
+		LEFT JOIN comments c ON r.target_type = 'comment' AND c.comment_id = r.target_id
+		LEFT JOIN users author ON author.user_id = CASE r.target_type
+			WHEN 'post' THEN p.user_id
+			WHEN 'comment' THEN c.user_id
+			ELSE r.target_id
+		END`
+
+type ReportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// CreateReport files a report on a post, comment or user
+func (rr *ReportRepository) CreateReport(reporterID string, req models.CreateReportRequest) (string, error) {
+	return utils.ExecuteInTransactionWithResult(rr.db, func(tx *sql.Tx) (string, error) {
+		ownerID, err := reportTargetOwner(tx, req.TargetType, req.TargetID)
+		if err != nil {
+			return "", err
+		}
+
+		if ownerID == reporterID {
+			return "", ErrReportOwnContent
+		}
+
+		// One pending report per reporter and target (also enforced by a unique index)
+		var pending int
+		err = tx.QueryRow(`SELECT COUNT(*) FROM reports
+			WHERE reporter_id = ? AND target_type = ? AND target_id = ? AND status IN ('open', 'claimed')`,
+			reporterID, req.TargetType, req.TargetID).Scan(&pending)
+		if err != nil {
+			return "", err
+		}
+		if pending > 0 {
+			return "", ErrAlreadyReported
+		}
+
+		reportID := utils.GenerateUUIDToken()
+		_, err = tx.Exec(`INSERT INTO reports (report_id, reporter_id, target_type, target_id, reason, details, status, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			reportID, reporterID, req.TargetType, req.TargetID, req.Reason, req.Details, models.ReportStatusOpen, time.Now())
+		if err != nil {
+			return "", err
+		}
+
+		return reportID, nil
+	})
+}
+
+// reportTargetOwner returns the author of a reported post or comment, or the reported user's own ID
+func reportTargetOwner(tx *sql.Tx, targetType, targetID string) (string, error) {
+	var query string
+	switch targetType {
+	case models.ReportTargetPost:
+		query = "SELECT user_id FROM posts WHERE post_id = ?"
+	case models.ReportTargetComment:
+		query = "SELECT user_id FROM comments WHERE comment_id = ? AND deleted_at IS NULL"
+	case models.ReportTargetUser:
+		query = "SELECT user_id FROM users WHERE user_id = ?"
+	default:
+		return "", ErrInvalidReportTarget
+	}
+
+	var ownerID string
+	err := tx.QueryRow(query, targetID).Scan(&ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrReportTargetNotFound
+		}
+		return "", err
+	}
+	return ownerID, nil
+}
+
+// GetReports returns a page of the moderation queue. Pending reports are oldest first
+// so nothing waits forever; closed reports are newest first.
+func (rr *ReportRepository) GetReports(filters models.ReportFilters, limit, offset int) ([]*models.Report, error) {
+	whereClause, args := buildReportFilters(filters)
+
+	order := " ORDER BY r.created_at DESC"
+	if filters.Status == models.ReportStatusPending || filters.Status == models.ReportStatusOpen || filters.Status == models.ReportStatusClaimed {
+		order = " ORDER BY r.created_at ASC"
+	}
+
+	args = append(args, limit, offset)
+	rows, err := rr.db.Query(reportSelectQuery+whereClause+order+" LIMIT ? OFFSET ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*models.Report
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+// GetCountReports counts the reports matching the queue filters
+func (rr *ReportRepository) GetCountReports(filters models.ReportFilters) (int, error) {
+	whereClause, args := buildReportFilters(filters)
+
+	var count int
+	err := rr.db.QueryRow("SELECT COUNT(*) FROM reports r"+whereClause, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func buildReportFilters(filters models.ReportFilters) (string, []interface{}) {
+	whereClause := " WHERE 1=1"
+	var args []interface{}
+
+	switch filters.Status {
+	case models.ReportStatusAll:
+	case models.ReportStatusPending:
+		whereClause += " AND r.status IN ('open', 'claimed')"
+	default:
+		whereClause += " AND r.status = ?"
+		args = append(args, filters.Status)
+	}
+
+	if filters.TargetType != "" {
+		whereClause += " AND r.target_type = ?"
+		args = append(args, filters.TargetType)
+	}
+
+	return whereClause, args
+}
+
+// GetReportByID retrieves a single report
+func (rr *ReportRepository) GetReportByID(reportID string) (*models.Report, error) {
+	report, err := scanReport(rr.db.QueryRow(reportSelectQuery+" WHERE r.report_id = ?", reportID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+// ClaimReport assigns a pending report to the acting moderator.
+// Admins may take over a report claimed by someone else.
+func (rr *ReportRepository) ClaimReport(reportID string, actor models.Actor) error {
+	return utils.ExecuteInTransaction(rr.db, func(tx *sql.Tx) error {
+		if _, err := checkReportPending(tx, reportID, actor); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec("UPDATE reports SET status = ?, claimed_by = ?, claimed_at = ? WHERE report_id = ?",
+			models.ReportStatusClaimed, actor.UserID, time.Now(), reportID)
+		return err
+	})
+}
+
+// CloseReport resolves or dismisses a pending report, recording who closed it and why
+func (rr *ReportRepository) CloseReport(reportID string, actor models.Actor, status, note string) error {
+	if status != models.ReportStatusResolved && status != models.ReportStatusDismissed {
+		return ErrInvalidReportStatus
+	}
+
+	return utils.ExecuteInTransaction(rr.db, func(tx *sql.Tx) error {
+		before, err := checkReportPending(tx, reportID, actor)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE reports SET status = ?, resolved_by = ?, resolved_at = ?, resolution_note = ? WHERE report_id = ?",
+			status, actor.UserID, time.Now(), note, reportID)
+		if err != nil {
+			return err
+		}
+
+		action := models.AuditReportResolve
+		if status == models.ReportStatusDismissed {
+			action = models.AuditReportDismiss
+		}
+		return writeAudit(tx, actor, action, models.AuditTargetReport, reportID,
+			map[string]string{"status": before},
+			map[string]string{"status": status, "resolution_note": note})
+	})
+}
+
+// checkReportPending makes sure a report is still pending and not claimed by another moderator,
+// returning its current status
+func checkReportPending(tx *sql.Tx, reportID string, actor models.Actor) (string, error) {
+	var status, claimedBy string
+	err := tx.QueryRow("SELECT status, COALESCE(claimed_by, '') FROM reports WHERE report_id = ?", reportID).
+		Scan(&status, &claimedBy)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrReportNotFound
+		}
+		return "", err
+	}
+
+	if status != models.ReportStatusOpen && status != models.ReportStatusClaimed {
+		return "", ErrReportClosed
+	}
+
+	if status == models.ReportStatusClaimed && claimedBy != actor.UserID && !models.HasRole(actor.Role, models.RoleAdmin) {
+		return "", ErrReportClaimed
+	}
+
+	return status, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanReport scans a row of reportSelectQuery
+func scanReport(scanner rowScanner) (*models.Report, error) {
+	var report models.Report
+	var claimedAt, resolvedAt sql.NullTime
+
+	err := scanner.Scan(
+		&report.ID,
+		&report.ReporterID,
+		&report.ReporterUsername,
+		&report.TargetType,
+		&report.TargetID,
+		&report.Reason,
+		&report.Details,
+		&report.PostID,
+		&report.TargetPreview,
+		&report.TargetAuthorID,
+		&report.TargetAuthorName,
+		&report.OpenReportsOnTarget,
+		&report.Status,
+		&report.ClaimedBy,
+		&report.ClaimedByName,
+		&claimedAt,
+		&report.ResolvedBy,
+		&report.ResolvedByName,
+		&resolvedAt,
+		&report.ResolutionNote,
+		&report.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The author join only matches while the post, comment or user still exists
+	report.TargetExists = report.TargetAuthorID != ""
+
+	if claimedAt.Valid {
+		report.ClaimedAt = &claimedAt.Time
+	}
+	if resolvedAt.Valid {
+		report.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &report, nil
+}
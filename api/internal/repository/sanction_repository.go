@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// sanctionSelectQuery is the shared SELECT for sanctions - callers append WHERE/ORDER BY
+const sanctionSelectQuery = `
+		SELECT
+			s.sanction_id,
+			s.user_id,
+			s.sanction_type,
+			s.reason,
+			s.expires_at,
+			COALESCE(s.issued_by, ''),
+			COALESCE(issuer.username, ''),
+			s.created_at,
+			COALESCE(s.lifted_by, ''),
+			s.lifted_at
+		FROM user_sanctions s
+		LEFT JOIN users issuer ON s.issued_by = issuer.user_id`
+
+type SanctionRepository struct {
+	db *sql.DB
+}
+
+func NewSanctionRepository(db *sql.DB) *SanctionRepository {
+	return &SanctionRepository{db: db}
+}
+
+// CreateSanction bans, suspends or mutes a user. Moderators can only sanction users
+// with a lower role than their own, and only admins can ban.
+func (sr *SanctionRepository) CreateSanction(userID string, actor models.Actor, req models.CreateSanctionRequest) (*models.Sanction, error) {
+	if req.Type == models.SanctionBan && !models.HasRole(actor.Role, models.RoleAdmin) {
+		return nil, ErrBanRequiresAdmin
+	}
+
+	return utils.ExecuteInTransactionWithResult(sr.db, func(tx *sql.Tx) (*models.Sanction, error) {
+		var target userRoleSnapshot
+		err := tx.QueryRow("SELECT username, role FROM users WHERE user_id = ?", userID).Scan(&target.Username, &target.Role)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrUserNotFound
+			}
+			return nil, err
+		}
+
+		if models.HasRole(target.Role, actor.Role) {
+			return nil, ErrSanctionOutranked
+		}
+
+		now := time.Now()
+		sanction := &models.Sanction{
+			ID:        utils.GenerateUUIDToken(),
+			UserID:    userID,
+			Type:      req.Type,
+			Reason:    req.Reason,
+			IssuedBy:  actor.UserID,
+			CreatedAt: now,
+			Active:    true,
+		}
+		if req.Type != models.SanctionBan {
+			expiresAt := now.Add(time.Duration(req.DurationHours) * time.Hour)
+			sanction.ExpiresAt = &expiresAt
+		}
+
+		_, err = tx.Exec(`INSERT INTO user_sanctions (sanction_id, user_id, sanction_type, reason, expires_at, issued_by, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			sanction.ID, userID, sanction.Type, sanction.Reason, sanction.ExpiresAt, actor.UserID, now)
+		if err != nil {
+			return nil, err
+		}
+
+		action := models.AuditUserMute
+		switch req.Type {
+		case models.SanctionBan:
+			action = models.AuditUserBan
+		case models.SanctionSuspension:
+			action = models.AuditUserSuspend
+		}
+		if err := writeAudit(tx, actor, action, models.AuditTargetUser, userID, target, sanction); err != nil {
+			return nil, err
+		}
+
+		return sanction, nil
+	})
+}
+
+// LiftSanction ends a sanction early. Only admins can lift a ban.
+func (sr *SanctionRepository) LiftSanction(sanctionID string, actor models.Actor) error {
+	return utils.ExecuteInTransaction(sr.db, func(tx *sql.Tx) error {
+		before, err := scanSanction(tx.QueryRow(sanctionSelectQuery+" WHERE s.sanction_id = ?", sanctionID))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrSanctionNotFound
+			}
+			return err
+		}
+
+		if !before.Active {
+			return ErrSanctionNotActive
+		}
+		if before.Type == models.SanctionBan && !models.HasRole(actor.Role, models.RoleAdmin) {
+			return ErrLiftRequiresAdmin
+		}
+
+		now := time.Now()
+		_, err = tx.Exec("UPDATE user_sanctions SET lifted_by = ?, lifted_at = ? WHERE sanction_id = ?", actor.UserID, now, sanctionID)
+		if err != nil {
+			return err
+		}
+
+		after := *before
+		after.LiftedBy = actor.UserID
+		after.LiftedAt = &now
+		after.Active = false
+		return writeAudit(tx, actor, models.AuditUserLift, models.AuditTargetUser, before.UserID, before, after)
+	})
+}
+
+// GetSanctionsByUser returns a user's sanction history, newest first
+func (sr *SanctionRepository) GetSanctionsByUser(userID string) ([]*models.Sanction, error) {
+	return sr.querySanctions(" WHERE s.user_id = ? ORDER BY s.created_at DESC", userID)
+}
+
+// GetActiveSanctions returns the sanctions currently in force on a user
+func (sr *SanctionRepository) GetActiveSanctions(userID string) ([]*models.Sanction, error) {
+	sanctions, err := sr.querySanctions(" WHERE s.user_id = ? AND s.lifted_at IS NULL", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []*models.Sanction
+	for _, sanction := range sanctions {
+		if sanction.Active {
+			active = append(active, sanction)
+		}
+	}
+	return active, nil
+}
+
+func (sr *SanctionRepository) querySanctions(whereClause string, args ...interface{}) ([]*models.Sanction, error) {
+	rows, err := sr.db.Query(sanctionSelectQuery+whereClause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sanctions []*models.Sanction
+	for rows.Next() {
+		sanction, err := scanSanction(rows)
+		if err != nil {
+			return nil, err
+		}
+		sanctions = append(sanctions, sanction)
+	}
+
+	return sanctions, rows.Err()
+}
+
+// scanSanction scans a row of sanctionSelectQuery
+func scanSanction(scanner rowScanner) (*models.Sanction, error) {
+	var sanction models.Sanction
+	var expiresAt, liftedAt sql.NullTime
+
+	err := scanner.Scan(
+		&sanction.ID,
+		&sanction.UserID,
+		&sanction.Type,
+		&sanction.Reason,
+		&expiresAt,
+		&sanction.IssuedBy,
+		&sanction.IssuedByName,
+		&sanction.CreatedAt,
+		&sanction.LiftedBy,
+		&liftedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		sanction.ExpiresAt = &expiresAt.Time
+	}
+	if liftedAt.Valid {
+		sanction.LiftedAt = &liftedAt.Time
+	}
+	sanction.Active = sanction.IsActive(time.Now())
+
+	return &sanction, nil
+}
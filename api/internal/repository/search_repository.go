@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/queries"
+)
+
+type SearchRepository struct {
+	db *sql.DB
+}
+
+// NewSearchRepository creates a new SearchRepository
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// SearchPosts returns posts matching the FTS5 expression, best match first
+func (sr *SearchRepository) SearchPosts(matchQuery string, filters models.SearchFilters, limit, offset int) ([]*models.SearchResult, error) {
+	whereClause, args := buildSearchWhere(queries.SearchMatchPostsWhere, matchQuery, filters)
+	args = append(args, limit, offset)
+
+	rows, err := sr.db.Query(queries.BuildSearchPostsQuery(whereClause), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*models.SearchResult
+	for rows.Next() {
+		result := &models.SearchResult{Type: "post"}
+		var categoriesStr sql.NullString
+		err := rows.Scan(
+			&result.PostID,
+			&result.UserID,
+			&result.Username,
+			&result.PostTitle,
+			&result.Snippet,
+			&result.CreatedAt,
+			&categoriesStr,
+			&result.Rank,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result.Categories = parseSearchCategories(categoriesStr)
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SearchComments returns comments matching the FTS5 expression, best match first
+func (sr *SearchRepository) SearchComments(matchQuery string, filters models.SearchFilters, limit, offset int) ([]*models.SearchResult, error) {
+	whereClause, args := buildSearchWhere(queries.SearchMatchCommentsWhere, matchQuery, filters)
+	args = append(args, limit, offset)
+
+	rows, err := sr.db.Query(queries.BuildSearchCommentsQuery(whereClause), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*models.SearchResult
+	for rows.Next() {
+		result := &models.SearchResult{Type: "comment"}
+		var categoriesStr sql.NullString
+		err := rows.Scan(
+			&result.CommentID,
+			&result.PostID,
+			&result.UserID,
+			&result.Username,
+			&result.PostTitle,
+			&result.Snippet,
+			&result.CreatedAt,
+			&categoriesStr,
+			&result.Rank,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result.Categories = parseSearchCategories(categoriesStr)
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// COUNT methods
+
+// GetCountSearchPosts returns the total number of posts matching the search
+func (sr *SearchRepository) GetCountSearchPosts(matchQuery string, filters models.SearchFilters) (int, error) {
+	whereClause, args := buildSearchWhere(queries.SearchMatchPostsWhere, matchQuery, filters)
+
+	var count int
+	err := sr.db.QueryRow(queries.BuildSearchPostsCountQuery(whereClause), args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetCountSearchComments returns the total number of comments matching the search
+func (sr *SearchRepository) GetCountSearchComments(matchQuery string, filters models.SearchFilters) (int, error) {
+	whereClause, args := buildSearchWhere(queries.SearchMatchCommentsWhere, matchQuery, filters)
+
+	var count int
+	err := sr.db.QueryRow(queries.BuildSearchCommentsCountQuery(whereClause), args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ..
+// Helper methods
+// ..
+
+// buildSearchWhere appends the optional filters to the MATCH clause and collects the arguments in order
+func buildSearchWhere(matchWhere, matchQuery string, filters models.SearchFilters) (string, []interface{}) {
+	whereClause := matchWhere
+	args := []interface{}{matchQuery}
+
+	if filters.CategoryID != "" {
+		whereClause += queries.SearchCategoryFilter
+		args = append(args, filters.CategoryID)
+	}
+	if filters.Author != "" {
+		whereClause += queries.SearchAuthorFilter
+		args = append(args, filters.Author)
+	}
+
+	return whereClause, args
+}
+
+// parseSearchCategories parses the "id:name,id:name" category list
+func parseSearchCategories(categoriesStr sql.NullString) []models.PostCategory {
+	var categories []models.PostCategory
+	if !categoriesStr.Valid || categoriesStr.String == "" {
+		return categories
+	}
+
+	for _, pair := range strings.Split(categoriesStr.String, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), ":")
+		if len(parts) == 2 {
+			categories = append(categories, models.PostCategory{
+				ID:   parts[0],
+				Name: parts[1],
+			})
+		}
+	}
+	return categories
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// SecurityEventRepository keeps the security event log that users review for their account
+type SecurityEventRepository struct {
+	db *sql.DB
+}
+
+func NewSecurityEventRepository(db *sql.DB) *SecurityEventRepository {
+	return &SecurityEventRepository{db: db}
+}
+
+// RecordEvent adds an event on its own
+func (ser *SecurityEventRepository) RecordEvent(event models.SecurityEvent) error {
+	return utils.ExecuteInTransaction(ser.db, func(tx *sql.Tx) error {
+		return writeSecurityEvent(tx, event)
+	})
+}
+
+// GetUserEvents lists the events concerning a user's account, newest first
+func (ser *SecurityEventRepository) GetUserEvents(userID string, limit, offset int) ([]models.SecurityEvent, error) {
+	rows, err := ser.db.Query(`SELECT event_id, event_type, email, ip_address, user_agent, details, created_at
+		FROM security_events WHERE user_id = ?
+		ORDER BY created_at DESC LIMIT ? OFFSET ?`,
+		userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.SecurityEvent{}
+	for rows.Next() {
+		event := models.SecurityEvent{UserID: userID}
+		err := rows.Scan(&event.ID, &event.Type, &event.Email, &event.IP, &event.UserAgent, &event.Details, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// writeSecurityEvent adds an event inside the transaction making the change it describes
+func writeSecurityEvent(tx *sql.Tx, event models.SecurityEvent) error {
+	var userID sql.NullString
+	if event.UserID != "" {
+		userID = sql.NullString{String: event.UserID, Valid: true}
+	}
+
+	_, err := tx.Exec(`INSERT INTO security_events (event_id, user_id, event_type, email, ip_address, user_agent, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		utils.GenerateUUIDToken(), userID, event.Type, event.Email, event.IP, event.UserAgent, event.Details, time.Now())
+	return err
+}
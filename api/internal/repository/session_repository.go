@@ -2,10 +2,9 @@ package repository
 
 import (
 	"database/sql"
-	"errors"
-	"net"
 	"time"
 
+	"github.com/PaulKerasidis/forum/config"
 	"github.com/PaulKerasidis/forum/internal/models"
 	"github.com/PaulKerasidis/forum/internal/utils"
 )
@@ -19,16 +18,39 @@ func NewSessionRepository(db *sql.DB) *SessionRepository {
 	return &SessionRepository{DB: db}
 }
 
-// Update your session_repository.go CreateSession method:
+// sessionSelectQuery selects the columns scanSession reads
+const sessionSelectQuery = `SELECT user_id, session_id, device_id, ip_address, user_agent, last_ip_address, last_user_agent,
+	role, created_at, last_seen_at, expires_at FROM sessions`
 
-func (r *SessionRepository) CreateSession(userID, ipAddress string) (*models.Session, error) {
+// scanSession scans a row of sessionSelectQuery
+func scanSession(scanner rowScanner) (*models.Session, error) {
+	var session models.Session
+	err := scanner.Scan(&session.UserID, &session.SessionID, &session.DeviceID, &session.IPAddress, &session.UserAgent,
+		&session.LastIPAddress, &session.LastUserAgent, &session.Role, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// CreateSession logs a user in on a new device. Other sessions stay valid, up to
+// MaxSessionsPerUser; beyond that the least recently used ones are logged out.
+func (r *SessionRepository) CreateSession(userID, ipAddress, userAgent string) (*models.Session, error) {
 	return utils.ExecuteInTransactionWithResult(r.DB, func(tx *sql.Tx) (*models.Session, error) {
-		// First, delete any existing sessions for this user
-		_, err := tx.Exec("DELETE FROM sessions WHERE user_id = ?", userID)
+		now := time.Now()
+
+		// Clear out this user's expired sessions while we are here
+		_, err := tx.Exec("DELETE FROM sessions WHERE user_id = ? AND expires_at <= ?", userID, now)
 		if err != nil {
 			return nil, err
 		}
 
+		// The session remembers the role it was issued for, so a role change replaces it
+		var role string
+		if err := tx.QueryRow("SELECT role FROM users WHERE user_id = ?", userID).Scan(&role); err != nil {
+			return nil, err
+		}
+
 		// Generate a new session ID and calculate expiry
 		sessionID, err := utils.GenerateSessionToken()
 		if err != nil {
@@ -36,54 +58,55 @@ func (r *SessionRepository) CreateSession(userID, ipAddress string) (*models.Ses
 		}
 
 		expiresAt := utils.CalculateSessionExpiry()
-		now := time.Now()
 
-		// FIXED: Clean the IP address before storing (remove port if present)
-		cleanIP := cleanIPAddress(ipAddress)
+		session := &models.Session{
+			UserID:        userID,
+			SessionID:     sessionID,
+			DeviceID:      utils.GenerateUUIDToken(),
+			IPAddress:     ipAddress,
+			UserAgent:     userAgent,
+			LastIPAddress: ipAddress,
+			LastUserAgent: userAgent,
+			Role:          role,
+			CreatedAt:     now,
+			LastSeenAt:    now,
+			ExpiresAt:     expiresAt,
+		}
 
-		// Insert the new session with clean IP
-		_, err = tx.Exec(
-			"INSERT INTO sessions (user_id, session_id, ip_address, created_at, expires_at) VALUES (?, ?, ?, ?, ?)",
-			userID, sessionID, cleanIP, now, expiresAt, // ← Use cleanIP here
-		)
-		if err != nil {
+		if err := insertSession(tx, session); err != nil {
 			return nil, err
 		}
 
-		// Return the session with clean IP
-		session := &models.Session{
-			UserID:    userID,
-			SessionID: sessionID,
-			IPAddress: cleanIP, // ← Use cleanIP here too
-			CreatedAt: now,
-			ExpiresAt: expiresAt,
+		// Keep only the most recently used sessions
+		_, err = tx.Exec(`DELETE FROM sessions WHERE user_id = ? AND session_id NOT IN (
+				SELECT session_id FROM sessions WHERE user_id = ? ORDER BY last_seen_at DESC LIMIT ?
+			)`, userID, userID, config.Config.MaxSessionsPerUser)
+		if err != nil {
+			return nil, err
 		}
 
 		return session, nil
 	})
 }
 
-// Add this helper function to session_repository.go:
-func cleanIPAddress(ipWithPossiblePort string) string {
-	host, _, err := net.SplitHostPort(ipWithPossiblePort)
-	if err != nil {
-		// If SplitHostPort fails, it's probably just an IP without port
-		return ipWithPossiblePort
-	}
-	return host
+// insertSession stores a session row
+func insertSession(tx *sql.Tx, session *models.Session) error {
+	_, err := tx.Exec(
+		`INSERT INTO sessions (session_id, device_id, user_id, ip_address, user_agent, last_ip_address, last_user_agent,
+			role, created_at, last_seen_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		session.SessionID, session.DeviceID, session.UserID, session.IPAddress, session.UserAgent,
+		session.LastIPAddress, session.LastUserAgent, session.Role, session.CreatedAt, session.LastSeenAt, session.ExpiresAt,
+	)
+	return err
 }
 
 // GetBySessionID retrieves a session by its ID
 func (sr *SessionRepository) GetBySessionID(sessionID string) (*models.Session, error) {
-	var session models.Session
-
-	err := sr.DB.QueryRow(
-		"SELECT user_id, session_id, ip_address, created_at, expires_at FROM sessions WHERE session_id = ?",
-		sessionID,
-	).Scan(&session.UserID, &session.SessionID, &session.IPAddress, &session.CreatedAt, &session.ExpiresAt)
+	session, err := scanSession(sr.DB.QueryRow(sessionSelectQuery+" WHERE session_id = ?", sessionID))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("session not found")
+			return nil, ErrSessionNotFound
 		}
 		return nil, err
 	}
@@ -92,11 +115,39 @@ func (sr *SessionRepository) GetBySessionID(sessionID string) (*models.Session,
 	if time.Now().After(session.ExpiresAt) {
 		// Delete the expired session
 		_, _ = sr.DB.Exec("DELETE FROM sessions WHERE session_id = ?", sessionID)
-		return nil, errors.New("session expired")
+		return nil, ErrSessionExpired
 	}
 
-	return &session, nil
+	return session, nil
+
+}
+
+// GetUserSessions lists a user's active sessions, most recently used first
+func (sr *SessionRepository) GetUserSessions(userID string) ([]*models.Session, error) {
+	rows, err := sr.DB.Query(sessionSelectQuery+" WHERE user_id = ? AND expires_at > ? ORDER BY last_seen_at DESC",
+		userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
 
+// CountActiveSessions counts the sessions that have not expired, across all users
+func (sr *SessionRepository) CountActiveSessions() (int, error) {
+	var count int
+	err := sr.DB.QueryRow("SELECT COUNT(*) FROM sessions WHERE expires_at > ?", time.Now()).Scan(&count)
+	return count, err
 }
 
 // // DeleteSession deletes a session by its ID
@@ -112,12 +163,46 @@ func (sr *SessionRepository) DeleteSession(sessionID string) error {
 	})
 }
 
-// Add this method to your session_repository.go:
+// DeleteUserSessions revokes every session of a user, logging them out everywhere
+func (sr *SessionRepository) DeleteUserSessions(userID string) error {
+	_, err := sr.DB.Exec("DELETE FROM sessions WHERE user_id = ?", userID)
+	return err
+}
+
+// DeleteUserSession logs one of a user's devices out
+func (sr *SessionRepository) DeleteUserSession(userID, deviceID string) error {
+	result, err := sr.DB.Exec("DELETE FROM sessions WHERE user_id = ? AND device_id = ?", userID, deviceID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// DeleteOtherSessions logs a user out everywhere except the given session, returning how many were revoked
+func (sr *SessionRepository) DeleteOtherSessions(userID, keepSessionID string) (int64, error) {
+	result, err := sr.DB.Exec("DELETE FROM sessions WHERE user_id = ? AND session_id <> ?", userID, keepSessionID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
 
-// UpdateSessionIP updates the IP address for an existing session
-func (sr *SessionRepository) UpdateSessionIP(sessionID, newIP string) error {
+// TouchSession records that a session was just used, with which address and user agent,
+// and when it now expires
+func (sr *SessionRepository) TouchSession(sessionID, ipAddress, userAgent string, expiresAt time.Time) error {
 	return utils.ExecuteInTransaction(sr.DB, func(tx *sql.Tx) error {
-		result, err := tx.Exec("UPDATE sessions SET ip_address = ? WHERE session_id = ?", newIP, sessionID)
+		result, err := tx.Exec(`UPDATE sessions SET last_ip_address = ?, last_user_agent = ?, last_seen_at = ?, expires_at = ?
+			WHERE session_id = ?`,
+			ipAddress, userAgent, time.Now(), expiresAt, sessionID)
 		if err != nil {
 			return err
 		}
@@ -128,9 +213,46 @@ func (sr *SessionRepository) UpdateSessionIP(sessionID, newIP string) error {
 		}
 
 		if rowsAffected == 0 {
-			return errors.New("session not found")
+			return ErrSessionNotFound
 		}
 
 		return nil
 	})
 }
+
+// RotateSession replaces a session's ID, for when the privileges behind it change. The
+// device keeps its place in the device list; the old ID stops working at once.
+func (sr *SessionRepository) RotateSession(session *models.Session, role, ipAddress, userAgent string, expiresAt time.Time) (*models.Session, error) {
+	return utils.ExecuteInTransactionWithResult(sr.DB, func(tx *sql.Tx) (*models.Session, error) {
+		sessionID, err := utils.GenerateSessionToken()
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := tx.Exec("DELETE FROM sessions WHERE session_id = ?", session.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if rowsAffected == 0 {
+			// Another request rotated or revoked it first
+			return nil, ErrSessionNotFound
+		}
+
+		rotated := *session
+		rotated.SessionID = sessionID
+		rotated.LastIPAddress = ipAddress
+		rotated.LastUserAgent = userAgent
+		rotated.Role = role
+		rotated.LastSeenAt = time.Now()
+		rotated.ExpiresAt = expiresAt
+
+		if err := insertSession(tx, &rotated); err != nil {
+			return nil, err
+		}
+		return &rotated, nil
+	})
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// TokenRepository issues the single-use tokens sent in verification and password reset emails
+type TokenRepository struct {
+	db *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// CreateToken issues a new token for the user and returns it. Earlier unused tokens
+// with the same purpose stop working, so only the latest emailed link is valid.
+func (tr *TokenRepository) CreateToken(userID, purpose string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateUserToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = utils.ExecuteInTransaction(tr.db, func(tx *sql.Tx) error {
+		_, err := tx.Exec("DELETE FROM user_tokens WHERE user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		_, err = tx.Exec(
+			"INSERT INTO user_tokens (token_hash, user_id, purpose, created_at, expires_at) VALUES (?, ?, ?, ?, ?)",
+			utils.HashUserToken(token), userID, purpose, now, now.Add(ttl),
+		)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeToken marks a token as used and returns its user. Unknown, expired and
+// already used tokens all give the same error so callers cannot tell them apart.
+func consumeToken(tx *sql.Tx, token, purpose string) (string, error) {
+	var userID string
+	var expiresAt time.Time
+	var usedAt sql.NullTime
+	err := tx.QueryRow(
+		"SELECT user_id, expires_at, used_at FROM user_tokens WHERE token_hash = ? AND purpose = ?",
+		utils.HashUserToken(token), purpose,
+	).Scan(&userID, &expiresAt, &usedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrInvalidToken
+		}
+		return "", err
+	}
+
+	now := time.Now()
+	if usedAt.Valid || !now.Before(expiresAt) {
+		return "", ErrInvalidToken
+	}
+
+	_, err = tx.Exec("UPDATE user_tokens SET used_at = ? WHERE token_hash = ?", now, utils.HashUserToken(token))
+	if err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,382 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// TwoFactorRepository handles TOTP enrollment, recovery codes and the second step of login
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// IsEnabled reports whether the user has a confirmed authenticator
+func (tr *TwoFactorRepository) IsEnabled(userID string) (bool, error) {
+	var enabled bool
+	err := tr.db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM user_totp WHERE user_id = ? AND confirmed_at IS NOT NULL)",
+		userID,
+	).Scan(&enabled)
+	return enabled, err
+}
+
+// GetStatus returns whether two-factor login is on and how many recovery codes are left
+func (tr *TwoFactorRepository) GetStatus(userID string) (*models.TwoFactorStatus, error) {
+	enabled, err := tr.IsEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TwoFactorStatus{Enabled: enabled}
+	if !enabled {
+		return status, nil
+	}
+
+	err = tr.db.QueryRow(
+		"SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL",
+		userID,
+	).Scan(&status.RecoveryCodesRemaining)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// BeginSetup issues a new authenticator secret. It replaces any earlier unconfirmed
+// secret but leaves an enabled authenticator alone.
+func (tr *TwoFactorRepository) BeginSetup(userID string) (string, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+
+	err = utils.ExecuteInTransaction(tr.db, func(tx *sql.Tx) error {
+		var enabled bool
+		err := tx.QueryRow(
+			"SELECT EXISTS(SELECT 1 FROM user_totp WHERE user_id = ? AND confirmed_at IS NOT NULL)",
+			userID,
+		).Scan(&enabled)
+		if err != nil {
+			return err
+		}
+		if enabled {
+			return ErrTwoFactorEnabled
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO user_totp (user_id, secret, created_at) VALUES (?, ?, ?)
+			ON CONFLICT(user_id) DO UPDATE SET secret = excluded.secret, created_at = excluded.created_at, last_used_step = 0`,
+			userID, secret, time.Now(),
+		)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// ConfirmSetup turns two-factor login on once the user enters a first code from
+// their app, and returns a fresh set of recovery codes
+func (tr *TwoFactorRepository) ConfirmSetup(userID, code string) ([]string, error) {
+	return utils.ExecuteInTransactionWithResult(tr.db, func(tx *sql.Tx) ([]string, error) {
+		var secret string
+		var confirmedAt sql.NullTime
+		err := tx.QueryRow("SELECT secret, confirmed_at FROM user_totp WHERE user_id = ?", userID).Scan(&secret, &confirmedAt)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrTwoFactorNotStarted
+			}
+			return nil, err
+		}
+		if confirmedAt.Valid {
+			return nil, ErrTwoFactorEnabled
+		}
+
+		now := time.Now()
+		step, ok := utils.ValidateTOTP(secret, utils.NormalizeTwoFactorCode(code), now, 0)
+		if !ok {
+			return nil, ErrInvalidCode
+		}
+
+		_, err = tx.Exec("UPDATE user_totp SET confirmed_at = ?, last_used_step = ? WHERE user_id = ?", now, step, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		return replaceRecoveryCodes(tx, userID)
+	})
+}
+
+// Disable turns two-factor login off after checking a current code
+func (tr *TwoFactorRepository) Disable(attempt models.TwoFactorAttempt, code string, maxAttempts int, lockout time.Duration) error {
+	return tr.withVerifiedCode(attempt, code, maxAttempts, lockout, func(tx *sql.Tx) error {
+		_, err := tx.Exec("DELETE FROM user_recovery_codes WHERE user_id = ?", attempt.UserID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("DELETE FROM user_totp WHERE user_id = ?", attempt.UserID)
+		return err
+	})
+}
+
+// RegenerateRecoveryCodes replaces all of the user's recovery codes after checking a current code
+func (tr *TwoFactorRepository) RegenerateRecoveryCodes(attempt models.TwoFactorAttempt, code string, maxAttempts int, lockout time.Duration) ([]string, error) {
+	var codes []string
+	err := tr.withVerifiedCode(attempt, code, maxAttempts, lockout, func(tx *sql.Tx) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, attempt.UserID)
+		return err
+	})
+	return codes, err
+}
+
+// withVerifiedCode makes a two-factor settings change once code is verified. Someone
+// holding only the session cannot guess their way through: maxAttempts wrong codes
+// refuse further changes for lockout, and each wrong code is a security event.
+func (tr *TwoFactorRepository) withVerifiedCode(attempt models.TwoFactorAttempt, code string, maxAttempts int, lockout time.Duration, change func(tx *sql.Tx) error) error {
+	err := utils.ExecuteInTransaction(tr.db, func(tx *sql.Tx) error {
+		var lockedUntil sql.NullTime
+		err := tx.QueryRow(
+			"SELECT locked_until FROM user_totp WHERE user_id = ? AND confirmed_at IS NOT NULL",
+			attempt.UserID,
+		).Scan(&lockedUntil)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrTwoFactorNotEnabled
+			}
+			return err
+		}
+		if lockedUntil.Valid && time.Now().Before(lockedUntil.Time) {
+			return ErrTwoFactorCodesLocked
+		}
+
+		if err := verifyTwoFactorCode(tx, attempt.UserID, code); err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE user_totp SET failed_attempts = 0, locked_until = NULL WHERE user_id = ?", attempt.UserID)
+		if err != nil {
+			return err
+		}
+		return change(tx)
+	})
+
+	// The transaction rolled back, so count the wrong code separately
+	if errors.Is(err, ErrInvalidCode) {
+		if countErr := tr.recordWrongCode(attempt, maxAttempts, lockout); countErr != nil {
+			return countErr
+		}
+	}
+	return err
+}
+
+// recordWrongCode counts a wrong code, locking code checks once maxAttempts is reached
+func (tr *TwoFactorRepository) recordWrongCode(attempt models.TwoFactorAttempt, maxAttempts int, lockout time.Duration) error {
+	return utils.ExecuteInTransaction(tr.db, func(tx *sql.Tx) error {
+		var failures int
+		err := tx.QueryRow(
+			"UPDATE user_totp SET failed_attempts = failed_attempts + 1 WHERE user_id = ? RETURNING failed_attempts",
+			attempt.UserID,
+		).Scan(&failures)
+		if err != nil {
+			return err
+		}
+		if err := writeSecurityEvent(tx, attempt.Event(models.SecurityEventTwoFactorFailed, attempt.Action)); err != nil {
+			return err
+		}
+		if failures < maxAttempts {
+			return nil
+		}
+
+		// The count starts over when the lock ends
+		lockedUntil := time.Now().Add(lockout)
+		_, err = tx.Exec("UPDATE user_totp SET failed_attempts = 0, locked_until = ? WHERE user_id = ?", lockedUntil, attempt.UserID)
+		if err != nil {
+			return err
+		}
+		details := fmt.Sprintf("locked until %s", lockedUntil.UTC().Format(time.RFC3339))
+		return writeSecurityEvent(tx, attempt.Event(models.SecurityEventTwoFactorLocked, details))
+	})
+}
+
+// CreateChallenge records a login that passed the password check and returns the
+// token the client redeems with a code. No challenge is issued while wrong codes have
+// the user's code checks locked, so new logins cannot restart the count.
+func (tr *TwoFactorRepository) CreateChallenge(userID string, ttl time.Duration) (string, time.Time, error) {
+	token, err := utils.GenerateUserToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	err = utils.ExecuteInTransaction(tr.db, func(tx *sql.Tx) error {
+		if err := checkCodesUnlocked(tx, userID, now); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec("DELETE FROM two_factor_challenges WHERE user_id = ? AND expires_at <= ?", userID, now)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO two_factor_challenges (token_hash, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)",
+			utils.HashUserToken(token), userID, now, expiresAt,
+		)
+		return err
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// CompleteChallenge checks the code for a pending login and returns the user it belongs to.
+// The challenge is used up on success. Wrong codes count towards maxAttempts for the
+// challenge and, like wrong codes for settings changes, towards the user's lockout.
+// attempt describes the client; its UserID is filled in from the challenge.
+func (tr *TwoFactorRepository) CompleteChallenge(token, code string, attempt models.TwoFactorAttempt, maxAttempts int, lockout time.Duration) (string, error) {
+	tokenHash := utils.HashUserToken(token)
+
+	var userID string
+	err := utils.ExecuteInTransaction(tr.db, func(tx *sql.Tx) error {
+		var attempts int
+		var expiresAt time.Time
+		err := tx.QueryRow(
+			"SELECT user_id, attempts, expires_at FROM two_factor_challenges WHERE token_hash = ?",
+			tokenHash,
+		).Scan(&userID, &attempts, &expiresAt)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrTwoFactorLoginExpired
+			}
+			return err
+		}
+		if attempts >= maxAttempts || !time.Now().Before(expiresAt) {
+			return ErrTwoFactorLoginExpired
+		}
+		if err := checkCodesUnlocked(tx, userID, time.Now()); err != nil {
+			return err
+		}
+
+		if err := verifyTwoFactorCode(tx, userID, code); err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE user_totp SET failed_attempts = 0, locked_until = NULL WHERE user_id = ?", userID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("DELETE FROM two_factor_challenges WHERE token_hash = ?", tokenHash)
+		return err
+	})
+
+	// The transaction rolled back, so count the wrong code separately
+	if errors.Is(err, ErrInvalidCode) {
+		if _, updateErr := tr.db.Exec("UPDATE two_factor_challenges SET attempts = attempts + 1 WHERE token_hash = ?", tokenHash); updateErr != nil {
+			return "", updateErr
+		}
+		attempt.UserID = userID
+		if countErr := tr.recordWrongCode(attempt, maxAttempts, lockout); countErr != nil {
+			return "", countErr
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// checkCodesUnlocked refuses code checks for a user whose wrong codes locked them
+func checkCodesUnlocked(tx *sql.Tx, userID string, now time.Time) error {
+	var lockedUntil sql.NullTime
+	err := tx.QueryRow("SELECT locked_until FROM user_totp WHERE user_id = ?", userID).Scan(&lockedUntil)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if lockedUntil.Valid && now.Before(lockedUntil.Time) {
+		return ErrTwoFactorCodesLocked
+	}
+	return nil
+}
+
+// verifyTwoFactorCode accepts a current authenticator code or an unused recovery code.
+// Accepted codes are used up: TOTP steps cannot be replayed and recovery codes work once.
+func verifyTwoFactorCode(tx *sql.Tx, userID, code string) error {
+	var secret string
+	var lastUsedStep int64
+	err := tx.QueryRow(
+		"SELECT secret, last_used_step FROM user_totp WHERE user_id = ? AND confirmed_at IS NOT NULL",
+		userID,
+	).Scan(&secret, &lastUsedStep)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+
+	code = utils.NormalizeTwoFactorCode(code)
+	now := time.Now()
+
+	if step, ok := utils.ValidateTOTP(secret, code, now, lastUsedStep); ok {
+		_, err = tx.Exec("UPDATE user_totp SET last_used_step = ? WHERE user_id = ?", step, userID)
+		return err
+	}
+
+	result, err := tx.Exec(
+		"UPDATE user_recovery_codes SET used_at = ? WHERE code_hash = ? AND user_id = ? AND used_at IS NULL",
+		now, utils.HashUserToken(code), userID,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrInvalidCode
+	}
+
+	return nil
+}
+
+// replaceRecoveryCodes issues a new set of recovery codes, invalidating the old ones
+func replaceRecoveryCodes(tx *sql.Tx, userID string) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(models.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("DELETE FROM user_recovery_codes WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, code := range codes {
+		_, err = tx.Exec(
+			"INSERT INTO user_recovery_codes (code_hash, user_id, created_at) VALUES (?, ?, ?)",
+			utils.HashUserToken(utils.NormalizeTwoFactorCode(code)), userID, now,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,170 @@
+//go:build sqlite_fts5
+
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/utils"
+)
+
+// enableTwoFactor creates a user with two-factor login on and returns them with their recovery codes
+func enableTwoFactor(t *testing.T, db *sql.DB) (*models.User, []string) {
+	t.Helper()
+	user, err := NewUserRepository(db).CreateUser(models.UserRegistration{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: "Passw0rd!",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	tr := NewTwoFactorRepository(db)
+	secret, err := tr.BeginSetup(user.ID)
+	if err != nil {
+		t.Fatalf("BeginSetup: %v", err)
+	}
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveryCodes, err := tr.ConfirmSetup(user.ID, code)
+	if err != nil {
+		t.Fatalf("ConfirmSetup: %v", err)
+	}
+	if len(recoveryCodes) != models.RecoveryCodeCount {
+		t.Fatalf("ConfirmSetup returned %d recovery codes, want %d", len(recoveryCodes), models.RecoveryCodeCount)
+	}
+	return user, recoveryCodes
+}
+
+func countSecurityEvents(t *testing.T, db *sql.DB, userID, eventType string) int {
+	t.Helper()
+	var n int
+	err := db.QueryRow("SELECT COUNT(*) FROM security_events WHERE user_id = ? AND event_type = ?", userID, eventType).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestRecoveryCodeWorksOnce(t *testing.T) {
+	db := openTestDB(t)
+	tr := NewTwoFactorRepository(db)
+	user, recoveryCodes := enableTwoFactor(t, db)
+
+	for i, want := range []error{nil, ErrInvalidCode} {
+		token, _, err := tr.CreateChallenge(user.ID, time.Minute)
+		if err != nil {
+			t.Fatalf("CreateChallenge: %v", err)
+		}
+		// Codes are accepted however the user spaces or capitalises them
+		_, err = tr.CompleteChallenge(token, " "+strings.ToUpper(recoveryCodes[0])+" ", models.TwoFactorAttempt{Action: "login"}, 5, time.Hour)
+		if !errors.Is(err, want) {
+			t.Errorf("use %d of a recovery code: error = %v, want %v", i+1, err, want)
+		}
+	}
+}
+
+func TestDisableLocksAfterWrongCodes(t *testing.T) {
+	const maxAttempts = 3
+	db := openTestDB(t)
+	tr := NewTwoFactorRepository(db)
+	user, recoveryCodes := enableTwoFactor(t, db)
+	attempt := models.TwoFactorAttempt{UserID: user.ID, Action: "disable"}
+
+	for i := 0; i < maxAttempts; i++ {
+		if err := tr.Disable(attempt, "000000", maxAttempts, time.Hour); !errors.Is(err, ErrInvalidCode) {
+			t.Fatalf("wrong code %d: error = %v, want ErrInvalidCode", i+1, err)
+		}
+	}
+
+	// A correct code no longer helps while the lock lasts
+	if err := tr.Disable(attempt, recoveryCodes[0], maxAttempts, time.Hour); !errors.Is(err, ErrTwoFactorCodesLocked) {
+		t.Errorf("Disable while locked: error = %v, want ErrTwoFactorCodesLocked", err)
+	}
+	if _, err := tr.RegenerateRecoveryCodes(attempt, recoveryCodes[0], maxAttempts, time.Hour); !errors.Is(err, ErrTwoFactorCodesLocked) {
+		t.Errorf("RegenerateRecoveryCodes while locked: error = %v, want ErrTwoFactorCodesLocked", err)
+	}
+
+	if n := countSecurityEvents(t, db, user.ID, models.SecurityEventTwoFactorFailed); n != maxAttempts {
+		t.Errorf("recorded %d failed code events, want %d", n, maxAttempts)
+	}
+	if n := countSecurityEvents(t, db, user.ID, models.SecurityEventTwoFactorLocked); n != 1 {
+		t.Errorf("recorded %d lock events, want 1", n)
+	}
+
+	// Once the lock ends the count starts over and a correct code works
+	if _, err := db.Exec("UPDATE user_totp SET locked_until = ? WHERE user_id = ?", time.Now().Add(-time.Second), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Disable(attempt, recoveryCodes[0], maxAttempts, time.Hour); err != nil {
+		t.Fatalf("Disable after the lock ended: %v", err)
+	}
+	if err := tr.Disable(attempt, recoveryCodes[1], maxAttempts, time.Hour); !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Errorf("Disable twice: error = %v, want ErrTwoFactorNotEnabled", err)
+	}
+}
+
+func TestLoginCodesLockAcrossChallenges(t *testing.T) {
+	const maxAttempts = 3
+	db := openTestDB(t)
+	tr := NewTwoFactorRepository(db)
+	user, recoveryCodes := enableTwoFactor(t, db)
+	attempt := models.TwoFactorAttempt{Action: "login", IP: "203.0.113.7"}
+
+	// A fresh challenge per guess does not start the count over
+	for i := 0; i < maxAttempts; i++ {
+		token, _, err := tr.CreateChallenge(user.ID, time.Minute)
+		if err != nil {
+			t.Fatalf("CreateChallenge %d: %v", i+1, err)
+		}
+		if _, err := tr.CompleteChallenge(token, "000000", attempt, maxAttempts, time.Hour); !errors.Is(err, ErrInvalidCode) {
+			t.Fatalf("wrong code %d: error = %v, want ErrInvalidCode", i+1, err)
+		}
+	}
+
+	if _, _, err := tr.CreateChallenge(user.ID, time.Minute); !errors.Is(err, ErrTwoFactorCodesLocked) {
+		t.Errorf("CreateChallenge while locked: error = %v, want ErrTwoFactorCodesLocked", err)
+	}
+	if n := countSecurityEvents(t, db, user.ID, models.SecurityEventTwoFactorFailed); n != maxAttempts {
+		t.Errorf("recorded %d failed code events, want %d", n, maxAttempts)
+	}
+	if n := countSecurityEvents(t, db, user.ID, models.SecurityEventTwoFactorLocked); n != 1 {
+		t.Errorf("recorded %d lock events, want 1", n)
+	}
+
+	// A challenge issued before the lock cannot be used to keep guessing either
+	if _, err := db.Exec("UPDATE user_totp SET locked_until = NULL WHERE user_id = ?", user.ID); err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := tr.CreateChallenge(user.ID, time.Minute)
+	if err != nil {
+		t.Fatalf("CreateChallenge after the lock ended: %v", err)
+	}
+	if _, err := db.Exec("UPDATE user_totp SET locked_until = ? WHERE user_id = ?", time.Now().Add(time.Hour), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.CompleteChallenge(token, recoveryCodes[0], attempt, maxAttempts, time.Hour); !errors.Is(err, ErrTwoFactorCodesLocked) {
+		t.Errorf("CompleteChallenge while locked: error = %v, want ErrTwoFactorCodesLocked", err)
+	}
+
+	// Once unlocked, a right code logs in and starts the count over
+	if _, err := db.Exec("UPDATE user_totp SET locked_until = NULL, failed_attempts = 2 WHERE user_id = ?", user.ID); err != nil {
+		t.Fatal(err)
+	}
+	userID, err := tr.CompleteChallenge(token, recoveryCodes[0], attempt, maxAttempts, time.Hour)
+	if err != nil || userID != user.ID {
+		t.Fatalf("CompleteChallenge = %q, %v, want %q", userID, err, user.ID)
+	}
+	var failures int
+	if err := db.QueryRow("SELECT failed_attempts FROM user_totp WHERE user_id = ?", user.ID).Scan(&failures); err != nil || failures != 0 {
+		t.Errorf("failed_attempts = %d, %v after a right code, want 0", failures, err)
+	}
+}
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"database/sql"
-	"errors"
 	"time"
 
 	"github.com/PaulKerasidis/forum/internal/models"
@@ -28,7 +27,7 @@ func (ur *UserRepository) CreateUser(reg models.UserRegistration) (*models.User,
 			return nil, err
 		}
 		if usernameCount > 0 {
-			return nil, errors.New("username already taken")
+			return nil, ErrUsernameTaken
 		}
 
 		// Check if email exists
@@ -38,7 +37,7 @@ func (ur *UserRepository) CreateUser(reg models.UserRegistration) (*models.User,
 			return nil, err
 		}
 		if emailCount > 0 {
-			return nil, errors.New("email already taken")
+			return nil, ErrEmailTaken
 		}
 
 		userID := utils.GenerateUUIDToken()
@@ -64,6 +63,7 @@ func (ur *UserRepository) CreateUser(reg models.UserRegistration) (*models.User,
 			ID:        userID,
 			Username:  reg.Username,
 			Email:     reg.Email,
+			Role:      models.RoleMember,
 			CreatedAt: createdAt,
 		}, nil
 	})
@@ -74,12 +74,12 @@ func (ur *UserRepository) GetUserBySessionID(id string) (*models.User, error) {
 	var user models.User
 
 	err := ur.DB.QueryRow(
-		"SELECT user_id, username, email, COALESCE(provider, ''), COALESCE(provider_id, ''), COALESCE(provider_email, ''), created_at FROM users WHERE user_id = ?",
+		"SELECT user_id, username, email, COALESCE(provider, ''), COALESCE(provider_id, ''), COALESCE(provider_email, ''), role, email_verified_at IS NOT NULL, created_at FROM users WHERE user_id = ?",
 		id,
-	).Scan(&user.ID, &user.Username, &user.Email, &user.Provider, &user.ProviderID, &user.ProviderEmail, &user.CreatedAt)
+	).Scan(&user.ID, &user.Username, &user.Email, &user.Provider, &user.ProviderID, &user.ProviderEmail, &user.Role, &user.EmailVerified, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -98,7 +98,7 @@ func (ur *UserRepository) GetAuthByUserID(userID string) (*models.UserPassword,
 	).Scan(&auth.UserID, &auth.PasswordHash)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("user authentication not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -112,12 +112,12 @@ func (ur *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	var user models.User
 
 	err := ur.DB.QueryRow(
-		"SELECT user_id, username, email, COALESCE(provider, ''), COALESCE(provider_id, ''), COALESCE(provider_email, ''), created_at FROM users WHERE LOWER(email) = LOWER(?)",
+		"SELECT user_id, username, email, COALESCE(provider, ''), COALESCE(provider_id, ''), COALESCE(provider_email, ''), role, email_verified_at IS NOT NULL, created_at FROM users WHERE LOWER(email) = LOWER(?)",
 		email,
-	).Scan(&user.ID, &user.Username, &user.Email, &user.Provider, &user.ProviderID, &user.ProviderEmail, &user.CreatedAt)
+	).Scan(&user.ID, &user.Username, &user.Email, &user.Provider, &user.ProviderID, &user.ProviderEmail, &user.Role, &user.EmailVerified, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -126,12 +126,25 @@ func (ur *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 
 }
 
+// HasPassword reports whether the user can log in with a password (Google-only accounts cannot)
+func (ur *UserRepository) HasPassword(userID string) (bool, error) {
+	var hasPassword bool
+	err := ur.DB.QueryRow("SELECT password_hash IS NOT NULL FROM users WHERE user_id = ?", userID).Scan(&hasPassword)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, ErrUserNotFound
+		}
+		return false, err
+	}
+	return hasPassword, nil
+}
+
 // Authenticate validates a user's login credentials
 func (ur *UserRepository) Authenticate(login models.UserLogin) (*models.User, error) {
 	// Get the user by email
 	user, err := ur.GetUserByEmail(login.Email)
 	if err != nil {
-		return nil, errors.New("email not found")
+		return nil, ErrEmailNotFound
 	}
 
 	// Get the user's authentication data
@@ -142,7 +155,7 @@ func (ur *UserRepository) Authenticate(login models.UserLogin) (*models.User, er
 
 	// Check the password
 	if !utils.CheckPasswordHash(login.Password, auth.PasswordHash) {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	return user, nil
@@ -153,12 +166,12 @@ func (ur *UserRepository) GetCurrentUser(userID string) (*models.User, error) {
 	var user models.User
 
 	err := ur.DB.QueryRow(
-		"SELECT user_id, username, email, COALESCE(provider, ''), COALESCE(provider_id, ''), COALESCE(provider_email, ''), created_at FROM users WHERE user_id = ?",
+		"SELECT user_id, username, email, COALESCE(provider, ''), COALESCE(provider_id, ''), COALESCE(provider_email, ''), role, email_verified_at IS NOT NULL, created_at FROM users WHERE user_id = ?",
 		userID,
-	).Scan(&user.ID, &user.Username, &user.Email, &user.Provider, &user.ProviderID, &user.ProviderEmail, &user.CreatedAt)
+	).Scan(&user.ID, &user.Username, &user.Email, &user.Provider, &user.ProviderID, &user.ProviderEmail, &user.Role, &user.EmailVerified, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -167,6 +180,86 @@ func (ur *UserRepository) GetCurrentUser(userID string) (*models.User, error) {
 
 }
 
+// SetUserRole changes a user's role and records the change in the audit log
+func (ur *UserRepository) SetUserRole(userID, role string, actor models.Actor) error {
+	if !models.IsValidRole(role) {
+		return ErrInvalidRole
+	}
+
+	return utils.ExecuteInTransaction(ur.DB, func(tx *sql.Tx) error {
+		var before userRoleSnapshot
+		err := tx.QueryRow("SELECT username, role FROM users WHERE user_id = ?", userID).Scan(&before.Username, &before.Role)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE users SET role = ? WHERE user_id = ?", role, userID)
+		if err != nil {
+			return err
+		}
+
+		after := before
+		after.Role = role
+		return writeAudit(tx, actor, models.AuditUserRoleChange, models.AuditTargetUser, userID, before, after)
+	})
+}
+
+// VerifyEmail confirms the user's email address using a token from a verification email
+func (ur *UserRepository) VerifyEmail(token string) error {
+	return utils.ExecuteInTransaction(ur.DB, func(tx *sql.Tx) error {
+		userID, err := consumeToken(tx, token, models.TokenPurposeVerifyEmail)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE users SET email_verified_at = COALESCE(email_verified_at, ?) WHERE user_id = ?", time.Now(), userID)
+		return err
+	})
+}
+
+// ResetPassword sets a new password using a token from a password reset email and
+// logs the user out everywhere. Following the link also proves the email address.
+func (ur *UserRepository) ResetPassword(token, password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return utils.ExecuteInTransaction(ur.DB, func(tx *sql.Tx) error {
+		userID, err := consumeToken(tx, token, models.TokenPurposeResetPassword)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"UPDATE users SET password_hash = ?, email_verified_at = COALESCE(email_verified_at, ?) WHERE user_id = ?",
+			hashedPassword, time.Now(), userID,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("DELETE FROM sessions WHERE user_id = ?", userID)
+		if err != nil {
+			return err
+		}
+
+		// Whoever was guessing the old password no longer keeps the owner out
+		var email string
+		if err := tx.QueryRow("SELECT LOWER(email) FROM users WHERE user_id = ?", userID).Scan(&email); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM login_failures WHERE email = ?", email); err != nil {
+			return err
+		}
+		_, err = tx.Exec("DELETE FROM login_lockouts WHERE subject = ?", "email:"+email)
+		return err
+	})
+}
+
 // GetUserProfile retrieves complete user profile with statistics
 func (ur *UserRepository) GetUserProfile(userID string) (*models.UserProfile, error) {
 	// First get basic user info using existing method
@@ -203,7 +296,7 @@ func (ur *UserRepository) GetProfileStats(userID string) (*models.ProfileStats,
 	}
 
 	// 2. Count total comments by user
-	err = ur.DB.QueryRow("SELECT COUNT(*) FROM comments WHERE user_id = ?", userID).Scan(&stats.TotalComments)
+	err = ur.DB.QueryRow("SELECT COUNT(*) FROM comments WHERE user_id = ? AND deleted_at IS NULL", userID).Scan(&stats.TotalComments)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +315,7 @@ func (ur *UserRepository) GetProfileStats(userID string) (*models.ProfileStats,
 	err = ur.DB.QueryRow(`
 		SELECT COUNT(DISTINCT p.post_id) 
 		FROM posts p
-		JOIN comments c ON p.post_id = c.post_id
+		JOIN comments c ON p.post_id = c.post_id AND c.deleted_at IS NULL
 		WHERE c.user_id = ?
 	`, userID).Scan(&stats.PostsCommentedOn)
 	if err != nil {
@@ -270,47 +363,70 @@ type OAuthUserResult struct {
 	IsNewUser bool
 }
 
-// CreateOrGetOAuthUser creates a new OAuth user or returns existing one
-func (ur *UserRepository) CreateOrGetOAuthUser(googleUser *models.GoogleUserInfo) (*models.User, bool, error) {
+// CreateOrGetOAuthUser returns the user a provider account is linked to, creating a new user
+// for accounts seen for the first time. When autoLink is set, a verified provider email that
+// matches an existing account's verified email links the provider to that account instead.
+func (ur *UserRepository) CreateOrGetOAuthUser(profile *models.OAuthProfile, autoLink bool) (*models.User, bool, error) {
 	result, err := utils.ExecuteInTransactionWithResult(ur.DB, func(tx *sql.Tx) (OAuthUserResult, error) {
-		// First, try to find existing user by OAuth provider and provider ID
-		var existingUser models.User
-		err := tx.QueryRow(`
-			SELECT user_id, username, email, provider, provider_id, provider_email, created_at 
-			FROM users 
-			WHERE provider = 'google' AND provider_id = ?
-		`, googleUser.ID).Scan(
-			&existingUser.ID, &existingUser.Username, &existingUser.Email,
-			&existingUser.Provider, &existingUser.ProviderID, &existingUser.ProviderEmail,
-			&existingUser.CreatedAt,
-		)
-		
+		// First, try to find the user this provider account is linked to
+		var userID string
+		err := tx.QueryRow(
+			"SELECT user_id FROM user_identities WHERE provider = ? AND provider_user_id = ?",
+			profile.Provider, profile.ProviderID,
+		).Scan(&userID)
+
 		if err == nil {
-			// User exists, return it
-			return OAuthUserResult{User: &existingUser, IsNewUser: false}, nil
+			_, err = tx.Exec(
+				"UPDATE user_identities SET last_used_at = ? WHERE provider = ? AND provider_user_id = ?",
+				time.Now(), profile.Provider, profile.ProviderID,
+			)
+			if err != nil {
+				return OAuthUserResult{}, err
+			}
+			user, err := getUserByIDTx(tx, userID)
+			return OAuthUserResult{User: user, IsNewUser: false}, err
 		}
-		
+
 		if err != sql.ErrNoRows {
 			// Database error
 			return OAuthUserResult{}, err
 		}
-		
-		// User doesn't exist, check if email is already taken by regular account
-		var emailCount int
-		err = tx.QueryRow("SELECT COUNT(*) FROM users WHERE LOWER(email) = LOWER(?) AND provider IS NULL", googleUser.Email).Scan(&emailCount)
-		if err != nil {
+
+		if profile.Email == "" {
+			return OAuthUserResult{}, ErrOAuthEmailMissing
+		}
+
+		// Provider account not linked yet, check if email is already taken by another account
+		var existingID string
+		var existingVerified bool
+		err = tx.QueryRow(
+			"SELECT user_id, email_verified_at IS NOT NULL FROM users WHERE LOWER(email) = LOWER(?)",
+			profile.Email,
+		).Scan(&existingID, &existingVerified)
+		if err == nil {
+			// Both sides must vouch for the address, or anyone could claim an account
+			// by registering its email with a provider
+			if !autoLink || !profile.EmailVerified || !existingVerified {
+				return OAuthUserResult{}, ErrOAuthEmailTaken
+			}
+			if err := linkIdentity(tx, existingID, profile); err != nil {
+				return OAuthUserResult{}, err
+			}
+			user, err := getUserByIDTx(tx, existingID)
+			return OAuthUserResult{User: user, IsNewUser: false}, err
+		}
+		if err != sql.ErrNoRows {
 			return OAuthUserResult{}, err
 		}
-		
-		if emailCount > 0 {
-			// Email exists for regular account, could link accounts but for now return error
-			return OAuthUserResult{}, errors.New("email already registered with regular account, please use regular login")
+
+		// Generate username from the provider's suggestion or the email
+		usernameSource := profile.Username
+		if usernameSource == "" {
+			usernameSource = profile.Email
 		}
-		
-		// Generate username from email
-		baseUsername := utils.GenerateUsernameFromEmail(googleUser.Email)
+		baseUsername := utils.GenerateUsernameFromEmail(usernameSource)
 		username := baseUsername
-		
+
 		// Ensure username is unique
 		counter := 1
 		for {
@@ -319,73 +435,126 @@ func (ur *UserRepository) CreateOrGetOAuthUser(googleUser *models.GoogleUserInfo
 			if err != nil {
 				return OAuthUserResult{}, err
 			}
-			
+
 			if usernameCount == 0 {
 				break // Username is available
 			}
-			
+
 			// Username taken, try with number suffix
 			username = baseUsername + utils.GenerateUUIDToken()[:4]
 			counter++
-			
+
 			if counter > 10 {
 				// Fallback to UUID if we can't find unique username
 				username = "user_" + utils.GenerateUUIDToken()[:8]
 				break
 			}
 		}
-		
+
 		// Create new OAuth user
-		userID := utils.GenerateUUIDToken()
+		userID = utils.GenerateUUIDToken()
 		createdAt := time.Now()
-		
+
+		// Only trust the address as verified when the provider says so
+		var emailVerifiedAt interface{}
+		if profile.EmailVerified {
+			emailVerifiedAt = createdAt
+		}
+
 		_, err = tx.Exec(`
-			INSERT INTO users (user_id, username, email, provider, provider_id, provider_email, created_at) 
-			VALUES (?, ?, ?, ?, ?, ?, ?)
-		`, userID, username, googleUser.Email, "google", googleUser.ID, googleUser.Email, createdAt)
-		
+			INSERT INTO users (user_id, username, email, provider, provider_id, provider_email, email_verified_at, created_at) 
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, userID, username, profile.Email, profile.Provider, profile.ProviderID, profile.Email, emailVerifiedAt, createdAt)
 		if err != nil {
 			return OAuthUserResult{}, err
 		}
-		
+
+		if err := linkIdentity(tx, userID, profile); err != nil {
+			return OAuthUserResult{}, err
+		}
+
 		// Return the new user
 		newUser := &models.User{
 			ID:            userID,
 			Username:      username,
-			Email:         googleUser.Email,
-			Provider:      "google",
-			ProviderID:    googleUser.ID,
-			ProviderEmail: googleUser.Email,
+			Email:         profile.Email,
+			Provider:      profile.Provider,
+			ProviderID:    profile.ProviderID,
+			ProviderEmail: profile.Email,
+			Role:          models.RoleMember,
+			EmailVerified: profile.EmailVerified,
 			CreatedAt:     createdAt,
 		}
-		
+
 		return OAuthUserResult{User: newUser, IsNewUser: true}, nil
 	})
-	
+
 	if err != nil {
 		return nil, false, err
 	}
-	
+
 	return result.User, result.IsNewUser, nil
 }
 
-// GetOAuthUserByProvider gets OAuth user by provider and provider ID
+// SetPassword gives a password to an account that has none, so users who signed up
+// through a provider can also log in with their email
+func (ur *UserRepository) SetPassword(userID, password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	result, err := ur.DB.Exec("UPDATE users SET password_hash = ? WHERE user_id = ? AND password_hash IS NULL", hashedPassword, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrPasswordAlreadySet
+	}
+
+	return nil
+}
+
+// getUserByIDTx loads a user inside a transaction
+func getUserByIDTx(tx *sql.Tx, userID string) (*models.User, error) {
+	var user models.User
+	err := tx.QueryRow(
+		"SELECT user_id, username, email, COALESCE(provider, ''), COALESCE(provider_id, ''), COALESCE(provider_email, ''), role, email_verified_at IS NOT NULL, created_at FROM users WHERE user_id = ?",
+		userID,
+	).Scan(
+		&user.ID, &user.Username, &user.Email,
+		&user.Provider, &user.ProviderID, &user.ProviderEmail,
+		&user.Role, &user.EmailVerified, &user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetOAuthUserByProvider gets the user a provider account is linked to
 func (ur *UserRepository) GetOAuthUserByProvider(provider, providerID string) (*models.User, error) {
 	var user models.User
 	
 	err := ur.DB.QueryRow(`
-		SELECT user_id, username, email, provider, provider_id, provider_email, created_at 
-		FROM users 
-		WHERE provider = ? AND provider_id = ?
+		SELECT u.user_id, u.username, u.email, COALESCE(u.provider, ''), COALESCE(u.provider_id, ''), COALESCE(u.provider_email, ''), u.role, u.email_verified_at IS NOT NULL, u.created_at 
+		FROM user_identities i
+		JOIN users u ON u.user_id = i.user_id
+		WHERE i.provider = ? AND i.provider_user_id = ?
 	`, provider, providerID).Scan(
 		&user.ID, &user.Username, &user.Email,
 		&user.Provider, &user.ProviderID, &user.ProviderEmail,
-		&user.CreatedAt,
+		&user.Role, &user.EmailVerified, &user.CreatedAt,
 	)
 	
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("oauth user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -7,44 +7,103 @@ import (
 
 	"github.com/PaulKerasidis/forum/config"
 	"github.com/PaulKerasidis/forum/internal/handlers"
+	"github.com/PaulKerasidis/forum/internal/mailer"
+	"github.com/PaulKerasidis/forum/internal/metrics"
 	"github.com/PaulKerasidis/forum/internal/middleware"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/notifier"
+	"github.com/PaulKerasidis/forum/internal/oauth"
 	"github.com/PaulKerasidis/forum/internal/repository"
 )
 
-func SetupRoutes(db *sql.DB) http.Handler {
+func SetupRoutes(db *sql.DB, m mailer.Mailer, n notifier.Notifier, providers *oauth.Registry) http.Handler {
 	mux := http.NewServeMux()
 	UserRepo := repository.NewUserRepository(db)
 	SessionRepo := repository.NewSessionRepository(db)
 	PostRepo := repository.NewPostsRepository(db)
 	CategoryRepo := repository.NewCategoryRepository(db)
 	CommentRepo := repository.NewCommentRepository(db)
+	SearchRepo := repository.NewSearchRepository(db)
+	ReportRepo := repository.NewReportRepository(db)
+	AuditRepo := repository.NewAuditRepository(db)
+	SanctionRepo := repository.NewSanctionRepository(db)
+	TokenRepo := repository.NewTokenRepository(db)
+	TwoFactorRepo := repository.NewTwoFactorRepository(db)
+	IdentityRepo := repository.NewIdentityRepository(db)
+	OAuthStateRepo := repository.NewOAuthStateRepository(db)
+	PersonalTokenRepo := repository.NewPersonalTokenRepository(db)
+	LoginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	SecurityEventRepo := repository.NewSecurityEventRepository(db)
+	HealthRepo := repository.NewHealthRepository(db)
 
 	// NEW: Create separate reaction repositories
 	PostReactionRepo := repository.NewPostReactionRepository(db)
 	CommentReactionRepo := repository.NewCommentReactionRepository(db)
 
-	AuthMiddleware := middleware.NewMiddleware(UserRepo, SessionRepo)
-
-	// NEW: Initialize RateLimiter with config values
-	RateLimiter := middleware.NewRateLimiter(
-		time.Duration(config.Config.RateLimitWindow)*time.Minute,
-		config.Config.RateLimitRequests,
-	)
+	AuthMiddleware := middleware.NewMiddleware(UserRepo, SessionRepo, SanctionRepo, PersonalTokenRepo, SecurityEventRepo)
+
+	// Personal access tokens only reach protected routes wrapped in the scope they need.
+	// Routes without one (account, security and admin settings) accept session logins only.
+	RequireRead := AuthMiddleware.RequireScope(models.ScopeRead)
+	RequireWritePosts := AuthMiddleware.RequireScope(models.ScopeWritePosts)
+	RequireWriteComments := AuthMiddleware.RequireScope(models.ScopeWriteComments)
+
+	// Rate limits per route from config; buckets live in this process unless shared through the database
+	var RateLimitStore middleware.RateLimitStore = middleware.NewMemoryRateLimitStore()
+	if config.Config.RateLimitStore == "sqlite" {
+		RateLimitStore = repository.NewRateLimitRepository(db)
+	}
+	RateLimiter := middleware.NewRateLimiter(RateLimitStore, config.Config.RateLimitRules, models.RateLimit{
+		Requests: config.Config.RateLimitRequests,
+		Window:   time.Duration(config.Config.RateLimitWindow) * time.Minute,
+	})
 
 	// ===== AUTH ROUTES  =====
-	mux.Handle("/api/auth/register", http.HandlerFunc(handlers.RegisterHandler(UserRepo)))
-	mux.Handle("/api/auth/login", http.HandlerFunc(handlers.LoginHandler(UserRepo, SessionRepo)))
+	mux.Handle("/api/auth/register", http.HandlerFunc(handlers.RegisterHandler(UserRepo, TokenRepo, m)))
+	mux.Handle("/api/auth/login", http.HandlerFunc(handlers.LoginHandler(UserRepo, SessionRepo, SanctionRepo, TwoFactorRepo, LoginAttemptRepo, n)))
+	mux.Handle("/api/auth/login/2fa", http.HandlerFunc(handlers.LoginTwoFactorHandler(UserRepo, SessionRepo, SanctionRepo, TwoFactorRepo, LoginAttemptRepo)))
 	mux.Handle("/api/auth/logout", AuthMiddleware.RequireAuth(handlers.LogoutHandler(UserRepo, SessionRepo)))
-	mux.Handle("/api/auth/me", AuthMiddleware.RequireAuth(handlers.GetCurrentUser()))
+	mux.Handle("/api/auth/me", RequireRead(AuthMiddleware.RequireAuth(handlers.GetCurrentUser())))
+
+	// Email verification and password reset
+	mux.Handle("/api/auth/forgot-password", http.HandlerFunc(handlers.ForgotPasswordHandler(UserRepo, TokenRepo, m)))
+	mux.Handle("/api/auth/reset-password", http.HandlerFunc(handlers.ResetPasswordHandler(UserRepo)))
+	mux.Handle("/api/auth/verify-email", http.HandlerFunc(handlers.VerifyEmailHandler(UserRepo)))
+	mux.Handle("/api/auth/resend-verification", AuthMiddleware.RequireAuth(handlers.ResendVerificationHandler(TokenRepo, m)))
+
+	// Two-factor authentication (TOTP) for password accounts
+	mux.Handle("/api/auth/2fa/status", AuthMiddleware.RequireAuth(handlers.GetTwoFactorStatusHandler(TwoFactorRepo)))
+	mux.Handle("/api/auth/2fa/setup", AuthMiddleware.RequireAuth(handlers.BeginTwoFactorSetupHandler(UserRepo, TwoFactorRepo)))
+	mux.Handle("/api/auth/2fa/confirm", AuthMiddleware.RequireAuth(handlers.ConfirmTwoFactorSetupHandler(TwoFactorRepo)))
+	mux.Handle("/api/auth/2fa/disable", AuthMiddleware.RequireAuth(handlers.DisableTwoFactorHandler(UserRepo, TwoFactorRepo)))
+	mux.Handle("/api/auth/2fa/recovery-codes", AuthMiddleware.RequireAuth(handlers.RegenerateRecoveryCodesHandler(TwoFactorRepo)))
+
+	// Devices the user is logged in on
+	mux.Handle("/api/auth/sessions", AuthMiddleware.RequireAuth(handlers.GetSessionsHandler(SessionRepo)))
+	mux.Handle("/api/auth/sessions/revoke/{id}", AuthMiddleware.RequireAuth(handlers.RevokeSessionHandler(SessionRepo)))
+	mux.Handle("/api/auth/sessions/revoke-others", AuthMiddleware.RequireAuth(handlers.RevokeOtherSessionsHandler(SessionRepo)))
+	mux.Handle("/api/auth/security-events", AuthMiddleware.RequireAuth(handlers.GetSecurityEventsHandler(SecurityEventRepo)))
+
+	// Personal access tokens for scripts and bots, managed from a session login
+	mux.Handle("/api/auth/tokens", AuthMiddleware.RequireAuth(handlers.GetPersonalTokensHandler(PersonalTokenRepo)))
+	mux.Handle("/api/auth/tokens/create", AuthMiddleware.RequireAuth(handlers.CreatePersonalTokenHandler(PersonalTokenRepo)))
+	mux.Handle("/api/auth/tokens/revoke/{id}", AuthMiddleware.RequireAuth(handlers.RevokePersonalTokenHandler(PersonalTokenRepo)))
 
 	// ===== OAUTH ROUTES =====
-	mux.Handle("/api/auth/google/login", http.HandlerFunc(handlers.GoogleLoginHandler()))
-	mux.Handle("/api/auth/google/callback", http.HandlerFunc(handlers.GoogleCallbackHandler(UserRepo, SessionRepo)))
-	mux.Handle("/api/auth/oauth/status", http.HandlerFunc(handlers.GoogleLoginStatusHandler()))
+	// {provider} is a name from the registry: google, github or the configured OIDC provider
+	mux.Handle("/api/auth/{provider}/login", http.HandlerFunc(handlers.OAuthLoginHandler(providers, OAuthStateRepo)))
+	mux.Handle("/api/auth/{provider}/callback", http.HandlerFunc(handlers.OAuthCallbackHandler(providers, OAuthStateRepo, UserRepo, IdentityRepo, SessionRepo, SanctionRepo, TwoFactorRepo)))
+	mux.Handle("/api/auth/oauth/status", http.HandlerFunc(handlers.OAuthStatusHandler(providers)))
+
+	// Add OAuth routes without /api prefix for provider callbacks
+	mux.HandleFunc("/auth/{provider}/login", handlers.OAuthLoginHandler(providers, OAuthStateRepo))
+	mux.HandleFunc("/auth/{provider}/callback", handlers.OAuthCallbackHandler(providers, OAuthStateRepo, UserRepo, IdentityRepo, SessionRepo, SanctionRepo, TwoFactorRepo))
 
-	// Add OAuth routes without /api prefix for Google callback
-	mux.HandleFunc("/auth/google/login", handlers.GoogleLoginHandler())
-	mux.HandleFunc("/auth/google/callback", handlers.GoogleCallbackHandler(UserRepo, SessionRepo))
+	// Login methods: linking providers to an account and adding a password
+	mux.Handle("/api/auth/identities", AuthMiddleware.RequireAuth(handlers.GetLoginMethodsHandler(UserRepo, IdentityRepo, providers)))
+	mux.Handle("/api/auth/{provider}/link", AuthMiddleware.RequireAuth(handlers.LinkProviderHandler(providers, OAuthStateRepo)))
+	mux.Handle("/api/auth/identities/unlink/{provider}", AuthMiddleware.RequireAuth(handlers.UnlinkProviderHandler(IdentityRepo)))
+	mux.Handle("/api/auth/password/set", AuthMiddleware.RequireAuth(handlers.SetPasswordHandler(UserRepo)))
 
 	// Test route to verify routing works
 	mux.HandleFunc("/auth/test", func(w http.ResponseWriter, r *http.Request) {
@@ -52,10 +111,14 @@ func SetupRoutes(db *sql.DB) http.Handler {
 		w.Write([]byte("OAuth routing is working!"))
 	})
 	// ===== USER PROFILE ROUTES =====
-	mux.Handle("/api/users/profile/{id}", AuthMiddleware.RequireAuth(handlers.GetUserProfileHandler(UserRepo)))
-	mux.Handle("/api/users/posts/{id}", AuthMiddleware.RequireAuth(handlers.GetUserPostsProfileHandler(PostRepo)))
-	mux.Handle("/api/users/liked-posts/{id}", AuthMiddleware.RequireAuth(handlers.GetUserLikedPostsProfileHandler(PostRepo)))
-	mux.Handle("/api/users/commented-posts/{id}", AuthMiddleware.RequireAuth(handlers.GetUserCommentedPostsProfileHandler(PostRepo)))
+	mux.Handle("/api/users/profile/{id}", RequireRead(AuthMiddleware.RequireAuth(handlers.GetUserProfileHandler(UserRepo))))
+	mux.Handle("/api/users/posts/{id}", RequireRead(AuthMiddleware.RequireAuth(handlers.GetUserPostsProfileHandler(PostRepo))))
+	mux.Handle("/api/users/liked-posts/{id}", RequireRead(AuthMiddleware.RequireAuth(handlers.GetUserLikedPostsProfileHandler(PostRepo))))
+	mux.Handle("/api/users/commented-posts/{id}", RequireRead(AuthMiddleware.RequireAuth(handlers.GetUserCommentedPostsProfileHandler(PostRepo))))
+
+	// ===== ADMIN ROUTES =====
+	mux.Handle("/api/admin/users/role/{id}", AuthMiddleware.RequireRole(models.RoleAdmin)(handlers.UpdateUserRoleHandler(UserRepo)))
+	mux.Handle("/api/admin/audit-log", AuthMiddleware.RequireRole(models.RoleAdmin)(handlers.GetAuditLogHandler(AuditRepo)))
 
 	// ===== POST ROUTES  =====
 	// Public GET routes
@@ -64,36 +127,92 @@ func SetupRoutes(db *sql.DB) http.Handler {
 	mux.Handle("/api/posts/by-category/{id}", http.HandlerFunc(handlers.GetPostsByCategoryHandler(PostRepo)))
 
 	// Protected POST routes (create only)
-	mux.Handle("/api/posts/create", AuthMiddleware.RequireAuth(handlers.CreatePostHandler(PostRepo, CategoryRepo)))
+	mux.Handle("/api/posts/create", RequireWritePosts(AuthMiddleware.RequireUnmuted(handlers.CreatePostHandler(PostRepo, CategoryRepo))))
 
 	// Protected PUT/DELETE routes (clear naming)
-	mux.Handle("/api/posts/edit/{id}", AuthMiddleware.RequireAuth(handlers.UpdatePostHandler(PostRepo, CategoryRepo)))
-	mux.Handle("/api/posts/remove/{id}", AuthMiddleware.RequireAuth(handlers.DeletePostHandler(PostRepo, CategoryRepo)))
+	mux.Handle("/api/posts/edit/{id}", RequireWritePosts(AuthMiddleware.RequireAuth(handlers.UpdatePostHandler(PostRepo, CategoryRepo))))
+	mux.Handle("/api/posts/remove/{id}", RequireWritePosts(AuthMiddleware.RequireAuth(handlers.DeletePostHandler(PostRepo, CategoryRepo))))
 
 	// ===== CATEGORY ROUTES =====
 	mux.Handle("/api/categories", http.HandlerFunc(handlers.GetAllCategoriesHandler(CategoryRepo, PostRepo)))
 
+	// ===== SEARCH ROUTES =====
+	mux.Handle("/api/search", http.HandlerFunc(handlers.SearchHandler(SearchRepo)))
+
 	// ===== COMMENT ROUTES =====
 	// Public GET routes
 	mux.Handle("/api/comments/for-post/{id}", http.HandlerFunc(handlers.GetCommentsByPostIDHandler(CommentRepo)))
+	mux.Handle("/api/comments/replies/{id}", http.HandlerFunc(handlers.GetCommentRepliesHandler(CommentRepo)))
 
 	// Protected routes
-	mux.Handle("/api/comments/create-on-post/{id}", AuthMiddleware.RequireAuth(handlers.CreateCommentHandler(CommentRepo)))
-	mux.Handle("/api/comments/edit/{id}", AuthMiddleware.RequireAuth(handlers.UpdateCommentHandler(CommentRepo)))
-	mux.Handle("/api/comments/remove/{id}", AuthMiddleware.RequireAuth(handlers.DeleteCommentHandler(CommentRepo)))
+	mux.Handle("/api/comments/create-on-post/{id}", RequireWriteComments(AuthMiddleware.RequireUnmuted(handlers.CreateCommentHandler(CommentRepo))))
+	mux.Handle("/api/comments/reply-to/{id}", RequireWriteComments(AuthMiddleware.RequireUnmuted(handlers.CreateReplyHandler(CommentRepo))))
+	mux.Handle("/api/comments/edit/{id}", RequireWriteComments(AuthMiddleware.RequireAuth(handlers.UpdateCommentHandler(CommentRepo))))
+	mux.Handle("/api/comments/remove/{id}", RequireWriteComments(AuthMiddleware.RequireAuth(handlers.DeleteCommentHandler(CommentRepo))))
 	mux.Handle("/api/comments/view/{id}", http.HandlerFunc(handlers.GetSingleCommentHandler(CommentRepo)))
 
+	// ===== REPORT ROUTES =====
+	mux.Handle("/api/reports/create", AuthMiddleware.RequireAuth(handlers.CreateReportHandler(ReportRepo)))
+
+	// Moderation queue (moderators and admins; tokens also need the moderate scope)
+	RequireModerator := func(next http.Handler) http.Handler {
+		return AuthMiddleware.RequireScope(models.ScopeModerate)(AuthMiddleware.RequireRole(models.RoleModerator)(next))
+	}
+	mux.Handle("/api/reports/queue", RequireModerator(handlers.GetReportQueueHandler(ReportRepo)))
+	mux.Handle("/api/reports/view/{id}", RequireModerator(handlers.GetReportHandler(ReportRepo)))
+	mux.Handle("/api/reports/claim/{id}", RequireModerator(handlers.ClaimReportHandler(ReportRepo)))
+	mux.Handle("/api/reports/resolve/{id}", RequireModerator(handlers.ResolveReportHandler(ReportRepo)))
+	mux.Handle("/api/reports/dismiss/{id}", RequireModerator(handlers.DismissReportHandler(ReportRepo)))
+
+	// ===== SANCTION ROUTES (moderators and admins; only admins ban) =====
+	mux.Handle("/api/sanctions/create/{id}", RequireModerator(handlers.CreateSanctionHandler(SanctionRepo, SessionRepo)))
+	mux.Handle("/api/sanctions/lift/{id}", RequireModerator(handlers.LiftSanctionHandler(SanctionRepo)))
+	mux.Handle("/api/sanctions/for-user/{id}", RequireModerator(handlers.GetUserSanctionsHandler(SanctionRepo)))
+
 	// ===== REACTION ROUTES - UPDATED =====
 	// Post reactions
-	mux.Handle("/api/reactions/posts/toggle", AuthMiddleware.RequireAuth(handlers.TogglePostReactionHandler(PostReactionRepo)))
+	mux.Handle("/api/reactions/posts/toggle", RequireWritePosts(AuthMiddleware.RequireUnmuted(handlers.TogglePostReactionHandler(PostReactionRepo))))
 
 	// Comment reactions
-	mux.Handle("/api/reactions/comments/toggle", AuthMiddleware.RequireAuth(handlers.ToggleCommentReactionHandler(CommentReactionRepo)))
+	mux.Handle("/api/reactions/comments/toggle", RequireWriteComments(AuthMiddleware.RequireUnmuted(handlers.ToggleCommentReactionHandler(CommentReactionRepo))))
 
 	//...
 	// Apply middleware
-	handler := RateLimiter.Limit(mux)
+	// Bad tokens are refused only here, so they are still rate limited and get the CORS and security headers
+	handler := AuthMiddleware.RejectInvalidToken(mux)
+	handler = RateLimiter.Limit(handler)
 	handler = middleware.SecurityHeaders(handler)
 	handler = middleware.CORS(handler)
-	return AuthMiddleware.Authenticate(handler)
+
+	// Probes answer ahead of authentication and rate limiting, so orchestrators are never turned away
+	root := http.NewServeMux()
+	root.Handle("/api/health", handlers.HealthHandler())
+	root.Handle("/api/ready", handlers.ReadyHandler(HealthRepo))
+	root.Handle("/api/version", handlers.VersionHandler())
+	if config.Config.MetricsEnabled {
+		root.Handle("/metrics", handlers.MetricsHandler())
+		registerMetrics(db, SessionRepo)
+	}
+	root.Handle("/", AuthMiddleware.Authenticate(handler))
+
+	// Requests are labelled with the pattern they match: an API route, else a root route
+	routeOf := func(r *http.Request) string {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			return pattern
+		}
+		if _, pattern := root.Handler(r); pattern != "/" {
+			return pattern
+		}
+		return ""
+	}
+	return middleware.RequestID(middleware.Instrument(routeOf, root))
+}
+
+// registerMetrics exports the values that are read when the metrics are scraped
+func registerMetrics(db *sql.DB, sr *repository.SessionRepository) {
+	metrics.RegisterDB(db)
+	metrics.Default.NewGaugeFunc("forum_active_sessions", "Sessions that have not expired.", func() (float64, error) {
+		count, err := sr.CountActiveSessions()
+		return float64(count), err
+	})
 }
@@ -2,19 +2,69 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/PaulKerasidis/forum/internal/models"
 )
 
-// RespondWithError sends a standardized error response
+// RespondWithError sends an application/problem+json error response
 func RespondWithError(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(models.APIResponse{
-		Success: false,
-		Error:   message,
-	})
+	respondWithProblem(w, models.Problem{Status: status, Detail: message})
+}
+
+// RespondWithDomainError sends the status code for the kind of err, with its message
+// and any field errors. Errors of no known kind are logged and answered with 500,
+// without their message.
+func RespondWithDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := models.Problem{Status: http.StatusInternalServerError, Detail: "Internal server error"}
+
+	var verr *models.ValidationError
+	var derr *models.DomainError
+	if errors.As(err, &derr) {
+		problem.Type = derr.Type
+	}
+	switch {
+	case errors.As(err, &verr):
+		problem.Status = http.StatusBadRequest
+		problem.Detail = verr.Error()
+		problem.Errors = verr.Fields
+	case errors.Is(err, models.ErrValidation):
+		problem.Status = http.StatusBadRequest
+		problem.Detail = err.Error()
+	case errors.Is(err, models.ErrUnauthorized):
+		problem.Status = http.StatusUnauthorized
+		problem.Detail = err.Error()
+	case errors.Is(err, models.ErrForbidden):
+		problem.Status = http.StatusForbidden
+		problem.Detail = err.Error()
+	case errors.Is(err, models.ErrNotFound):
+		problem.Status = http.StatusNotFound
+		problem.Detail = err.Error()
+	case errors.Is(err, models.ErrConflict):
+		problem.Status = http.StatusConflict
+		problem.Detail = err.Error()
+	default:
+		slog.ErrorContext(r.Context(), "Request failed", "path", r.URL.Path, "err", err)
+	}
+
+	problem.Instance = r.URL.Path
+	respondWithProblem(w, problem)
+}
+
+// respondWithProblem fills in the standard members of problem and sends it
+func respondWithProblem(w http.ResponseWriter, problem models.Problem) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	problem.Title = http.StatusText(problem.Status)
+	problem.Success = false
+	problem.Error = problem.Detail
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
 }
 
 // RespondWithSuccess sends a standardized success response
@@ -28,7 +78,7 @@ func RespondWithSuccess(w http.ResponseWriter, status int, data interface{}) {
 }
 
 // RespondWithPaginatedPosts sends a standardized paginated posts response
-func RespondWithPaginatedPosts(w http.ResponseWriter, posts []*models.Post, totalCount, limit, offset int) {
+func RespondWithPaginatedPosts(w http.ResponseWriter, posts []*models.PostSummary, totalCount, limit, offset int) {
 	response := models.NewPaginatedPostsResponse(posts, totalCount, limit, offset)
 	RespondWithSuccess(w, http.StatusOK, response)
 }
@@ -38,3 +88,37 @@ func RespondWithPaginatedComments(w http.ResponseWriter, comments []*models.Comm
 	response := models.NewPaginatedCommentsResponse(comments, totalCount, limit, offset)
 	RespondWithSuccess(w, http.StatusOK, response)
 }
+
+// RespondWithPaginatedSearch sends a standardized paginated search response
+func RespondWithPaginatedSearch(w http.ResponseWriter, query, searchType string, results []*models.SearchResult, totalCount, limit, offset int) {
+	response := models.NewPaginatedSearchResponse(query, searchType, results, totalCount, limit, offset)
+	RespondWithSuccess(w, http.StatusOK, response)
+}
+
+// RespondWithPaginatedReports sends a standardized paginated moderation queue response
+func RespondWithPaginatedReports(w http.ResponseWriter, status string, reports []*models.Report, totalCount, limit, offset int) {
+	response := models.NewPaginatedReportsResponse(status, reports, totalCount, limit, offset)
+	RespondWithSuccess(w, http.StatusOK, response)
+}
+
+// RespondWithPaginatedAuditLog sends a standardized paginated audit log response
+func RespondWithPaginatedAuditLog(w http.ResponseWriter, entries []*models.AuditEntry, totalCount, limit, offset int) {
+	response := models.NewPaginatedAuditLogResponse(entries, totalCount, limit, offset)
+	RespondWithSuccess(w, http.StatusOK, response)
+}
+
+// RespondWithReadiness sends the readiness probe's outcome, with 503 Service Unavailable
+// and the failed checks when the API is not ready
+func RespondWithReadiness(w http.ResponseWriter, readiness models.Readiness) {
+	if readiness.Ready {
+		RespondWithSuccess(w, http.StatusOK, readiness)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(models.APIResponse{
+		Success: false,
+		Data:    readiness,
+		Error:   "not ready",
+	})
+}
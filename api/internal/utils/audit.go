@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+// ParseAuditFilters reads the audit log query parameters: actor, action, target_type, target_id,
+// and since/until as RFC 3339 timestamps or YYYY-MM-DD dates (an until date includes that whole day).
+func ParseAuditFilters(r *http.Request) (models.AuditFilters, error) {
+	query := r.URL.Query()
+	filters := models.AuditFilters{
+		ActorID:    strings.TrimSpace(query.Get("actor")),
+		Action:     strings.ToLower(strings.TrimSpace(query.Get("action"))),
+		TargetType: strings.ToLower(strings.TrimSpace(query.Get("target_type"))),
+		TargetID:   strings.TrimSpace(query.Get("target_id")),
+	}
+
+	since, err := parseAuditTime(query.Get("since"), false)
+	if err != nil {
+		return filters, errors.New("since must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+	}
+	until, err := parseAuditTime(query.Get("until"), true)
+	if err != nil {
+		return filters, errors.New("until must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+	}
+	filters.Since, filters.Until = since, until
+
+	return filters, nil
+}
+
+func parseAuditTime(value string, endOfDay bool) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// background tracks work started by requests that outlives them, so shutdown can wait for it
+var background sync.WaitGroup
+
+// RunInBackground runs work without making the request wait for it, e.g. sending mail.
+// A failure is logged with what and the request ID of ctx; ctx does not cancel the work.
+func RunInBackground(ctx context.Context, what string, work func() error) {
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		if err := work(); err != nil {
+			slog.ErrorContext(ctx, "Background task failed", "task", what, "err", err)
+		}
+	}()
+}
+
+// WaitForBackground waits for background work to finish, or returns ctx's error if it ends first
+func WaitForBackground(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
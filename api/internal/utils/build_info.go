@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"runtime/debug"
+
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+// ReadBuildInfo returns the module version, Go version and VCS details the toolchain
+// embedded in the binary. Fields are empty when the binary was built without them.
+func ReadBuildInfo() models.BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return models.BuildInfo{}
+	}
+
+	build := models.BuildInfo{
+		Module:    info.Main.Path,
+		Version:   info.Main.Version,
+		GoVersion: info.GoVersion,
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Revision = setting.Value
+		case "vcs.time":
+			build.RevisionTime = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		case "-tags":
+			build.Tags = setting.Value
+		}
+	}
+	return build
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/PaulKerasidis/forum/config"
+)
+
+// ClientIP returns the address of the client making the request. This is the one place
+// client addresses are worked out, for sessions, rate limits, login protection and logs.
+//
+// Forwarding headers can be set by anyone, so they are only believed when the connection
+// comes from a proxy in TRUSTED_PROXIES. X-Forwarded-For is then read right to left,
+// skipping the trusted proxies that appended to it; the first address that is not one of
+// them is the client. Everything to its left was written by the client and is ignored.
+func ClientIP(r *http.Request) string {
+	ip, _ := ResolveClientIP(r)
+	return ip
+}
+
+// ResolveClientIP is ClientIP, also reporting whether the address is the client's own.
+// It is not when a trusted proxy did not say who it forwarded for: the address is then
+// the proxy's, shared by all of its clients, and must not be locked out.
+func ResolveClientIP(r *http.Request) (string, bool) {
+	peer, ok := parseIP(r.RemoteAddr)
+	if !ok {
+		return r.RemoteAddr, false
+	}
+	if !isTrustedProxy(peer) {
+		return peer.String(), true
+	}
+
+	hops := forwardedFor(r)
+	if len(hops) == 0 {
+		// A trusted proxy that reports the client in X-Real-IP instead (nginx)
+		if realIP, ok := parseIP(r.Header.Get("X-Real-IP")); ok {
+			return realIP.String(), !isTrustedProxy(realIP)
+		}
+		return peer.String(), false
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseIP(hops[i])
+		if !ok {
+			// Garbage in the chain: the last address a trusted proxy vouched for is all we know
+			break
+		}
+		client = hop
+		if !isTrustedProxy(hop) {
+			break
+		}
+	}
+	return client.String(), !isTrustedProxy(client)
+}
+
+// forwardedFor returns the X-Forwarded-For entries of all the request's headers, in order
+func forwardedFor(r *http.Request) []string {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// parseIP reads an address with or without a port, as in RemoteAddr and some proxies' headers
+func parseIP(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	addr, err := netip.ParseAddr(strings.Trim(value, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	// IPv4 clients of a dual-stack listener arrive as ::ffff:a.b.c.d
+	return addr.Unmap().WithZone(""), true
+}
+
+func isTrustedProxy(addr netip.Addr) bool {
+	for _, prefix := range config.Config.TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/PaulKerasidis/forum/config"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	saved := config.Config.TrustedProxies
+	t.Cleanup(func() { config.Config.TrustedProxies = saved })
+	config.Config.TrustedProxies = []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string // X-Forwarded-For headers
+		realIP     string
+		want       string
+		wantOwn    bool
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "", "203.0.113.7", true},
+		{"direct client forging a header", "203.0.113.7:5000", []string{"198.51.100.1"}, "198.51.100.2", "203.0.113.7", true},
+		{"IPv4 on a dual-stack listener", "[::ffff:203.0.113.7]:5000", nil, "", "203.0.113.7", true},
+		{"IPv6 client", "[2001:db8::1]:5000", nil, "", "2001:db8::1", true},
+		{"one trusted proxy", "10.0.0.2:5000", []string{"203.0.113.7"}, "", "203.0.113.7", true},
+		{"chain of trusted proxies", "10.0.0.2:5000", []string{"203.0.113.7, 10.0.0.5, 10.0.0.3"}, "", "203.0.113.7", true},
+		{"client prepended a fake hop", "10.0.0.2:5000", []string{"198.51.100.1, 203.0.113.7"}, "", "203.0.113.7", true},
+		{"hops split over several headers", "10.0.0.2:5000", []string{"198.51.100.1", "203.0.113.7, 10.0.0.5"}, "", "203.0.113.7", true},
+		{"hop with a port", "10.0.0.2:5000", []string{"203.0.113.7:6000"}, "", "203.0.113.7", true},
+		{"garbage in the chain", "10.0.0.2:5000", []string{"203.0.113.7, not-an-ip, 10.0.0.5"}, "", "10.0.0.5", false},
+		{"only trusted hops", "10.0.0.2:5000", []string{"10.0.0.5"}, "", "10.0.0.5", false},
+		{"trusted IPv6 proxy", "[fd00::2]:5000", []string{"2001:db8::1"}, "", "2001:db8::1", true},
+		{"trusted proxy using X-Real-IP", "10.0.0.2:5000", nil, "203.0.113.7", "203.0.113.7", true},
+		{"trusted proxy naming no client", "10.0.0.2:5000", nil, "", "10.0.0.2", false},
+		{"unparsable remote address", "pipe", nil, "", "pipe", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			got, own := ResolveClientIP(r)
+			if got != tt.want || own != tt.wantOwn {
+				t.Errorf("ResolveClientIP() = %q, %v, want %q, %v", got, own, tt.want, tt.wantOwn)
+			}
+			if ip := ClientIP(r); ip != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", ip, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveClientIPWithoutTrustedProxies(t *testing.T) {
+	saved := config.Config.TrustedProxies
+	t.Cleanup(func() { config.Config.TrustedProxies = saved })
+	config.Config.TrustedProxies = nil
+
+	// With no proxies configured, forwarding headers are never believed
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.2:5000"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+	r.Header.Set("X-Real-IP", "203.0.113.8")
+	if got, own := ResolveClientIP(r); got != "10.0.0.2" || !own {
+		t.Errorf("ResolveClientIP() = %q, %v, want 10.0.0.2, true", got, own)
+	}
+}
@@ -33,3 +33,32 @@ func SetSessionCookie(value string, w http.ResponseWriter, r *http.Request, expi
 		Domain:   "localhost",          // Allow cookie to be shared across ports
 	})
 }
+
+// OAuthBindingCookie holds the value tying a pending OAuth login to the browser that started it
+const OAuthBindingCookie = "oauth_binding"
+
+func SetOAuthBindingCookie(value string, w http.ResponseWriter, expiresAt time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OAuthBindingCookie,
+		Value:    value,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   false,                // Set to true in production with HTTPS
+		SameSite: http.SameSiteLaxMode, // Lax so it is sent on the provider's redirect back
+		Domain:   "localhost",          // Allow cookie to be shared across ports
+	})
+}
+
+func ClearOAuthBindingCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OAuthBindingCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteLaxMode,
+		Domain:   "localhost",
+	})
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// BuildExcerpt shortens post content for list views. source is the start of the content
+// as read from the database and fullLength the length of the whole content; "…" is
+// appended whenever the excerpt doesn't reach the end of the post.
+func BuildExcerpt(source string, fullLength, maxLength int) string {
+	// Collapse newlines and repeated spaces so the excerpt reads as one line
+	excerpt := strings.Join(strings.Fields(source), " ")
+	truncated := fullLength > utf8.RuneCountInString(source)
+
+	if maxLength > 0 && utf8.RuneCountInString(excerpt) > maxLength {
+		runes := []rune(excerpt)
+		excerpt = string(runes[:maxLength])
+
+		// Prefer cutting at a word boundary
+		if cut := strings.LastIndex(excerpt, " "); cut > len(excerpt)/2 {
+			excerpt = excerpt[:cut]
+		}
+		truncated = true
+	}
+
+	if truncated {
+		return strings.TrimRight(excerpt, " .,;:") + "…"
+	}
+	return excerpt
+}
+
+// ParseSQLiteTimestamp parses a timestamp returned as text by the sqlite driver
+// (e.g. from MAX() over a subquery, where the column type is lost)
+func ParseSQLiteTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSuffix(value, "Z")
+	var err error
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		var t time.Time
+		if t, err = time.ParseInLocation(format, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
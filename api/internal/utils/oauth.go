@@ -4,24 +4,13 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"strings"
-	"time"
-
-	"github.com/PaulKerasidis/forum/config"
-	"github.com/PaulKerasidis/forum/internal/models"
 )
 
-const (
-	googleAuthURL  = "https://accounts.google.com/o/oauth2/auth"
-	googleTokenURL = "https://oauth2.googleapis.com/token"
-	googleUserURL  = "https://www.googleapis.com/oauth2/v2/userinfo"
-)
+// maxReturnToLength bounds the post-login path kept with an OAuth state
+const maxReturnToLength = 512
 
 // GenerateOAuthState generates a secure random state for OAuth flow
 func GenerateOAuthState() (string, error) {
@@ -33,94 +22,46 @@ func GenerateOAuthState() (string, error) {
 	return base64.URLEncoding.EncodeToString(b), nil
 }
 
-// GenerateGoogleAuthURL generates the Google OAuth authorization URL
-func GenerateGoogleAuthURL(state string) string {
-	params := url.Values{}
-	params.Set("client_id", config.Config.GoogleOAuthClientID)
-	params.Set("redirect_uri", config.Config.GoogleOAuthRedirectURL)
-	params.Set("scope", "openid email profile")
-	params.Set("response_type", "code")
-	params.Set("state", state)
-	params.Set("access_type", "offline")
-	params.Set("prompt", "consent")
-
-	return googleAuthURL + "?" + params.Encode()
-}
-
-// ExchangeGoogleCode exchanges authorization code for access token
-func ExchangeGoogleCode(code string) (*models.GoogleTokenResponse, error) {
-	data := url.Values{}
-	data.Set("client_id", config.Config.GoogleOAuthClientID)
-	data.Set("client_secret", config.Config.GoogleOAuthClientSecret)
-	data.Set("code", code)
-	data.Set("grant_type", "authorization_code")
-	data.Set("redirect_uri", config.Config.GoogleOAuthRedirectURL)
-
-	req, err := http.NewRequest("POST", googleTokenURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create token request: %v", err)
-	}
-
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("Accept", "application/json")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to exchange code: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("token exchange failed with status %d: %s", resp.StatusCode, string(body))
+// ValidateOAuthState validates the OAuth state parameter
+func ValidateOAuthState(receivedState, expectedState string) error {
+	if receivedState == "" {
+		return errors.New("missing state parameter")
 	}
-
-	var tokenResp models.GoogleTokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return nil, fmt.Errorf("failed to decode token response: %v", err)
+	if receivedState != expectedState {
+		return errors.New("invalid state parameter")
 	}
-
-	return &tokenResp, nil
+	return nil
 }
 
-// GetGoogleUserInfo fetches user information from Google using access token
-func GetGoogleUserInfo(accessToken string) (*models.GoogleUserInfo, error) {
-	req, err := http.NewRequest("GET", googleUserURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create user info request: %v", err)
+// GeneratePKCEVerifier creates a PKCE code verifier (RFC 7636): 43 characters of base64url
+func GeneratePKCEVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
 
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-	req.Header.Set("Accept", "application/json")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %v", err)
-	}
-	defer resp.Body.Close()
+// PKCEChallenge derives the S256 code challenge sent in the authorization URL
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("user info request failed with status %d: %s", resp.StatusCode, string(body))
+// ValidateReturnTo checks that a post-login destination is a path on this site.
+// Anything that could leave the site (absolute or scheme-relative URLs, backslash
+// tricks browsers normalise to "//") is rejected so it cannot be used as an open redirect.
+func ValidateReturnTo(returnTo string) error {
+	if len(returnTo) > maxReturnToLength {
+		return errors.New("return_to is too long")
 	}
-
-	var userInfo models.GoogleUserInfo
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
-		return nil, fmt.Errorf("failed to decode user info: %v", err)
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.ContainsAny(returnTo, "\\\r\n\t") {
+		return errors.New("return_to must be a local path")
 	}
 
-	return &userInfo, nil
-}
-
-// ValidateOAuthState validates the OAuth state parameter
-func ValidateOAuthState(receivedState, expectedState string) error {
-	if receivedState == "" {
-		return errors.New("missing state parameter")
-	}
-	if receivedState != expectedState {
-		return errors.New("invalid state parameter")
+	u, err := url.Parse(returnTo)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil {
+		return errors.New("return_to must be a local path")
 	}
 	return nil
 }
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPKCEChallenge(t *testing.T) {
+	// The example from RFC 7636, appendix B
+	got := PKCEChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("PKCEChallenge = %q, want %q", got, want)
+	}
+}
+
+func TestGeneratePKCEVerifier(t *testing.T) {
+	// RFC 7636 allows 43 to 128 unreserved characters
+	pattern := regexp.MustCompile(`^[A-Za-z0-9._~-]{43,128}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		verifier, err := GeneratePKCEVerifier()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pattern.MatchString(verifier) {
+			t.Errorf("GeneratePKCEVerifier = %q, not a valid verifier", verifier)
+		}
+		if seen[verifier] {
+			t.Errorf("GeneratePKCEVerifier repeated %q", verifier)
+		}
+		seen[verifier] = true
+	}
+}
+
+func TestValidateReturnTo(t *testing.T) {
+	tests := []struct {
+		returnTo string
+		valid    bool
+	}{
+		{"/", true},
+		{"/post/123", true},
+		{"/search?q=go&type=posts", true},
+		{"", false},
+		{"post/123", false},
+		{"https://evil.example/", false},
+		{"//evil.example/", false},
+		{"/\\evil.example", false},
+		{"/post/1\r\nSet-Cookie: x=1", false},
+		{"javascript:alert(1)", false},
+		{"/" + strings.Repeat("a", maxReturnToLength), false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateReturnTo(tt.returnTo)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateReturnTo(%q) error = %v, want valid %v", tt.returnTo, err, tt.valid)
+		}
+	}
+}
@@ -38,3 +38,28 @@ func ValidatePaginationParams(limit, offset int) (int, int) {
 
 	return limit, offset
 }
+
+// ParseCommentTreeParams extracts how many reply levels to load and how many replies per subtree
+func ParseCommentTreeParams(r *http.Request) (depth, repliesLimit int) {
+	depth = config.Config.CommentTreeDepth
+	if depthStr := r.URL.Query().Get("depth"); depthStr != "" {
+		if parsed, err := strconv.Atoi(depthStr); err == nil && parsed >= 0 {
+			depth = parsed
+		}
+	}
+	if depth > config.Config.MaxCommentDepth {
+		depth = config.Config.MaxCommentDepth
+	}
+
+	repliesLimit = config.Config.CommentRepliesPageSize
+	if limitStr := r.URL.Query().Get("replies_limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			repliesLimit = parsed
+		}
+	}
+	if repliesLimit > config.Config.MaxPageSize {
+		repliesLimit = config.Config.MaxPageSize
+	}
+
+	return depth, repliesLimit
+}
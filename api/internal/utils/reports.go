@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+// ValidateReportText checks report details and moderator notes, which are optional
+func ValidateReportText(text string) error {
+	if utf8.RuneCountInString(text) > config.Config.MaxReportDetailsLength {
+		return fmt.Errorf("report text must be at most %d characters", config.Config.MaxReportDetailsLength)
+	}
+	return nil
+}
+
+// ParseReportFilters reads the "status" and "type" query parameters of the moderation queue.
+// Status defaults to pending (open and claimed reports).
+func ParseReportFilters(r *http.Request) (models.ReportFilters, error) {
+	filters := models.ReportFilters{
+		Status:     strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status"))),
+		TargetType: strings.ToLower(strings.TrimSpace(r.URL.Query().Get("type"))),
+	}
+
+	switch filters.Status {
+	case "":
+		filters.Status = models.ReportStatusPending
+	case models.ReportStatusPending, models.ReportStatusAll,
+		models.ReportStatusOpen, models.ReportStatusClaimed,
+		models.ReportStatusResolved, models.ReportStatusDismissed:
+	default:
+		return filters, errors.New("status must be pending, open, claimed, resolved, dismissed or all")
+	}
+
+	if filters.TargetType != "" && !models.IsValidReportTarget(filters.TargetType) {
+		return filters, errors.New("type must be post, comment or user")
+	}
+
+	return filters, nil
+}
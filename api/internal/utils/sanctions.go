@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+// ValidateSanctionRequest checks a ban, suspension or mute. Every sanction needs a reason;
+// suspensions and mutes need a duration, bans are permanent.
+func ValidateSanctionRequest(req models.CreateSanctionRequest) error {
+	if !models.IsValidSanctionType(req.Type) {
+		return errors.New("type must be ban, suspension or mute")
+	}
+
+	if req.Reason == "" {
+		return errors.New("a reason is required")
+	}
+	if utf8.RuneCountInString(req.Reason) > config.Config.MaxReportDetailsLength {
+		return fmt.Errorf("reason must be at most %d characters", config.Config.MaxReportDetailsLength)
+	}
+
+	if req.Type == models.SanctionBan {
+		if req.DurationHours != 0 {
+			return errors.New("bans are permanent and take no duration")
+		}
+		return nil
+	}
+
+	if req.DurationHours < 1 || req.DurationHours > config.Config.MaxSanctionHours {
+		return fmt.Errorf("duration_hours must be between 1 and %d", config.Config.MaxSanctionHours)
+	}
+	return nil
+}
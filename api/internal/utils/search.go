@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PaulKerasidis/forum/config"
+	"github.com/PaulKerasidis/forum/internal/models"
+)
+
+// maxSearchTerms caps how many terms end up in a single MATCH expression
+const maxSearchTerms = 10
+
+// ValidateSearchQuery checks the raw search text length using configuration
+func ValidateSearchQuery(query string) error {
+	length := utf8.RuneCountInString(strings.TrimSpace(query))
+	if length < config.Config.MinSearchQueryLength || length > config.Config.MaxSearchQueryLength {
+		return fmt.Errorf("search query must be between %d and %d characters",
+			config.Config.MinSearchQueryLength, config.Config.MaxSearchQueryLength)
+	}
+	return nil
+}
+
+// BuildFTSMatchQuery turns free text into a safe FTS5 MATCH expression.
+// Every term is quoted so user input can never be parsed as FTS5 syntax
+// (AND/OR/NEAR, column filters, unbalanced quotes); terms are implicitly ANDed
+// and the last term is a prefix match so partial words still find results.
+func BuildFTSMatchQuery(query string) (string, error) {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		term := strings.ReplaceAll(field, `"`, "")
+		if term == "" {
+			continue
+		}
+		terms = append(terms, `"`+term+`"`)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+
+	if len(terms) == 0 {
+		return "", errors.New("search query has no searchable terms")
+	}
+
+	terms[len(terms)-1] += "*"
+	return strings.Join(terms, " "), nil
+}
+
+// ParseSearchType reads the "type" query parameter, defaulting to posts
+func ParseSearchType(r *http.Request) (string, error) {
+	searchType := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("type")))
+	switch searchType {
+	case "", models.SearchTypePosts:
+		return models.SearchTypePosts, nil
+	case models.SearchTypeComments:
+		return models.SearchTypeComments, nil
+	default:
+		return "", errors.New("search type must be posts or comments")
+	}
+}
+
+// ParseSearchFilters extracts the optional category and author filters
+func ParseSearchFilters(r *http.Request) models.SearchFilters {
+	return models.SearchFilters{
+		CategoryID: strings.TrimSpace(r.URL.Query().Get("category")),
+		Author:     strings.TrimSpace(r.URL.Query().Get("author")),
+	}
+}
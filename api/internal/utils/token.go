@@ -27,8 +27,8 @@ func GenerateSessionToken() (string, error) {
 	return base64.URLEncoding.EncodeToString(b), nil
 }
 
-// CalculateSessionExpiry calculates the expiry time for a session
-// Default session lifetime is 24 hours
+// CalculateSessionExpiry calculates the expiry time for a session created now
 func CalculateSessionExpiry() time.Time {
-	return time.Now().Add(config.Config.SessionDuration) // fix later for not having magic numbers
+	now := time.Now()
+	return config.Config.SessionPolicy.ExpiresAt(now, now)
 }
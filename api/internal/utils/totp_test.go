@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 6238 SHA1 test key "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B vectors, cut to the six digits we use
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode at %d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode at %d = %q, want %q", tt.unix, got, tt.want)
+		}
+	}
+
+	// Apps show secrets in either case
+	if got, _ := TOTPCode(strings.ToLower(rfcSecret), 1); got != "287082" {
+		t.Errorf("TOTPCode with a lower case secret = %q, want 287082", got)
+	}
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("TOTPCode accepted an invalid secret")
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TOTPStep(now)
+	code := func(step int64) string {
+		c, err := TOTPCode(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name         string
+		code         string
+		lastUsedStep int64
+		wantStep     int64
+		wantOK       bool
+	}{
+		{"current step", code(current), 0, current, true},
+		{"previous step", code(current - 1), 0, current - 1, true},
+		{"next step", code(current + 1), 0, current + 1, true},
+		{"two steps old", code(current - 2), 0, 0, false},
+		{"two steps ahead", code(current + 2), 0, 0, false},
+		{"replayed code", code(current), current, 0, false},
+		{"code older than the last one used", code(current - 1), current, 0, false},
+		{"code newer than the last one used", code(current + 1), current, current + 1, true},
+		{"too short", code(current)[:5], 0, 0, false},
+		{"too long", code(current) + "0", 0, 0, false},
+		{"empty", "", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfcSecret, tt.code, now, tt.lastUsedStep)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP(%q, last used %d) = %d, %v, want %d, %v", tt.code, tt.lastUsedStep, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	format := regexp.MustCompile(`^[a-hjkmnp-z2-9]{5}-[a-hjkmnp-z2-9]{5}$`)
+
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("GenerateRecoveryCodes(10) returned %d codes", len(codes))
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range codes {
+		if !format.MatchString(c) {
+			t.Errorf("recovery code %q does not look like xxxxx-xxxxx without 0, o, 1, l or i", c)
+		}
+		if seen[c] {
+			t.Errorf("recovery code %q was generated twice", c)
+		}
+		seen[c] = true
+	}
+}
+
+func TestNormalizeTwoFactorCode(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"123456", "123456"},
+		{" 123 456 ", "123456"},
+		{"K7M2P-X9Q4T", "k7m2px9q4t"},
+		{"k7m2p x9q4t\n", "k7m2px9q4t"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeTwoFactorCode(tt.in); got != tt.want {
+			t.Errorf("NormalizeTwoFactorCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/mail"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/PaulKerasidis/forum/config"
@@ -31,8 +32,8 @@ func ValidatePassword(password string) error {
 	// Password validation using configuration
 	if len(password) < config.Config.MinPasswordLen || len(password) > config.Config.MaxPasswordLen {
 		return errors.New("password must be between " +
-			string(rune(config.Config.MinPasswordLen)) + " and " +
-			string(rune(config.Config.MaxPasswordLen)) + " characters")
+			strconv.Itoa(config.Config.MinPasswordLen) + " and " +
+			strconv.Itoa(config.Config.MaxPasswordLen) + " characters")
 	}
 
 	// Check password complexity
@@ -52,8 +53,8 @@ func ValidateUsername(username string) error {
 	// Username validation using configuration
 	if len(username) < config.Config.MinUsernameLen || len(username) > config.Config.MaxUsernameLen {
 		return errors.New("username must be between " +
-			string(rune(config.Config.MinUsernameLen)) + " and " +
-			string(rune(config.Config.MaxUsernameLen)) + " characters")
+			strconv.Itoa(config.Config.MinUsernameLen) + " and " +
+			strconv.Itoa(config.Config.MaxUsernameLen) + " characters")
 	}
 
 	// Username character validation (alphanumeric and underscore only)
@@ -78,12 +79,36 @@ func ValidateUserInput(username, email, password string) error {
 	return nil
 }
 
+func ValidatePostTitle(title string) error {
+	// Title validation using configuration
+	if len(title) < config.Config.MinPostTitleLength || len(title) > config.Config.MaxPostTitleLength {
+		return errors.New("post title must be between " +
+			strconv.Itoa(config.Config.MinPostTitleLength) + " and " +
+			strconv.Itoa(config.Config.MaxPostTitleLength) + " characters")
+	}
+
+	// Titles are a single line
+	if strings.ContainsAny(title, "\r\n") {
+		return errors.New("post title must be a single line")
+	}
+
+	// Check for prohibited words (example)
+	prohibitedWords := []string{"fuck", "bitch", "asshole"}
+	for _, word := range prohibitedWords {
+		if strings.Contains(strings.ToLower(title), word) {
+			return errors.New("post title contains prohibited words")
+		}
+	}
+
+	return nil
+}
+
 func ValidatePostContent(content string) error {
 	// Content validation using configuration
 	if len(content) < config.Config.MinPostContentLength || len(content) > config.Config.MaxPostContentLength {
 		return errors.New("post content must be between " +
-			string(rune(config.Config.MinPostContentLength)) + " and " +
-			string(rune(config.Config.MaxPostContentLength)) + " characters")
+			strconv.Itoa(config.Config.MinPostContentLength) + " and " +
+			strconv.Itoa(config.Config.MaxPostContentLength) + " characters")
 	}
 
 	// Check for prohibited words (example)
@@ -101,8 +126,8 @@ func ValidateCommentContent(content string) error {
 	// Content validation using configuration
 	if len(content) < config.Config.MinCommentLength || len(content) > config.Config.MaxCommentLength {
 		return errors.New("comment content must be between " +
-			string(rune(config.Config.MinCommentLength)) + " and " +
-			string(rune(config.Config.MaxCommentLength)) + " characters")
+			strconv.Itoa(config.Config.MinCommentLength) + " and " +
+			strconv.Itoa(config.Config.MaxCommentLength) + " characters")
 	}
 
 	// Check for prohibited words (example)
@@ -1,47 +1,183 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/PaulKerasidis/forum/config"
 	"github.com/PaulKerasidis/forum/database"
+	"github.com/PaulKerasidis/forum/database/migrations"
+	"github.com/PaulKerasidis/forum/internal/logging"
+	"github.com/PaulKerasidis/forum/internal/mailer"
+	"github.com/PaulKerasidis/forum/internal/models"
+	"github.com/PaulKerasidis/forum/internal/notifier"
+	"github.com/PaulKerasidis/forum/internal/oauth"
+	"github.com/PaulKerasidis/forum/internal/repository"
 	"github.com/PaulKerasidis/forum/internal/routes"
+	"github.com/PaulKerasidis/forum/internal/utils"
 )
 
 func main() {
 	// Load configuration
 	err := config.LoadConfig()
 	if err != nil {
-		log.Panic(err)
+		fatal("Invalid configuration", err)
 	}
 
-	// Debug: Check if OAuth config is loaded
-	fmt.Printf("OAuth Client ID loaded: %s\n", config.Config.GoogleOAuthClientID)
-	if config.Config.GoogleOAuthClientID == "" {
-		log.Println("WARNING: Google OAuth Client ID is empty!")
+	// JSON logs on stdout, with secrets redacted
+	logging.Setup(os.Stdout, config.Config.LogLevel)
+
+	// "migrate status|up|to N" manages the schema and exits without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	// "set-role <email> <role>" promotes or demotes a user, e.g. to create the first admin
+	if len(os.Args) > 1 && os.Args[1] == "set-role" {
+		runSetRole(os.Args[2:])
+		return
 	}
 
 	// Initialize the database
 	db, err := database.InitDB()
 	if err != nil {
-		panic(err)
+		fatal("Failed to initialize database", err)
+	}
+
+	// Email delivery for verification and password reset links
+	m, err := mailer.New()
+	if err != nil {
+		fatal("Failed to set up mailer", err)
+	}
+
+	// Tells users about lockouts and other security events on their account
+	n, err := notifier.New(m)
+	if err != nil {
+		fatal("Failed to set up notifier", err)
+	}
+
+	// Login providers with a client ID configured
+	providers, err := oauth.NewRegistryFromConfig()
+	if err != nil {
+		fatal("Failed to set up OAuth providers", err)
 	}
-	defer db.Close()
 
 	// Setup API routes
-	apiRoutes := routes.SetupRoutes(db)
+	apiRoutes := routes.SetupRoutes(db, m, n, providers)
 
 	// Create server using config values
 	serverAddr := fmt.Sprintf("%s:%s", config.Config.ServerHost, config.Config.ServerPort)
+	server := &http.Server{
+		Addr:              serverAddr,
+		Handler:           apiRoutes,
+		ReadTimeout:       config.Config.ServerReadTimeout,
+		ReadHeaderTimeout: config.Config.ServerReadHeaderTimeout,
+		WriteTimeout:      config.Config.ServerWriteTimeout,
+		IdleTimeout:       config.Config.ServerIdleTimeout,
+		MaxHeaderBytes:    config.Config.ServerMaxHeaderBytes,
+	}
+
+	for _, p := range providers.Providers() {
+		slog.Info("OAuth provider enabled", "provider", p.Name, "name", p.DisplayName)
+	}
+	slog.Info("Starting API server", "addr", serverAddr, "log_level", config.Config.LogLevel.String())
+
+	serveErr := serve(server)
+	shutdown(server, db)
+	if serveErr != nil {
+		fatal("Server failed", serveErr)
+	}
+}
+
+// fatal logs an error that stops the server from starting or running, and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// serve runs the server until it fails or the process receives SIGINT or SIGTERM
+func serve(server *http.Server) error {
+	stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-stop.Done():
+		slog.Info("Shutting down", "timeout", config.Config.ShutdownTimeout.String())
+		return nil
+	}
+}
+
+// shutdown stops accepting connections, lets in-flight requests and background work
+// such as mail finish within SHUTDOWN_TIMEOUT, then checkpoints and closes the database
+func shutdown(server *http.Server, db *sql.DB) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Config.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("Error draining requests", "err", err)
+	}
+	if err := utils.WaitForBackground(ctx); err != nil {
+		slog.Error("Error waiting for background work", "err", err)
+	}
+	if err := database.CloseDB(db); err != nil {
+		slog.Error("Error closing database", "err", err)
+		return
+	}
+	slog.Info("Server stopped")
+}
 
-	fmt.Printf("OAuth routes registered:\n")
-	fmt.Printf("- /auth/google/login\n")
-	fmt.Printf("- /auth/google/callback\n")
-	fmt.Printf("- /api/auth/google/login\n")
-	fmt.Printf("- /api/auth/google/callback\n")
-	fmt.Printf("Starting API server on %s\n", serverAddr)
+// runMigrate runs the migrate subcommand against the configured database
+func runMigrate(args []string) {
+	db, err := database.OpenDB()
+	if err != nil {
+		fatal("Failed to open database", err)
+	}
+	defer db.Close()
+
+	if err := migrations.RunCommand(db, args, os.Stdout); err != nil {
+		db.Close()
+		fatal("Migration command failed", err)
+	}
+}
+
+// runSetRole changes a user's role from the command line
+func runSetRole(args []string) {
+	if len(args) != 2 {
+		fatal("Invalid arguments", errors.New("usage: set-role <email> <member|moderator|admin>"))
+	}
+
+	db, err := database.InitDB()
+	if err != nil {
+		fatal("Failed to initialize database", err)
+	}
+	defer db.Close()
+
+	userRepo := repository.NewUserRepository(db)
+	user, err := userRepo.GetUserByEmail(args[0])
+	if err != nil {
+		db.Close()
+		fatal("Failed to find user", fmt.Errorf("%s: %w", args[0], err))
+	}
+
+	if err := userRepo.SetUserRole(user.ID, args[1], models.SystemActor); err != nil {
+		db.Close()
+		fatal("Failed to set role", fmt.Errorf("%s: %w", args[0], err))
+	}
 
-	log.Fatal(http.ListenAndServe(serverAddr, apiRoutes))
+	fmt.Printf("%s (%s) is now %s\n", user.Username, user.Email, args[1])
 }
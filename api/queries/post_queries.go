@@ -5,15 +5,37 @@ const (
 	BaseSelectFields = `p.post_id,
 		p.user_id,
 		u.username,
+		p.title,
 		p.content,
 		p.created_at,
 		p.updated_at,
+		CASE WHEN p.updated_by <> p.user_id THEN p.updated_by_role ELSE '' END as updated_by_role,
 		COALESCE(like_counts.count, 0) as like_count,
 		COALESCE(dislike_counts.count, 0) as dislike_count,
 		COALESCE(comment_counts.count, 0) as comment_count,
 		GROUP_CONCAT(DISTINCT c.category_id || ':' || c.category_name) as categories,
 		ur.reaction_type as user_reaction`
 
+	// SELECT fields for post summaries (list views) - only the start of the content is read,
+	// content_length tells whether the excerpt was cut
+	SummarySelectFields = `p.post_id,
+		p.user_id,
+		u.username,
+		p.title,
+		substr(p.content, 1, ` + ExcerptSourceLength + `) as excerpt,
+		length(p.content) as content_length,
+		p.created_at,
+		p.updated_at,
+		comment_counts.last_comment_at,
+		COALESCE(like_counts.count, 0) as like_count,
+		COALESCE(dislike_counts.count, 0) as dislike_count,
+		COALESCE(comment_counts.count, 0) as comment_count,
+		GROUP_CONCAT(DISTINCT c.category_id || ':' || c.category_name) as categories,
+		ur.reaction_type as user_reaction`
+
+	// Upper bound for POST_EXCERPT_LENGTH
+	ExcerptSourceLength = `500`
+
 	// Base JOINs for posts -
 	BaseJoins = `JOIN users u ON p.user_id = u.user_id
 		LEFT JOIN post_categories pc ON p.post_id = pc.post_id
@@ -31,7 +53,7 @@ const (
 		LEFT JOIN categories c ON pc.category_id = c.category_id`
 
 	// Commented posts filtering JOIN -
-	CommentedPostsJoin = `JOIN comments com ON p.post_id = com.post_id
+	CommentedPostsJoin = `JOIN comments com ON p.post_id = com.post_id AND com.deleted_at IS NULL
 		JOIN users u ON p.user_id = u.user_id
 		LEFT JOIN post_categories pc ON p.post_id = pc.post_id
 		LEFT JOIN categories c ON pc.category_id = c.category_id`
@@ -50,8 +72,9 @@ const (
 			GROUP BY post_id
 		) dislike_counts ON p.post_id = dislike_counts.post_id
 		LEFT JOIN (
-			SELECT post_id, COUNT(*) as count
+			SELECT post_id, COUNT(*) as count, MAX(created_at) as last_comment_at
 			FROM comments
+			WHERE deleted_at IS NULL
 			GROUP BY post_id
 		) comment_counts ON p.post_id = comment_counts.post_id`
 
@@ -131,9 +154,9 @@ var (
 
 // NEW: Dynamic Query Builder Functions
 
-// BuildPostsQuery creates a dynamic query with sorting and filtering options
+// BuildPostsQuery creates a dynamic post summaries query with sorting and filtering options
 func BuildPostsQuery(joins, whereClause, orderClause string) string {
-	return `SELECT ` + BaseSelectFields + `
+	return `SELECT ` + SummarySelectFields + `
 		FROM posts p
 		` + joins + `
 		` + ReactionCountJoins + `
@@ -0,0 +1,87 @@
+package queries
+
+const (
+	// Snippet markers - the frontend escapes the snippet and turns these back into <mark> tags
+	SearchHighlightStart = `<mark>`
+	SearchHighlightEnd   = `</mark>`
+
+	// Post categories as a correlated subquery - FTS5 auxiliary functions
+	// (snippet, rank) can't be used together with GROUP BY
+	searchPostCategories = `(SELECT GROUP_CONCAT(cat.category_id || ':' || cat.category_name)
+			FROM post_categories spc
+			JOIN categories cat ON spc.category_id = cat.category_id
+			WHERE spc.post_id = p.post_id) as categories`
+
+	// Base SELECT fields for post search results
+	SearchPostsSelectFields = `p.post_id,
+		p.user_id,
+		u.username,
+		p.title,
+		snippet(posts_fts, 2, '` + SearchHighlightStart + `', '` + SearchHighlightEnd + `', '…', 24) as snippet,
+		p.created_at,
+		` + searchPostCategories + `,
+		posts_fts.rank`
+
+	// Base SELECT fields for comment search results
+	SearchCommentsSelectFields = `c.comment_id,
+		c.post_id,
+		c.user_id,
+		u.username,
+		p.title,
+		snippet(comments_fts, 1, '` + SearchHighlightStart + `', '` + SearchHighlightEnd + `', '…', 24) as snippet,
+		c.created_at,
+		` + searchPostCategories + `,
+		comments_fts.rank`
+
+	// FROM/JOIN clauses
+	SearchPostsFrom = `FROM posts_fts
+		JOIN posts p ON p.post_id = posts_fts.post_id
+		JOIN users u ON p.user_id = u.user_id`
+
+	SearchCommentsFrom = `FROM comments_fts
+		JOIN comments c ON c.comment_id = comments_fts.comment_id
+		JOIN posts p ON p.post_id = c.post_id
+		JOIN users u ON c.user_id = u.user_id`
+
+	// Filters - the post is always aliased as p so the category filter works for both
+	SearchMatchPostsWhere    = `WHERE posts_fts MATCH ?`
+	SearchMatchCommentsWhere = `WHERE comments_fts MATCH ?`
+	SearchCategoryFilter     = ` AND EXISTS (SELECT 1 FROM post_categories fpc WHERE fpc.post_id = p.post_id AND fpc.category_id = ?)`
+	SearchAuthorFilter       = ` AND u.username = ? COLLATE NOCASE`
+
+	// bm25 ranking - lower is better, newest first on ties
+	SearchPostsOrderByRank    = `ORDER BY posts_fts.rank, p.created_at DESC`
+	SearchCommentsOrderByRank = `ORDER BY comments_fts.rank, c.created_at DESC`
+)
+
+// BuildSearchPostsQuery creates the ranked post search query for the given WHERE clause
+func BuildSearchPostsQuery(whereClause string) string {
+	return `SELECT ` + SearchPostsSelectFields + `
+		` + SearchPostsFrom + `
+		` + whereClause + `
+		` + SearchPostsOrderByRank + `
+		` + LimitOffset
+}
+
+// BuildSearchCommentsQuery creates the ranked comment search query for the given WHERE clause
+func BuildSearchCommentsQuery(whereClause string) string {
+	return `SELECT ` + SearchCommentsSelectFields + `
+		` + SearchCommentsFrom + `
+		` + whereClause + `
+		` + SearchCommentsOrderByRank + `
+		` + LimitOffset
+}
+
+// BuildSearchPostsCountQuery counts all post matches for the given WHERE clause
+func BuildSearchPostsCountQuery(whereClause string) string {
+	return `SELECT COUNT(*)
+		` + SearchPostsFrom + `
+		` + whereClause
+}
+
+// BuildSearchCommentsCountQuery counts all comment matches for the given WHERE clause
+func BuildSearchCommentsCountQuery(whereClause string) string {
+	return `SELECT COUNT(*)
+		` + SearchCommentsFrom + `
+		` + whereClause
+}
@@ -1,11 +1,25 @@
 package config
 
-import "os"
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
 
 // Config holds all configuration for the frontend service
 type Config struct {
 	// Server configuration
-	Port string
+	Port     string
+	LogLevel slog.Level // least severe level logged
+
+	// HTTP server limits
+	ReadTimeout       time.Duration // whole request, body included
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration // must leave time for the API calls a page makes
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	ShutdownTimeout   time.Duration // time allowed to drain requests on SIGTERM
 
 	// Backend API configuration
 	APIBaseURL string
@@ -16,16 +30,27 @@ type Config struct {
 
 	// Session configuration (only what's needed)
 	SessionName string // For cookie name consistency with backend
+
+	// Key CSRF tokens are derived with; replicas must share it
+	CSRFSecret string
 }
 
 // LoadConfig loads configuration from environment variables with defaults
 func LoadConfig() *Config {
 	return &Config{
-		Port:         getEnv("FRONTEND_PORT", "3000"),
-		APIBaseURL:   getEnv("API_BASE_URL", "http://localhost:8080/api"),
-		TemplatesDir: getEnv("TEMPLATES_DIR", "./web/templates"),
-		StaticDir:    getEnv("STATIC_DIR", "./web/static"),
-		SessionName:  getEnv("SESSION_NAME", "forum_session"),
+		Port:              getEnv("FRONTEND_PORT", "3000"),
+		LogLevel:          getEnvAsLogLevel("LOG_LEVEL", slog.LevelInfo),
+		ReadTimeout:       getEnvAsDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+		ReadHeaderTimeout: getEnvAsDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+		WriteTimeout:      getEnvAsDuration("SERVER_WRITE_TIMEOUT", 60*time.Second),
+		IdleTimeout:       getEnvAsDuration("SERVER_IDLE_TIMEOUT", 2*time.Minute),
+		MaxHeaderBytes:    getEnvAsInt("SERVER_MAX_HEADER_BYTES", 1<<20),
+		ShutdownTimeout:   getEnvAsDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
+		APIBaseURL:        getEnv("API_BASE_URL", "http://localhost:8080/api"),
+		TemplatesDir:      getEnv("TEMPLATES_DIR", "./web/templates"),
+		StaticDir:         getEnv("STATIC_DIR", "./web/static"),
+		SessionName:       getEnv("SESSION_NAME", "forum_session"),
+		CSRFSecret:        getEnv("CSRF_SECRET", ""),
 	}
 }
 
@@ -36,3 +61,28 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvAsDuration reads a positive duration such as "30s", keeping the default otherwise
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return defaultValue
+}
+
+// getEnvAsInt reads a positive integer, keeping the default otherwise
+func getEnvAsInt(key string, defaultValue int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return defaultValue
+}
+
+// getEnvAsLogLevel reads debug, info, warn or error, keeping the default otherwise
+func getEnvAsLogLevel(key string, defaultValue slog.Level) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(os.Getenv(key))); err == nil {
+		return level
+	}
+	return defaultValue
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"frontend-service/internal/models"
+	"frontend-service/internal/services"
+	"frontend-service/internal/session"
+	"frontend-service/internal/validations"
+)
+
+// AccountHandler serves the password reset and email verification pages
+type AccountHandler struct {
+	authService     *services.AuthService
+	templateService *services.TemplateService
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(authService *services.AuthService, templateService *services.TemplateService) *AccountHandler {
+	return &AccountHandler{
+		authService:     authService,
+		templateService: templateService,
+	}
+}
+
+// ServeForgotPassword shows the forgot password form and emails a reset link on submit
+func (h *AccountHandler) ServeForgotPassword(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.render(w, r, "forgot-password.html", models.AccountPageData{})
+	case http.MethodPost:
+		if err := r.ParseForm(); err != nil {
+			h.render(w, r, "forgot-password.html", models.AccountPageData{Error: "Invalid form data"})
+			return
+		}
+
+		email := strings.TrimSpace(r.FormValue("email"))
+		if err := validations.ValidateEmail(email); err != nil {
+			h.render(w, r, "forgot-password.html", models.AccountPageData{Error: err.Error(), Email: email})
+			return
+		}
+
+		if err := h.authService.ForgotPassword(r.Context(), email); err != nil {
+			h.render(w, r, "forgot-password.html", models.AccountPageData{Error: apiErrorMessage(r.Context(), err, "forgot password"), Email: email})
+			return
+		}
+
+		h.render(w, r, "forgot-password.html", models.AccountPageData{
+			Success: "If an account exists for that email, we have sent a link to reset your password. Check your inbox.",
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ServeResetPassword shows the new password form for a reset link and applies it on submit
+func (h *AccountHandler) ServeResetPassword(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			h.render(w, r, "reset-password.html", models.AccountPageData{Error: "This reset link is incomplete. Please request a new one."})
+			return
+		}
+		h.render(w, r, "reset-password.html", models.AccountPageData{Token: token})
+	case http.MethodPost:
+		if err := r.ParseForm(); err != nil {
+			h.render(w, r, "reset-password.html", models.AccountPageData{Error: "Invalid form data"})
+			return
+		}
+
+		token := r.FormValue("token")
+		password := r.FormValue("password")
+		confirmPassword := r.FormValue("confirm_password")
+
+		if password != confirmPassword {
+			h.render(w, r, "reset-password.html", models.AccountPageData{Error: "Passwords do not match", Token: token})
+			return
+		}
+		if err := validations.ValidatePassword(password); err != nil {
+			h.render(w, r, "reset-password.html", models.AccountPageData{Error: err.Error(), Token: token})
+			return
+		}
+
+		if err := h.authService.ResetPassword(r.Context(), token, password, confirmPassword); err != nil {
+			h.render(w, r, "reset-password.html", models.AccountPageData{
+				Error:       apiErrorMessage(r.Context(), err, "reset password"),
+				FieldErrors: services.FieldErrors(err),
+				Token:       token,
+			})
+			return
+		}
+
+		h.render(w, r, "login.html", models.LoginPageData{
+			Success:  "Your password has been reset. Please log in with your new password.",
+			FormData: &models.UserLogin{},
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ServeVerifyEmail confirms the email address from a verification link
+func (h *AccountHandler) ServeVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		h.render(w, r, "verify-email.html", models.AccountPageData{Error: "This verification link is incomplete."})
+		return
+	}
+
+	if err := h.authService.VerifyEmail(r.Context(), token); err != nil {
+		h.render(w, r, "verify-email.html", models.AccountPageData{Error: apiErrorMessage(r.Context(), err, "verify email")})
+		return
+	}
+
+	h.render(w, r, "verify-email.html", models.AccountPageData{Success: "Thanks, your email address is confirmed."})
+}
+
+// ServeResendVerification emails the logged-in user a new verification link
+func (h *AccountHandler) ServeResendVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessionCookie, err := session.GetSessionCookie(r, h.authService)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	result := "sent"
+	if err := h.authService.ResendVerification(r.Context(), sessionCookie); err != nil {
+		if services.IsStatus(err, http.StatusUnauthorized) {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error resending verification email", "err", err)
+		result = "failed"
+	}
+
+	http.Redirect(w, r, "/profile?email="+result, http.StatusSeeOther)
+}
+
+// render writes an account page, logging template failures
+func (h *AccountHandler) render(w http.ResponseWriter, r *http.Request, name string, data interface{}) {
+	if err := h.templateService.Render(w, name, data); err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering template", "template", name, "err", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
+
+// fieldErrorsMessage heads a form whose fields the API rejected
+const fieldErrorsMessage = "Please correct the highlighted fields"
+
+// apiErrorMessage returns the backend's message for a rejected request, or a generic one
+func apiErrorMessage(ctx context.Context, err error, action string) string {
+	if services.FieldErrors(err) != nil {
+		return fieldErrorsMessage
+	}
+	if msg := services.ErrorMessage(err); msg != "" {
+		return msg
+	}
+	slog.ErrorContext(ctx, "API call failed", "action", action, "err", err)
+	return "Something went wrong, please try again later"
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"frontend-service/config"
@@ -59,40 +61,32 @@ func (h *AuthHandler) ServeLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("Logout request received")
-
 	// Get session cookie using config value
 	cookie, err := session.GetSessionCookie(r, h.authService) // CHANGED: Use utility function with config
 	if err != nil {
-		log.Printf("No session cookie found during logout")
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
 
-	log.Printf("Found session cookie, calling backend logout")
-
 	// Call backend to logout
-	if err := h.authService.LogoutUser(cookie.Value); err != nil {
-		log.Printf("Error during backend logout: %v", err)
-	} else {
-		log.Printf("Backend logout successful")
+	if err := h.authService.LogoutUser(r.Context(), cookie.Value); err != nil {
+		slog.ErrorContext(r.Context(), "Error during backend logout", "err", err)
 	}
 
 	// Clear the session cookie on frontend using config value
 	utils.ClearSessionCookie(h.config.SessionName, w) // CHANGED: Use config session name
 
-	log.Printf("Session cookie cleared, redirecting to home")
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
 // showRegisterForm displays the registration form (GET request)
-func (h *AuthHandler) showRegisterForm(w http.ResponseWriter, _ *http.Request) {
+func (h *AuthHandler) showRegisterForm(w http.ResponseWriter, r *http.Request) {
 	data := models.RegisterPageData{
 		FormData: &models.UserRegistration{}, // Empty form data for initial load
 	}
 
 	if err := h.templateService.Render(w, "register.html", data); err != nil {
-		log.Printf("Error rendering register template: %v", err)
+		slog.ErrorContext(r.Context(), "Error rendering register template", "err", err)
 		http.Error(w, "Failed to render page", http.StatusInternalServerError)
 	}
 }
@@ -101,8 +95,8 @@ func (h *AuthHandler) showRegisterForm(w http.ResponseWriter, _ *http.Request) {
 func (h *AuthHandler) handleRegisterForm(w http.ResponseWriter, r *http.Request) {
 	// Parse form data
 	if err := r.ParseForm(); err != nil {
-		log.Printf("Error parsing form: %v", err)
-		h.showRegisterError(w, "Invalid form data", &models.UserRegistration{})
+		slog.ErrorContext(r.Context(), "Error parsing form", "err", err)
+		h.showRegisterError(w, "Invalid form data", nil, &models.UserRegistration{})
 		return
 	}
 
@@ -116,73 +110,79 @@ func (h *AuthHandler) handleRegisterForm(w http.ResponseWriter, r *http.Request)
 
 	// Basic validation (check if all fields are provided)
 	if formData.Username == "" || formData.Email == "" || formData.Password == "" {
-		h.showRegisterError(w, "All fields are required", &formData)
+		h.showRegisterError(w, "All fields are required", nil, &formData)
 		return
 	}
 
 	// NEW: Check password confirmation
 	if formData.ConfirmPassword == "" {
-		h.showRegisterError(w, "Password confirmation is required", &formData)
+		h.showRegisterError(w, "Password confirmation is required", nil, &formData)
 		return
 	}
 
 	// NEW: Validate passwords match (frontend validation)
 	if formData.Password != formData.ConfirmPassword {
-		h.showRegisterError(w, "Passwords do not match", &formData)
+		h.showRegisterError(w, "Passwords do not match", nil, &formData)
 		return
 	}
 
 	// FRONTEND VALIDATION using your validation functions
 	if err := validations.ValidateUserInput(formData.Username, formData.Email, formData.Password); err != nil {
-		h.showRegisterError(w, err.Error(), &formData)
+		h.showRegisterError(w, err.Error(), nil, &formData)
 		return
 	}
 
 	// Call backend API to register user (backend will also validate)
-	if err := h.authService.RegisterUser(formData); err != nil {
-		log.Printf("Registration error: %v", err)
-		h.showRegisterError(w, err.Error(), &formData)
+	if err := h.authService.RegisterUser(r.Context(), formData); err != nil {
+		h.showRegisterError(w, apiErrorMessage(r.Context(), err, "registration"), services.FieldErrors(err), &formData)
 		return
 	}
 
 	// Registration successful - show success message with empty form
 	data := models.RegisterPageData{
-		Success:  "Registration successful! You can now login.",
+		Success:  "Registration successful! We have emailed you a link to confirm your address. You can login now.",
 		FormData: &models.UserRegistration{}, // Clear form on success
 	}
 
 	if err := h.templateService.Render(w, "register.html", data); err != nil {
-		log.Printf("Error rendering register template: %v", err)
+		slog.ErrorContext(r.Context(), "Error rendering register template", "err", err)
 		http.Error(w, "Failed to render page", http.StatusInternalServerError)
 	}
 }
 
 // showRegisterError displays registration form with error message AND preserved form data
-func (h *AuthHandler) showRegisterError(w http.ResponseWriter, errorMsg string, formData *models.UserRegistration) {
+// fieldErrors, when the API sent them, are shown next to their inputs.
+func (h *AuthHandler) showRegisterError(w http.ResponseWriter, errorMsg string, fieldErrors map[string]string, formData *models.UserRegistration) {
 	// Clear password for security - user will need to retype it
 	if formData != nil {
 		formData.Password = ""
 	}
 
 	data := models.RegisterPageData{
-		Error:    errorMsg,
-		FormData: formData, // Pass back the form data so fields stay populated
+		Error:       errorMsg,
+		FieldErrors: fieldErrors,
+		FormData:    formData, // Pass back the form data so fields stay populated
 	}
 
 	if err := h.templateService.Render(w, "register.html", data); err != nil {
-		log.Printf("Error rendering register template: %v", err)
+		slog.Error("Error rendering register template", "err", err)
 		http.Error(w, "Failed to render page", http.StatusInternalServerError)
 	}
 }
 
 // showLoginForm displays the login form (GET request)
-func (h *AuthHandler) showLoginForm(w http.ResponseWriter, _ *http.Request) {
+func (h *AuthHandler) showLoginForm(w http.ResponseWriter, r *http.Request) {
 	data := models.LoginPageData{
-		FormData: &models.UserLogin{}, // Empty form data for initial load
+		Error:          oauthErrors[r.URL.Query().Get("error")],
+		FormData:       &models.UserLogin{}, // Empty form data for initial load
+		OAuthProviders: h.oauthProviders(r),
+	}
+	if returnTo := utils.SafeReturnTo(r.URL.Query().Get("return_to")); returnTo != "/" {
+		data.ReturnTo = returnTo
 	}
 
 	if err := h.templateService.Render(w, "login.html", data); err != nil {
-		log.Printf("Error rendering login template: %v", err)
+		slog.ErrorContext(r.Context(), "Error rendering login template", "err", err)
 		http.Error(w, "Failed to render page", http.StatusInternalServerError)
 	}
 }
@@ -191,8 +191,8 @@ func (h *AuthHandler) showLoginForm(w http.ResponseWriter, _ *http.Request) {
 func (h *AuthHandler) handleLoginForm(w http.ResponseWriter, r *http.Request) {
 	// Parse form data
 	if err := r.ParseForm(); err != nil {
-		log.Printf("Error parsing form: %v", err)
-		h.showLoginError(w, "Invalid form data", &models.UserLogin{})
+		slog.ErrorContext(r.Context(), "Error parsing form", "err", err)
+		h.showLoginError(w, r, "Invalid form data", &models.UserLogin{})
 		return
 	}
 
@@ -204,27 +204,33 @@ func (h *AuthHandler) handleLoginForm(w http.ResponseWriter, r *http.Request) {
 
 	// Basic validation (check if all fields are provided)
 	if formData.Email == "" || formData.Password == "" {
-		h.showLoginError(w, "Email and password are required", &formData)
+		h.showLoginError(w, r, "Email and password are required", &formData)
 		return
 	}
 
 	// FRONTEND VALIDATION using your validation functions
 	err := validations.ValidateEmail(formData.Email)
 	if err != nil {
-		h.showLoginError(w, err.Error(), &formData)
+		h.showLoginError(w, r, err.Error(), &formData)
 		return
 	}
 	err = validations.ValidatePassword(formData.Password)
 	if err != nil {
-		h.showLoginError(w, err.Error(), &formData)
+		h.showLoginError(w, r, err.Error(), &formData)
 		return
 	}
 
 	// Call backend API to login user
-	user, sessionID, err := h.authService.LoginUser(formData)
+	user, sessionID, err := h.authService.LoginUser(r.Context(), formData, r.UserAgent())
 	if err != nil {
-		log.Printf("Login error: %v", err)
-		h.showLoginError(w, err.Error(), &formData)
+		// Password accepted; ask for the code from the user's authenticator app
+		var twoFactor *services.TwoFactorRequiredError
+		if errors.As(err, &twoFactor) {
+			h.showTwoFactorForm(w, twoFactor.Token, "", "")
+			return
+		}
+		slog.ErrorContext(r.Context(), "Login error", "err", err)
+		h.showLoginError(w, r, err.Error(), &formData)
 		return
 	}
 
@@ -233,24 +239,106 @@ func (h *AuthHandler) handleLoginForm(w http.ResponseWriter, r *http.Request) {
 	utils.SetSessionCookie(h.config.SessionName, sessionID, w, r, expiresAt) // CHANGED: Use utility with config session name
 
 	// Login successful - redirect to home page
-	log.Printf("User %s logged in successfully", user.Username)
+	slog.InfoContext(r.Context(), "User logged in", "user_id", user.ID)
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+// ServeLoginTwoFactor handles the second login step for accounts with two-factor login on
+func (h *AuthHandler) ServeLoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	// Provider logins for accounts with two-factor on are redirected here with the token
+	if r.Method == http.MethodGet {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		h.showTwoFactorForm(w, token, r.URL.Query().Get("return_to"), "")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.showLoginError(w, r, "Invalid form data", &models.UserLogin{})
+		return
+	}
+
+	token := r.FormValue("token")
+	returnTo := r.FormValue("return_to")
+	code := strings.TrimSpace(r.FormValue("code"))
+	if token == "" {
+		h.showLoginError(w, r, "Your login has expired, please sign in again", &models.UserLogin{})
+		return
+	}
+	if code == "" {
+		h.showTwoFactorForm(w, token, returnTo, "Enter the code from your authenticator app")
+		return
+	}
+
+	user, sessionID, err := h.authService.LoginTwoFactor(r.Context(), token, code, r.UserAgent())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Two-factor login error", "err", err)
+		if services.IsProblem(err, services.ProblemInvalidCode) {
+			h.showTwoFactorForm(w, token, returnTo, "That code is not valid, please try again")
+			return
+		}
+		// Expired logins, too many wrong codes and sanctions all mean starting over
+		h.showLoginError(w, r, err.Error(), &models.UserLogin{})
+		return
+	}
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	utils.SetSessionCookie(h.config.SessionName, sessionID, w, r, expiresAt)
+
+	slog.InfoContext(r.Context(), "User logged in", "user_id", user.ID, "two_factor", true)
+	http.Redirect(w, r, utils.SafeReturnTo(returnTo), http.StatusSeeOther)
+}
+
+// showTwoFactorForm asks for the second factor of a login in progress.
+// returnTo is carried through the form so provider logins still land where they started.
+func (h *AuthHandler) showTwoFactorForm(w http.ResponseWriter, token, returnTo, errorMsg string) {
+	data := models.LoginTwoFactorPageData{
+		Token: token,
+		Error: errorMsg,
+	}
+	if returnTo = utils.SafeReturnTo(returnTo); returnTo != "/" {
+		data.ReturnTo = returnTo
+	}
+
+	if err := h.templateService.Render(w, "login-2fa.html", data); err != nil {
+		slog.Error("Error rendering two-factor login template", "err", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
+
 // showLoginError displays login form with error message AND preserved form data
-func (h *AuthHandler) showLoginError(w http.ResponseWriter, errorMsg string, formData *models.UserLogin) {
+func (h *AuthHandler) showLoginError(w http.ResponseWriter, r *http.Request, errorMsg string, formData *models.UserLogin) {
 	// Clear password for security - user will need to retype it
 	if formData != nil {
 		formData.Password = ""
 	}
 
 	data := models.LoginPageData{
-		Error:    errorMsg,
-		FormData: formData, // Pass back the form data so fields stay populated
+		Error:          errorMsg,
+		FormData:       formData, // Pass back the form data so fields stay populated
+		OAuthProviders: h.oauthProviders(r),
 	}
 
 	if err := h.templateService.Render(w, "login.html", data); err != nil {
-		log.Printf("Error rendering login template: %v", err)
+		slog.ErrorContext(r.Context(), "Error rendering login template", "err", err)
 		http.Error(w, "Failed to render page", http.StatusInternalServerError)
 	}
 }
+
+// oauthProviders lists the login providers to offer; the page still works without them
+func (h *AuthHandler) oauthProviders(r *http.Request) []models.OAuthProvider {
+	providers, err := h.authService.GetOAuthProviders(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error loading login providers", "err", err)
+		return nil
+	}
+	return providers
+}
@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 
 	"frontend-service/internal/models"
@@ -48,17 +48,17 @@ func (h *CategoryHandler) ServeCategoryPosts(w http.ResponseWriter, r *http.Requ
 	sessionCookie, _ := session.GetSessionCookie(r, h.authService)
 
 	// Get posts by category from backend
-	categoryData, err := h.postService.GetPostsByCategory(categoryID, pagination.Limit, pagination.Offset, sortBy, sessionCookie)
+	categoryData, err := h.postService.GetPostsByCategory(r.Context(), categoryID, pagination.Limit, pagination.Offset, sortBy, sessionCookie)
 	if err != nil {
-		log.Printf("Error fetching category posts: %v", err)
+		slog.ErrorContext(r.Context(), "Error fetching category posts", "err", err)
 		http.Error(w, "Failed to load posts", http.StatusInternalServerError)
 		return
 	}
 
 	// Get all categories for sidebar
-	categories, err := h.categoryService.GetCategories()
+	categories, err := h.categoryService.GetCategories(r.Context())
 	if err != nil {
-		log.Printf("Error fetching categories: %v", err)
+		slog.ErrorContext(r.Context(), "Error fetching categories", "err", err)
 		categories = []models.Category{} // Empty fallback
 	}
 
@@ -108,7 +108,7 @@ func (h *CategoryHandler) ServeCategoryPosts(w http.ResponseWriter, r *http.Requ
 
 	// Render template
 	if err := h.templateService.Render(w, "category.html", data); err != nil {
-		log.Printf("Error rendering category template: %v", err)
+		slog.ErrorContext(r.Context(), "Error rendering category template", "err", err)
 		http.Error(w, "Failed to render page", http.StatusInternalServerError)
 	}
 }
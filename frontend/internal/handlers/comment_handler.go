@@ -1,8 +1,9 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"frontend-service/internal/models"
@@ -76,9 +77,9 @@ func (h *CommentHandler) ServeCreateComment(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Create comment via API
-	_, err = h.commentService.CreateComment(postID, content, sessionCookie)
+	_, err = h.commentService.CreateComment(r.Context(), postID, content, sessionCookie)
 	if err != nil {
-		if strings.Contains(err.Error(), "unauthorized") {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
@@ -90,6 +91,153 @@ func (h *CommentHandler) ServeCreateComment(w http.ResponseWriter, r *http.Reque
 	http.Redirect(w, r, "/post/"+postID, http.StatusSeeOther)
 }
 
+// ServeReplyComment handles replying to an existing comment (form submission)
+func (h *CommentHandler) ServeReplyComment(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST method
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check if user is logged in
+	user := session.GetUserFromSession(r, h.authService)
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	// Extract parent comment ID from URL path
+	commentID := r.PathValue("comment_id")
+	if commentID == "" {
+		http.Error(w, "Comment ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	// Work out where to send the user back to (post page or thread page)
+	redirectTo := r.FormValue("redirect_to")
+	if !strings.HasPrefix(redirectTo, "/post/") && !strings.HasPrefix(redirectTo, "/comment/") {
+		postID := r.FormValue("post_id")
+		if postID == "" {
+			redirectTo = "/comment/" + commentID
+		} else {
+			redirectTo = "/post/" + postID
+		}
+	}
+
+	// Get and validate reply content
+	content := strings.TrimSpace(r.FormValue("content"))
+	if content == "" {
+		http.Redirect(w, r, redirectTo+"?error=empty_content", http.StatusSeeOther)
+		return
+	}
+
+	if err := validations.ValidateCommentContent(content); err != nil {
+		http.Redirect(w, r, redirectTo+"?error=validation_failed", http.StatusSeeOther)
+		return
+	}
+
+	// Get session cookie for API call
+	sessionCookie, err := session.GetSessionCookie(r, h.authService)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	// Create reply via API
+	_, err = h.commentService.ReplyToComment(r.Context(), commentID, content, sessionCookie)
+	if err != nil {
+		if services.IsStatus(err, http.StatusUnauthorized) {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		if services.IsProblem(err, services.ProblemMaxReplyDepth) {
+			http.Redirect(w, r, redirectTo+"?error=max_depth", http.StatusSeeOther)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error creating reply", "err", err)
+		http.Redirect(w, r, redirectTo+"?error=create_failed", http.StatusSeeOther)
+		return
+	}
+
+	// Redirect back (reply will appear after page refresh)
+	http.Redirect(w, r, redirectTo, http.StatusSeeOther)
+}
+
+// ServeCommentThread shows a single comment with its paginated replies
+func (h *CommentHandler) ServeCommentThread(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get comment ID from URL
+	commentID := r.PathValue("id")
+	if commentID == "" {
+		http.Error(w, "Comment ID required", http.StatusBadRequest)
+		return
+	}
+
+	// Parse pagination parameters from query string
+	limit := 10 // Default replies per page
+	offset := 0 // Default starting point
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if parsed, err := strconv.Atoi(offsetStr); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	// Check if user is logged in and get session cookie
+	user := session.GetUserFromSession(r, h.authService)
+	var sessionCookie *http.Cookie
+	if user != nil {
+		sessionCookie, _ = session.GetSessionCookie(r, h.authService)
+	}
+
+	// Get the comment itself
+	comment, err := h.commentService.GetCommentByID(r.Context(), commentID, sessionCookie)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting comment", "err", err)
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+
+	// Get a page of its replies
+	replies, err := h.commentService.GetCommentReplies(r.Context(), commentID, limit, offset, "oldest", sessionCookie)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting replies", "err", err)
+		http.Error(w, "Failed to load replies", http.StatusInternalServerError)
+		return
+	}
+
+	// Attach the page to the comment so the tree template renders it in place
+	comment.Replies = replies.Comments
+
+	data := models.CommentThreadPageData{
+		Comment: comment,
+		Replies: replies,
+		User:    user,
+		Notice:  reportNotice(r),
+	}
+
+	if err := h.templateService.Render(w, "comment-thread.html", data); err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering template", "err", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
+
 // ServeEditComment handles both GET (show edit form) and POST (save changes)
 func (h *CommentHandler) ServeEditComment(w http.ResponseWriter, r *http.Request) {
 	// Check if user is logged in
@@ -146,9 +294,9 @@ func (h *CommentHandler) ServeDeleteComment(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Delete comment via API
-	err = h.commentService.DeleteComment(commentID, sessionCookie)
+	err = h.commentService.DeleteComment(r.Context(), commentID, sessionCookie)
 	if err != nil {
-		if strings.Contains(err.Error(), "unauthorized") {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
@@ -159,7 +307,7 @@ func (h *CommentHandler) ServeDeleteComment(w http.ResponseWriter, r *http.Reque
 
 	// Try to get the referring post URL to redirect back
 	referer := r.Header.Get("Referer")
-	if referer != "" && strings.Contains(referer, "/post/") {
+	if referer != "" && (strings.Contains(referer, "/post/") || strings.Contains(referer, "/comment/")) {
 		http.Redirect(w, r, referer, http.StatusSeeOther)
 		return
 	}
@@ -206,17 +354,17 @@ func (h *CommentHandler) handleEditCommentForm(w http.ResponseWriter, r *http.Re
 	}
 
 	// Update comment via API
-	err = h.commentService.UpdateComment(commentID, content, sessionCookie)
+	err = h.commentService.UpdateComment(r.Context(), commentID, content, sessionCookie)
 	if err != nil {
-		if strings.Contains(err.Error(), "unauthorized") {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
-		if strings.Contains(err.Error(), "forbidden") {
+		if services.IsStatus(err, http.StatusForbidden) {
 			http.Error(w, "You can only edit your own comments", http.StatusForbidden)
 			return
 		}
-		if strings.Contains(err.Error(), "not found") {
+		if services.IsStatus(err, http.StatusNotFound) {
 			http.Error(w, "Comment not found", http.StatusNotFound)
 			return
 		}
@@ -226,7 +374,7 @@ func (h *CommentHandler) handleEditCommentForm(w http.ResponseWriter, r *http.Re
 
 	// Try to redirect back to the referring post
 	referer := r.Header.Get("Referer")
-	if referer != "" && strings.Contains(referer, "/post/") {
+	if referer != "" && (strings.Contains(referer, "/post/") || strings.Contains(referer, "/comment/")) {
 		http.Redirect(w, r, referer, http.StatusSeeOther)
 		return
 	}
@@ -260,15 +408,15 @@ func (h *CommentHandler) ServeEditCommentForm(w http.ResponseWriter, r *http.Req
 	sessionCookie, _ := session.GetSessionCookie(r, h.authService)
 
 	// Get the comment
-	comment, err := h.commentService.GetCommentByID(commentID, sessionCookie)
+	comment, err := h.commentService.GetCommentByID(r.Context(), commentID, sessionCookie)
 	if err != nil {
-		log.Printf("Error getting comment: %v", err)
+		slog.ErrorContext(r.Context(), "Error getting comment", "err", err)
 		http.Error(w, "Comment not found", http.StatusNotFound)
 		return
 	}
 
-	// Check if user owns the comment
-	if comment.UserID != user.ID {
+	// Check if user owns the comment (moderators may edit any comment)
+	if comment.UserID != user.ID && !user.CanModerate() {
 		http.Error(w, "Forbidden: You can only edit your own comments", http.StatusForbidden)
 		return
 	}
@@ -285,7 +433,7 @@ func (h *CommentHandler) ServeEditCommentForm(w http.ResponseWriter, r *http.Req
 
 	// Render edit comment template
 	if err := h.templateService.Render(w, "edit-comment.html", data); err != nil {
-		log.Printf("Error rendering template: %v", err)
+		slog.ErrorContext(r.Context(), "Error rendering template", "err", err)
 		http.Error(w, "Failed to render page", http.StatusInternalServerError)
 	}
 }
@@ -313,7 +461,7 @@ func (h *CommentHandler) ServeEditCommentSubmit(w http.ResponseWriter, r *http.R
 
 	// Parse form
 	if err := r.ParseForm(); err != nil {
-		log.Printf("Error parsing form: %v", err)
+		slog.ErrorContext(r.Context(), "Error parsing form", "err", err)
 		http.Error(w, "Invalid form data", http.StatusBadRequest)
 		return
 	}
@@ -333,8 +481,8 @@ func (h *CommentHandler) ServeEditCommentSubmit(w http.ResponseWriter, r *http.R
 	sessionCookie, _ := session.GetSessionCookie(r, h.authService)
 
 	// Update the comment
-	if err := h.commentService.UpdateComment(commentID, content, sessionCookie); err != nil {
-		log.Printf("Error updating comment: %v", err)
+	if err := h.commentService.UpdateComment(r.Context(), commentID, content, sessionCookie); err != nil {
+		slog.ErrorContext(r.Context(), "Error updating comment", "err", err)
 		h.showEditCommentError(w, r, commentID, content, err.Error())
 		return
 	}
@@ -367,7 +515,7 @@ func (h *CommentHandler) showEditCommentError(w http.ResponseWriter, r *http.Req
 	}
 
 	if err := h.templateService.Render(w, "edit-comment.html", data); err != nil {
-		log.Printf("Error rendering template: %v", err)
+		slog.ErrorContext(r.Context(), "Error rendering template", "err", err)
 		http.Error(w, "Failed to render page", http.StatusInternalServerError)
 	}
 }
@@ -48,7 +48,7 @@ func (h *CreatePostHandler) showCreatePostForm(w http.ResponseWriter, r *http.Re
 	}
 
 	// Get all categories for the form
-	categories, err := h.categoryService.GetCategories()
+	categories, err := h.categoryService.GetCategories(r.Context())
 	if err != nil {
 		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
 		return
@@ -79,17 +79,28 @@ func (h *CreatePostHandler) handleCreatePostForm(w http.ResponseWriter, r *http.
 
 	// Parse form data
 	if err := r.ParseForm(); err != nil {
-		h.showCreatePostError(w, r, "Invalid form data", nil)
+		h.showCreatePostError(w, r, "Invalid form data", nil, nil)
 		return
 	}
 
 	// Get form values
+	title := strings.TrimSpace(r.FormValue("title"))
 	content := strings.TrimSpace(r.FormValue("content"))
 	selectedCategories := r.Form["categories"] // This will be a slice of category names
 
 	// Basic validation
+	if title == "" {
+		h.showCreatePostError(w, r, "Post title is required", nil, map[string]interface{}{
+			"title":      title,
+			"content":    content,
+			"categories": selectedCategories,
+		})
+		return
+	}
+
 	if content == "" {
-		h.showCreatePostError(w, r, "Post content is required", map[string]interface{}{
+		h.showCreatePostError(w, r, "Post content is required", nil, map[string]interface{}{
+			"title":      title,
 			"content":    content,
 			"categories": selectedCategories,
 		})
@@ -97,16 +108,27 @@ func (h *CreatePostHandler) handleCreatePostForm(w http.ResponseWriter, r *http.
 	}
 
 	if len(selectedCategories) == 0 {
-		h.showCreatePostError(w, r, "At least one category must be selected", map[string]interface{}{
+		h.showCreatePostError(w, r, "At least one category must be selected", nil, map[string]interface{}{
+			"title":      title,
+			"content":    content,
+			"categories": selectedCategories,
+		})
+		return
+	}
+
+	// Validate post title and content using existing validation
+	if err := validations.ValidatePostTitle(title); err != nil {
+		h.showCreatePostError(w, r, err.Error(), nil, map[string]interface{}{
+			"title":      title,
 			"content":    content,
 			"categories": selectedCategories,
 		})
 		return
 	}
 
-	// Validate post content using existing validation
 	if err := validations.ValidatePostContent(content); err != nil {
-		h.showCreatePostError(w, r, err.Error(), map[string]interface{}{
+		h.showCreatePostError(w, r, err.Error(), nil, map[string]interface{}{
+			"title":      title,
 			"content":    content,
 			"categories": selectedCategories,
 		})
@@ -121,13 +143,14 @@ func (h *CreatePostHandler) handleCreatePostForm(w http.ResponseWriter, r *http.
 	}
 
 	// Call backend API to create post
-	createResponse, err := h.postService.CreatePost(selectedCategories, content, sessionCookie)
+	createResponse, err := h.postService.CreatePost(r.Context(), selectedCategories, title, content, sessionCookie)
 	if err != nil {
-		if strings.Contains(err.Error(), "unauthorized") {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
-		h.showCreatePostError(w, r, err.Error(), map[string]interface{}{
+		h.showCreatePostError(w, r, apiErrorMessage(r.Context(), err, "create post"), services.FieldErrors(err), map[string]interface{}{
+			"title":      title,
 			"content":    content,
 			"categories": selectedCategories,
 		})
@@ -139,12 +162,12 @@ func (h *CreatePostHandler) handleCreatePostForm(w http.ResponseWriter, r *http.
 }
 
 // showCreatePostError displays the create post form with error message and preserved form data
-func (h *CreatePostHandler) showCreatePostError(w http.ResponseWriter, r *http.Request, errorMsg string, formData map[string]interface{}) {
+func (h *CreatePostHandler) showCreatePostError(w http.ResponseWriter, r *http.Request, errorMsg string, fieldErrors map[string]string, formData map[string]interface{}) {
 	// Get user (should be logged in if we reach this point)
 	user := session.GetUserFromSession(r, h.authService)
 
 	// Get all categories for the form
-	categories, err := h.categoryService.GetCategories()
+	categories, err := h.categoryService.GetCategories(r.Context())
 	if err != nil {
 		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
 		return
@@ -152,10 +175,11 @@ func (h *CreatePostHandler) showCreatePostError(w http.ResponseWriter, r *http.R
 
 	// Prepare data for template
 	data := map[string]interface{}{
-		"User":       user,
-		"Categories": categories,
-		"Error":      errorMsg,
-		"FormData":   formData,
+		"User":        user,
+		"Categories":  categories,
+		"Error":       errorMsg,
+		"FieldErrors": fieldErrors,
+		"FormData":    formData,
 	}
 
 	// Render the template with error
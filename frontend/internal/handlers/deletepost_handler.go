@@ -45,9 +45,9 @@ func (h *DeletePostHandler) ServeDeletePost(w http.ResponseWriter, r *http.Reque
 
 	// Get the post to verify ownership
 	sessionCookie, _ := session.GetSessionCookie(r, h.authService) // CHANGED: Use utility function instead of hardcoded "session_id"
-	post, _, err := h.postService.GetSinglePostWithComments(postID, 1, 0, "oldest", sessionCookie)
+	post, _, err := h.postService.GetSinglePostWithComments(r.Context(), postID, 1, 0, "oldest", sessionCookie)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if services.IsStatus(err, http.StatusNotFound) {
 			http.Error(w, "Post not found", http.StatusNotFound)
 			return
 		}
@@ -55,25 +55,25 @@ func (h *DeletePostHandler) ServeDeletePost(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Check if user owns the post
-	if post.UserID != user.ID {
+	// Check if user owns the post (moderators may delete any post)
+	if post.UserID != user.ID && !user.CanModerate() {
 		http.Error(w, "You can only delete your own posts", http.StatusForbidden)
 		return
 	}
 
 	// Call backend API to delete post
-	err = h.postService.DeletePost(postID, sessionCookie)
+	err = h.postService.DeletePost(r.Context(), postID, sessionCookie)
 	if err != nil {
 		// Handle different error types
-		if strings.Contains(err.Error(), "unauthorized") {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
-		if strings.Contains(err.Error(), "forbidden") {
+		if services.IsStatus(err, http.StatusForbidden) {
 			http.Error(w, "You can only delete your own posts", http.StatusForbidden)
 			return
 		}
-		if strings.Contains(err.Error(), "not found") {
+		if services.IsStatus(err, http.StatusNotFound) {
 			http.Error(w, "Post not found", http.StatusNotFound)
 			return
 		}
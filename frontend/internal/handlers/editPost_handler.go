@@ -57,9 +57,9 @@ func (h *EditPostHandler) showEditPostForm(w http.ResponseWriter, r *http.Reques
 
 	// Get the post to edit
 	sessionCookie, _ := session.GetSessionCookie(r, h.authService) // CHANGED: Use utility function instead of hardcoded "session_id"
-	post, _, err := h.postService.GetSinglePostWithComments(postID, 1, 0, "oldest", sessionCookie)
+	post, _, err := h.postService.GetSinglePostWithComments(r.Context(), postID, 1, 0, "oldest", sessionCookie)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if services.IsStatus(err, http.StatusNotFound) {
 			http.Error(w, "Post not found", http.StatusNotFound)
 			return
 		}
@@ -67,14 +67,14 @@ func (h *EditPostHandler) showEditPostForm(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Check if user owns the post
-	if post.UserID != user.ID {
+	// Check if user owns the post (moderators may edit any post)
+	if post.UserID != user.ID && !user.CanModerate() {
 		http.Error(w, "You can only edit your own posts", http.StatusForbidden)
 		return
 	}
 
 	// Get all categories for the form
-	categories, err := h.categoryService.GetCategories()
+	categories, err := h.categoryService.GetCategories(r.Context())
 	if err != nil {
 		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
 		return
@@ -92,6 +92,7 @@ func (h *EditPostHandler) showEditPostForm(w http.ResponseWriter, r *http.Reques
 		"Post":       post,
 		"Categories": categories,
 		"FormData": map[string]interface{}{
+			"title":      post.Title,
 			"content":    post.Content,
 			"categories": postCategoryNames,
 		},
@@ -123,17 +124,28 @@ func (h *EditPostHandler) handleEditPostForm(w http.ResponseWriter, r *http.Requ
 
 	// Parse form data
 	if err := r.ParseForm(); err != nil {
-		h.showEditPostError(w, r, postID, "Invalid form data", nil)
+		h.showEditPostError(w, r, postID, "Invalid form data", nil, nil)
 		return
 	}
 
 	// Get form values
+	title := strings.TrimSpace(r.FormValue("title"))
 	content := strings.TrimSpace(r.FormValue("content"))
 	selectedCategories := r.Form["categories"] // This will be a slice of category names
 
 	// Basic validation
+	if title == "" {
+		h.showEditPostError(w, r, postID, "Post title is required", nil, map[string]interface{}{
+			"title":      title,
+			"content":    content,
+			"categories": selectedCategories,
+		})
+		return
+	}
+
 	if content == "" {
-		h.showEditPostError(w, r, postID, "Post content is required", map[string]interface{}{
+		h.showEditPostError(w, r, postID, "Post content is required", nil, map[string]interface{}{
+			"title":      title,
 			"content":    content,
 			"categories": selectedCategories,
 		})
@@ -141,16 +153,27 @@ func (h *EditPostHandler) handleEditPostForm(w http.ResponseWriter, r *http.Requ
 	}
 
 	if len(selectedCategories) == 0 {
-		h.showEditPostError(w, r, postID, "At least one category must be selected", map[string]interface{}{
+		h.showEditPostError(w, r, postID, "At least one category must be selected", nil, map[string]interface{}{
+			"title":      title,
+			"content":    content,
+			"categories": selectedCategories,
+		})
+		return
+	}
+
+	// Validate post title and content using existing validation
+	if err := validations.ValidatePostTitle(title); err != nil {
+		h.showEditPostError(w, r, postID, err.Error(), nil, map[string]interface{}{
+			"title":      title,
 			"content":    content,
 			"categories": selectedCategories,
 		})
 		return
 	}
 
-	// Validate post content using existing validation
 	if err := validations.ValidatePostContent(content); err != nil {
-		h.showEditPostError(w, r, postID, err.Error(), map[string]interface{}{
+		h.showEditPostError(w, r, postID, err.Error(), nil, map[string]interface{}{
+			"title":      title,
 			"content":    content,
 			"categories": selectedCategories,
 		})
@@ -165,17 +188,18 @@ func (h *EditPostHandler) handleEditPostForm(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Call backend API to update post
-	err = h.postService.UpdatePost(postID, selectedCategories, content, sessionCookie)
+	err = h.postService.UpdatePost(r.Context(), postID, selectedCategories, title, content, sessionCookie)
 	if err != nil {
-		if strings.Contains(err.Error(), "unauthorized") {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
-		if strings.Contains(err.Error(), "forbidden") {
+		if services.IsStatus(err, http.StatusForbidden) {
 			http.Error(w, "You can only edit your own posts", http.StatusForbidden)
 			return
 		}
-		h.showEditPostError(w, r, postID, err.Error(), map[string]interface{}{
+		h.showEditPostError(w, r, postID, apiErrorMessage(r.Context(), err, "edit post"), services.FieldErrors(err), map[string]interface{}{
+			"title":      title,
 			"content":    content,
 			"categories": selectedCategories,
 		})
@@ -187,20 +211,20 @@ func (h *EditPostHandler) handleEditPostForm(w http.ResponseWriter, r *http.Requ
 }
 
 // showEditPostError displays the edit post form with error message and preserved form data
-func (h *EditPostHandler) showEditPostError(w http.ResponseWriter, r *http.Request, postID, errorMsg string, formData map[string]interface{}) {
+func (h *EditPostHandler) showEditPostError(w http.ResponseWriter, r *http.Request, postID, errorMsg string, fieldErrors map[string]string, formData map[string]interface{}) {
 	// Get user (should be logged in if we reach this point)
 	user := session.GetUserFromSession(r, h.authService)
 
 	// Get the original post for reference
 	sessionCookie, _ := session.GetSessionCookie(r, h.authService) // CHANGED: Use utility function instead of hardcoded "session_id"
-	post, _, err := h.postService.GetSinglePostWithComments(postID, 1, 0, "oldest", sessionCookie)
+	post, _, err := h.postService.GetSinglePostWithComments(r.Context(), postID, 1, 0, "oldest", sessionCookie)
 	if err != nil {
 		http.Error(w, "Failed to load post", http.StatusInternalServerError)
 		return
 	}
 
 	// Get all categories for the form
-	categories, err := h.categoryService.GetCategories()
+	categories, err := h.categoryService.GetCategories(r.Context())
 	if err != nil {
 		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
 		return
@@ -208,12 +232,13 @@ func (h *EditPostHandler) showEditPostError(w http.ResponseWriter, r *http.Reque
 
 	// Prepare data for template
 	data := map[string]interface{}{
-		"User":       user,
-		"Post":       post,
-		"Categories": categories,
-		"Error":      errorMsg,
-		"FormData":   formData,
-		"IsEdit":     true,
+		"User":        user,
+		"Post":        post,
+		"Categories":  categories,
+		"Error":       errorMsg,
+		"FieldErrors": fieldErrors,
+		"FormData":    formData,
+		"IsEdit":      true,
 	}
 
 	// Render the template with error
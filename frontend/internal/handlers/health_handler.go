@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"frontend-service/internal/models"
+	"frontend-service/internal/services"
+	"frontend-service/internal/utils"
+)
+
+// readinessTimeout bounds the API check, so a hanging backend fails the probe instead of hanging it
+const readinessTimeout = 2 * time.Second
+
+// HealthHandler serves the liveness, readiness and version probes
+type HealthHandler struct {
+	client *services.BaseClient
+	build  models.BuildInfo
+}
+
+// NewHealthHandler creates a new health handler. client is used to reach the API.
+func NewHealthHandler(client *services.BaseClient) *HealthHandler {
+	return &HealthHandler{client: client, build: utils.ReadBuildInfo()}
+}
+
+// ServeHealth answers as long as the server is running
+func (h *HealthHandler) ServeHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeProbe(w, http.StatusOK, models.APIResponse{Success: true, Data: map[string]string{"status": "ok"}})
+}
+
+// ServeReady answers 200 while the API can be reached and 503 otherwise
+func (h *HealthHandler) ServeReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	readiness := models.Readiness{Ready: true, Checks: make(map[string]models.ReadinessCheck)}
+	if err := h.client.CheckAPI(ctx); err != nil {
+		readiness.Ready = false
+		readiness.Checks["api"] = models.ReadinessCheck{OK: false, Message: err.Error()}
+		writeProbe(w, http.StatusServiceUnavailable, models.APIResponse{Success: false, Data: readiness, Error: "not ready"})
+		return
+	}
+	readiness.Checks["api"] = models.ReadinessCheck{OK: true, Message: "reachable at " + h.client.BaseURL}
+	writeProbe(w, http.StatusOK, models.APIResponse{Success: true, Data: readiness})
+}
+
+// ServeVersion describes the running build
+func (h *HealthHandler) ServeVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeProbe(w, http.StatusOK, models.APIResponse{Success: true, Data: h.build})
+}
+
+// writeProbe sends a probe response in the same envelope the API uses
+func writeProbe(w http.ResponseWriter, status int, response models.APIResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
@@ -33,12 +33,16 @@ func NewHomeHandler(authService *services.AuthService, postService *services.Pos
 func (h *HomeHandler) ServeHome(w http.ResponseWriter, r *http.Request) {
 	// Handle OAuth success - set session cookie if session_id is provided
 	if sessionID := r.URL.Query().Get("session_id"); sessionID != "" {
-		log.Printf("OAuth callback detected! Setting session cookie: %s", sessionID)
 		// Set session cookie with 24 hour expiration
 		expiresAt := time.Now().Add(24 * time.Hour)
 		utils.SetSessionCookie("forum_session", sessionID, w, r, expiresAt)
-		log.Printf("Session cookie set, redirecting to clean URL")
 		
+		// Continue to the page the login started from, if it named one
+		if returnTo := utils.SafeReturnTo(r.URL.Query().Get("return_to")); returnTo != "/" {
+			http.Redirect(w, r, returnTo, http.StatusSeeOther)
+			return
+		}
+
 		// Redirect to clean URL without session_id parameter
 		http.Redirect(w, r, "/?oauth=success", http.StatusTemporaryRedirect)
 		return
@@ -70,27 +74,20 @@ func (h *HomeHandler) ServeHome(w http.ResponseWriter, r *http.Request) {
 	user := session.GetUserFromSession(r, h.authService)
 	var sessionCookie *http.Cookie
 	if user != nil {
-		log.Printf("User is logged in: %s (%s)", user.Username, user.Email)
 		sessionCookie, _ = session.GetSessionCookie(r, h.authService)
-	} else {
-		log.Printf("No user found in session")
-		// Debug: check if there's a session cookie at all
-		if cookie, err := r.Cookie("forum_session"); err == nil {
-			log.Printf("Session cookie exists but user validation failed: %s", cookie.Value)
-		} else {
-			log.Printf("No session cookie found")
-		}
+	} else if _, err := r.Cookie("forum_session"); err == nil {
+		slog.DebugContext(r.Context(), "Session cookie did not validate")
 	}
 
 	// 🔧 FIX: Get posts from backend API WITH sort parameter
-	postsResponse, err := h.postService.GetAllPosts(limit, offset, sortBy, sessionCookie)
+	postsResponse, err := h.postService.GetAllPosts(r.Context(), limit, offset, sortBy, sessionCookie)
 	if err != nil {
 		http.Error(w, "Failed to load posts", http.StatusInternalServerError)
 		return
 	}
 
 	// Get categories from backend API
-	categories, err := h.categoryService.GetCategories()
+	categories, err := h.categoryService.GetCategories(r.Context())
 	if err != nil {
 		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
 		return
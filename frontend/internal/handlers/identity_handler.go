@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"frontend-service/internal/services"
+	"frontend-service/internal/session"
+	"frontend-service/internal/utils"
+)
+
+// Outcome of sign-in method changes shown on the profile page, keyed by the "identity" query parameter.
+// The backend's OAuth callback uses the same keys when it sends the user back after linking.
+var identityNotices = map[string]string{
+	"linked":       "Provider linked. You can now log in with it.",
+	"unlinked":     "Provider unlinked.",
+	"password_set": "Password set. You can now log in with your email and password.",
+}
+
+var identityErrors = map[string]string{
+	"taken":          "That provider account is already linked to another user.",
+	"already_linked": "You already have an account from that provider linked. Unlink it first to use a different one.",
+	"cancelled":      "Linking was cancelled.",
+	"last_method":    "That is your only way to log in. Set a password or link another provider first.",
+	"password":       "The password could not be set. Passwords must match and include an uppercase letter, a lowercase letter, a number and a special character.",
+	"has_password":   "Your account already has a password. Use Forgot Password to change it.",
+	"unavailable":    "That login provider is unavailable right now.",
+	"link_failed":    "The sign-in method could not be updated, please try again.",
+}
+
+// IdentityHandler serves linking and unlinking login providers and adding a password from the profile page
+type IdentityHandler struct {
+	authService *services.AuthService
+}
+
+// NewIdentityHandler creates a new identity handler
+func NewIdentityHandler(authService *services.AuthService) *IdentityHandler {
+	return &IdentityHandler{authService: authService}
+}
+
+// ServeLink sends the user to the provider to link it to their account
+func (h *IdentityHandler) ServeLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessionCookie, ok := h.requireLogin(w, r)
+	if !ok {
+		return
+	}
+
+	start, err := h.authService.LinkProvider(r.Context(), r.PathValue("provider"), sessionCookie)
+	if err != nil {
+		h.redirectWithError(w, r, err)
+		return
+	}
+
+	utils.SetOAuthBindingCookie(start.Binding, w, start.ExpiresAt)
+	http.Redirect(w, r, start.AuthURL, http.StatusSeeOther)
+}
+
+// ServeUnlink removes a linked provider
+func (h *IdentityHandler) ServeUnlink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessionCookie, ok := h.requireLogin(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.UnlinkProvider(r.Context(), r.PathValue("provider"), sessionCookie); err != nil {
+		h.redirectWithError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/profile?identity=unlinked#sign-in-methods", http.StatusSeeOther)
+}
+
+// ServeSetPassword adds a password to an account that only logs in through providers
+func (h *IdentityHandler) ServeSetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessionCookie, ok := h.requireLogin(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.authService.SetPassword(r.Context(), r.FormValue("password"), r.FormValue("confirm_password"), sessionCookie)
+	if err != nil {
+		h.redirectWithError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/profile?identity=password_set#sign-in-methods", http.StatusSeeOther)
+}
+
+// requireLogin returns the session cookie, redirecting to the login page when there is none
+func (h *IdentityHandler) requireLogin(w http.ResponseWriter, r *http.Request) (*http.Cookie, bool) {
+	sessionCookie, err := session.GetSessionCookie(r, h.authService)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, false
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return sessionCookie, true
+}
+
+// redirectWithError sends the user back to the sign-in methods section of their profile with an error
+func (h *IdentityHandler) redirectWithError(w http.ResponseWriter, r *http.Request, err error) {
+	code := "link_failed"
+	switch {
+	case services.IsStatus(err, http.StatusUnauthorized):
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	case services.IsProblem(err, services.ProblemLastLoginMethod):
+		code = "last_method"
+	case services.IsStatus(err, http.StatusNotFound), services.IsStatus(err, http.StatusBadGateway):
+		code = "unavailable"
+	case services.IsProblem(err, services.ProblemPasswordAlreadySet):
+		code = "has_password"
+	case services.FieldErrors(err)["password"] != "", services.FieldErrors(err)["confirm_password"] != "":
+		code = "password"
+	default:
+		slog.ErrorContext(r.Context(), "Error updating sign-in methods", "err", err)
+	}
+	http.Redirect(w, r, "/profile?identity="+code+"#sign-in-methods", http.StatusSeeOther)
+}
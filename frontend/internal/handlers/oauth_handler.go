@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -10,6 +10,21 @@ import (
 	"frontend-service/internal/utils"
 )
 
+// Login errors reported by the backend's OAuth callback, keyed by the "error" query parameter
+var oauthErrors = map[string]string{
+	"oauth_cancelled":      "Login was cancelled or refused by the provider.",
+	"oauth_invalid":        "The login response was not valid, please try again.",
+	"oauth_expired":        "The login took too long or was already used, please try again.",
+	"oauth_token_failed":   "The provider could not confirm your login, please try again.",
+	"oauth_user_failed":    "Your account could not be signed in, please try again.",
+	"oauth_email_taken":    "An account with this email already exists. Log in to it and link the provider from your profile.",
+	"oauth_session_failed": "Your session could not be started, please try again.",
+	"oauth_unavailable":    "That login provider is unavailable right now.",
+	"account_ban":          "This account has been banned.",
+	"account_suspension":   "This account is suspended.",
+	"two_factor_locked":    "Too many wrong two-factor codes were entered. Try again later.",
+}
+
 type OAuthHandler struct {
 	authService     *services.AuthService
 	templateService *services.TemplateService
@@ -25,32 +40,45 @@ func NewOAuthHandler(authService *services.AuthService, templateService *service
 	}
 }
 
-// GoogleLoginHandler initiates Google OAuth login
-func (h *OAuthHandler) GoogleLoginHandler(w http.ResponseWriter, r *http.Request) {
+// LoginHandler initiates OAuth login with the provider named in the path.
+// A return_to query parameter is passed on so the user lands back where they started.
+func (h *OAuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Call backend to get Google auth URL
-	authURL, err := h.authService.InitiateGoogleOAuth()
+	provider := r.PathValue("provider")
+
+	returnTo := r.URL.Query().Get("return_to")
+	if utils.SafeReturnTo(returnTo) == "/" {
+		returnTo = ""
+	}
+
+	// Call backend to get the provider's auth URL
+	start, err := h.authService.InitiateOAuth(r.Context(), provider, returnTo)
 	if err != nil {
-		log.Printf("Failed to initiate Google OAuth: %v", err)
-		http.Error(w, "Failed to initiate Google login", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Failed to start OAuth login", "provider", provider, "err", err)
+		http.Redirect(w, r, "/login?error=oauth_unavailable", http.StatusSeeOther)
 		return
 	}
 
-	// Redirect to Google OAuth
-	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
+	// The backend's callback only accepts the flow from the browser holding this cookie
+	utils.SetOAuthBindingCookie(start.Binding, w, start.ExpiresAt)
+
+	// Redirect to the provider
+	http.Redirect(w, r, start.AuthURL, http.StatusTemporaryRedirect)
 }
 
-// GoogleCallbackHandler handles Google OAuth callback
-func (h *OAuthHandler) GoogleCallbackHandler(w http.ResponseWriter, r *http.Request) {
+// CallbackHandler handles the OAuth callback of the provider named in the path
+func (h *OAuthHandler) CallbackHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	provider := r.PathValue("provider")
+
 	// Get query parameters
 	code := r.URL.Query().Get("code")
 	state := r.URL.Query().Get("state")
@@ -58,22 +86,22 @@ func (h *OAuthHandler) GoogleCallbackHandler(w http.ResponseWriter, r *http.Requ
 
 	// Check for OAuth errors
 	if errorParam != "" {
-		log.Printf("OAuth error: %s", errorParam)
-		h.showLoginError("Google login was cancelled or failed")
+		slog.WarnContext(r.Context(), "OAuth error from provider", "provider", provider, "error", errorParam)
+		h.showLoginError(r, provider+" login was cancelled or failed")
 		return
 	}
 
 	// Validate required parameters
 	if code == "" || state == "" {
-		h.showLoginError("Invalid OAuth response")
+		h.showLoginError(r, "Invalid OAuth response")
 		return
 	}
 
 	// Forward to backend for processing
-	user, sessionID, isNewUser, err := h.authService.HandleGoogleCallback(code, state)
+	user, sessionID, isNewUser, err := h.authService.HandleOAuthCallback(r.Context(), provider, code, state)
 	if err != nil {
-		log.Printf("Google OAuth callback error: %v", err)
-		h.showLoginError(err.Error())
+		slog.ErrorContext(r.Context(), "OAuth callback failed", "provider", provider, "err", err)
+		h.showLoginError(r, err.Error())
 		return
 	}
 
@@ -81,17 +109,17 @@ func (h *OAuthHandler) GoogleCallbackHandler(w http.ResponseWriter, r *http.Requ
 	expiresAt := time.Now().Add(24 * time.Hour)
 	utils.SetSessionCookie("forum_session", sessionID, w, r, expiresAt)
 
-	log.Printf("Google OAuth successful for user: %s (new user: %t)", user.Username, isNewUser)
+	slog.InfoContext(r.Context(), "OAuth login succeeded", "provider", provider, "user_id", user.ID, "new_user", isNewUser)
 
 	// Redirect to home page with success
 	http.Redirect(w, r, "/?oauth=success", http.StatusSeeOther)
 }
 
 // showLoginError redirects to login page with error
-func (h *OAuthHandler) showLoginError(errorMsg string) {
+func (h *OAuthHandler) showLoginError(r *http.Request, errorMsg string) {
 	// In a real implementation, you might store the error in a session
 	// and redirect to login page to display it
-	log.Printf("OAuth login error: %s", errorMsg)
+	slog.WarnContext(r.Context(), "OAuth login failed", "error", errorMsg)
 }
 
 // OAuthStatusHandler returns OAuth configuration for frontend
@@ -102,9 +130,9 @@ func (h *OAuthHandler) OAuthStatusHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	// Get OAuth status from backend
-	status, err := h.authService.GetOAuthStatus()
+	status, err := h.authService.GetOAuthStatus(r.Context())
 	if err != nil {
-		log.Printf("Failed to get OAuth status: %v", err)
+		slog.ErrorContext(r.Context(), "Failed to get OAuth status", "err", err)
 		http.Error(w, "Failed to get OAuth status", http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"frontend-service/internal/models"
+	"frontend-service/internal/services"
+	"frontend-service/internal/session"
+)
+
+// Outcome of personal access token changes shown on the profile page, keyed by the "token" query parameter
+var tokenNotices = map[string]string{
+	"revoked": "Token revoked. Scripts using it can no longer sign in.",
+}
+
+var tokenErrors = map[string]string{
+	"name":      "Give the token a name of up to 50 characters.",
+	"scopes":    "Choose at least one permission for the token.",
+	"expiry":    "Choose how long the token should last.",
+	"limit":     "You have reached the maximum number of tokens. Revoke one first.",
+	"not_found": "That token no longer exists.",
+	"failed":    "The token could not be updated, please try again.",
+}
+
+// PersonalTokenHandler serves personal access token management from the profile page
+type PersonalTokenHandler struct {
+	authService     *services.AuthService
+	templateService *services.TemplateService
+}
+
+// NewPersonalTokenHandler creates a new personal access token handler
+func NewPersonalTokenHandler(authService *services.AuthService, templateService *services.TemplateService) *PersonalTokenHandler {
+	return &PersonalTokenHandler{
+		authService:     authService,
+		templateService: templateService,
+	}
+}
+
+// ServeCreate creates a token and shows its value, the only time it is visible
+func (h *PersonalTokenHandler) ServeCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, sessionCookie, ok := h.requireLogin(w, r)
+	if !ok {
+		return
+	}
+
+	days, _ := strconv.Atoi(r.FormValue("expires_in_days"))
+	token, err := h.authService.CreatePersonalToken(r.Context(), strings.TrimSpace(r.FormValue("name")), r.Form["scopes"], days, sessionCookie)
+	if err != nil {
+		h.redirectWithError(w, r, err)
+		return
+	}
+
+	data := models.PersonalTokenPageData{User: user, Token: token}
+	if err := h.templateService.Render(w, "personal-token.html", data); err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering personal token template", "err", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
+
+// ServeRevoke deletes one of the user's tokens
+func (h *PersonalTokenHandler) ServeRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	_, sessionCookie, ok := h.requireLogin(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.RevokePersonalToken(r.Context(), r.PathValue("id"), sessionCookie); err != nil {
+		h.redirectWithError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/profile?token=revoked#api-tokens", http.StatusSeeOther)
+}
+
+// requireLogin returns the logged-in user and their session cookie, redirecting to login otherwise
+func (h *PersonalTokenHandler) requireLogin(w http.ResponseWriter, r *http.Request) (*models.User, *http.Cookie, bool) {
+	user := session.GetUserFromSession(r, h.authService)
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, nil, false
+	}
+
+	sessionCookie, err := session.GetSessionCookie(r, h.authService)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, nil, false
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	return user, sessionCookie, true
+}
+
+// redirectWithError sends the user back to the tokens section of their profile with an error
+func (h *PersonalTokenHandler) redirectWithError(w http.ResponseWriter, r *http.Request, err error) {
+	code := "failed"
+	switch {
+	case services.IsStatus(err, http.StatusUnauthorized):
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	case services.FieldErrors(err)["name"] != "":
+		code = "name"
+	case services.FieldErrors(err)["scopes"] != "":
+		code = "scopes"
+	case services.FieldErrors(err)["expires_in_days"] != "":
+		code = "expiry"
+	case services.IsStatus(err, http.StatusConflict):
+		code = "limit"
+	case services.IsStatus(err, http.StatusNotFound):
+		code = "not_found"
+	default:
+		slog.ErrorContext(r.Context(), "Error updating personal access tokens", "err", err)
+	}
+	http.Redirect(w, r, "/profile?token="+code+"#api-tokens", http.StatusSeeOther)
+}
@@ -47,9 +47,9 @@ func (h *PostHandler) ServePostView(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get post and comments from backend API (with session for reaction data)
-	post, comments, err := h.postService.GetSinglePostWithComments(postID, paginationParams.Limit, paginationParams.Offset, sortBy, sessionCookie)
+	post, comments, err := h.postService.GetSinglePostWithComments(r.Context(), postID, paginationParams.Limit, paginationParams.Offset, sortBy, sessionCookie)
 	if err != nil {
-		if err.Error() == "post not found" {
+		if services.IsStatus(err, http.StatusNotFound) {
 			http.Error(w, "Post not found", http.StatusNotFound)
 			return
 		}
@@ -70,6 +70,7 @@ func (h *PostHandler) ServePostView(w http.ResponseWriter, r *http.Request) {
 		Post:     post,
 		Comments: commentsSlice,
 		User:     user, // Pass user for reaction buttons
+		Notice:   reportNotice(r),
 	}
 
 	// Render the template
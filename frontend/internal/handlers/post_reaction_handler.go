@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -77,13 +77,13 @@ func (h *PostReactionHandler) ServeTogglePostReaction(w http.ResponseWriter, r *
 		reactionName = "dislike"
 	}
 
-	log.Printf("Toggling post reaction: user=%s, post=%s, reaction=%s", user.Username, postID, reactionName)
+	slog.DebugContext(r.Context(), "Toggling post reaction", "user_id", user.ID, "post_id", postID, "reaction", reactionName)
 
 	// Toggle reaction via API
-	result, err := h.postReactionService.TogglePostReaction(postID, reactionType, sessionCookie)
+	result, err := h.postReactionService.TogglePostReaction(r.Context(), postID, reactionType, sessionCookie)
 	if err != nil {
 		// Handle authentication errors
-		if strings.Contains(err.Error(), "unauthorized") {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
@@ -93,7 +93,7 @@ func (h *PostReactionHandler) ServeTogglePostReaction(w http.ResponseWriter, r *
 		return
 	}
 
-	log.Printf("Post reaction toggled successfully: %s - %s", result.Action, result.Message)
+	slog.DebugContext(r.Context(), "Post reaction toggled", "action", result.Action)
 
 	// Always redirect back to the referring page to show updated reaction state
 	h.redirectBack(w, r, postID)
@@ -150,13 +150,13 @@ func (h *PostReactionHandler) ServeToggleCommentReaction(w http.ResponseWriter,
 		reactionName = "dislike"
 	}
 
-	log.Printf("Toggling comment reaction: user=%s, comment=%s, reaction=%s", user.Username, commentID, reactionName)
+	slog.DebugContext(r.Context(), "Toggling comment reaction", "user_id", user.ID, "comment_id", commentID, "reaction", reactionName)
 
 	// Toggle reaction via API
-	result, err := h.commentReactionService.ToggleCommentReaction(commentID, reactionType, sessionCookie)
+	result, err := h.commentReactionService.ToggleCommentReaction(r.Context(), commentID, reactionType, sessionCookie)
 	if err != nil {
 		// Handle authentication errors
-		if strings.Contains(err.Error(), "unauthorized") {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
@@ -166,7 +166,7 @@ func (h *PostReactionHandler) ServeToggleCommentReaction(w http.ResponseWriter,
 		return
 	}
 
-	log.Printf("Comment reaction toggled successfully: %s - %s", result.Action, result.Message)
+	slog.DebugContext(r.Context(), "Comment reaction toggled", "action", result.Action)
 
 	// Always redirect back to the referring page (should be the post page)
 	h.redirectBackForComment(w, r)
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"log/slog"
 	"net/http"
 
 	"frontend-service/internal/models"
@@ -9,6 +10,26 @@ import (
 	"frontend-service/internal/utils"
 )
 
+// Messages shown above the active devices list, keyed by the "notice" and "error" query parameters
+var sessionNotices = map[string]string{
+	"revoked":        "Device signed out.",
+	"revoked_others": "Signed out of all other devices.",
+}
+
+var sessionErrors = map[string]string{
+	"not_found": "That device is no longer signed in.",
+	"failed":    "The device could not be signed out, please try again.",
+}
+
+// Outcome of resending the verification email, keyed by the "email" query parameter
+var emailNotices = map[string]string{
+	"sent":   "We have sent you a new verification link.",
+	"failed": "The verification email could not be sent, please try again.",
+}
+
+// How many recent security events the profile page shows
+const securityEventsShown = 10
+
 type ProfileHandler struct {
 	authService     *services.AuthService
 	userService     *services.UserService
@@ -47,13 +68,13 @@ func (h *ProfileHandler) ServeProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user profile with stats from backend API
-	userProfile, err := h.userService.GetUserProfile(user.ID, sessionCookie)
+	userProfile, err := h.userService.GetUserProfile(r.Context(), user.ID, sessionCookie)
 	if err != nil {
-		if err.Error() == "unauthorized: please log in" {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
-		if err.Error() == "forbidden: you can only view your own profile" {
+		if services.IsStatus(err, http.StatusForbidden) {
 			http.Error(w, "Access denied", http.StatusForbidden)
 			return
 		}
@@ -61,10 +82,51 @@ func (h *ProfileHandler) ServeProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A failure here only hides the devices list, the rest of the profile still renders
+	sessions, err := h.authService.GetSessions(r.Context(), sessionCookie)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error loading sessions", "user_id", user.ID, "err", err)
+	}
+
+	securityEvents, err := h.authService.GetSecurityEvents(r.Context(), securityEventsShown, sessionCookie)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error loading security events", "user_id", user.ID, "err", err)
+	}
+
+	twoFactor, err := h.authService.GetTwoFactorStatus(r.Context(), sessionCookie)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error loading two-factor status", "user_id", user.ID, "err", err)
+	}
+
+	loginMethods, err := h.authService.GetLoginMethods(r.Context(), sessionCookie)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error loading login methods", "user_id", user.ID, "err", err)
+	}
+
+	tokens, err := h.authService.GetPersonalTokens(r.Context(), sessionCookie)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error loading personal access tokens", "user_id", user.ID, "err", err)
+	}
+
 	// Prepare data for template
 	data := models.ProfilePageData{
-		Profile: userProfile,
-		User:    user,
+		Profile:        userProfile,
+		Sessions:       sessions,
+		SecurityEvents: securityEvents,
+		User:           user,
+		Notice:         sessionNotices[r.URL.Query().Get("notice")],
+		Error:          sessionErrors[r.URL.Query().Get("error")],
+		EmailNotice:    emailNotices[r.URL.Query().Get("email")],
+		TwoFactor:      twoFactor,
+		TwoFactorDone:  twoFactorNotices[r.URL.Query().Get("twofactor")],
+		TwoFactorError: twoFactorErrors[r.URL.Query().Get("twofactor")],
+		LoginMethods:   loginMethods,
+		IdentityDone:   identityNotices[r.URL.Query().Get("identity")],
+		IdentityError:  identityErrors[r.URL.Query().Get("identity")],
+		Tokens:         tokens,
+		TokensFailed:   tokens == nil,
+		TokenDone:      tokenNotices[r.URL.Query().Get("token")],
+		TokenError:     tokenErrors[r.URL.Query().Get("token")],
 	}
 
 	// Render the profile template
@@ -74,6 +136,64 @@ func (h *ProfileHandler) ServeProfile(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ServeRevokeSession signs the user out of one of their devices, or of all other devices when the ID is "others"
+func (h *ProfileHandler) ServeRevokeSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := session.GetUserFromSession(r, h.authService)
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	sessionCookie, err := session.GetSessionCookie(r, h.authService)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	deviceID := r.PathValue("id")
+	if deviceID == "" {
+		http.Error(w, "Device ID is required", http.StatusBadRequest)
+		return
+	}
+
+	notice := "revoked"
+	if deviceID == "others" {
+		err = h.authService.RevokeOtherSessions(r.Context(), sessionCookie)
+		notice = "revoked_others"
+	} else {
+		err = h.authService.RevokeSession(r.Context(), deviceID, sessionCookie)
+	}
+
+	if err != nil {
+		code := "failed"
+		switch {
+		case services.IsStatus(err, http.StatusUnauthorized):
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		case services.IsStatus(err, http.StatusNotFound):
+			code = "not_found"
+		default:
+			slog.ErrorContext(r.Context(), "Error revoking session", "device_id", deviceID, "err", err)
+		}
+		http.Redirect(w, r, "/profile?error="+code+"#devices", http.StatusSeeOther)
+		return
+	}
+
+	// Signing out the current device ends this browser's session too
+	if r.FormValue("current") == "true" {
+		utils.ClearSessionCookie(sessionCookie.Name, w)
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/profile?notice="+notice+"#devices", http.StatusSeeOther)
+}
+
 // ServeUserPosts handles the "My Posts" page - shows posts created by the user
 func (h *ProfileHandler) ServeUserPosts(w http.ResponseWriter, r *http.Request) {
 	// Only allow GET method
@@ -101,9 +221,9 @@ func (h *ProfileHandler) ServeUserPosts(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Get user's posts from backend API
-	postsResponse, err := h.userService.GetUserPosts(user.ID, paginationParams.Limit, paginationParams.Offset, sortBy, sessionCookie)
+	postsResponse, err := h.userService.GetUserPosts(r.Context(), user.ID, paginationParams.Limit, paginationParams.Offset, sortBy, sessionCookie)
 	if err != nil {
-		if err.Error() == "unauthorized: please log in" {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
@@ -155,9 +275,9 @@ func (h *ProfileHandler) ServeUserLikedPosts(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Get user's liked posts from backend API
-	postsResponse, err := h.userService.GetUserLikedPosts(user.ID, paginationParams.Limit, paginationParams.Offset, sortBy, sessionCookie)
+	postsResponse, err := h.userService.GetUserLikedPosts(r.Context(), user.ID, paginationParams.Limit, paginationParams.Offset, sortBy, sessionCookie)
 	if err != nil {
-		if err.Error() == "unauthorized: please log in" {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
@@ -210,9 +330,9 @@ func (h *ProfileHandler) ServeUserCommentedPosts(w http.ResponseWriter, r *http.
 	}
 
 	// Get posts user commented on from backend API
-	postsResponse, err := h.userService.GetUserCommentedPosts(user.ID, paginationParams.Limit, paginationParams.Offset, sortBy, sessionCookie)
+	postsResponse, err := h.userService.GetUserCommentedPosts(r.Context(), user.ID, paginationParams.Limit, paginationParams.Offset, sortBy, sessionCookie)
 	if err != nil {
-		if err.Error() == "unauthorized: please log in" {
+		if services.IsStatus(err, http.StatusUnauthorized) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"frontend-service/internal/models"
+	"frontend-service/internal/services"
+	"frontend-service/internal/session"
+	"frontend-service/internal/utils"
+)
+
+// Messages shown after a report is submitted, keyed by the "report" query parameter
+var reportNotices = map[string]string{
+	"sent":      "🚩 Thanks, your report was sent to the moderators.",
+	"duplicate": "You have already reported this, a moderator will look at it soon.",
+	"own":       "You cannot report your own content.",
+	"invalid":   "Please choose a reason and keep the details under 500 characters.",
+	"failed":    "Your report could not be sent, please try again later.",
+}
+
+// Messages shown on the moderator dashboard, keyed by the "notice" and "error" query parameters
+var moderationNotices = map[string]string{
+	"claimed":   "Report claimed.",
+	"resolved":  "Report resolved.",
+	"dismissed": "Report dismissed.",
+}
+
+var moderationErrors = map[string]string{
+	"claimed_by_other": "That report is claimed by another moderator.",
+	"closed":           "That report is already closed.",
+	"not_found":        "That report no longer exists.",
+	"invalid":          "Moderator notes must be under 500 characters.",
+	"failed":           "The report could not be updated, please try again.",
+}
+
+// reportNotice returns the message for a report submitted from the current page, if any
+func reportNotice(r *http.Request) string {
+	return reportNotices[r.URL.Query().Get("report")]
+}
+
+type ReportHandler struct {
+	authService     *services.AuthService
+	reportService   *services.ReportService
+	templateService *services.TemplateService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(authService *services.AuthService, reportService *services.ReportService, templateService *services.TemplateService) *ReportHandler {
+	return &ReportHandler{
+		authService:     authService,
+		reportService:   reportService,
+		templateService: templateService,
+	}
+}
+
+// ServeCreateReport handles the report form on posts and comments
+func (h *ReportHandler) ServeCreateReport(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST method
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check if user is logged in
+	user := session.GetUserFromSession(r, h.authService)
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	// Only redirect back to local pages
+	redirectTo := r.FormValue("redirect_to")
+	if !strings.HasPrefix(redirectTo, "/") || strings.HasPrefix(redirectTo, "//") {
+		redirectTo = "/"
+	}
+
+	targetType := r.FormValue("target_type")
+	targetID := r.FormValue("target_id")
+	reason := r.FormValue("reason")
+	details := strings.TrimSpace(r.FormValue("details"))
+	if targetID == "" || reason == "" || len(details) > 500 {
+		http.Redirect(w, r, withQueryParam(redirectTo, "report", "invalid"), http.StatusSeeOther)
+		return
+	}
+
+	// Get session cookie for API call
+	sessionCookie, err := session.GetSessionCookie(r, h.authService)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	outcome := "sent"
+	err = h.reportService.CreateReport(r.Context(), targetType, targetID, reason, details, sessionCookie)
+	if err != nil {
+		switch {
+		case services.IsStatus(err, http.StatusUnauthorized):
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		case services.IsStatus(err, http.StatusConflict):
+			outcome = "duplicate"
+		case services.IsProblem(err, services.ProblemReportOwnContent):
+			outcome = "own"
+		case services.IsStatus(err, http.StatusBadRequest):
+			outcome = "invalid"
+		default:
+			slog.ErrorContext(r.Context(), "Error creating report", "err", err)
+			outcome = "failed"
+		}
+	}
+
+	http.Redirect(w, r, withQueryParam(redirectTo, "report", outcome), http.StatusSeeOther)
+}
+
+// ServeModeration renders the moderator dashboard with a page of the report queue
+func (h *ReportHandler) ServeModeration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Moderators and admins only
+	user := session.GetUserFromSession(r, h.authService)
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	if !user.CanModerate() {
+		http.Error(w, "Forbidden: moderators only", http.StatusForbidden)
+		return
+	}
+
+	data := models.ModerationPageData{
+		Status: r.URL.Query().Get("status"),
+		Type:   r.URL.Query().Get("type"),
+		Notice: moderationNotices[r.URL.Query().Get("notice")],
+		Error:  moderationErrors[r.URL.Query().Get("error")],
+		User:   user,
+	}
+	if data.Status == "" {
+		data.Status = "pending"
+	}
+
+	pagination := utils.ParsePaginationFromRequest(r)
+	sessionCookie, _ := session.GetSessionCookie(r, h.authService)
+
+	reports, err := h.reportService.GetReportQueue(r.Context(), data.Status, data.Type, pagination.Limit, pagination.Offset, sessionCookie)
+	if err != nil {
+		if message := services.ErrorMessage(err); message != "" {
+			data.Error = message
+		} else {
+			slog.ErrorContext(r.Context(), "Error fetching report queue", "err", err)
+			data.Error = "The report queue is unavailable right now, please try again later"
+		}
+	} else {
+		data.Reports = reports
+	}
+
+	if err := h.templateService.Render(w, "moderation.html", data); err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering moderation template", "err", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
+
+// ServeModerationAction claims, resolves or dismisses a report from the dashboard
+func (h *ReportHandler) ServeModerationAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := session.GetUserFromSession(r, h.authService)
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	if !user.CanModerate() {
+		http.Error(w, "Forbidden: moderators only", http.StatusForbidden)
+		return
+	}
+
+	reportID := r.PathValue("id")
+	action := r.PathValue("action")
+	if reportID == "" {
+		http.Error(w, "Report ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	// Go back to the same queue view
+	redirectTo := r.FormValue("redirect_to")
+	if !strings.HasPrefix(redirectTo, "/moderation") {
+		redirectTo = "/moderation"
+	}
+
+	note := strings.TrimSpace(r.FormValue("note"))
+	if len(note) > 500 {
+		http.Redirect(w, r, withQueryParam(redirectTo, "error", "invalid"), http.StatusSeeOther)
+		return
+	}
+
+	sessionCookie, err := session.GetSessionCookie(r, h.authService)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	var notice string
+	switch action {
+	case "claim":
+		err = h.reportService.ClaimReport(r.Context(), reportID, sessionCookie)
+		notice = "claimed"
+	case "resolve":
+		err = h.reportService.ResolveReport(r.Context(), reportID, note, sessionCookie)
+		notice = "resolved"
+	case "dismiss":
+		err = h.reportService.DismissReport(r.Context(), reportID, note, sessionCookie)
+		notice = "dismissed"
+	default:
+		http.Error(w, "Unknown moderation action", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		code := "failed"
+		switch {
+		case services.IsStatus(err, http.StatusUnauthorized):
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		case services.IsProblem(err, services.ProblemReportClaimed):
+			code = "claimed_by_other"
+		case services.IsProblem(err, services.ProblemReportClosed):
+			code = "closed"
+		case services.IsStatus(err, http.StatusNotFound):
+			code = "not_found"
+		default:
+			slog.ErrorContext(r.Context(), "Error updating report", "report_id", reportID, "err", err)
+		}
+		http.Redirect(w, r, withQueryParam(redirectTo, "error", code), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, withQueryParam(redirectTo, "notice", notice), http.StatusSeeOther)
+}
+
+// withQueryParam sets a flash query parameter on a local URL, dropping any earlier flash message
+func withQueryParam(target, key, value string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	q := u.Query()
+	q.Del("notice")
+	q.Del("error")
+	q.Del("report")
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
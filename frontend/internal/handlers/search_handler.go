@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"frontend-service/internal/models"
+	"frontend-service/internal/services"
+	"frontend-service/internal/session"
+	"frontend-service/internal/utils"
+)
+
+type SearchHandler struct {
+	authService     *services.AuthService
+	searchService   *services.SearchService
+	categoryService *services.CategoryService
+	templateService *services.TemplateService
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(authService *services.AuthService, searchService *services.SearchService, categoryService *services.CategoryService, templateService *services.TemplateService) *SearchHandler {
+	return &SearchHandler{
+		authService:     authService,
+		searchService:   searchService,
+		categoryService: categoryService,
+		templateService: templateService,
+	}
+}
+
+// ServeSearch renders the search form and, when a query is given, the ranked results
+func (h *SearchHandler) ServeSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get authenticated user
+	user := session.GetUserFromSession(r, h.authService)
+
+	// Read the search form
+	data := models.SearchPageData{
+		Query:      strings.TrimSpace(r.URL.Query().Get("q")),
+		Type:       r.URL.Query().Get("type"),
+		CategoryID: r.URL.Query().Get("category"),
+		Author:     strings.TrimSpace(r.URL.Query().Get("author")),
+		User:       user,
+	}
+	if data.Type != "comments" {
+		data.Type = "posts"
+	}
+
+	// Categories for the filter dropdown
+	categories, err := h.categoryService.GetCategories(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching categories", "err", err)
+		categories = []models.Category{} // Empty fallback
+	}
+	data.Categories = categories
+
+	// Only hit the backend once the user actually searched
+	if data.Query != "" {
+		pagination := utils.ParsePaginationFromRequest(r)
+		sessionCookie, _ := session.GetSessionCookie(r, h.authService)
+
+		results, err := h.searchService.Search(r.Context(), data.Query, data.Type, data.CategoryID, data.Author, pagination.Limit, pagination.Offset, sessionCookie)
+		if err != nil {
+			if services.IsStatus(err, http.StatusBadRequest) {
+				// Pass the backend message through so the query can be fixed
+				data.Error = services.ErrorMessage(err)
+			} else {
+				slog.ErrorContext(r.Context(), "Error searching", "err", err)
+				data.Error = "Search is unavailable right now, please try again later"
+			}
+		} else {
+			data.Results = results
+		}
+	}
+
+	// Render template
+	if err := h.templateService.Render(w, "search.html", data); err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering search template", "err", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"frontend-service/internal/models"
+	"frontend-service/internal/services"
+	"frontend-service/internal/session"
+)
+
+// Outcome of two-factor changes shown on the profile page, keyed by the "twofactor" query parameter
+var twoFactorNotices = map[string]string{
+	"disabled": "Two-factor authentication is now off.",
+}
+
+var twoFactorErrors = map[string]string{
+	"invalid_code":       "That code is not valid, please try again.",
+	"incorrect_password": "Incorrect password.",
+	"unavailable":        "Two-factor authentication is only available for accounts with a password.",
+	"locked":             "Too many wrong codes, please try again later.",
+	"failed":             "Two-factor authentication could not be updated, please try again.",
+}
+
+// TwoFactorHandler serves two-factor enrollment and management from the profile page
+type TwoFactorHandler struct {
+	authService     *services.AuthService
+	templateService *services.TemplateService
+}
+
+// NewTwoFactorHandler creates a new two-factor handler
+func NewTwoFactorHandler(authService *services.AuthService, templateService *services.TemplateService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		authService:     authService,
+		templateService: templateService,
+	}
+}
+
+// ServeSetup creates an authenticator secret and shows it with a QR code and a confirmation form
+func (h *TwoFactorHandler) ServeSetup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, sessionCookie, ok := h.requireLogin(w, r)
+	if !ok {
+		return
+	}
+
+	setup, err := h.authService.BeginTwoFactorSetup(r.Context(), sessionCookie)
+	if err != nil {
+		h.redirectWithError(w, r, err)
+		return
+	}
+
+	h.render(w, models.TwoFactorPageData{User: user, Setup: setup})
+}
+
+// ServeConfirm turns two-factor login on with a first code and shows the recovery codes
+func (h *TwoFactorHandler) ServeConfirm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, sessionCookie, ok := h.requireLogin(w, r)
+	if !ok {
+		return
+	}
+
+	// The secret was shown on the setup page and is carried back only to show it again on error
+	setup := &models.TwoFactorSetup{
+		Secret:     r.FormValue("secret"),
+		OTPAuthURI: r.FormValue("otpauth_uri"),
+	}
+
+	codes, err := h.authService.ConfirmTwoFactorSetup(r.Context(), strings.TrimSpace(r.FormValue("code")), sessionCookie)
+	if err != nil {
+		if message := services.FieldErrors(err)["code"]; message != "" {
+			h.render(w, models.TwoFactorPageData{User: user, Setup: setup, Error: message})
+			return
+		}
+		h.redirectWithError(w, r, err)
+		return
+	}
+
+	h.render(w, models.TwoFactorPageData{User: user, RecoveryCodes: codes})
+}
+
+// ServeRecoveryCodes replaces the recovery codes and shows the new ones
+func (h *TwoFactorHandler) ServeRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, sessionCookie, ok := h.requireLogin(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(r.Context(), strings.TrimSpace(r.FormValue("code")), sessionCookie)
+	if err != nil {
+		h.redirectWithError(w, r, err)
+		return
+	}
+
+	h.render(w, models.TwoFactorPageData{User: user, RecoveryCodes: codes})
+}
+
+// ServeDisable turns two-factor login off
+func (h *TwoFactorHandler) ServeDisable(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	_, sessionCookie, ok := h.requireLogin(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.authService.DisableTwoFactor(r.Context(), r.FormValue("password"), strings.TrimSpace(r.FormValue("code")), sessionCookie)
+	if err != nil {
+		h.redirectWithError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/profile?twofactor=disabled#two-factor", http.StatusSeeOther)
+}
+
+// requireLogin returns the logged-in user and their session cookie, redirecting to login otherwise
+func (h *TwoFactorHandler) requireLogin(w http.ResponseWriter, r *http.Request) (*models.User, *http.Cookie, bool) {
+	user := session.GetUserFromSession(r, h.authService)
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, nil, false
+	}
+
+	sessionCookie, err := session.GetSessionCookie(r, h.authService)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, nil, false
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	return user, sessionCookie, true
+}
+
+// redirectWithError sends the user back to the two-factor section of their profile with an error
+func (h *TwoFactorHandler) redirectWithError(w http.ResponseWriter, r *http.Request, err error) {
+	code := "failed"
+	switch {
+	case services.IsStatus(err, http.StatusUnauthorized):
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	case services.IsProblem(err, services.ProblemInvalidCode):
+		code = "invalid_code"
+	case services.FieldErrors(err)["password"] != "":
+		code = "incorrect_password"
+	case services.IsStatus(err, http.StatusForbidden):
+		code = "unavailable"
+	case services.IsStatus(err, http.StatusTooManyRequests):
+		code = "locked"
+	default:
+		slog.ErrorContext(r.Context(), "Error updating two-factor authentication", "err", err)
+	}
+	http.Redirect(w, r, "/profile?twofactor="+code+"#two-factor", http.StatusSeeOther)
+}
+
+// render writes the two-factor page, logging template failures
+func (h *TwoFactorHandler) render(w http.ResponseWriter, data models.TwoFactorPageData) {
+	if err := h.templateService.Render(w, "two-factor.html", data); err != nil {
+		slog.Error("Error rendering two-factor template", "err", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
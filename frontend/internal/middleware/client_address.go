@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"net/http"
+
+	"frontend-service/internal/services"
+)
+
+// ForwardClientAddress remembers the browser's address for the API calls made while
+// serving the request. Without it the API would see every visitor as the frontend.
+func ForwardClientAddress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(services.WithClientAddress(r.Context(), r.RemoteAddr)))
+	})
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+
+	"frontend-service/internal/models"
+	"frontend-service/internal/services"
+)
+
+const (
+	// CSRFFieldName is the hidden form field templates add with csrfField
+	CSRFFieldName = "csrf_token"
+	// CSRFHeaderName carries the token for requests made from scripts
+	CSRFHeaderName = "X-CSRF-Token"
+
+	// anonymousCookieName identifies a browser that is not logged in, so login and
+	// registration forms are protected too
+	anonymousCookieName = "forum_csrf"
+)
+
+// CSRF protects state-changing requests with tokens tied to the browser's cookie.
+// A browser's token is an HMAC of its session cookie (or an anonymous cookie before
+// login), so nothing is stored and any replica holding the same secret accepts it.
+// Pages embed the token in every form and unsafe requests must send it back. A
+// cross-site form cannot read it, so it cannot forge a submission even though the
+// browser attaches the cookies.
+type CSRF struct {
+	sessionName     string
+	secret          []byte
+	templateService *services.TemplateService
+}
+
+// NewCSRF creates the CSRF middleware. sessionName is the session cookie's name and
+// secret the key tokens are derived with; without one, a random key is used and
+// tokens stop working when the process restarts.
+func NewCSRF(sessionName, secret string, templateService *services.TemplateService) *CSRF {
+	key := []byte(secret)
+	if secret == "" {
+		slog.Warn("CSRF_SECRET is not set; forms loaded before a restart, or from another replica, will be refused")
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	return &CSRF{
+		sessionName:     sessionName,
+		secret:          key,
+		templateService: templateService,
+	}
+}
+
+// Protect checks the token on unsafe requests and makes it available to templates
+func (c *CSRF) Protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := c.browserKey(r)
+		if !ok {
+			// First visit: give the browser an identity for its forms
+			id, err := randomToken()
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Error generating CSRF cookie", "err", err)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{
+				Name:     anonymousCookieName,
+				Value:    id,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   false,                // Set to true in production with HTTPS
+				SameSite: http.SameSiteLaxMode, // Consistent with the session cookie
+			})
+			key = "a:" + id
+		}
+		token := c.tokenFor(key)
+
+		if !isSafeMethod(r.Method) {
+			submitted := r.Header.Get(CSRFHeaderName)
+			if submitted == "" {
+				submitted = r.PostFormValue(CSRFFieldName)
+			}
+			// A browser seen for the first time has no token it could have been given
+			if !ok || submitted == "" || !hmac.Equal([]byte(submitted), []byte(token)) {
+				slog.WarnContext(r.Context(), "CSRF check failed", "method", r.Method, "path", r.URL.Path)
+				c.reject(w)
+				return
+			}
+		}
+
+		next.ServeHTTP(&csrfResponseWriter{ResponseWriter: w, token: token}, r)
+	})
+}
+
+// browserKey identifies the browser by its session cookie, or by the anonymous cookie
+// when it is not logged in. ok is false when it has neither.
+func (c *CSRF) browserKey(r *http.Request) (string, bool) {
+	if cookie, err := r.Cookie(c.sessionName); err == nil && cookie.Value != "" {
+		return "s:" + cookie.Value, true
+	}
+	if cookie, err := r.Cookie(anonymousCookieName); err == nil && cookie.Value != "" {
+		return "a:" + cookie.Value, true
+	}
+	return "", false
+}
+
+// tokenFor returns the browser's token. It changes with the cookie, so logging in or
+// out, or the API replacing the session, makes forms load a new one.
+func (c *CSRF) tokenFor(key string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(key))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// reject shows the error page for a submission without a valid token
+func (c *CSRF) reject(w http.ResponseWriter) {
+	data := models.ErrorPageData{
+		Title:   "Form expired",
+		Message: "This form could not be accepted because it has expired or did not come from this site. Go back, reload the page and try again.",
+	}
+	if err := c.templateService.RenderStatus(w, http.StatusForbidden, "error.html", data); err != nil {
+		slog.Error("Error rendering error template", "err", err)
+	}
+}
+
+// csrfResponseWriter hands the request's token to TemplateService.Render
+type csrfResponseWriter struct {
+	http.ResponseWriter
+	token string
+}
+
+// CSRFToken returns the token forms on this page must submit
+func (w *csrfResponseWriter) CSRFToken() string {
+	return w.token
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"frontend-service/internal/services"
+)
+
+const testSessionName = "forum_session"
+
+// newTestCSRF wraps a handler that renders the request's token in CSRF middleware
+// using secret. The error page shows its title so rejections are easy to spot.
+func newTestCSRF(t *testing.T, secret string) http.Handler {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"error.html": "{{.Title}}",
+		"page.html":  "{{csrfToken}}",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts, err := services.NewTemplateService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := ts.Render(w, "page.html", nil); err != nil {
+			t.Errorf("Render: %v", err)
+		}
+	})
+	return NewCSRF(testSessionName, secret, ts).Protect(page)
+}
+
+// loadForm GETs a page the way a browser would and returns the token it was given,
+// along with the anonymous cookie if this was the browser's first visit
+func loadForm(t *testing.T, h http.Handler, cookies ...*http.Cookie) (string, []*http.Cookie) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/login", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want 200", rec.Code)
+	}
+	return rec.Body.String(), append(cookies, rec.Result().Cookies()...)
+}
+
+func TestCSRFProtect(t *testing.T) {
+	h := newTestCSRF(t, "test-secret")
+	session := &http.Cookie{Name: testSessionName, Value: "session-1"}
+	otherSession := &http.Cookie{Name: testSessionName, Value: "session-2"}
+
+	anonToken, anonCookies := loadForm(t, h)
+	if len(anonCookies) != 1 || anonCookies[0].Name != anonymousCookieName {
+		t.Fatalf("first visit set cookies %v, want the %s cookie", anonCookies, anonymousCookieName)
+	}
+	if anonToken == "" {
+		t.Fatal("first visit rendered an empty token")
+	}
+	sessionToken, _ := loadForm(t, h, session)
+	if sessionToken == anonToken {
+		t.Fatal("logging in kept the anonymous token")
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		cookies []*http.Cookie
+		field   string // token in the form body
+		header  string // token in the X-CSRF-Token header
+		want    int
+	}{
+		{"form field", http.MethodPost, []*http.Cookie{session}, sessionToken, "", http.StatusOK},
+		{"header", http.MethodDelete, []*http.Cookie{session}, "", sessionToken, http.StatusOK},
+		{"anonymous browser", http.MethodPost, anonCookies, anonToken, "", http.StatusOK},
+		{"missing token", http.MethodPost, []*http.Cookie{session}, "", "", http.StatusForbidden},
+		{"wrong token", http.MethodPost, []*http.Cookie{session}, "not-the-token", "", http.StatusForbidden},
+		{"another session's token", http.MethodPost, []*http.Cookie{otherSession}, sessionToken, "", http.StatusForbidden},
+		{"token from before login", http.MethodPost, append([]*http.Cookie{session}, anonCookies...), anonToken, "", http.StatusForbidden},
+		{"first visit", http.MethodPost, nil, anonToken, "", http.StatusForbidden},
+		{"safe method without a token", http.MethodGet, []*http.Cookie{session}, "", "", http.StatusOK},
+		{"head without a token", http.MethodHead, []*http.Cookie{session}, "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			if tt.field != "" {
+				form.Set(CSRFFieldName, tt.field)
+			}
+			req := httptest.NewRequest(tt.method, "/posts", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.header != "" {
+				req.Header.Set(CSRFHeaderName, tt.header)
+			}
+			for _, c := range tt.cookies {
+				req.AddCookie(c)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %q)", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusForbidden && !strings.Contains(rec.Body.String(), "Form expired") {
+				t.Errorf("rejection rendered %q, want the error page", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCSRFTokensAcrossReplicas(t *testing.T) {
+	session := &http.Cookie{Name: testSessionName, Value: "session-1"}
+	token, _ := loadForm(t, newTestCSRF(t, "shared-secret"), session)
+
+	tests := []struct {
+		name   string
+		secret string
+		want   int
+	}{
+		{"same secret", "shared-secret", http.StatusOK},
+		{"different secret", "other-secret", http.StatusForbidden},
+		{"random secret", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+			req.Header.Set(CSRFHeaderName, token)
+			req.AddCookie(session)
+			rec := httptest.NewRecorder()
+
+			newTestCSRF(t, tt.secret).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
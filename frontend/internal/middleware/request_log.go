@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"frontend-service/internal/logging"
+)
+
+// quietPaths are polled by orchestrators or fetched with every page; they are logged at debug level
+var quietPaths = []string{"/health", "/ready", "/version", "/static/"}
+
+// LogRequests gives every request a new X-Request-ID and logs it once served. The ID is
+// sent with each API call the request makes, so the API's log lines for it carry the same ID.
+func LogRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := logging.NewRequestID()
+		w.Header().Set(logging.RequestIDHeader, id)
+		r = r.WithContext(logging.WithRequestID(r.Context(), id))
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// The path only: query strings can carry session IDs and tokens
+		level := slog.LevelInfo
+		for _, quiet := range quietPaths {
+			if r.URL.Path == quiet || strings.HasSuffix(quiet, "/") && strings.HasPrefix(r.URL.Path, quiet) {
+				level = slog.LevelDebug
+				break
+			}
+		}
+		slog.Log(r.Context(), level, "Request served",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"duration_ms", time.Since(start).Milliseconds())
+	})
+}
+
+// statusRecorder remembers the status code a handler responded with
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"frontend-service/internal/session"
+	"frontend-service/internal/utils"
+)
+
+// SessionRenewal passes renewed and replaced session cookies from the backend on to the
+// browser. Sessions slide forward while in use, and the backend gives a session a new ID
+// when the user's role changes; the old ID stops working at once.
+type SessionRenewal struct {
+	sessionName string
+}
+
+// NewSessionRenewal creates the middleware. sessionName is the session cookie's name.
+func NewSessionRenewal(sessionName string) *SessionRenewal {
+	return &SessionRenewal{sessionName: sessionName}
+}
+
+// Renew tracks the session cookie through the request and sets the latest one on the response
+func (s *SessionRenewal) Renew(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, renewal := session.WithRenewal(r)
+		next.ServeHTTP(&renewalResponseWriter{ResponseWriter: w, r: r, renewal: renewal, sessionName: s.sessionName}, r)
+	})
+}
+
+// renewalResponseWriter adds the renewed cookie just before the headers are sent
+type renewalResponseWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	renewal     *session.Renewal
+	sessionName string
+	wroteHeader bool
+}
+
+func (w *renewalResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.setRenewedCookie()
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *renewalResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// setRenewedCookie sends the backend's new session cookie, unless the handler set or
+// cleared the session cookie itself (login and logout)
+func (w *renewalResponseWriter) setRenewedCookie() {
+	cookie := w.renewal.Cookie()
+	if cookie == nil {
+		return
+	}
+	for _, header := range w.Header()["Set-Cookie"] {
+		if strings.HasPrefix(header, w.sessionName+"=") {
+			return
+		}
+	}
+	utils.SetSessionCookie(w.sessionName, cookie.Value, w, w.r, cookie.Expires)
+}
@@ -12,6 +12,16 @@ type Comment struct {
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 
+	// Role of the staff member who last edited it (empty unless edited by someone other than the author)
+	UpdatedByRole string `json:"updated_by_role,omitempty"`
+
+	// Thread position
+	ParentCommentID *string `json:"parent_comment_id,omitempty"` // nil for top-level comments
+	Depth           int     `json:"depth"`
+
+	// Deleted comments with replies stay in the thread without their author and content
+	Deleted bool `json:"deleted,omitempty"`
+
 	// Aggregated metrics
 	LikeCount    int `json:"like_count"`
 	DislikeCount int `json:"dislike_count"`
@@ -19,4 +29,9 @@ type Comment struct {
 	// User context
 	UserReaction *int `json:"user_reaction,omitempty"` // nil, 1=like, 2=dislike
 	IsOwner      bool `json:"is_owner,omitempty"`      // can current user edit/delete
+
+	// Replies subtree (first page only, see HasMoreReplies)
+	ReplyCount     int        `json:"reply_count"`
+	Replies        []*Comment `json:"replies,omitempty"`
+	HasMoreReplies bool       `json:"has_more_replies,omitempty"`
 }
@@ -1,7 +1,7 @@
 package models
 
 type HomePageData struct {
-	Posts      []*Post        `json:"posts"`
+	Posts      []*PostSummary `json:"posts"`
 	Categories []Category     `json:"categories"`
 	Pagination PaginationInfo `json:"pagination"`
 	User       *User          `json:"user,omitempty"` // Current logged-in user
@@ -10,16 +10,28 @@ type HomePageData struct {
 
 // RegisterPageData - Data for registration page template
 type RegisterPageData struct {
-	Error    string            `json:"error,omitempty"`
-	Success  string            `json:"success,omitempty"`
-	FormData *UserRegistration `json:"form_data,omitempty"` // this is to keep the form data in case of validation errors
+	Error       string            `json:"error,omitempty"`
+	FieldErrors map[string]string `json:"field_errors,omitempty"` // problems the API found with each form field
+	Success     string            `json:"success,omitempty"`
+	FormData    *UserRegistration `json:"form_data,omitempty"` // this is to keep the form data in case of validation errors
 }
 
 // LoginPageData - Data for login page template
 type LoginPageData struct {
-	Error    string     `json:"error,omitempty"`
-	Success  string     `json:"success,omitempty"`
-	FormData *UserLogin `json:"form_data,omitempty"` // this is to keep the form data in case of validation errors
+	Error          string          `json:"error,omitempty"`
+	Success        string          `json:"success,omitempty"`
+	FormData       *UserLogin      `json:"form_data,omitempty"` // this is to keep the form data in case of validation errors
+	OAuthProviders []OAuthProvider `json:"oauth_providers,omitempty"`
+	ReturnTo       string          `json:"return_to,omitempty"` // local page provider logins continue to
+}
+
+// AccountPageData - Data for the forgot password, reset password and verify email pages
+type AccountPageData struct {
+	Error       string            `json:"error,omitempty"`
+	FieldErrors map[string]string `json:"field_errors,omitempty"` // problems the API found with each form field
+	Success     string            `json:"success,omitempty"`
+	Token       string            `json:"token,omitempty"` // token from the emailed link, carried through the reset form
+	Email       string            `json:"email,omitempty"`
 }
 
 // PostPageData - Data for single post page template
@@ -27,6 +39,15 @@ type PostPageData struct {
 	Post     *Post     `json:"post"`
 	Comments []Comment `json:"comments"`
 	User     *User     `json:"user,omitempty"`
+	Notice   string    `json:"notice,omitempty"` // outcome of a report submitted from the page
+}
+
+// CommentThreadPageData - Data for a single comment and its paginated replies
+type CommentThreadPageData struct {
+	Comment *Comment                   `json:"comment"`
+	Replies *PaginatedCommentsResponse `json:"replies"`
+	User    *User                      `json:"user,omitempty"`
+	Notice  string                     `json:"notice,omitempty"` // outcome of a report submitted from the page
 }
 
 // ProfilePageData - Data for user profile page template
@@ -35,7 +56,43 @@ type ProfilePageData struct {
 	Posts          *PaginatedPostsResponse `json:"posts,omitempty"`
 	LikedPosts     *PaginatedPostsResponse `json:"liked_posts,omitempty"`
 	CommentedPosts *PaginatedPostsResponse `json:"commented_posts,omitempty"`
+	Sessions       []DeviceSession         `json:"sessions,omitempty"` // devices the user is signed in on
+	SecurityEvents []SecurityEvent         `json:"security_events,omitempty"`
 	User           *User                   `json:"user,omitempty"`
+	Notice         string                  `json:"notice,omitempty"`
+	Error          string                  `json:"error,omitempty"`
+	EmailNotice    string                  `json:"email_notice,omitempty"` // outcome of resending the verification email
+	TwoFactor      *TwoFactorStatus        `json:"two_factor,omitempty"`
+	TwoFactorError string                  `json:"two_factor_error,omitempty"`
+	TwoFactorDone  string                  `json:"two_factor_done,omitempty"` // outcome of the last two-factor change
+	LoginMethods   *LoginMethods           `json:"login_methods,omitempty"`
+	IdentityError  string                  `json:"identity_error,omitempty"`
+	IdentityDone   string                  `json:"identity_done,omitempty"` // outcome of the last sign-in method change
+	Tokens         []PersonalToken         `json:"tokens,omitempty"`
+	TokensFailed   bool                    `json:"tokens_failed,omitempty"` // the token list could not be loaded
+	TokenError     string                  `json:"token_error,omitempty"`
+	TokenDone      string                  `json:"token_done,omitempty"` // outcome of the last token change
+}
+
+// TwoFactorPageData - Data for the two-factor setup and recovery codes page
+type TwoFactorPageData struct {
+	User          *User           `json:"user,omitempty"`
+	Setup         *TwoFactorSetup `json:"setup,omitempty"`          // set while enrolling
+	RecoveryCodes []string        `json:"recovery_codes,omitempty"` // set once, right after they are issued
+	Error         string          `json:"error,omitempty"`
+}
+
+// PersonalTokenPageData - Data for the page showing a newly created personal access token
+type PersonalTokenPageData struct {
+	User  *User                 `json:"user,omitempty"`
+	Token *CreatedPersonalToken `json:"token"`
+}
+
+// LoginTwoFactorPageData - Data for the second login step
+type LoginTwoFactorPageData struct {
+	Token    string `json:"token"`
+	ReturnTo string `json:"return_to,omitempty"` // local page to continue to once the code is accepted
+	Error    string `json:"error,omitempty"`
 }
 
 // CategoryPageData - Data for category posts page template
@@ -49,3 +106,31 @@ type CategoryPageData struct {
 // For better readability in frontend handlers
 type RegisterFormData = UserRegistration
 type LoginFormData = UserLogin
+
+// SearchPageData - Data for search page template
+type SearchPageData struct {
+	Query      string                   `json:"query"`
+	Type       string                   `json:"type"`
+	CategoryID string                   `json:"category_id,omitempty"`
+	Author     string                   `json:"author,omitempty"`
+	Results    *PaginatedSearchResponse `json:"results,omitempty"`
+	Categories []Category               `json:"categories"`
+	Error      string                   `json:"error,omitempty"`
+	User       *User                    `json:"user,omitempty"`
+}
+
+// ModerationPageData - Data for the moderator dashboard template
+type ModerationPageData struct {
+	Status  string                    `json:"status"`
+	Type    string                    `json:"type,omitempty"`
+	Reports *PaginatedReportsResponse `json:"reports,omitempty"`
+	Notice  string                    `json:"notice,omitempty"`
+	Error   string                    `json:"error,omitempty"`
+	User    *User                     `json:"user,omitempty"`
+}
+
+// ErrorPageData - Data for the generic error page
+type ErrorPageData struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
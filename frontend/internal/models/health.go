@@ -0,0 +1,24 @@
+package models
+
+// Readiness - Outcome of a readiness probe (same shape as the backend's)
+type Readiness struct {
+	Ready  bool                      `json:"ready"`
+	Checks map[string]ReadinessCheck `json:"checks"`
+}
+
+// ReadinessCheck - One dependency the service needs to serve requests
+type ReadinessCheck struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// BuildInfo - Description of the running binary (same shape as the backend's)
+type BuildInfo struct {
+	Module       string `json:"module"`
+	Version      string `json:"version"` // module version, "(devel)" for local builds
+	GoVersion    string `json:"go_version"`
+	Revision     string `json:"revision,omitempty"` // VCS commit the binary was built from
+	RevisionTime string `json:"revision_time,omitempty"`
+	Modified     bool   `json:"modified"` // built with uncommitted changes
+	Tags         string `json:"tags,omitempty"`
+}
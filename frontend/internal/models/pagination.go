@@ -12,7 +12,7 @@ type PaginationInfo struct {
 
 // PaginatedPostsResponse - Paginated posts response (matches backend exactly)
 type PaginatedPostsResponse struct {
-	Posts      []*Post        `json:"posts"`
+	Posts      []*PostSummary `json:"posts"`
 	Pagination PaginationInfo `json:"pagination"`
 }
 
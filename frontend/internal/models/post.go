@@ -9,10 +9,38 @@ type Post struct {
 	UserID     string         `json:"user_id"`
 	Username   string         `json:"username"`
 	Categories []PostCategory `json:"categories"`
+	Title      string         `json:"post_title"`
 	Content    string         `json:"post_content"`
 	CreatedAt  time.Time      `json:"created_at"`
 	UpdatedAt  *time.Time     `json:"updated_at,omitempty"`
 
+	// Role of the staff member who last edited it (empty unless edited by someone other than the author)
+	UpdatedByRole string `json:"updated_by_role,omitempty"`
+
+	// Aggregated metrics
+	LikeCount    int `json:"like_count"`
+	DislikeCount int `json:"dislike_count"`
+	CommentCount int `json:"comment_count"`
+
+	// User context
+	UserReaction *int `json:"user_reaction,omitempty"` // nil, 1=like, 2=dislike
+	IsOwner      bool `json:"is_owner,omitempty"`      // can current user edit/delete
+}
+
+// PostSummary - Lightweight post used by list views (matches backend exactly)
+type PostSummary struct {
+	ID         string         `json:"post_id"`
+	UserID     string         `json:"user_id"`
+	Username   string         `json:"username"`
+	Categories []PostCategory `json:"categories"`
+	Title      string         `json:"post_title"`
+	Excerpt    string         `json:"excerpt"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  *time.Time     `json:"updated_at,omitempty"`
+
+	// Latest of created_at, updated_at and the newest comment
+	LastActivityAt time.Time `json:"last_activity_at"`
+
 	// Aggregated metrics
 	LikeCount    int `json:"like_count"`
 	DislikeCount int `json:"dislike_count"`
@@ -26,7 +54,7 @@ type Post struct {
 // CategoryPostsResponse represents the response from the backend for category posts
 type CategoryPostsResponse struct {
 	Category   Category               `json:"category"`
-	Posts      []*Post                `json:"posts"`
+	Posts      []*PostSummary         `json:"posts"`
 	Pagination PaginationInfo         `json:"pagination"`
 	Sort       map[string]interface{} `json:"sort"`
 }
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Report - A user's flag on a post, comment or user (matches backend exactly)
+type Report struct {
+	ID               string `json:"report_id"`
+	ReporterID       string `json:"reporter_id,omitempty"`
+	ReporterUsername string `json:"reporter_username,omitempty"`
+
+	TargetType string `json:"target_type"` // "post", "comment" or "user"
+	TargetID   string `json:"target_id"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details,omitempty"`
+
+	PostID              string `json:"post_id,omitempty"`
+	TargetPreview       string `json:"target_preview,omitempty"`
+	TargetAuthorID      string `json:"target_author_id,omitempty"`
+	TargetAuthorName    string `json:"target_author_username,omitempty"`
+	TargetExists        bool   `json:"target_exists"`
+	OpenReportsOnTarget int    `json:"open_reports_on_target"`
+
+	Status         string     `json:"status"`
+	ClaimedBy      string     `json:"claimed_by,omitempty"`
+	ClaimedByName  string     `json:"claimed_by_username,omitempty"`
+	ClaimedAt      *time.Time `json:"claimed_at,omitempty"`
+	ResolvedBy     string     `json:"resolved_by,omitempty"`
+	ResolvedByName string     `json:"resolved_by_username,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// IsPending reports whether the report still needs a moderator
+func (r *Report) IsPending() bool {
+	return r.Status == "open" || r.Status == "claimed"
+}
+
+// PaginatedReportsResponse - Paginated moderation queue response (matches backend exactly)
+type PaginatedReportsResponse struct {
+	Status     string         `json:"status"`
+	Reports    []*Report      `json:"reports"`
+	Pagination PaginationInfo `json:"pagination"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// SearchResult - A single ranked full-text match (matches backend exactly)
+type SearchResult struct {
+	Type       string         `json:"type"` // "post" or "comment"
+	PostID     string         `json:"post_id"`
+	CommentID  string         `json:"comment_id,omitempty"`
+	UserID     string         `json:"user_id"`
+	Username   string         `json:"username"`
+	PostTitle  string         `json:"post_title"`
+	Snippet    string         `json:"snippet"` // matched terms wrapped in <mark></mark>
+	Categories []PostCategory `json:"categories"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Rank       float64        `json:"rank"`
+}
+
+// PaginatedSearchResponse - Paginated search response (matches backend exactly)
+type PaginatedSearchResponse struct {
+	Query      string          `json:"query"`
+	Type       string          `json:"type"`
+	Results    []*SearchResult `json:"results"`
+	Pagination PaginationInfo  `json:"pagination"`
+}
@@ -4,10 +4,25 @@ import "time"
 
 // User - Basic user information (matches backend exactly)
 type User struct {
-	ID        string    `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            string    `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`           // member, moderator or admin
+	EmailVerified bool      `json:"email_verified"` // false until the verification link is followed
+	Mute          *Sanction `json:"mute,omitempty"` // set while the user is muted (read-only)
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Sanction - A ban, suspension or mute placed on a user by a moderator
+type Sanction struct {
+	Type      string     `json:"type"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"` // nil for permanent bans
+}
+
+// CanModerate reports whether the user may edit or remove other users' posts and comments
+func (u *User) CanModerate() bool {
+	return u.Role == "moderator" || u.Role == "admin"
 }
 
 // UserProfile - Complete user profile with statistics (matches backend exactly)
@@ -28,3 +43,105 @@ type ProfileStats struct {
 	LikesReceived    int `json:"likes_received"`     // Total likes on user's posts/comments
 	DislikesReceived int `json:"dislikes_received"`  // Total dislikes on user's posts/comments
 }
+
+// DeviceSession - A device the user is signed in on (matches backend exactly)
+type DeviceSession struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // the session making the request
+}
+
+// SecurityEvent - An entry of the user's security log (matches backend exactly)
+type SecurityEvent struct {
+	ID        string    `json:"event_id"`
+	Type      string    `json:"event_type"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// securityEventTitles describes the backend's event types
+var securityEventTitles = map[string]string{
+	"login_failed":               "Failed login",
+	"account_locked":             "Logins locked after repeated failures",
+	"ip_locked":                  "Logins from an address locked after repeated failures",
+	"session_ip_rejected":        "Signed out after a change of network",
+	"session_ip_changed":         "Session used from a new address",
+	"session_user_agent_changed": "Session used from a different browser",
+	"session_rotated":            "Session renewed after a role change",
+	"two_factor_failed":          "Wrong two-factor code entered in settings",
+	"two_factor_locked":          "Two-factor changes locked after repeated wrong codes",
+}
+
+// Title describes the event for the security log
+func (e SecurityEvent) Title() string {
+	if title, ok := securityEventTitles[e.Type]; ok {
+		return title
+	}
+	return e.Type
+}
+
+// PersonalToken - A personal access token for scripts and bots (matches backend exactly)
+type PersonalToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // first characters of the token, to recognise it
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+}
+
+// CreatedPersonalToken - A new token, returned only once with its secret value
+type CreatedPersonalToken struct {
+	Token string `json:"token"`
+	PersonalToken
+}
+
+// TwoFactorStatus - Whether the user has two-factor login on (matches backend exactly)
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetup - A new authenticator secret during enrollment (matches backend exactly)
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// OAuthProvider - A login provider enabled on the backend (matches backend exactly)
+type OAuthProvider struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OAuthStart - A login or link flow started on the backend (matches backend exactly)
+type OAuthStart struct {
+	AuthURL   string    `json:"auth_url"`
+	Binding   string    `json:"binding"`    // value of the cookie the browser must carry back to the callback
+	ExpiresAt time.Time `json:"expires_at"` // when the flow stops being accepted
+}
+
+// UserIdentity - A provider account linked to the user (matches backend exactly)
+type UserIdentity struct {
+	Provider    string     `json:"provider"`
+	DisplayName string     `json:"display_name"`
+	Email       string     `json:"email,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+// LoginMethods - How the user can log in (matches backend exactly)
+type LoginMethods struct {
+	HasPassword bool            `json:"has_password"`
+	Identities  []UserIdentity  `json:"identities"`
+	Available   []OAuthProvider `json:"available"`
+}
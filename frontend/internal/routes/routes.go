@@ -5,11 +5,12 @@ import (
 
 	"frontend-service/config"
 	"frontend-service/internal/handlers"
+	"frontend-service/internal/middleware"
 	"frontend-service/internal/services"
 )
 
 // SetupRoutes configures all routes for the frontend service
-func SetupRoutes(authService *services.AuthService, postService *services.PostService, categoryService *services.CategoryService, userService *services.UserService, commentService *services.CommentService, postReactionService *services.PostReactionService, commentReactionService *services.CommentReactionService, templateService *services.TemplateService, cfg *config.Config) *http.ServeMux { // CHANGED: Added cfg parameter
+func SetupRoutes(authService *services.AuthService, postService *services.PostService, categoryService *services.CategoryService, userService *services.UserService, commentService *services.CommentService, postReactionService *services.PostReactionService, commentReactionService *services.CommentReactionService, searchService *services.SearchService, reportService *services.ReportService, templateService *services.TemplateService, cfg *config.Config) http.Handler { // CHANGED: Added cfg parameter
 	mux := http.NewServeMux()
 
 	// Serve static files (CSS, JS, images, etc.)
@@ -20,6 +21,10 @@ func SetupRoutes(authService *services.AuthService, postService *services.PostSe
 	// Create handlers with config access where needed
 	homeHandler := handlers.NewHomeHandler(authService, postService, categoryService, templateService)
 	authHandler := handlers.NewAuthHandler(authService, templateService, cfg) // CHANGED: Pass config to auth handler
+	accountHandler := handlers.NewAccountHandler(authService, templateService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(authService, templateService)
+	identityHandler := handlers.NewIdentityHandler(authService)
+	personalTokenHandler := handlers.NewPersonalTokenHandler(authService, templateService)
 	oauthHandler := handlers.NewOAuthHandler(authService, templateService, cfg.APIBaseURL) // NEW: OAuth handler
 	categoryHandler := handlers.NewCategoryHandler(authService, postService, categoryService, templateService)
 	postHandler := handlers.NewPostHandler(authService, postService, templateService)
@@ -28,6 +33,8 @@ func SetupRoutes(authService *services.AuthService, postService *services.PostSe
 	deletePostHandler := handlers.NewDeletePostHandler(authService, postService)
 	profileHandler := handlers.NewProfileHandler(authService, userService, templateService)
 	commentHandler := handlers.NewCommentHandler(authService, commentService, postService, templateService)
+	searchHandler := handlers.NewSearchHandler(authService, searchService, categoryService, templateService)
+	reportHandler := handlers.NewReportHandler(authService, reportService, templateService)
 
 	// UPDATED: Post reaction handler now handles both post and comment reactions
 	postReactionHandler := handlers.NewPostReactionHandler(authService, postReactionService, commentReactionService)
@@ -36,16 +43,26 @@ func SetupRoutes(authService *services.AuthService, postService *services.PostSe
 	mux.HandleFunc("/", homeHandler.ServeHome)
 	mux.HandleFunc("/register", authHandler.ServeRegister)
 	mux.HandleFunc("/login", authHandler.ServeLogin)
+	mux.HandleFunc("/login/2fa", authHandler.ServeLoginTwoFactor)
 	mux.HandleFunc("/logout", authHandler.ServeLogout)
 
+	// Password reset and email verification
+	mux.HandleFunc("/forgot-password", accountHandler.ServeForgotPassword)
+	mux.HandleFunc("/reset-password", accountHandler.ServeResetPassword)
+	mux.HandleFunc("/verify-email", accountHandler.ServeVerifyEmail)
+	mux.HandleFunc("/verify-email/resend", accountHandler.ServeResendVerification)
+
 	// OAuth routes
-	mux.HandleFunc("/auth/google/login", oauthHandler.GoogleLoginHandler)
-	mux.HandleFunc("/auth/google/callback", oauthHandler.GoogleCallbackHandler)
+	mux.HandleFunc("/auth/{provider}/login", oauthHandler.LoginHandler)
+	mux.HandleFunc("/auth/{provider}/callback", oauthHandler.CallbackHandler)
 	mux.HandleFunc("/api/auth/oauth/status", oauthHandler.OAuthStatusHandler)
 
 	// Category routes
 	mux.HandleFunc("/category/{id}", categoryHandler.ServeCategoryPosts)
 
+	// Search routes
+	mux.HandleFunc("/search", searchHandler.ServeSearch)
+
 	// Post routes
 	mux.HandleFunc("/post/{id}", postHandler.ServePostView)
 	mux.HandleFunc("/create-post", createPostHandler.ServeCreatePost)
@@ -57,16 +74,50 @@ func SetupRoutes(authService *services.AuthService, postService *services.PostSe
 	mux.HandleFunc("/profile/my-posts", profileHandler.ServeUserPosts)
 	mux.HandleFunc("/profile/liked-posts", profileHandler.ServeUserLikedPosts)
 	mux.HandleFunc("/profile/commented-posts", profileHandler.ServeUserCommentedPosts)
+	mux.HandleFunc("/profile/sessions/{id}/revoke", profileHandler.ServeRevokeSession)
+	mux.HandleFunc("/profile/2fa/setup", twoFactorHandler.ServeSetup)
+	mux.HandleFunc("/profile/2fa/confirm", twoFactorHandler.ServeConfirm)
+	mux.HandleFunc("/profile/2fa/recovery-codes", twoFactorHandler.ServeRecoveryCodes)
+	mux.HandleFunc("/profile/2fa/disable", twoFactorHandler.ServeDisable)
+
+	// Sign-in methods: linked login providers and adding a password
+	mux.HandleFunc("/profile/identities/link/{provider}", identityHandler.ServeLink)
+	mux.HandleFunc("/profile/identities/unlink/{provider}", identityHandler.ServeUnlink)
+	mux.HandleFunc("/profile/password/set", identityHandler.ServeSetPassword)
+
+	// Personal access tokens for scripts and bots
+	mux.HandleFunc("/profile/tokens/create", personalTokenHandler.ServeCreate)
+	mux.HandleFunc("/profile/tokens/{id}/revoke", personalTokenHandler.ServeRevoke)
 
 	// Comment routes (form handlers)
 	mux.HandleFunc("/api/comments/create/{post_id}", commentHandler.ServeCreateComment)
 	mux.HandleFunc("/api/comments/edit/{comment_id}", commentHandler.ServeEditComment)
 	mux.HandleFunc("/api/comments/delete/{comment_id}", commentHandler.ServeDeleteComment)
+	mux.HandleFunc("/api/comments/reply/{comment_id}", commentHandler.ServeReplyComment)
+	mux.HandleFunc("/comment/{id}", commentHandler.ServeCommentThread)
 	mux.HandleFunc("/edit-comment/{id}", commentHandler.ServeEditCommentForm)
 	mux.HandleFunc("/edit-comment/{id}/submit", commentHandler.ServeEditCommentSubmit)
+	// Report and moderation routes
+	mux.HandleFunc("/report", reportHandler.ServeCreateReport)
+	mux.HandleFunc("/moderation", reportHandler.ServeModeration)
+	mux.HandleFunc("/moderation/reports/{id}/{action}", reportHandler.ServeModerationAction)
+
 	// Reaction routes (both post and comment reactions handled by same handler)
 	mux.HandleFunc("/reactions/posts/toggle", postReactionHandler.ServeTogglePostReaction)
 	mux.HandleFunc("/reactions/comments/toggle", postReactionHandler.ServeToggleCommentReaction) // NEW: Comment reactions
 
-	return mux
+	// Every state-changing request must carry the CSRF token from the page it came from
+	csrf := middleware.NewCSRF(cfg.SessionName, cfg.CSRFSecret, templateService)
+
+	// Sessions the backend renews or replaces reach the browser with the response
+	renewal := middleware.NewSessionRenewal(cfg.SessionName)
+
+	// Probes answer ahead of sessions and CSRF, so orchestrators get no cookies
+	healthHandler := handlers.NewHealthHandler(authService.BaseClient)
+	root := http.NewServeMux()
+	root.HandleFunc("/health", healthHandler.ServeHealth)
+	root.HandleFunc("/ready", healthHandler.ServeReady)
+	root.HandleFunc("/version", healthHandler.ServeVersion)
+	root.Handle("/", middleware.ForwardClientAddress(renewal.Renew(csrf.Protect(mux))))
+	return middleware.LogRequests(root)
 }
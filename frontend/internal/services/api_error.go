@@ -0,0 +1,102 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Problem types the API gives errors that share a status code with others, so handlers
+// can tell them apart without reading messages
+const (
+	ProblemInvalidCode        = "urn:forum:problem:invalid-code"
+	ProblemLastLoginMethod    = "urn:forum:problem:last-login-method"
+	ProblemPasswordAlreadySet = "urn:forum:problem:password-already-set"
+	ProblemMaxReplyDepth      = "urn:forum:problem:max-reply-depth"
+	ProblemReportOwnContent   = "urn:forum:problem:report-own-content"
+	ProblemReportClaimed      = "urn:forum:problem:report-claimed"
+	ProblemReportClosed       = "urn:forum:problem:report-closed"
+)
+
+// APIError is an error response from the API. The API answers errors with RFC 7807
+// problem details, listing the problems with each request field under "errors".
+type APIError struct {
+	Status      int
+	Type        string // problem type, "about:blank" unless the API names the problem
+	Message     string
+	FieldErrors map[string]string // field name -> problem, for forms to show next to their inputs
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// newAPIError decodes an error response body
+func newAPIError(status int, body []byte) *APIError {
+	var problem struct {
+		Type   string `json:"type"`
+		Detail string `json:"detail"`
+		Error  string `json:"error"`
+		Errors []struct {
+			Field   string `json:"field"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	apiErr := &APIError{Status: status}
+	if json.Unmarshal(body, &problem) != nil {
+		apiErr.Message = fmt.Sprintf("request failed with status %d", status)
+		return apiErr
+	}
+
+	apiErr.Type = problem.Type
+	apiErr.Message = problem.Detail
+	if apiErr.Message == "" {
+		apiErr.Message = problem.Error
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(status)
+	}
+	for _, fe := range problem.Errors {
+		if apiErr.FieldErrors == nil {
+			apiErr.FieldErrors = map[string]string{}
+		}
+		// The first problem with a field is the one to fix first
+		if _, ok := apiErr.FieldErrors[fe.Field]; !ok {
+			apiErr.FieldErrors[fe.Field] = fe.Message
+		}
+	}
+	return apiErr
+}
+
+// FieldErrors returns the problems the API found with each field of a form, or nil
+func FieldErrors(err error) map[string]string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.FieldErrors
+	}
+	return nil
+}
+
+// ErrorMessage returns the message of an API response rejecting the request, or "" for
+// other errors, including failures of the API itself
+func ErrorMessage(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status < http.StatusInternalServerError {
+		return apiErr.Message
+	}
+	return ""
+}
+
+// IsStatus reports whether err is an API response with the given status code
+func IsStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Status == status
+}
+
+// IsProblem reports whether err is an API response with the given problem type
+func IsProblem(err error, problemType string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Type == problemType
+}
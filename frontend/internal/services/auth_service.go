@@ -2,10 +2,12 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
 	"frontend-service/config"
 	"frontend-service/internal/models"
@@ -26,7 +28,7 @@ func NewAuthService(baseClient *BaseClient, cfg *config.Config) *AuthService { /
 }
 
 // RegisterUser registers a new user via the backend API
-func (s *AuthService) RegisterUser(formData models.RegisterFormData) error {
+func (s *AuthService) RegisterUser(ctx context.Context, formData models.RegisterFormData) error {
 	err := validations.ValidateUserInput(formData.Username, formData.Email, formData.Password)
 	if err != nil {
 		return fmt.Errorf("invalid user input: %w", err)
@@ -42,7 +44,13 @@ func (s *AuthService) RegisterUser(formData models.RegisterFormData) error {
 	registerURL := s.BaseURL + "/auth/register"
 
 	// Make HTTP POST request
-	resp, err := s.HTTPClient.Post(registerURL, "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", registerURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to register user: %w", err)
 	}
@@ -54,24 +62,27 @@ func (s *AuthService) RegisterUser(formData models.RegisterFormData) error {
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
-	// Parse JSON response to get better error messages
-	var apiResponse models.APIResponse
-	if err := json.Unmarshal(body, &apiResponse); err == nil {
-		if !apiResponse.Success {
-			return fmt.Errorf("%s", apiResponse.Error)
-		}
-	}
-
-	// Check for HTTP errors
+	// Check for HTTP errors; the API explains them, field by field for invalid input
 	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("registration failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp.StatusCode, body)
 	}
 
 	return nil
 }
 
+// TwoFactorRequiredError is returned by LoginUser when the password was right but the
+// account has two-factor login on; Token is redeemed with a code through LoginTwoFactor
+type TwoFactorRequiredError struct {
+	Token string
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "two-factor code required"
+}
+
 // LoginUser logs in a user via the backend API
-func (s *AuthService) LoginUser(formData models.LoginFormData) (*models.User, string, error) {
+// userAgent is the browser's, so the backend can tell the user's devices apart
+func (s *AuthService) LoginUser(ctx context.Context, formData models.LoginFormData, userAgent string) (*models.User, string, error) {
 	err := validations.ValidateEmail(formData.Email)
 	if err != nil {
 		return nil, "", fmt.Errorf("invalid email format: %w", err)
@@ -80,17 +91,38 @@ func (s *AuthService) LoginUser(formData models.LoginFormData) (*models.User, st
 	if err != nil {
 		return nil, "", fmt.Errorf("invalid password format: %w", err)
 	}
+
+	// FIXED: Remove duplicate /api from URL
+	return s.sendLogin(ctx, "/auth/login", formData, userAgent)
+}
+
+// LoginTwoFactor completes a two-factor login with a code from the user's authenticator app or a recovery code
+func (s *AuthService) LoginTwoFactor(ctx context.Context, token, code, userAgent string) (*models.User, string, error) {
+	requestData := map[string]string{
+		"two_factor_token": token,
+		"code":             code,
+	}
+	return s.sendLogin(ctx, "/auth/login/2fa", requestData, userAgent)
+}
+
+// sendLogin posts login data and returns the logged-in user and session ID
+func (s *AuthService) sendLogin(ctx context.Context, path string, payload interface{}, userAgent string) (*models.User, string, error) {
 	// Convert form data to JSON
-	jsonData, err := json.Marshal(formData)
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to marshal form data: %w", err)
 	}
 
-	// FIXED: Remove duplicate /api from URL
-	loginURL := s.BaseURL + "/auth/login"
+	// Create HTTP POST request
+	req, err := http.NewRequestWithContext(ctx, "POST", s.BaseURL+path, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
 
-	// Make HTTP POST request
-	resp, err := s.HTTPClient.Post(loginURL, "application/json", bytes.NewBuffer(jsonData))
+	// Make HTTP request
+	resp, err := s.HTTPClient.Do(req)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to login user: %w", err)
 	}
@@ -102,7 +134,12 @@ func (s *AuthService) LoginUser(formData models.LoginFormData) (*models.User, st
 		return nil, "", fmt.Errorf("failed to read response: %w", err)
 	}
 
-	// Parse JSON response first to get better error messages
+	// Check for HTTP errors, 202 Accepted asks for a two-factor code
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return nil, "", newAPIError(resp.StatusCode, body)
+	}
+
+	// Parse JSON response
 	var apiResponse models.APIResponse
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
 		return nil, "", fmt.Errorf("failed to parse JSON response: %w", err)
@@ -113,17 +150,23 @@ func (s *AuthService) LoginUser(formData models.LoginFormData) (*models.User, st
 		return nil, "", fmt.Errorf("%s", apiResponse.Error)
 	}
 
-	// Check for HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("login failed with status %d", resp.StatusCode)
-	}
-
 	// Convert data to LoginResponse (which contains User and SessionID)
 	dataBytes, err := json.Marshal(apiResponse.Data)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to marshal data: %w", err)
 	}
 
+	// The password was right but a two-factor code is still needed
+	if resp.StatusCode == http.StatusAccepted {
+		var challenge struct {
+			Token string `json:"two_factor_token"`
+		}
+		if err := json.Unmarshal(dataBytes, &challenge); err != nil {
+			return nil, "", fmt.Errorf("failed to parse login data: %w", err)
+		}
+		return nil, "", &TwoFactorRequiredError{Token: challenge.Token}
+	}
+
 	var loginResponse struct {
 		User      models.User `json:"user"`
 		SessionID string      `json:"session_id"`
@@ -136,12 +179,12 @@ func (s *AuthService) LoginUser(formData models.LoginFormData) (*models.User, st
 }
 
 // LogoutUser logs out a user via the backend API
-func (s *AuthService) LogoutUser(sessionID string) error {
+func (s *AuthService) LogoutUser(ctx context.Context, sessionID string) error {
 	// FIXED: Remove duplicate /api from URL
 	logoutURL := s.BaseURL + "/auth/logout"
 
 	// Create request with session cookie
-	req, err := http.NewRequest("POST", logoutURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", logoutURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -167,21 +210,23 @@ func (s *AuthService) LogoutUser(sessionID string) error {
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("logout failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp.StatusCode, body)
 	}
 
 	return nil
 }
 
-// ValidateSession validates a session ID with the backend API
-func (s *AuthService) ValidateSession(sessionID string) (*models.User, error) {
+// ValidateSession validates a session ID with the backend API. userAgent is the browser's,
+// so the backend can compare it with the one the session logged in with. When the backend
+// renewed or replaced the session, the new session cookie is returned as well.
+func (s *AuthService) ValidateSession(ctx context.Context, sessionID, userAgent string) (*models.User, *http.Cookie, error) {
 	// FIXED: Remove duplicate /api from URL
 	validateURL := s.BaseURL + "/auth/me"
 
 	// Create request with session cookie
-	req, err := http.NewRequest("POST", validateURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", validateURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add session cookie to request using config value
@@ -189,103 +234,111 @@ func (s *AuthService) ValidateSession(sessionID string) (*models.User, error) {
 		Name:  s.SessionName, // CHANGED: Use config value instead of hardcoded "session_id"
 		Value: sessionID,
 	})
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
 
 	// Make HTTP request
 	resp, err := s.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to validate session: %w", err)
+		return nil, nil, fmt.Errorf("failed to validate session: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Check for HTTP errors (401 means invalid session)
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("invalid session")
-	}
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("session validation failed: %s", string(body))
+		return nil, nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
 	var apiResponse models.APIResponse
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
 	// Check API success
 	if !apiResponse.Success {
-		return nil, fmt.Errorf("session validation failed: %s", apiResponse.Error)
+		return nil, nil, fmt.Errorf("session validation failed: %s", apiResponse.Error)
 	}
 
 	// Convert data to User
 	dataBytes, err := json.Marshal(apiResponse.Data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal data: %w", err)
+		return nil, nil, fmt.Errorf("failed to marshal data: %w", err)
 	}
 
 	var user models.User
 	if err := json.Unmarshal(dataBytes, &user); err != nil {
-		return nil, fmt.Errorf("failed to parse user data: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse user data: %w", err)
 	}
 
-	return &user, nil
+	return &user, renewedSessionCookie(resp, s.SessionName), nil
 }
 
-// InitiateGoogleOAuth initiates Google OAuth flow via backend
-func (s *AuthService) InitiateGoogleOAuth() (string, error) {
-	loginURL := s.BaseURL + "/auth/google/login"
-
-	resp, err := s.HTTPClient.Get(loginURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to initiate Google OAuth: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+// renewedSessionCookie returns the session cookie a backend response set, if any
+func renewedSessionCookie(resp *http.Response, name string) *http.Cookie {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == name && cookie.Value != "" {
+			return cookie
+		}
 	}
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("OAuth initiation failed with status %d: %s", resp.StatusCode, string(body))
+// InitiateOAuth asks the backend to start a login with the named provider.
+// returnTo is the local page to continue to afterwards, or empty for the home page.
+func (s *AuthService) InitiateOAuth(ctx context.Context, provider, returnTo string) (*models.OAuthStart, error) {
+	path := "/auth/" + url.PathEscape(provider) + "/login"
+	if returnTo != "" {
+		path += "?return_to=" + url.QueryEscape(returnTo)
 	}
+	return s.startOAuth(ctx, path, nil)
+}
 
-	var apiResponse models.APIResponse
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return "", fmt.Errorf("failed to parse JSON response: %w", err)
-	}
+// LinkProvider asks the backend to start adding a provider to the logged-in user
+func (s *AuthService) LinkProvider(ctx context.Context, provider string, sessionCookie *http.Cookie) (*models.OAuthStart, error) {
+	return s.startOAuth(ctx, "/auth/"+url.PathEscape(provider)+"/link", sessionCookie)
+}
 
-	if !apiResponse.Success {
-		return "", fmt.Errorf("%s", apiResponse.Error)
+// startOAuth starts an OAuth flow on the backend and returns where to send the browser
+func (s *AuthService) startOAuth(ctx context.Context, path string, sessionCookie *http.Cookie) (*models.OAuthStart, error) {
+	data, err := s.doRequest(ctx, "GET", path, nil, http.StatusOK, sessionCookie)
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract auth_url from response
-	dataMap, ok := apiResponse.Data.(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("invalid response data format")
+	var start models.OAuthStart
+	if err := json.Unmarshal(data, &start); err != nil {
+		return nil, fmt.Errorf("failed to parse OAuth response: %w", err)
 	}
-
-	authURL, ok := dataMap["auth_url"].(string)
-	if !ok {
-		return "", fmt.Errorf("auth_url not found in response")
+	if start.AuthURL == "" || start.Binding == "" {
+		return nil, fmt.Errorf("auth_url not found in response")
 	}
 
-	return authURL, nil
+	return &start, nil
 }
 
-// HandleGoogleCallback handles Google OAuth callback via backend
-func (s *AuthService) HandleGoogleCallback(code, state string) (*models.User, string, bool, error) {
-	callbackURL := fmt.Sprintf("%s/auth/google/callback?code=%s&state=%s", s.BaseURL, code, state)
+// HandleOAuthCallback handles a login provider's OAuth callback via backend
+func (s *AuthService) HandleOAuthCallback(ctx context.Context, provider, code, state string) (*models.User, string, bool, error) {
+	params := url.Values{}
+	params.Set("code", code)
+	params.Set("state", state)
+	callbackURL := fmt.Sprintf("%s/auth/%s/callback?%s", s.BaseURL, url.PathEscape(provider), params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", callbackURL, nil)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to create request: %w", err)
+	}
 
-	resp, err := s.HTTPClient.Get(callbackURL)
+	resp, err := s.HTTPClient.Do(req)
 	if err != nil {
-		return nil, "", false, fmt.Errorf("failed to handle Google callback: %w", err)
+		return nil, "", false, fmt.Errorf("failed to handle %s callback: %w", provider, err)
 	}
 	defer resp.Body.Close()
 
@@ -295,7 +348,7 @@ func (s *AuthService) HandleGoogleCallback(code, state string) (*models.User, st
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", false, fmt.Errorf("OAuth callback failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, "", false, newAPIError(resp.StatusCode, body)
 	}
 
 	var apiResponse models.APIResponse
@@ -326,10 +379,15 @@ func (s *AuthService) HandleGoogleCallback(code, state string) (*models.User, st
 }
 
 // GetOAuthStatus gets OAuth configuration from backend
-func (s *AuthService) GetOAuthStatus() (map[string]interface{}, error) {
+func (s *AuthService) GetOAuthStatus(ctx context.Context) (map[string]interface{}, error) {
 	statusURL := s.BaseURL + "/auth/oauth/status"
 
-	resp, err := s.HTTPClient.Get(statusURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", statusURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get OAuth status: %w", err)
 	}
@@ -341,7 +399,7 @@ func (s *AuthService) GetOAuthStatus() (map[string]interface{}, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OAuth status request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	var apiResponse models.APIResponse
@@ -360,3 +418,235 @@ func (s *AuthService) GetOAuthStatus() (map[string]interface{}, error) {
 
 	return status, nil
 }
+
+// GetOAuthProviders lists the login providers enabled on the backend
+func (s *AuthService) GetOAuthProviders(ctx context.Context) ([]models.OAuthProvider, error) {
+	data, err := s.doRequest(ctx, "GET", "/auth/oauth/status", nil, http.StatusOK, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var status struct {
+		Providers []models.OAuthProvider `json:"providers"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse OAuth status: %w", err)
+	}
+
+	return status.Providers, nil
+}
+
+// GetSessions lists the devices the user is currently signed in on
+func (s *AuthService) GetSessions(ctx context.Context, sessionCookie *http.Cookie) ([]models.DeviceSession, error) {
+	data, err := s.doRequest(ctx, "GET", "/auth/sessions", nil, http.StatusOK, sessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []models.DeviceSession
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse sessions data: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// GetSecurityEvents lists the user's most recent security events, newest first
+func (s *AuthService) GetSecurityEvents(ctx context.Context, limit int, sessionCookie *http.Cookie) ([]models.SecurityEvent, error) {
+	data, err := s.doRequest(ctx, "GET", fmt.Sprintf("/auth/security-events?limit=%d", limit), nil, http.StatusOK, sessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []models.SecurityEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("failed to parse security events: %w", err)
+	}
+
+	return events, nil
+}
+
+// RevokeSession signs the user out of one device
+func (s *AuthService) RevokeSession(ctx context.Context, deviceID string, sessionCookie *http.Cookie) error {
+	_, err := s.doRequest(ctx, "DELETE", "/auth/sessions/revoke/"+deviceID, nil, http.StatusOK, sessionCookie)
+	return err
+}
+
+// RevokeOtherSessions signs the user out of every device except the current one
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, sessionCookie *http.Cookie) error {
+	_, err := s.doRequest(ctx, "DELETE", "/auth/sessions/revoke-others", nil, http.StatusOK, sessionCookie)
+	return err
+}
+
+// ForgotPassword asks the backend to email a password reset link
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	requestData := map[string]string{"email": email}
+	_, err := s.doRequest(ctx, "POST", "/auth/forgot-password", requestData, http.StatusOK, nil)
+	return err
+}
+
+// ResetPassword sets a new password using the token from a reset link
+func (s *AuthService) ResetPassword(ctx context.Context, token, password, confirmPassword string) error {
+	requestData := map[string]string{
+		"token":            token,
+		"password":         password,
+		"confirm_password": confirmPassword,
+	}
+	_, err := s.doRequest(ctx, "POST", "/auth/reset-password", requestData, http.StatusOK, nil)
+	return err
+}
+
+// VerifyEmail confirms the user's email address using the token from a verification link
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	requestData := map[string]string{"token": token}
+	_, err := s.doRequest(ctx, "POST", "/auth/verify-email", requestData, http.StatusOK, nil)
+	return err
+}
+
+// ResendVerification emails the logged-in user a new verification link
+func (s *AuthService) ResendVerification(ctx context.Context, sessionCookie *http.Cookie) error {
+	_, err := s.doRequest(ctx, "POST", "/auth/resend-verification", nil, http.StatusOK, sessionCookie)
+	return err
+}
+
+// GetPersonalTokens lists the user's personal access tokens
+func (s *AuthService) GetPersonalTokens(ctx context.Context, sessionCookie *http.Cookie) ([]models.PersonalToken, error) {
+	data, err := s.doRequest(ctx, "GET", "/auth/tokens", nil, http.StatusOK, sessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := []models.PersonalToken{}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse tokens data: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// CreatePersonalToken creates a personal access token. Its value is only returned here.
+func (s *AuthService) CreatePersonalToken(ctx context.Context, name string, scopes []string, expiresInDays int, sessionCookie *http.Cookie) (*models.CreatedPersonalToken, error) {
+	requestData := map[string]interface{}{
+		"name":            name,
+		"scopes":          scopes,
+		"expires_in_days": expiresInDays,
+	}
+
+	data, err := s.doRequest(ctx, "POST", "/auth/tokens/create", requestData, http.StatusCreated, sessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	var token models.CreatedPersonalToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token data: %w", err)
+	}
+
+	return &token, nil
+}
+
+// RevokePersonalToken deletes one of the user's personal access tokens
+func (s *AuthService) RevokePersonalToken(ctx context.Context, tokenID string, sessionCookie *http.Cookie) error {
+	_, err := s.doRequest(ctx, "DELETE", "/auth/tokens/revoke/"+url.PathEscape(tokenID), nil, http.StatusOK, sessionCookie)
+	return err
+}
+
+// GetTwoFactorStatus reports whether the user has two-factor login on
+func (s *AuthService) GetTwoFactorStatus(ctx context.Context, sessionCookie *http.Cookie) (*models.TwoFactorStatus, error) {
+	data, err := s.doRequest(ctx, "GET", "/auth/2fa/status", nil, http.StatusOK, sessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	var status models.TwoFactorStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse two-factor status: %w", err)
+	}
+
+	return &status, nil
+}
+
+// BeginTwoFactorSetup creates an authenticator secret for the user to add to their app
+func (s *AuthService) BeginTwoFactorSetup(ctx context.Context, sessionCookie *http.Cookie) (*models.TwoFactorSetup, error) {
+	data, err := s.doRequest(ctx, "POST", "/auth/2fa/setup", nil, http.StatusOK, sessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	var setup models.TwoFactorSetup
+	if err := json.Unmarshal(data, &setup); err != nil {
+		return nil, fmt.Errorf("failed to parse two-factor setup: %w", err)
+	}
+
+	return &setup, nil
+}
+
+// ConfirmTwoFactorSetup turns two-factor login on and returns the recovery codes
+func (s *AuthService) ConfirmTwoFactorSetup(ctx context.Context, code string, sessionCookie *http.Cookie) ([]string, error) {
+	requestData := map[string]string{"code": code}
+	return s.recoveryCodesRequest(ctx, "/auth/2fa/confirm", requestData, sessionCookie)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, code string, sessionCookie *http.Cookie) ([]string, error) {
+	requestData := map[string]string{"code": code}
+	return s.recoveryCodesRequest(ctx, "/auth/2fa/recovery-codes", requestData, sessionCookie)
+}
+
+// DisableTwoFactor turns two-factor login off
+func (s *AuthService) DisableTwoFactor(ctx context.Context, password, code string, sessionCookie *http.Cookie) error {
+	requestData := map[string]string{
+		"password": password,
+		"code":     code,
+	}
+	_, err := s.doRequest(ctx, "POST", "/auth/2fa/disable", requestData, http.StatusOK, sessionCookie)
+	return err
+}
+
+// GetLoginMethods lists the user's password and linked providers, and the providers they can link
+func (s *AuthService) GetLoginMethods(ctx context.Context, sessionCookie *http.Cookie) (*models.LoginMethods, error) {
+	data, err := s.doRequest(ctx, "GET", "/auth/identities", nil, http.StatusOK, sessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods models.LoginMethods
+	if err := json.Unmarshal(data, &methods); err != nil {
+		return nil, fmt.Errorf("failed to parse login methods: %w", err)
+	}
+
+	return &methods, nil
+}
+
+// UnlinkProvider removes a linked provider from the user's login methods
+func (s *AuthService) UnlinkProvider(ctx context.Context, provider string, sessionCookie *http.Cookie) error {
+	_, err := s.doRequest(ctx, "DELETE", "/auth/identities/unlink/"+url.PathEscape(provider), nil, http.StatusOK, sessionCookie)
+	return err
+}
+
+// SetPassword adds a password to an account that only logs in through providers
+func (s *AuthService) SetPassword(ctx context.Context, password, confirmPassword string, sessionCookie *http.Cookie) error {
+	requestData := map[string]string{
+		"password":         password,
+		"confirm_password": confirmPassword,
+	}
+	_, err := s.doRequest(ctx, "POST", "/auth/password/set", requestData, http.StatusOK, sessionCookie)
+	return err
+}
+
+// recoveryCodesRequest posts to an endpoint that answers with new recovery codes
+func (s *AuthService) recoveryCodesRequest(ctx context.Context, path string, payload interface{}, sessionCookie *http.Cookie) ([]string, error) {
+	data, err := s.doRequest(ctx, "POST", path, payload, http.StatusOK, sessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse recovery codes: %w", err)
+	}
+
+	return result.RecoveryCodes, nil
+}
@@ -1,8 +1,16 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"time"
+
+	"frontend-service/internal/logging"
 )
 
 // BaseClient provides shared HTTP client functionality
@@ -16,7 +24,123 @@ func NewBaseClient(baseURL string) *BaseClient {
 	return &BaseClient{
 		BaseURL: baseURL,
 		HTTPClient: &http.Client{
-			Timeout: 15 * time.Second,
+			Timeout:   15 * time.Second,
+			Transport: forwardingTransport{http.DefaultTransport},
 		},
 	}
 }
+
+type clientAddressKey struct{}
+
+// WithClientAddress returns a context carrying the address of the browser being served
+func WithClientAddress(ctx context.Context, remoteAddr string) context.Context {
+	return context.WithValue(ctx, clientAddressKey{}, remoteAddr)
+}
+
+// forwardingTransport sends the request ID and browser address of the page being served
+// with every API call. The API only believes X-Forwarded-For from its TRUSTED_PROXIES,
+// so the frontend's address must be listed there.
+type forwardingTransport struct {
+	next http.RoundTripper
+}
+
+func (t forwardingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := logging.RequestID(req.Context())
+	remoteAddr, _ := req.Context().Value(clientAddressKey{}).(string)
+	if id == "" && remoteAddr == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the request it is given
+	req = req.Clone(req.Context())
+	if id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+	if remoteAddr != "" {
+		host, _, err := net.SplitHostPort(remoteAddr)
+		if err != nil {
+			host = remoteAddr
+		}
+		req.Header.Add("X-Forwarded-For", host)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// doRequest calls the API and returns the raw "data" of a successful response.
+// Error responses are returned as an *APIError, so their message and field errors can be shown to the user.
+func (s *BaseClient) doRequest(ctx context.Context, method, path string, payload interface{}, expectedStatus int, sessionCookie *http.Cookie) (json.RawMessage, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request data: %w", err)
+		}
+		reqBody = bytes.NewBuffer(jsonData)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, method, s.BaseURL+path, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Add session cookie for authentication
+	if sessionCookie != nil {
+		req.AddCookie(sessionCookie)
+	}
+
+	// Make HTTP request
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != expectedStatus {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	var apiResponse struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+	}
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if !apiResponse.Success {
+		return nil, fmt.Errorf("API error: %s", apiResponse.Error)
+	}
+
+	return apiResponse.Data, nil
+}
+
+// CheckAPI reports whether the API answers its liveness probe
+func (s *BaseClient) CheckAPI(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.BaseURL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach API: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API health check failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"frontend-service/internal/logging"
+)
+
+// recordingTransport keeps the request it was asked to send
+type recordingTransport struct {
+	req *http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.req = req
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestForwardingTransport(t *testing.T) {
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		requestID     string
+		existing      []string // X-Forwarded-For already on the request
+		wantForwarded []string
+	}{
+		{name: "nothing to forward"},
+		{name: "IPv4 browser", remoteAddr: "203.0.113.7:5000", wantForwarded: []string{"203.0.113.7"}},
+		{name: "IPv6 browser", remoteAddr: "[2001:db8::1]:5000", wantForwarded: []string{"2001:db8::1"}},
+		{name: "address without a port", remoteAddr: "203.0.113.7", wantForwarded: []string{"203.0.113.7"}},
+		{name: "request ID only", requestID: "req-1"},
+		{
+			name:          "appended to an existing chain",
+			remoteAddr:    "203.0.113.7:5000",
+			existing:      []string{"198.51.100.1"},
+			wantForwarded: []string{"198.51.100.1", "203.0.113.7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.remoteAddr != "" {
+				ctx = WithClientAddress(ctx, tt.remoteAddr)
+			}
+			if tt.requestID != "" {
+				ctx = logging.WithRequestID(ctx, tt.requestID)
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://api.example/api/posts", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range tt.existing {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+
+			next := &recordingTransport{}
+			if _, err := (forwardingTransport{next}).RoundTrip(req); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := next.req.Header.Values("X-Forwarded-For"); !slices.Equal(got, tt.wantForwarded) && len(got)+len(tt.wantForwarded) > 0 {
+				t.Errorf("X-Forwarded-For = %v, want %v", got, tt.wantForwarded)
+			}
+			if got := next.req.Header.Get(logging.RequestIDHeader); got != tt.requestID {
+				t.Errorf("%s = %q, want %q", logging.RequestIDHeader, got, tt.requestID)
+			}
+			// The caller's request is left as it was
+			if got := req.Header.Values("X-Forwarded-For"); !slices.Equal(got, tt.existing) && len(got)+len(tt.existing) > 0 {
+				t.Errorf("caller's X-Forwarded-For changed to %v", got)
+			}
+		})
+	}
+}
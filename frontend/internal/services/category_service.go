@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -21,9 +22,14 @@ func NewCategoryService(baseClient *BaseClient) *CategoryService {
 }
 
 // GetCategories retrieves categories from the backend API
-func (s *CategoryService) GetCategories() ([]models.Category, error) {
+func (s *CategoryService) GetCategories(ctx context.Context) ([]models.Category, error) {
 	// Make HTTP request
-	resp, err := s.HTTPClient.Get(s.BaseURL + "/categories")
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/categories", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch categories: %w", err)
 	}
@@ -37,7 +43,7 @@ func (s *CategoryService) GetCategories() ([]models.Category, error) {
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,7 +23,7 @@ func NewCommentReactionService(baseClient *BaseClient) *CommentReactionService {
 }
 
 // ToggleCommentReaction toggles a like/dislike reaction on a comment
-func (s *CommentReactionService) ToggleCommentReaction(commentID string, reactionType int, sessionCookie *http.Cookie) (*models.ReactionResult, error) {
+func (s *CommentReactionService) ToggleCommentReaction(ctx context.Context, commentID string, reactionType int, sessionCookie *http.Cookie) (*models.ReactionResult, error) {
 	// Prepare request data
 	requestData := models.CommentReactionRequest{
 		CommentID:    commentID,
@@ -47,7 +48,7 @@ func (s *CommentReactionService) ToggleCommentReaction(commentID string, reactio
 	toggleURL := s.BaseURL + "/reactions/comments/toggle"
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", toggleURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", toggleURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -74,16 +75,8 @@ func (s *CommentReactionService) ToggleCommentReaction(commentID string, reactio
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: please log in")
-	}
 	if resp.StatusCode != http.StatusOK {
-		// Try to parse error message
-		var apiResponse models.APIResponse
-		if json.Unmarshal(body, &apiResponse) == nil && !apiResponse.Success {
-			return nil, fmt.Errorf("API error: %s", apiResponse.Error)
-		}
-		return nil, fmt.Errorf("toggle comment reaction failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -112,12 +105,12 @@ func (s *CommentReactionService) ToggleCommentReaction(commentID string, reactio
 }
 
 // GetCommentReactionStatus gets the current user's reaction status on a comment
-func (s *CommentReactionService) GetCommentReactionStatus(commentID string, sessionCookie *http.Cookie) (*int, error) {
+func (s *CommentReactionService) GetCommentReactionStatus(ctx context.Context, commentID string, sessionCookie *http.Cookie) (*int, error) {
 	// Build URL for get comment reaction status
 	statusURL := s.BaseURL + "/reactions/comments/status/" + commentID
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", statusURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", statusURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -141,16 +134,8 @@ func (s *CommentReactionService) GetCommentReactionStatus(commentID string, sess
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: please log in")
-	}
 	if resp.StatusCode != http.StatusOK {
-		// Try to parse error message
-		var apiResponse models.APIResponse
-		if json.Unmarshal(body, &apiResponse) == nil && !apiResponse.Success {
-			return nil, fmt.Errorf("API error: %s", apiResponse.Error)
-		}
-		return nil, fmt.Errorf("get comment reaction status failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
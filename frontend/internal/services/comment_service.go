@@ -2,10 +2,12 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
 	"frontend-service/internal/models"
 )
@@ -22,7 +24,7 @@ func NewCommentService(baseClient *BaseClient) *CommentService {
 }
 
 // CreateComment creates a new comment on a post
-func (s *CommentService) CreateComment(postID, content string, sessionCookie *http.Cookie) (*models.Comment, error) {
+func (s *CommentService) CreateComment(ctx context.Context, postID, content string, sessionCookie *http.Cookie) (*models.Comment, error) {
 	// Prepare request data
 	requestData := map[string]interface{}{
 		"content": content,
@@ -38,7 +40,7 @@ func (s *CommentService) CreateComment(postID, content string, sessionCookie *ht
 	createURL := s.BaseURL + "/comments/create-on-post/" + postID
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", createURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", createURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -65,16 +67,8 @@ func (s *CommentService) CreateComment(postID, content string, sessionCookie *ht
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: please log in")
-	}
 	if resp.StatusCode != http.StatusCreated {
-		// Try to parse error message
-		var apiResponse models.APIResponse
-		if json.Unmarshal(body, &apiResponse) == nil && !apiResponse.Success {
-			return nil, fmt.Errorf("API error: %s", apiResponse.Error)
-		}
-		return nil, fmt.Errorf("create comment failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -103,7 +97,7 @@ func (s *CommentService) CreateComment(postID, content string, sessionCookie *ht
 }
 
 // UpdateComment updates an existing comment
-func (s *CommentService) UpdateComment(commentID, content string, sessionCookie *http.Cookie) error {
+func (s *CommentService) UpdateComment(ctx context.Context, commentID, content string, sessionCookie *http.Cookie) error {
 	// Prepare request data
 	requestData := map[string]interface{}{
 		"content": content,
@@ -119,7 +113,7 @@ func (s *CommentService) UpdateComment(commentID, content string, sessionCookie
 	updateURL := s.BaseURL + "/comments/edit/" + commentID
 
 	// Create HTTP request
-	req, err := http.NewRequest("PUT", updateURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -146,22 +140,8 @@ func (s *CommentService) UpdateComment(commentID, content string, sessionCookie
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("unauthorized: please log in")
-	}
-	if resp.StatusCode == http.StatusForbidden {
-		return fmt.Errorf("forbidden: you can only edit your own comments")
-	}
-	if resp.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("comment not found")
-	}
 	if resp.StatusCode != http.StatusOK {
-		// Try to parse error message
-		var apiResponse models.APIResponse
-		if json.Unmarshal(body, &apiResponse) == nil && !apiResponse.Success {
-			return fmt.Errorf("API error: %s", apiResponse.Error)
-		}
-		return fmt.Errorf("update comment failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response to check for API errors
@@ -179,12 +159,12 @@ func (s *CommentService) UpdateComment(commentID, content string, sessionCookie
 }
 
 // DeleteComment deletes a comment
-func (s *CommentService) DeleteComment(commentID string, sessionCookie *http.Cookie) error {
+func (s *CommentService) DeleteComment(ctx context.Context, commentID string, sessionCookie *http.Cookie) error {
 	// Build URL for delete comment
 	deleteURL := s.BaseURL + "/comments/remove/" + commentID
 
 	// Create HTTP request
-	req, err := http.NewRequest("DELETE", deleteURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", deleteURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -208,22 +188,8 @@ func (s *CommentService) DeleteComment(commentID string, sessionCookie *http.Coo
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("unauthorized: please log in")
-	}
-	if resp.StatusCode == http.StatusForbidden {
-		return fmt.Errorf("forbidden: you can only delete your own comments")
-	}
-	if resp.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("comment not found")
-	}
 	if resp.StatusCode != http.StatusOK {
-		// Try to parse error message
-		var apiResponse models.APIResponse
-		if json.Unmarshal(body, &apiResponse) == nil && !apiResponse.Success {
-			return fmt.Errorf("API error: %s", apiResponse.Error)
-		}
-		return fmt.Errorf("delete comment failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response to check for API errors
@@ -241,12 +207,12 @@ func (s *CommentService) DeleteComment(commentID string, sessionCookie *http.Coo
 }
 
 // GetCommentByID retrieves a single comment by ID
-func (s *CommentService) GetCommentByID(commentID string, sessionCookie *http.Cookie) (*models.Comment, error) {
+func (s *CommentService) GetCommentByID(ctx context.Context, commentID string, sessionCookie *http.Cookie) (*models.Comment, error) {
 	// Build URL for get single comment
 	commentURL := s.BaseURL + "/comments/view/" + commentID
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", commentURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", commentURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -270,11 +236,8 @@ func (s *CommentService) GetCommentByID(commentID string, sessionCookie *http.Co
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("comment not found")
-	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get comment: %s", string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -300,3 +263,146 @@ func (s *CommentService) GetCommentByID(commentID string, sessionCookie *http.Co
 
 	return &comment, nil
 }
+
+// ReplyToComment creates a reply under an existing comment
+func (s *CommentService) ReplyToComment(ctx context.Context, parentCommentID, content string, sessionCookie *http.Cookie) (*models.Comment, error) {
+	// Prepare request data
+	requestData := map[string]interface{}{
+		"content": content,
+	}
+
+	// Convert to JSON
+	jsonData, err := json.Marshal(requestData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request data: %w", err)
+	}
+
+	// Build URL for reply
+	replyURL := s.BaseURL + "/comments/reply-to/" + parentCommentID
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", replyURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+
+	// Add session cookie for authentication
+	if sessionCookie != nil {
+		req.AddCookie(sessionCookie)
+	}
+
+	// Make HTTP request
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reply: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusCreated {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	// Parse JSON response
+	var apiResponse models.APIResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Check API success
+	if !apiResponse.Success {
+		return nil, fmt.Errorf("API error: %s", apiResponse.Error)
+	}
+
+	// Convert data to Comment (the API returns the new reply's ID and position)
+	dataBytes, err := json.Marshal(apiResponse.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	var comment models.Comment
+	if err := json.Unmarshal(dataBytes, &comment); err != nil {
+		return nil, fmt.Errorf("failed to parse reply data: %w", err)
+	}
+
+	return &comment, nil
+}
+
+// GetCommentReplies retrieves a page of replies to a comment, each with its own reply subtree
+func (s *CommentService) GetCommentReplies(ctx context.Context, commentID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedCommentsResponse, error) {
+	// Build URL with query parameters
+	u, err := url.Parse(s.BaseURL + "/comments/replies/" + commentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Add query parameters
+	params := url.Values{}
+	params.Add("limit", fmt.Sprintf("%d", limit))
+	params.Add("offset", fmt.Sprintf("%d", offset))
+	if sortBy != "" {
+		params.Add("sort", sortBy)
+	}
+	u.RawQuery = params.Encode()
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Add session cookie if provided (for user reaction data)
+	if sessionCookie != nil {
+		req.AddCookie(sessionCookie)
+	}
+
+	// Make HTTP request
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch replies: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	// Parse JSON response
+	var apiResponse models.APIResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if !apiResponse.Success {
+		return nil, fmt.Errorf("API error: %s", apiResponse.Error)
+	}
+
+	// Convert data to PaginatedCommentsResponse
+	dataBytes, err := json.Marshal(apiResponse.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	var repliesResponse models.PaginatedCommentsResponse
+	if err := json.Unmarshal(dataBytes, &repliesResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse replies data: %w", err)
+	}
+
+	return &repliesResponse, nil
+}
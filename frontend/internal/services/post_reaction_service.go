@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,7 +23,7 @@ func NewPostReactionService(baseClient *BaseClient) *PostReactionService {
 }
 
 // TogglePostReaction toggles a like/dislike reaction on a post
-func (s *PostReactionService) TogglePostReaction(postID string, reactionType int, sessionCookie *http.Cookie) (*models.ReactionResult, error) {
+func (s *PostReactionService) TogglePostReaction(ctx context.Context, postID string, reactionType int, sessionCookie *http.Cookie) (*models.ReactionResult, error) {
 	// Prepare request data
 	requestData := models.PostReactionRequest{
 		PostID:       postID,
@@ -47,7 +48,7 @@ func (s *PostReactionService) TogglePostReaction(postID string, reactionType int
 	toggleURL := s.BaseURL + "/reactions/posts/toggle"
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", toggleURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", toggleURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -74,16 +75,8 @@ func (s *PostReactionService) TogglePostReaction(postID string, reactionType int
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: please log in")
-	}
 	if resp.StatusCode != http.StatusOK {
-		// Try to parse error message
-		var apiResponse models.APIResponse
-		if json.Unmarshal(body, &apiResponse) == nil && !apiResponse.Success {
-			return nil, fmt.Errorf("API error: %s", apiResponse.Error)
-		}
-		return nil, fmt.Errorf("toggle post reaction failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -112,12 +105,12 @@ func (s *PostReactionService) TogglePostReaction(postID string, reactionType int
 }
 
 // GetPostReactionStatus gets the current user's reaction status on a post
-func (s *PostReactionService) GetPostReactionStatus(postID string, sessionCookie *http.Cookie) (*int, error) {
+func (s *PostReactionService) GetPostReactionStatus(ctx context.Context, postID string, sessionCookie *http.Cookie) (*int, error) {
 	// Build URL for get post reaction status
 	statusURL := s.BaseURL + "/reactions/posts/status/" + postID
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", statusURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", statusURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -141,16 +134,8 @@ func (s *PostReactionService) GetPostReactionStatus(postID string, sessionCookie
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: please log in")
-	}
 	if resp.StatusCode != http.StatusOK {
-		// Try to parse error message
-		var apiResponse models.APIResponse
-		if json.Unmarshal(body, &apiResponse) == nil && !apiResponse.Success {
-			return nil, fmt.Errorf("API error: %s", apiResponse.Error)
-		}
-		return nil, fmt.Errorf("get post reaction status failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
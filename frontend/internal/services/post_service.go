@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -23,7 +24,7 @@ func NewPostService(baseClient *BaseClient) *PostService {
 }
 
 // GetAllPosts retrieves posts from the backend API (updated to accept sort parameter)
-func (s *PostService) GetAllPosts(limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedPostsResponse, error) {
+func (s *PostService) GetAllPosts(ctx context.Context, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedPostsResponse, error) {
 	// Build URL with query parameters
 	u, err := url.Parse(s.BaseURL + "/posts")
 	if err != nil {
@@ -41,7 +42,7 @@ func (s *PostService) GetAllPosts(limit, offset int, sortBy string, sessionCooki
 	u.RawQuery = params.Encode()
 
 	// Create request (instead of using GET directly)
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -66,7 +67,7 @@ func (s *PostService) GetAllPosts(limit, offset int, sortBy string, sessionCooki
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -95,7 +96,7 @@ func (s *PostService) GetAllPosts(limit, offset int, sortBy string, sessionCooki
 }
 
 // GetPostsByCategory retrieves posts filtered by category from the backend API
-func (s *PostService) GetPostsByCategory(categoryID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.CategoryPostsResponse, error) {
+func (s *PostService) GetPostsByCategory(ctx context.Context, categoryID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.CategoryPostsResponse, error) {
 	// Build URL with query parameters
 	u, err := url.Parse(s.BaseURL + "/posts/by-category/" + categoryID)
 	if err != nil {
@@ -112,7 +113,7 @@ func (s *PostService) GetPostsByCategory(categoryID string, limit, offset int, s
 	u.RawQuery = params.Encode()
 
 	// Create request instead of using GET directly
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -137,7 +138,7 @@ func (s *PostService) GetPostsByCategory(categoryID string, limit, offset int, s
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -167,15 +168,15 @@ func (s *PostService) GetPostsByCategory(categoryID string, limit, offset int, s
 
 // GetSinglePostWithComments retrieves a single post and its comments from the backend API
 // Returns data formatted for the existing PostPageData struct
-func (s *PostService) GetSinglePostWithComments(postID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.Post, []*models.Comment, error) {
+func (s *PostService) GetSinglePostWithComments(ctx context.Context, postID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.Post, []*models.Comment, error) {
 	// First, get the post
-	post, err := s.getSinglePost(postID, sessionCookie)
+	post, err := s.getSinglePost(ctx, postID, sessionCookie)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Then, get the comments (we'll extract just the comments array)
-	commentsResponse, err := s.getPostComments(postID, limit, offset, sortBy, sessionCookie)
+	commentsResponse, err := s.getPostComments(ctx, postID, limit, offset, sortBy, sessionCookie)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -185,7 +186,7 @@ func (s *PostService) GetSinglePostWithComments(postID string, limit, offset int
 }
 
 // Helper method to get a single post (updated to accept session cookie)
-func (s *PostService) getSinglePost(postID string, sessionCookie *http.Cookie) (*models.Post, error) {
+func (s *PostService) getSinglePost(ctx context.Context, postID string, sessionCookie *http.Cookie) (*models.Post, error) {
 	// Build URL for single post
 	u, err := url.Parse(s.BaseURL + "/posts/view/" + postID)
 	if err != nil {
@@ -193,7 +194,7 @@ func (s *PostService) getSinglePost(postID string, sessionCookie *http.Cookie) (
 	}
 
 	// Create request instead of using GET directly
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -217,11 +218,8 @@ func (s *PostService) getSinglePost(postID string, sessionCookie *http.Cookie) (
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("post not found")
-	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -250,7 +248,7 @@ func (s *PostService) getSinglePost(postID string, sessionCookie *http.Cookie) (
 }
 
 // Helper method to get post comments (updated to accept session cookie)
-func (s *PostService) getPostComments(postID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedCommentsResponse, error) {
+func (s *PostService) getPostComments(ctx context.Context, postID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedCommentsResponse, error) {
 	// Build URL with query parameters
 	u, err := url.Parse(s.BaseURL + "/comments/for-post/" + postID)
 	if err != nil {
@@ -267,7 +265,7 @@ func (s *PostService) getPostComments(postID string, limit, offset int, sortBy s
 	u.RawQuery = params.Encode()
 
 	// Create request instead of using GET directly
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -292,7 +290,7 @@ func (s *PostService) getPostComments(postID string, limit, offset int, sortBy s
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -323,10 +321,11 @@ func (s *PostService) getPostComments(postID string, limit, offset int, sortBy s
 // Add this method to your existing post_service.go file
 
 // CreatePost submits a new post to the backend API
-func (s *PostService) CreatePost(categoryNames []string, content string, sessionCookie *http.Cookie) (*models.CreatePostResponse, error) {
+func (s *PostService) CreatePost(ctx context.Context, categoryNames []string, title, content string, sessionCookie *http.Cookie) (*models.CreatePostResponse, error) {
 	// Prepare request data
 	requestData := map[string]interface{}{
 		"category_names": categoryNames,
+		"title":          title,
 		"content":        content,
 	}
 
@@ -340,7 +339,7 @@ func (s *PostService) CreatePost(categoryNames []string, content string, session
 	createURL := s.BaseURL + "/posts/create"
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", createURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", createURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -367,16 +366,8 @@ func (s *PostService) CreatePost(categoryNames []string, content string, session
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: please log in")
-	}
 	if resp.StatusCode != http.StatusCreated {
-		// Try to parse error message
-		var apiResponse models.APIResponse
-		if json.Unmarshal(body, &apiResponse) == nil && !apiResponse.Success {
-			return nil, fmt.Errorf("API error: %s", apiResponse.Error)
-		}
-		return nil, fmt.Errorf("create post failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -407,10 +398,11 @@ func (s *PostService) CreatePost(categoryNames []string, content string, session
 // Add these methods to your existing post_service.go file
 
 // UpdatePost updates an existing post via the backend API
-func (s *PostService) UpdatePost(postID string, categoryNames []string, content string, sessionCookie *http.Cookie) error {
+func (s *PostService) UpdatePost(ctx context.Context, postID string, categoryNames []string, title, content string, sessionCookie *http.Cookie) error {
 	// Prepare request data
 	requestData := map[string]interface{}{
 		"category_names": categoryNames,
+		"title":          title,
 		"content":        content,
 	}
 
@@ -424,7 +416,7 @@ func (s *PostService) UpdatePost(postID string, categoryNames []string, content
 	updateURL := s.BaseURL + "/posts/edit/" + postID
 
 	// Create HTTP request
-	req, err := http.NewRequest("PUT", updateURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -451,22 +443,8 @@ func (s *PostService) UpdatePost(postID string, categoryNames []string, content
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("unauthorized: please log in")
-	}
-	if resp.StatusCode == http.StatusForbidden {
-		return fmt.Errorf("forbidden: you can only edit your own posts")
-	}
-	if resp.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("post not found")
-	}
 	if resp.StatusCode != http.StatusOK {
-		// Try to parse error message
-		var apiResponse models.APIResponse
-		if json.Unmarshal(body, &apiResponse) == nil && !apiResponse.Success {
-			return fmt.Errorf("API error: %s", apiResponse.Error)
-		}
-		return fmt.Errorf("update post failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response to check for API errors
@@ -484,12 +462,12 @@ func (s *PostService) UpdatePost(postID string, categoryNames []string, content
 }
 
 // DeletePost deletes a post via the backend API
-func (s *PostService) DeletePost(postID string, sessionCookie *http.Cookie) error {
+func (s *PostService) DeletePost(ctx context.Context, postID string, sessionCookie *http.Cookie) error {
 	// Build URL for delete post
 	deleteURL := s.BaseURL + "/posts/remove/" + postID
 
 	// Create HTTP request
-	req, err := http.NewRequest("DELETE", deleteURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", deleteURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -513,22 +491,8 @@ func (s *PostService) DeletePost(postID string, sessionCookie *http.Cookie) erro
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("unauthorized: please log in")
-	}
-	if resp.StatusCode == http.StatusForbidden {
-		return fmt.Errorf("forbidden: you can only delete your own posts")
-	}
-	if resp.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("post not found")
-	}
 	if resp.StatusCode != http.StatusOK {
-		// Try to parse error message
-		var apiResponse models.APIResponse
-		if json.Unmarshal(body, &apiResponse) == nil && !apiResponse.Success {
-			return fmt.Errorf("API error: %s", apiResponse.Error)
-		}
-		return fmt.Errorf("delete post failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response to check for API errors
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"frontend-service/internal/models"
+)
+
+type ReportService struct {
+	*BaseClient
+}
+
+// NewReportService creates a new report service
+func NewReportService(baseClient *BaseClient) *ReportService {
+	return &ReportService{
+		BaseClient: baseClient,
+	}
+}
+
+// CreateReport reports a post, comment or user to the moderators
+func (s *ReportService) CreateReport(ctx context.Context, targetType, targetID, reason, details string, sessionCookie *http.Cookie) error {
+	requestData := map[string]interface{}{
+		"target_type": targetType,
+		"target_id":   targetID,
+		"reason":      reason,
+		"details":     details,
+	}
+
+	_, err := s.doRequest(ctx, "POST", "/reports/create", requestData, http.StatusCreated, sessionCookie)
+	return err
+}
+
+// GetReportQueue fetches a page of the moderation queue
+func (s *ReportService) GetReportQueue(ctx context.Context, status, targetType string, limit, offset int, sessionCookie *http.Cookie) (*models.PaginatedReportsResponse, error) {
+	params := url.Values{}
+	params.Add("limit", fmt.Sprintf("%d", limit))
+	params.Add("offset", fmt.Sprintf("%d", offset))
+	if status != "" {
+		params.Add("status", status)
+	}
+	if targetType != "" {
+		params.Add("type", targetType)
+	}
+
+	data, err := s.doRequest(ctx, "GET", "/reports/queue?"+params.Encode(), nil, http.StatusOK, sessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports models.PaginatedReportsResponse
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return nil, fmt.Errorf("failed to parse reports data: %w", err)
+	}
+
+	return &reports, nil
+}
+
+// ClaimReport assigns a pending report to the current moderator
+func (s *ReportService) ClaimReport(ctx context.Context, reportID string, sessionCookie *http.Cookie) error {
+	_, err := s.doRequest(ctx, "PUT", "/reports/claim/"+url.PathEscape(reportID), nil, http.StatusOK, sessionCookie)
+	return err
+}
+
+// ResolveReport closes a report after action was taken
+func (s *ReportService) ResolveReport(ctx context.Context, reportID, note string, sessionCookie *http.Cookie) error {
+	requestData := map[string]interface{}{"note": note}
+	_, err := s.doRequest(ctx, "PUT", "/reports/resolve/"+url.PathEscape(reportID), requestData, http.StatusOK, sessionCookie)
+	return err
+}
+
+// DismissReport closes a report that needs no action
+func (s *ReportService) DismissReport(ctx context.Context, reportID, note string, sessionCookie *http.Cookie) error {
+	requestData := map[string]interface{}{"note": note}
+	_, err := s.doRequest(ctx, "PUT", "/reports/dismiss/"+url.PathEscape(reportID), requestData, http.StatusOK, sessionCookie)
+	return err
+}
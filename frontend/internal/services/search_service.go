@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"frontend-service/internal/models"
+)
+
+type SearchService struct {
+	*BaseClient
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(baseClient *BaseClient) *SearchService {
+	return &SearchService{
+		BaseClient: baseClient,
+	}
+}
+
+// Search runs a full-text search against the backend API
+func (s *SearchService) Search(ctx context.Context, query, searchType, categoryID, author string, limit, offset int, sessionCookie *http.Cookie) (*models.PaginatedSearchResponse, error) {
+	// Build URL with query parameters
+	u, err := url.Parse(s.BaseURL + "/search")
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Add query parameters
+	params := url.Values{}
+	params.Add("q", query)
+	params.Add("limit", fmt.Sprintf("%d", limit))
+	params.Add("offset", fmt.Sprintf("%d", offset))
+	if searchType != "" {
+		params.Add("type", searchType)
+	}
+	if categoryID != "" {
+		params.Add("category", categoryID)
+	}
+	if author != "" {
+		params.Add("author", author)
+	}
+	u.RawQuery = params.Encode()
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Add session cookie if provided
+	if sessionCookie != nil {
+		req.AddCookie(sessionCookie)
+	}
+
+	// Make HTTP request
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	// Parse JSON response
+	var apiResponse models.APIResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Check API success
+	if !apiResponse.Success {
+		return nil, fmt.Errorf("API error: %s", apiResponse.Error)
+	}
+
+	// Convert data to PaginatedSearchResponse
+	dataBytes, err := json.Marshal(apiResponse.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	var searchResponse models.PaginatedSearchResponse
+	if err := json.Unmarshal(dataBytes, &searchResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse search data: %w", err)
+	}
+
+	return &searchResponse, nil
+}
@@ -5,10 +5,22 @@ import (
 	"html/template"
 	"net/http"
 	"path/filepath"
+	"strings"
+	"sync"
 )
 
 type TemplateService struct {
 	templates *template.Template
+
+	// bound holds *boundTemplates for Render to reuse, so the set is not cloned per page
+	bound sync.Pool
+}
+
+// boundTemplates is a clone of the templates whose CSRF helpers read token, set by
+// each render that uses it
+type boundTemplates struct {
+	tmpl  *template.Template
+	token string
 }
 
 // NewTemplateService creates a new template service with helper functions
@@ -58,6 +70,36 @@ func NewTemplateService(templatesDir string) (*TemplateService, error) {
 		"printf": func(format string, args ...interface{}) string {
 			return fmt.Sprintf(format, args...)
 		},
+		// dict builds a map so nested templates (like the recursive comment tree) get several values
+		"dict": func(pairs ...interface{}) (map[string]interface{}, error) {
+			if len(pairs)%2 != 0 {
+				return nil, fmt.Errorf("dict needs an even number of arguments")
+			}
+			result := make(map[string]interface{}, len(pairs)/2)
+			for i := 0; i < len(pairs); i += 2 {
+				key, ok := pairs[i].(string)
+				if !ok {
+					return nil, fmt.Errorf("dict keys must be strings")
+				}
+				result[key] = pairs[i+1]
+			}
+			return result, nil
+		},
+		// highlight escapes a search snippet and keeps only the <mark> tags added by the API
+		"highlight": func(snippet string) template.HTML {
+			escaped := template.HTMLEscapeString(snippet)
+			escaped = strings.ReplaceAll(escaped, "&lt;mark&gt;", "<mark>")
+			escaped = strings.ReplaceAll(escaped, "&lt;/mark&gt;", "</mark>")
+			return template.HTML(escaped)
+		},
+		// csrfField and csrfToken are bound to the request's CSRF token in Render;
+		// every form that POSTs must include {{csrfField}}
+		"csrfField": func() template.HTML {
+			return ""
+		},
+		"csrfToken": func() string {
+			return ""
+		},
 	}
 
 	// Parse all templates in the directory with the function map
@@ -71,8 +113,55 @@ func NewTemplateService(templatesDir string) (*TemplateService, error) {
 	}, nil
 }
 
+// csrfTokener is implemented by the response writer of requests that passed the CSRF middleware
+type csrfTokener interface {
+	CSRFToken() string
+}
+
 // Render executes a template with the given data
 func (ts *TemplateService) Render(w http.ResponseWriter, templateName string, data interface{}) error {
+	return ts.RenderStatus(w, http.StatusOK, templateName, data)
+}
+
+// RenderStatus executes a template with the given data and HTTP status
+func (ts *TemplateService) RenderStatus(w http.ResponseWriter, status int, templateName string, data interface{}) error {
+	bound, err := ts.forRequest(w)
+	if err != nil {
+		return err
+	}
+	defer ts.bound.Put(bound)
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	return ts.templates.ExecuteTemplate(w, templateName, data)
-}
\ No newline at end of file
+	w.WriteHeader(status)
+	return bound.tmpl.ExecuteTemplate(w, templateName, data)
+}
+
+// forRequest returns templates with the CSRF helpers bound to this request's token,
+// cloning the parsed set only when no earlier clone is free. The parsed set is never
+// executed itself, so it can always be cloned.
+func (ts *TemplateService) forRequest(w http.ResponseWriter) (*boundTemplates, error) {
+	var token string
+	if t, ok := w.(csrfTokener); ok {
+		token = t.CSRFToken()
+	}
+
+	if bound, ok := ts.bound.Get().(*boundTemplates); ok {
+		bound.token = token
+		return bound, nil
+	}
+
+	tmpl, err := ts.templates.Clone()
+	if err != nil {
+		return nil, err
+	}
+	bound := &boundTemplates{token: token}
+	bound.tmpl = tmpl.Funcs(template.FuncMap{
+		"csrfField": func() template.HTML {
+			return template.HTML(`<input type="hidden" name="csrf_token" value="` + template.HTMLEscapeString(bound.token) + `">`)
+		},
+		"csrfToken": func() string {
+			return bound.token
+		},
+	})
+	return bound, nil
+}
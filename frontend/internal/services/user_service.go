@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,12 +23,12 @@ func NewUserService(baseClient *BaseClient) *UserService {
 }
 
 // GetUserProfile retrieves user profile with stats from the backend API
-func (s *UserService) GetUserProfile(userID string, sessionCookie *http.Cookie) (*models.UserProfile, error) {
+func (s *UserService) GetUserProfile(ctx context.Context, userID string, sessionCookie *http.Cookie) (*models.UserProfile, error) {
 	// Build URL for user profile
 	profileURL := s.BaseURL + "/users/profile/" + userID
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", profileURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", profileURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -38,7 +39,7 @@ func (s *UserService) GetUserProfile(userID string, sessionCookie *http.Cookie)
 	}
 
 	// Create HTTP request
-	req, err = http.NewRequest("GET", profileURL, nil)
+	req, err = http.NewRequestWithContext(ctx, "GET", profileURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -49,7 +50,7 @@ func (s *UserService) GetUserProfile(userID string, sessionCookie *http.Cookie)
 	}
 
 	// Create HTTP request
-	req, err = http.NewRequest("GET", profileURL, nil)
+	req, err = http.NewRequestWithContext(ctx, "GET", profileURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -73,17 +74,8 @@ func (s *UserService) GetUserProfile(userID string, sessionCookie *http.Cookie)
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: please log in")
-	}
-	if resp.StatusCode == http.StatusForbidden {
-		return nil, fmt.Errorf("forbidden: you can only view your own profile")
-	}
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("user not found")
-	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -112,7 +104,7 @@ func (s *UserService) GetUserProfile(userID string, sessionCookie *http.Cookie)
 }
 
 // GetUserPosts retrieves posts created by a specific user
-func (s *UserService) GetUserPosts(userID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedPostsResponse, error) {
+func (s *UserService) GetUserPosts(ctx context.Context, userID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedPostsResponse, error) {
 	// Build URL with query parameters
 	u, err := url.Parse(s.BaseURL + "/users/posts/" + userID)
 	if err != nil {
@@ -129,7 +121,7 @@ func (s *UserService) GetUserPosts(userID string, limit, offset int, sortBy stri
 	u.RawQuery = params.Encode()
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -153,14 +145,8 @@ func (s *UserService) GetUserPosts(userID string, limit, offset int, sortBy stri
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: please log in")
-	}
-	if resp.StatusCode == http.StatusForbidden {
-		return nil, fmt.Errorf("forbidden: you can only view your own posts")
-	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -189,7 +175,7 @@ func (s *UserService) GetUserPosts(userID string, limit, offset int, sortBy stri
 }
 
 // GetUserLikedPosts retrieves posts liked by a specific user
-func (s *UserService) GetUserLikedPosts(userID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedPostsResponse, error) {
+func (s *UserService) GetUserLikedPosts(ctx context.Context, userID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedPostsResponse, error) {
 	// Build URL with query parameters
 	u, err := url.Parse(s.BaseURL + "/users/liked-posts/" + userID)
 	if err != nil {
@@ -206,7 +192,7 @@ func (s *UserService) GetUserLikedPosts(userID string, limit, offset int, sortBy
 	u.RawQuery = params.Encode()
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -230,14 +216,8 @@ func (s *UserService) GetUserLikedPosts(userID string, limit, offset int, sortBy
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: please log in")
-	}
-	if resp.StatusCode == http.StatusForbidden {
-		return nil, fmt.Errorf("forbidden: you can only view your own liked posts")
-	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -266,7 +246,7 @@ func (s *UserService) GetUserLikedPosts(userID string, limit, offset int, sortBy
 }
 
 // GetUserCommentedPosts retrieves posts that a specific user has commented on
-func (s *UserService) GetUserCommentedPosts(userID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedPostsResponse, error) {
+func (s *UserService) GetUserCommentedPosts(ctx context.Context, userID string, limit, offset int, sortBy string, sessionCookie *http.Cookie) (*models.PaginatedPostsResponse, error) {
 	// Build URL with query parameters
 	u, err := url.Parse(s.BaseURL + "/users/commented-posts/" + userID)
 	if err != nil {
@@ -283,7 +263,7 @@ func (s *UserService) GetUserCommentedPosts(userID string, limit, offset int, so
 	u.RawQuery = params.Encode()
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -307,14 +287,8 @@ func (s *UserService) GetUserCommentedPosts(userID string, limit, offset int, so
 	}
 
 	// Check for HTTP errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: please log in")
-	}
-	if resp.StatusCode == http.StatusForbidden {
-		return nil, fmt.Errorf("forbidden: you can only view your own commented posts")
-	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Parse JSON response
@@ -1,23 +1,58 @@
 package session
 
 import (
+	"context"
 	"net/http"
 
 	"frontend-service/internal/models"
 	"frontend-service/internal/services"
 )
 
+type contextKey string
+
+const renewalContextKey contextKey = "session-renewal"
+
+// Renewal tracks the session cookie during one request. The backend may renew a session
+// or replace its ID when validating it; later API calls in the request must use the new
+// ID and the browser must be sent it.
+type Renewal struct {
+	validated bool
+	cookie    *http.Cookie // the cookie the backend issued, nil while unchanged
+}
+
+// WithRenewal returns a request that tracks session renewals for the response to send
+func WithRenewal(r *http.Request) (*http.Request, *Renewal) {
+	renewal := &Renewal{}
+	return r.WithContext(context.WithValue(r.Context(), renewalContextKey, renewal)), renewal
+}
+
+// Cookie returns the session cookie the backend issued during the request, or nil
+func (rn *Renewal) Cookie() *http.Cookie {
+	return rn.cookie
+}
+
+func renewalFrom(r *http.Request) *Renewal {
+	renewal, _ := r.Context().Value(renewalContextKey).(*Renewal)
+	return renewal
+}
+
 // GetUserFromSession gets user from session cookie and validates with backend
 func GetUserFromSession(r *http.Request, authService *services.AuthService) *models.User {
 	// Get session cookie using the session name from auth service
-	cookie, err := r.Cookie(authService.SessionName) // CHANGED: Use SessionName from authService instead of hardcoded "session_id"
+	cookie, err := currentCookie(r, authService)
 	if err != nil {
 		// No session cookie found
 		return nil
 	}
 
 	// Validate session with backend
-	user, err := authService.ValidateSession(cookie.Value)
+	user, renewed, err := authService.ValidateSession(r.Context(), cookie.Value, r.UserAgent())
+	if renewal := renewalFrom(r); renewal != nil {
+		renewal.validated = true
+		if renewed != nil {
+			renewal.cookie = renewed
+		}
+	}
 	if err != nil {
 		// Session is invalid or expired
 		return nil
@@ -26,8 +61,20 @@ func GetUserFromSession(r *http.Request, authService *services.AuthService) *mod
 	return user
 }
 
-// GetSessionCookie is a helper function to get the session cookie by name from auth service
+// GetSessionCookie is a helper function to get the session cookie by name from auth service.
+// The session is validated first if the request has not done so yet, so any new session ID
+// is known before the cookie is sent on to the backend.
 func GetSessionCookie(r *http.Request, authService *services.AuthService) (*http.Cookie, error) {
-	// ADDED: Helper function to get session cookie using the correct name from config
+	if renewal := renewalFrom(r); renewal != nil && !renewal.validated {
+		GetUserFromSession(r, authService)
+	}
+	return currentCookie(r, authService)
+}
+
+// currentCookie returns the session cookie as the backend last issued it
+func currentCookie(r *http.Request, authService *services.AuthService) (*http.Cookie, error) {
+	if renewal := renewalFrom(r); renewal != nil && renewal.cookie != nil {
+		return &http.Cookie{Name: authService.SessionName, Value: renewal.cookie.Value}, nil
+	}
 	return r.Cookie(authService.SessionName)
 }
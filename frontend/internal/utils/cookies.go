@@ -30,3 +30,20 @@ func SetSessionCookie(name, value string, w http.ResponseWriter, r *http.Request
 		SameSite: http.SameSiteLaxMode, // Consistent with frontend
 	})
 }
+
+// OAuthBindingCookie must match the cookie name the backend's OAuth callback reads
+const OAuthBindingCookie = "oauth_binding"
+
+// SetOAuthBindingCookie stores the value that ties a pending OAuth login to this browser.
+// It is host-only, so on localhost it is also sent to the backend's callback on another port.
+func SetOAuthBindingCookie(value string, w http.ResponseWriter, expiresAt time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OAuthBindingCookie,
+		Value:    value,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   false,                // Set to true in production with HTTPS
+		SameSite: http.SameSiteLaxMode, // Lax so it is sent on the provider's redirect back
+	})
+}
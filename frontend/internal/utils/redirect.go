@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SafeReturnTo returns returnTo if it is a path on this site, otherwise the home page.
+// It guards redirects taken from query parameters against being pointed at other sites.
+func SafeReturnTo(returnTo string) string {
+	if returnTo == "" || len(returnTo) > 512 {
+		return "/"
+	}
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.ContainsAny(returnTo, "\\\r\n\t") {
+		return "/"
+	}
+
+	u, err := url.Parse(returnTo)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil {
+		return "/"
+	}
+	return returnTo
+}
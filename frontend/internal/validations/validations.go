@@ -77,6 +77,31 @@ func ValidateUserInput(username, email, password string) error {
 	return nil
 }
 
+func ValidatePostTitle(title string) error {
+	// Title validation using configuration
+	minTitleLen := 5
+	maxTitleLen := 100
+
+	if len(title) < minTitleLen || len(title) > maxTitleLen {
+		return errors.New("post title must be between 5 and 100 characters")
+	}
+
+	// Titles are a single line
+	if strings.ContainsAny(title, "\r\n") {
+		return errors.New("post title must be a single line")
+	}
+
+	// Check for prohibited words (example)
+	prohibitedWords := []string{"fuck", "bitch", "asshole"}
+	for _, word := range prohibitedWords {
+		if strings.Contains(strings.ToLower(title), word) {
+			return errors.New("post title contains prohibited words")
+		}
+	}
+
+	return nil
+}
+
 func ValidatePostContent(content string) error {
 	// Content validation using configuration
 	minContentLen := 10
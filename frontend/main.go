@@ -1,11 +1,15 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"frontend-service/config"
+	"frontend-service/internal/logging"
 	"frontend-service/internal/routes"
 	"frontend-service/internal/services"
 )
@@ -13,6 +17,7 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	logging.Setup(os.Stdout, cfg.LogLevel)
 
 	// Create base client
 	baseClient := services.NewBaseClient(cfg.APIBaseURL)
@@ -25,28 +30,61 @@ func main() {
 	commentService := services.NewCommentService(baseClient)
 	postReactionService := services.NewPostReactionService(baseClient)
 	commentReactionService := services.NewCommentReactionService(baseClient)
+	searchService := services.NewSearchService(baseClient)
+	reportService := services.NewReportService(baseClient)
 
 	// Create template service
 	templateService, err := services.NewTemplateService(cfg.TemplatesDir)
 	if err != nil {
-		log.Fatal("Failed to create template service:", err)
+		fatal("Failed to create template service", err)
 	}
 
 	// Setup routes with all services including config for session name
 	mux := routes.SetupRoutes(authService, postService, categoryService,
 		userService, commentService, postReactionService,
-		commentReactionService, templateService, cfg) // CHANGED: Pass config
+		commentReactionService, searchService, reportService, templateService, cfg) // CHANGED: Pass config
 
 	// Create server
 	server := &http.Server{
-		Addr:    ":" + cfg.Port,
-		Handler: mux,
+		Addr:              ":" + cfg.Port,
+		Handler:           mux,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
 	}
 
-	fmt.Printf("Frontend server starting on port %s\n", cfg.Port)
-	fmt.Printf("Backend API URL: %s\n", cfg.APIBaseURL)
-	fmt.Printf("Session Cookie Name: %s\n", cfg.SessionName) // ADDED: Debug info
+	slog.Info("Starting frontend server", "port", cfg.Port, "api_base_url", cfg.APIBaseURL,
+		"session_cookie_name", cfg.SessionName, "log_level", cfg.LogLevel.String())
 
-	// Start server
-	log.Fatal(server.ListenAndServe())
+	// Start server, then on SIGINT or SIGTERM let in-flight requests finish
+	stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		fatal("Server failed", err)
+	case <-stop.Done():
+	}
+
+	slog.Info("Shutting down", "timeout", cfg.ShutdownTimeout.String())
+	ctx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("Error draining requests", "err", err)
+		return
+	}
+	slog.Info("Server stopped")
+}
+
+// fatal logs an error that stops the server from starting or running, and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }